@@ -28,6 +28,7 @@ import (
 	"github.com/konveyor/mig-controller/pkg/webhook"
 	"github.com/konveyor/mig-controller/pkg/zapmod"
 	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
@@ -87,6 +88,10 @@ func main() {
 		log.Error(err, "unable to add OpenShift route APIs to scheme")
 		os.Exit(1)
 	}
+	if err := buildv1.AddToScheme(mgr.GetScheme()); err != nil {
+		log.Error(err, "unable to add OpenShift build APIs to scheme")
+		os.Exit(1)
+	}
 	if err := conversion.RegisterConversions(mgr.GetScheme()); err != nil {
 		log.Error(err, "unable to register nessesary conversions")
 		os.Exit(1)
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The transfer agent copies the data of direct volume migrations. The server runs in the
+// transfer Pod of the destination cluster, the client in the client Pod of each source PVC.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/transfer"
+)
+
+// stringList flag set several times
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s server|client [flags]\n", os.Args[0])
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "server":
+		err = runServer(os.Args[2:])
+	case "client":
+		err = runClient(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected server or client\n", os.Args[1])
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func certFlags(flags *flag.FlagSet) *transfer.Certs {
+	certs := &transfer.Certs{}
+	flags.StringVar(&certs.CertFile, "cert", "/etc/transfer/certs/tls.crt", "certificate file")
+	flags.StringVar(&certs.KeyFile, "key", "/etc/transfer/certs/tls.key", "key file")
+	flags.StringVar(&certs.CAFile, "ca", "/etc/transfer/certs/ca.crt", "CA bundle file")
+	return certs
+}
+
+func runServer(args []string) error {
+	flags := flag.NewFlagSet("server", flag.ExitOnError)
+	listen := flags.String("listen", fmt.Sprintf(":%d", transfer.DefaultPort), "listen address")
+	root := flags.String("root", "/mnt", "directory holding the destination volumes")
+	certs := certFlags(flags)
+	_ = flags.Parse(args)
+	config, err := certs.ServerTLSConfig()
+	if err != nil {
+		return err
+	}
+	server := &transfer.Server{
+		Root:      *root,
+		TLSConfig: config,
+		Log:       log.New(os.Stderr, "", log.LstdFlags),
+	}
+	return server.ListenAndServe(*listen)
+}
+
+func runClient(args []string) error {
+	flags := flag.NewFlagSet("client", flag.ExitOnError)
+	addresses := stringList{}
+	filters := migapi.RsyncFilters{}
+	source := flags.String("source", "", "source directory, or device file with --block")
+	block := flags.Bool("block", false, "transfer the content of a block device")
+	volume := flags.String("volume", "", "name of the destination volume on the server")
+	flags.Var(&addresses, "address", "host:port of the server, may be repeated")
+	proxy := flags.String("proxy", "", "HTTP proxy URL the connections are tunneled through")
+	streams := flags.Int("streams", transfer.DefaultStreams, "number of parallel connections")
+	checksum := flags.Bool("checksum", false, "compare the checksums of files whose size and modification time match")
+	del := flags.Bool("delete", false, "delete the destination files missing from the source")
+	flags.Var((*stringList)(&filters.Include), "include", "Rsync pattern of the files included, may be repeated")
+	flags.Var((*stringList)(&filters.Exclude), "exclude", "Rsync pattern of the files excluded, may be repeated")
+	bwLimit := flags.Int64("bwlimit", 0, "bandwidth limit in KiB/s, 0 for no limit")
+	connectTimeout := flags.Duration("connect-timeout", 10*time.Minute, "time during which the connection is retried")
+	progressInterval := flags.Duration("progress-interval", 5*time.Second, "interval of the progress lines")
+	certs := certFlags(flags)
+	_ = flags.Parse(args)
+	if *source == "" || *volume == "" || len(addresses) == 0 {
+		return fmt.Errorf("--source, --volume and --address are required")
+	}
+	err := filters.Validate()
+	if err != nil {
+		return err
+	}
+	config, err := certs.ClientTLSConfig()
+	if err != nil {
+		return err
+	}
+	client := &transfer.Client{
+		Source:           *source,
+		Block:            *block,
+		Volume:           *volume,
+		Addresses:        addresses,
+		TLSConfig:        config,
+		Streams:          *streams,
+		Checksum:         *checksum,
+		Delete:           *del,
+		BandwidthLimit:   *bwLimit * 1024,
+		ConnectTimeout:   *connectTimeout,
+		ProgressInterval: *progressInterval,
+		Output:           os.Stdout,
+	}
+	if *proxy != "" {
+		client.Proxy, err = url.Parse(*proxy)
+		if err != nil {
+			return err
+		}
+	}
+	if !filters.IsEmpty() {
+		client.Exclude = func(path string, dir bool) bool {
+			if dir {
+				return filters.ExcludesDir(path)
+			}
+			return filters.Excludes(path)
+		}
+	}
+	return client.Run(context.Background())
+}
@@ -0,0 +1,237 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BandwidthSchedule time windows limiting the bandwidth of the direct volume transfers.
+// The first window matching the current time applies, the default Rsync bandwidth limit
+// of the MigrationController applies outside of the windows.
+type BandwidthSchedule struct {
+	// TimeZone IANA name of the time zone of the windows, UTC when not set
+	TimeZone string `json:"timeZone,omitempty"`
+	// Windows time windows with their bandwidth limits
+	Windows []BandwidthWindow `json:"windows,omitempty"`
+}
+
+// BandwidthWindow bandwidth limits applied during a time window of the week.
+// A window ending before it starts spans midnight, a window starting when it ends lasts the whole day.
+type BandwidthWindow struct {
+	// Days days of the week the window starts on (Mon, Tue, Wed, Thu, Fri, Sat, Sun), every day when not set
+	Days []string `json:"days,omitempty"`
+	// Start time of the day the window starts at, formatted as HH:MM
+	Start string `json:"start"`
+	// End time of the day the window ends at, formatted as HH:MM
+	End string `json:"end"`
+	// PodLimit bandwidth limit of each transfer Pod in KiB/s, not limited when not set
+	PodLimit *int64 `json:"podLimit,omitempty"`
+	// AggregateLimit bandwidth limit in KiB/s shared by the transfer Pods of a migration, not limited when not set
+	AggregateLimit *int64 `json:"aggregateLimit,omitempty"`
+}
+
+// EffectiveBandwidthLimit bandwidth limit applied to the transfer Pods
+type EffectiveBandwidthLimit struct {
+	// PodLimit bandwidth limit of each transfer Pod in KiB/s, -1 when not limited
+	PodLimit int64 `json:"podLimit"`
+	// Window index of the schedule window in effect, not set outside of the windows
+	Window *int `json:"window,omitempty"`
+	// NextChange time at which the schedule selects another window
+	NextChange *metav1.Time `json:"nextChange,omitempty"`
+}
+
+var weekDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// IsEmpty returns whether no window is set.
+func (r *BandwidthSchedule) IsEmpty() bool {
+	return r == nil || len(r.Windows) == 0
+}
+
+// Validate returns an error for the first invalid setting.
+func (r *BandwidthSchedule) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if _, err := r.getLocation(); err != nil {
+		return err
+	}
+	for i, window := range r.Windows {
+		if err := window.validate(); err != nil {
+			return fmt.Errorf("window %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// GetWindow returns the index of the window in effect at a time, -1 outside of the windows,
+// and the next time another window is selected, nil when the selection never changes.
+func (r *BandwidthSchedule) GetWindow(now time.Time) (int, *time.Time) {
+	if r.IsEmpty() {
+		return -1, nil
+	}
+	location, err := r.getLocation()
+	if err != nil {
+		return -1, nil
+	}
+	now = now.In(location)
+	current := r.getWindowAt(now)
+	// the selection only changes when a window starts or ends
+	candidates := []time.Time{}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	for day := 0; day <= 7; day++ {
+		date := midnight.AddDate(0, 0, day)
+		for _, window := range r.Windows {
+			for _, clock := range []string{window.Start, window.End} {
+				minutes, err := parseClock(clock)
+				if err != nil {
+					continue
+				}
+				candidate := time.Date(date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, location)
+				if candidate.After(now) {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+	for _, candidate := range candidates {
+		if r.getWindowAt(candidate) != current {
+			next := candidate
+			return current, &next
+		}
+	}
+	return current, nil
+}
+
+// GetEffectiveLimit returns the bandwidth limit of each of the transfer Pods of a migration at a time.
+// The aggregate limit of a window is shared evenly by the Pods, defaultLimit applies outside of the windows.
+func (r *BandwidthSchedule) GetEffectiveLimit(now time.Time, pods int, defaultLimit int64) EffectiveBandwidthLimit {
+	index, next := r.GetWindow(now)
+	effective := EffectiveBandwidthLimit{PodLimit: defaultLimit}
+	if next != nil {
+		effective.NextChange = &metav1.Time{Time: *next}
+	}
+	if index < 0 {
+		return effective
+	}
+	effective.Window = &index
+	window := r.Windows[index]
+	effective.PodLimit = -1
+	if window.PodLimit != nil {
+		effective.PodLimit = *window.PodLimit
+	}
+	if window.AggregateLimit != nil {
+		if pods < 1 {
+			pods = 1
+		}
+		shared := *window.AggregateLimit / int64(pods)
+		if shared < 1 {
+			shared = 1
+		}
+		if effective.PodLimit == -1 || shared < effective.PodLimit {
+			effective.PodLimit = shared
+		}
+	}
+	return effective
+}
+
+func (r *BandwidthSchedule) getLocation() (*time.Location, error) {
+	if r.TimeZone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", r.TimeZone)
+	}
+	return location, nil
+}
+
+// getWindowAt returns the index of the first window matching a time in the location of the schedule
+func (r *BandwidthSchedule) getWindowAt(now time.Time) int {
+	for i, window := range r.Windows {
+		if window.matches(now) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *BandwidthWindow) validate() error {
+	for _, day := range r.Days {
+		if _, found := weekDays[strings.ToLower(day)]; !found {
+			return fmt.Errorf("invalid day %q", day)
+		}
+	}
+	if _, err := parseClock(r.Start); err != nil {
+		return err
+	}
+	if _, err := parseClock(r.End); err != nil {
+		return err
+	}
+	if r.PodLimit != nil && *r.PodLimit < 1 {
+		return fmt.Errorf("podLimit must be >= 1")
+	}
+	if r.AggregateLimit != nil && *r.AggregateLimit < 1 {
+		return fmt.Errorf("aggregateLimit must be >= 1")
+	}
+	return nil
+}
+
+// matches returns whether the window is in effect at a time
+func (r *BandwidthWindow) matches(now time.Time) bool {
+	start, err := parseClock(r.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(r.End)
+	if err != nil {
+		return false
+	}
+	minutes := now.Hour()*60 + now.Minute()
+	today := r.startsOn(now.Weekday())
+	switch {
+	case start == end:
+		return today
+	case start < end:
+		return today && minutes >= start && minutes < end
+	default:
+		// the window started the day before and ends after midnight
+		yesterday := r.startsOn((now.Weekday() + 6) % 7)
+		return (today && minutes >= start) || (yesterday && minutes < end)
+	}
+}
+
+func (r *BandwidthWindow) startsOn(weekday time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, day := range r.Days {
+		if weekDays[strings.ToLower(day)] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock returns the minutes since midnight of a time of the day formatted as HH:MM
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,140 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+)
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestBandwidthSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule *BandwidthSchedule
+		wantErr  bool
+	}{
+		{
+			name:     "no schedule",
+			schedule: nil,
+			wantErr:  false,
+		},
+		{
+			name: "valid windows",
+			schedule: &BandwidthSchedule{
+				TimeZone: "Europe/Paris",
+				Windows: []BandwidthWindow{
+					{Days: []string{"Mon", "fri"}, Start: "08:00", End: "18:30", PodLimit: int64Ptr(1024)},
+					{Start: "22:00", End: "06:00", AggregateLimit: int64Ptr(102400)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "invalid time zone",
+			schedule: &BandwidthSchedule{TimeZone: "Mars/Olympus"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid day",
+			schedule: &BandwidthSchedule{Windows: []BandwidthWindow{{Days: []string{"Funday"}, Start: "08:00", End: "18:00"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid time",
+			schedule: &BandwidthSchedule{Windows: []BandwidthWindow{{Start: "8am", End: "18:00"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "zero limit",
+			schedule: &BandwidthSchedule{Windows: []BandwidthWindow{{Start: "08:00", End: "18:00", PodLimit: int64Ptr(0)}}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schedule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBandwidthSchedule_GetEffectiveLimit(t *testing.T) {
+	// business hours on week days, unlimited nights
+	schedule := &BandwidthSchedule{
+		Windows: []BandwidthWindow{
+			{Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "08:00", End: "18:00", PodLimit: int64Ptr(1000), AggregateLimit: int64Ptr(3000)},
+			{Days: []string{"Fri"}, Start: "22:00", End: "06:00"},
+		},
+	}
+	date := func(day int, hour int, minute int) time.Time {
+		// 2021-05-17 is a Monday
+		return time.Date(2021, 5, 17+day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name           string
+		now            time.Time
+		pods           int
+		wantLimit      int64
+		wantWindow     int
+		wantNextChange time.Time
+	}{
+		{
+			name:           "business hours with few pods",
+			now:            date(0, 9, 30),
+			pods:           2,
+			wantLimit:      1000,
+			wantWindow:     0,
+			wantNextChange: date(0, 18, 0),
+		},
+		{
+			name:           "business hours with many pods share the aggregate limit",
+			now:            date(2, 17, 59),
+			pods:           6,
+			wantLimit:      500,
+			wantWindow:     0,
+			wantNextChange: date(2, 18, 0),
+		},
+		{
+			name:           "week night uses the default limit",
+			now:            date(0, 20, 0),
+			pods:           2,
+			wantLimit:      2048,
+			wantWindow:     -1,
+			wantNextChange: date(1, 8, 0),
+		},
+		{
+			name:           "window spanning midnight",
+			now:            date(5, 2, 0),
+			pods:           2,
+			wantLimit:      -1,
+			wantWindow:     1,
+			wantNextChange: date(5, 6, 0),
+		},
+		{
+			name:           "week end until monday morning",
+			now:            date(5, 6, 0),
+			pods:           2,
+			wantLimit:      2048,
+			wantWindow:     -1,
+			wantNextChange: date(7, 8, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schedule.GetEffectiveLimit(tt.now, tt.pods, 2048)
+			window := -1
+			if got.Window != nil {
+				window = *got.Window
+			}
+			if got.PodLimit != tt.wantLimit || window != tt.wantWindow {
+				t.Errorf("GetEffectiveLimit() = %d, window %d, want %d, window %d", got.PodLimit, window, tt.wantLimit, tt.wantWindow)
+			}
+			if got.NextChange == nil || !got.NextChange.Time.Equal(tt.wantNextChange) {
+				t.Errorf("GetEffectiveLimit() next change = %v, want %v", got.NextChange, tt.wantNextChange)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	liberr "github.com/konveyor/controller/pkg/error"
 	imagev1 "github.com/openshift/api/image/v1"
 	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,20 +38,34 @@ type DirectImageMigrationSpec struct {
 
 	// Holds names of all namespaces to run DIM to get all the imagestreams in these namespaces.
 	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Selects the ImageStream tags and history items copied by the DirectImageStreamMigrations.
+	TagFilter *ImageStreamTagFilter `json:"tagFilter,omitempty"`
+
+	// Registry the images are copied to, defaults to the image registry of the destination cluster.
+	DestImageRegistry *ImageRegistry `json:"destImageRegistry,omitempty"`
+
+	// If set True, the verification of the copied images checks that their source signatures were copied.
+	VerifySignatures bool `json:"verifySignatures,omitempty"`
 }
 
 // DirectImageMigrationStatus defines the observed state of DirectImageMigration
 type DirectImageMigrationStatus struct {
 	Conditions     `json:","`
-	ObservedDigest string                 `json:"observedDigest,omitempty"`
-	StartTimestamp *metav1.Time           `json:"startTimestamp,omitempty"`
-	Phase          string                 `json:"phase,omitempty"`
-	Itinerary      string                 `json:"itinerary,omitempty"`
-	Errors         []string               `json:"errors,omitempty"`
-	NewISs         []*ImageStreamListItem `json:"newISs,omitempty"`
-	SuccessfulISs  []*ImageStreamListItem `json:"successfulISs,omitempty"`
-	DeletedISs     []*ImageStreamListItem `json:"deletedISs,omitempty"`
-	FailedISs      []*ImageStreamListItem `json:"failedISs,omitempty"`
+	ObservedDigest string                   `json:"observedDigest,omitempty"`
+	StartTimestamp *metav1.Time             `json:"startTimestamp,omitempty"`
+	Phase          string                   `json:"phase,omitempty"`
+	Itinerary      string                   `json:"itinerary,omitempty"`
+	Errors         []string                 `json:"errors,omitempty"`
+	NewISs         []*ImageStreamListItem   `json:"newISs,omitempty"`
+	SuccessfulISs  []*ImageStreamListItem   `json:"successfulISs,omitempty"`
+	DeletedISs     []*ImageStreamListItem   `json:"deletedISs,omitempty"`
+	FailedISs      []*ImageStreamListItem   `json:"failedISs,omitempty"`
+	WorkloadImages []*WorkloadImageListItem `json:"workloadImages,omitempty"`
+	// Number of bytes copied to the destination registry by the DirectImageStreamMigrations
+	BytesCopied int64 `json:"bytesCopied,omitempty"`
+	// Numbers of images verified by the DirectImageStreamMigrations
+	ImageVerificationResult `json:",inline"`
 }
 
 type ImageStreamListItem struct {
@@ -61,6 +76,21 @@ type ImageStreamListItem struct {
 	Errors                []string              `json:"errors,omitempty"`
 }
 
+// WorkloadImageListItem an image of the source internal registry referenced by the
+// pod templates of workloads and not provided by a migrated ImageStream
+type WorkloadImageListItem struct {
+	// Source namespace of the workloads
+	Namespace string `json:"namespace"`
+	// Destination namespace of the workloads, the image is copied into this namespace
+	DestNamespace string `json:"destNamespace,omitempty"`
+	// Reference of the image on the source internal registry
+	SourceReference string `json:"sourceReference"`
+	// Reference of the copied image on the destination internal registry
+	DestReference string   `json:"destReference,omitempty"`
+	Copied        bool     `json:"copied,omitempty"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -92,6 +122,11 @@ func (r *DirectImageMigration) GetDestinationCluster(client k8sclient.Client) (*
 	return GetCluster(client, r.Spec.DestMigClusterRef)
 }
 
+// GetDestinationImageRegistry get the registry the images are copied to, nil for the destination internal registry
+func (r *DirectImageMigration) GetDestinationImageRegistry(client k8sclient.Client) (*ImageRegistry, error) {
+	return getDestinationImageRegistry(client, r.Spec.DestImageRegistry, r.Spec.DestMigClusterRef)
+}
+
 // Get the MigMigration that owns this DirectImageMigration. If not owned, return nil.
 func (r *DirectImageMigration) GetMigrationForDIM(client k8sclient.Client) (*MigMigration, error) {
 	owner := &MigMigration{}
@@ -204,18 +239,22 @@ func (r *DirectImageMigration) HasCompleted() (bool, []string, []string) {
 	}
 
 	totalISs := successfulISs + deletedISs + failedISs + newISs
-	dimProgress := fmt.Sprintf("%v total ImageStreams; %v running; %v successful; %v failed%v",
+	dimProgress := fmt.Sprintf("%v total ImageStreams; %v running; %v successful; %v failed%v; %v copied; %v images verified; %v images failed verification",
 		totalISs,
 		newISs,
 		successfulISs,
 		failedISs,
-		deletedMsg)
+		deletedMsg,
+		resource.NewQuantity(r.Status.BytesCopied, resource.BinarySI).String(),
+		r.Status.VerifiedImages,
+		r.Status.FailedVerifications)
 	progress = append(progress, dimProgress)
 
 	progress = append(progress, r.getDISMProgress(r.Status.NewISs, "Running")...)
 	progress = append(progress, r.getDISMProgress(r.Status.SuccessfulISs, "Completed")...)
 	progress = append(progress, r.getDISMProgress(r.Status.FailedISs, "Failed")...)
 	progress = append(progress, r.getDISMProgress(r.Status.DeletedISs, "Deleted")...)
+	progress = append(progress, r.getWorkloadImageProgress()...)
 
 	return completed, reasons, progress
 }
@@ -234,6 +273,22 @@ func (r *DirectImageMigration) getDISMProgress(items []*ImageStreamListItem, sta
 	return progress
 }
 
+func (r *DirectImageMigration) getWorkloadImageProgress() []string {
+	progress := []string{}
+	for _, item := range r.Status.WorkloadImages {
+		state := "Running"
+		switch {
+		case len(item.Errors) > 0:
+			state = "Failed"
+		case item.Copied:
+			state = "Completed"
+		}
+		progress = append(progress, fmt.Sprintf("Workload image %s (namespace %s): %s ",
+			item.SourceReference, item.Namespace, state))
+	}
+	return progress
+}
+
 func init() {
 	SchemeBuilder.Register(&DirectImageMigration{}, &DirectImageMigrationList{})
 }
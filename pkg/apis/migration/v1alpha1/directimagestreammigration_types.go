@@ -19,6 +19,9 @@ package v1alpha1
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path"
+	"strings"
 
 	liberr "github.com/konveyor/controller/pkg/error"
 	imagev1 "github.com/openshift/api/image/v1"
@@ -36,6 +39,15 @@ type DirectImageStreamMigrationSpec struct {
 
 	//  Holds the name of the namespace on destination cluster where imagestreams should be migrated.
 	DestNamespace string `json:"destNamespace,omitempty"`
+
+	// Selects the tags and history items of the ImageStream to copy.
+	TagFilter *ImageStreamTagFilter `json:"tagFilter,omitempty"`
+
+	// Registry the images are copied to, defaults to the image registry of the destination cluster.
+	DestImageRegistry *ImageRegistry `json:"destImageRegistry,omitempty"`
+
+	// If set True, the verification of the copied images checks that their source signatures were copied.
+	VerifySignatures bool `json:"verifySignatures,omitempty"`
 }
 
 // DirectImageStreamMigrationStatus defines the observed state of DirectImageStreamMigration
@@ -46,6 +58,133 @@ type DirectImageStreamMigrationStatus struct {
 	Phase          string       `json:"phase,omitempty"`
 	Itinerary      string       `json:"itinerary,omitempty"`
 	Errors         []string     `json:"errors,omitempty"`
+
+	// Numbers of tags and history items left out by the tag filter
+	ImageStreamTagFilterResult `json:",inline"`
+
+	// Copy status of the ImageStream tags
+	Tags []*ImageTagCopy `json:"tags,omitempty"`
+	// Copy status of the history items being copied or that failed, of the newest item of each tag
+	// and of the items whose manifest was converted, in copy order
+	Images []*ImageCopy `json:"images,omitempty"`
+	// Number of bytes copied to the destination registry
+	BytesCopied int64 `json:"bytesCopied,omitempty"`
+
+	// Numbers of copied images verified on the destination registry
+	ImageVerificationResult `json:",inline"`
+}
+
+// Image copy phases
+const (
+	ImageCopyPending         = "Pending"
+	ImageCopyCopied          = "Copied"
+	ImageCopySkippedExisting = "SkippedExisting"
+	ImageCopyFailed          = "Failed"
+)
+
+// Image verification results
+const (
+	ImageVerified           = "Verified"
+	ImageManifestConverted  = "ManifestConverted"
+	ImageDigestMismatch     = "DigestMismatch"
+	ImageSignaturesMissing  = "SignaturesMissing"
+	ImageVerificationFailed = "VerificationFailed"
+)
+
+// ImageVerificationResult numbers of copied images verified on the destination registry
+type ImageVerificationResult struct {
+	// VerifiedImages number of images matching their source
+	VerifiedImages int `json:"verifiedImages,omitempty"`
+	// ConvertedImages number of verified images whose manifest was converted to a schema accepted by the destination registry
+	ConvertedImages int `json:"convertedImages,omitempty"`
+	// FailedVerifications number of images not matching their source or failing to verify
+	FailedVerifications int `json:"failedVerifications,omitempty"`
+}
+
+// ImageTagCopy copy status of the history items of an ImageStream tag. The history items are
+// processed from the oldest, only the items listed in the images of the status have a status of their own.
+type ImageTagCopy struct {
+	// Tag of the ImageStream
+	Tag string `json:"tag"`
+	// Number of history items to copy
+	Items int `json:"items"`
+	// Number of history items processed, from the oldest
+	Processed int `json:"processed,omitempty"`
+	// Digest of the last processed history item, locates the next item when the history of the tag changed
+	LastDigest string `json:"lastDigest,omitempty"`
+	// Number of history items copied
+	Copied int `json:"copied,omitempty"`
+	// Number of history items skipped as already on the destination registry
+	SkippedExisting int `json:"skippedExisting,omitempty"`
+	// Number of history items that failed to copy
+	Failed int `json:"failed,omitempty"`
+	// Number of bytes of the processed history items copied to the destination registry
+	BytesCopied int64 `json:"bytesCopied,omitempty"`
+	// Number of processed history items verified, from the oldest
+	Verified int `json:"verified,omitempty"`
+	// Digest of the last verified history item, locates the next item when the history of the tag changed
+	LastVerifiedDigest string `json:"lastVerifiedDigest,omitempty"`
+}
+
+// IsDone returns whether all the history items of the tag have been processed
+func (r *ImageTagCopy) IsDone() bool {
+	return r.Processed >= r.Items
+}
+
+// ImageCopy copy status of a history item of an ImageStream tag
+type ImageCopy struct {
+	// Tag of the ImageStream
+	Tag string `json:"tag"`
+	// Digest of the image
+	Digest string `json:"digest,omitempty"`
+	// Reference of the image on the source registry
+	SourceReference string `json:"sourceReference"`
+	// Reference of the image on the destination registry
+	DestReference string `json:"destReference"`
+	// Phase Pending, Copied, SkippedExisting or Failed
+	Phase string `json:"phase"`
+	// Number of copy attempts
+	Attempts int `json:"attempts,omitempty"`
+	// Time of the next attempt of a pending image that failed to copy
+	NextAttempt *metav1.Time `json:"nextAttempt,omitempty"`
+	// Number of bytes of the image blobs copied to the destination registry
+	BytesCopied int64 `json:"bytesCopied,omitempty"`
+	// Error of the last failed attempt
+	Error string `json:"error,omitempty"`
+	// Manifest digest of the image written to the destination registry, differs from the digest
+	// when a schema1 manifest was converted on copy
+	CopiedDigest string `json:"copiedDigest,omitempty"`
+	// Manifest digest of the image found on the destination registry by the verification
+	DestDigest string `json:"destDigest,omitempty"`
+	// Verification Verified, ManifestConverted, DigestMismatch, SignaturesMissing or VerificationFailed
+	Verification string `json:"verification,omitempty"`
+}
+
+// IsDone returns whether the image no longer needs to be copied
+func (r *ImageCopy) IsDone() bool {
+	return r.Phase != ImageCopyPending
+}
+
+// IsCopied returns whether the image is on the destination registry
+func (r *ImageCopy) IsCopied() bool {
+	return r.Phase == ImageCopyCopied || r.Phase == ImageCopySkippedExisting
+}
+
+// GetDestDigest returns the manifest digest of the image on the destination registry
+func (r *ImageCopy) GetDestDigest() string {
+	if r.CopiedDigest != "" {
+		return r.CopiedDigest
+	}
+	return r.Digest
+}
+
+// GetDestDigestReference returns the reference by digest of the image on the destination registry
+func (r *ImageCopy) GetDestDigestReference() string {
+	reference := r.DestReference
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		reference = reference[:i]
+	}
+	return reference + "@" + r.GetDestDigest()
 }
 
 // +genclient
@@ -79,6 +218,11 @@ func (r *DirectImageStreamMigration) GetDestinationCluster(client k8sclient.Clie
 	return GetCluster(client, r.Spec.DestMigClusterRef)
 }
 
+// GetDestinationImageRegistry get the registry the images are copied to, nil for the destination internal registry
+func (r *DirectImageStreamMigration) GetDestinationImageRegistry(client k8sclient.Client) (*ImageRegistry, error) {
+	return getDestinationImageRegistry(client, r.Spec.DestImageRegistry, r.Spec.DestMigClusterRef)
+}
+
 // Get the DirectImageMigration that owns this DirectImageStreamMigration. If not owned, return nil.
 func (r *DirectImageStreamMigration) GetDIMforDISM(client k8sclient.Client) (*DirectImageMigration, error) {
 	owner := &DirectImageMigration{}
@@ -155,6 +299,18 @@ func (r *DirectImageStreamMigration) HasErrors() bool {
 	return len(r.Status.Errors) > 0
 }
 
+// GetImageCopyErrors returns the errors of the images that failed to copy
+func (r *DirectImageStreamMigration) GetImageCopyErrors() []string {
+	reasons := []string{}
+	for _, image := range r.Status.Images {
+		if image.Phase == ImageCopyFailed {
+			reasons = append(reasons, fmt.Sprintf("ImageStream %s tag %s (%s): %s",
+				path.Join(r.Spec.ImageStreamRef.Namespace, r.Spec.ImageStreamRef.Name), image.Tag, image.Digest, image.Error))
+		}
+	}
+	return reasons
+}
+
 // HasCompleted gets whether a DirectImageStreamMigration has completed and a list of errors, if any
 func (r *DirectImageStreamMigration) HasCompleted() (bool, []string) {
 	completed := r.Status.Phase == "Completed"
@@ -61,3 +61,22 @@ func TestStorageDirectImageStreamMigration(t *testing.T) {
 	g.Expect(c.Delete(context.TODO(), fetched)).NotTo(gomega.HaveOccurred())
 	g.Expect(c.Get(context.TODO(), key, fetched)).To(gomega.HaveOccurred())
 }
+
+func TestImageCopy_GetDestDigestReference(t *testing.T) {
+	tests := []struct {
+		reference    string
+		copiedDigest string
+		want         string
+	}{
+		{reference: "registry.example.com/dest/app:latest", want: "registry.example.com/dest/app@sha256:abc"},
+		{reference: "registry.example.com/dest/app:latest", copiedDigest: "sha256:def", want: "registry.example.com/dest/app@sha256:def"},
+		{reference: "registry.example.com/dest/app", want: "registry.example.com/dest/app@sha256:abc"},
+		{reference: "registry:5000/dest/app", want: "registry:5000/dest/app@sha256:abc"},
+	}
+	for _, tt := range tests {
+		image := ImageCopy{DestReference: tt.reference, Digest: "sha256:abc", CopiedDigest: tt.copiedDigest}
+		if got := image.GetDestDigestReference(); got != tt.want {
+			t.Errorf("GetDestDigestReference(%s) = %v, want %v", tt.reference, got, tt.want)
+		}
+	}
+}
@@ -18,7 +18,9 @@ package v1alpha1
 
 import (
 	"fmt"
+	"time"
 
+	migref "github.com/konveyor/mig-controller/pkg/reference"
 	kapi "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +32,54 @@ type PVCToMigrate struct {
 	TargetAccessModes     []kapi.PersistentVolumeAccessMode `json:"targetAccessModes"`
 	TargetNamespace       string                            `json:"targetNamespace,omitempty"`
 	Verify                bool                              `json:"verify,omitempty"`
+	// VolumeMode of the source PVC, Block volumes are attached to the Rsync Pods as raw devices
+	VolumeMode kapi.PersistentVolumeMode `json:"volumeMode,omitempty"`
+	// VolumeSnapshotClass of the source CSI driver, when set the PVC is copied from a temporary
+	// clone provisioned from a VolumeSnapshot of the source PVC instead of the source PVC itself
+	VolumeSnapshotClass string `json:"volumeSnapshotClass,omitempty"`
+	// Filters include and exclude patterns of the Rsync transfer, not applied to Block volumes
+	Filters *RsyncFilters `json:"filters,omitempty"`
+	// AdoptExisting whether an existing destination PVC is validated and reused instead of
+	// creating a new one, adopted PVCs are never deleted by a rollback
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+	// TargetName name of the destination PVC, defaults to the name of the source PVC
+	TargetName string `json:"targetName,omitempty"`
+}
+
+// GetTargetName returns the name of the destination PVC.
+func (r *PVCToMigrate) GetTargetName() string {
+	if r.TargetName != "" {
+		return r.TargetName
+	}
+	return r.Name
+}
+
+// IsBlock returns whether the PVC is a raw block volume.
+func (r *PVCToMigrate) IsBlock() bool {
+	return r.VolumeMode == kapi.PersistentVolumeBlock
+}
+
+// IsSnapshotSource returns whether the PVC is copied from a CSI VolumeSnapshot of the source PVC.
+func (r *PVCToMigrate) IsSnapshotSource() bool {
+	return r.VolumeSnapshotClass != ""
+}
+
+// TransferPodPolicy defines the placement and the resources of the Rsync transfer and client Pods
+type TransferPodPolicy struct {
+	// NodeSelector of the Rsync Pods. Not applied to the Rsync client Pods pinned to the node of their source volume
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the Rsync Pods
+	Tolerations []kapi.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the Rsync Pods. Not applied to the Rsync client Pods pinned to the node of their source volume
+	Affinity *kapi.Affinity `json:"affinity,omitempty"`
+	// PriorityClassName of the Rsync Pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// RsyncResources resources of the Rsync containers, override the TRANSFER_POD_* and CLIENT_POD_* settings
+	RsyncResources *kapi.ResourceRequirements `json:"rsyncResources,omitempty"`
+	// StunnelResources resources of the Stunnel containers, override the STUNNEL_POD_* settings
+	StunnelResources *kapi.ResourceRequirements `json:"stunnelResources,omitempty"`
+	// ImagePullSecrets used to pull the image of the Rsync Pods
+	ImagePullSecrets []kapi.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 // DirectVolumeMigrationSpec defines the desired state of DirectVolumeMigration
@@ -48,8 +98,84 @@ type DirectVolumeMigrationSpec struct {
 
 	// Specifies if progress reporting CRs needs to be deleted or not
 	DeleteProgressReportingCRs bool `json:"deleteProgressReportingCRs,omitempty"`
+
+	// Set true to keep the Rsync transfer resources running and repeat incremental Rsync passes until cutover
+	Continuous bool `json:"continuous,omitempty"`
+
+	// ReplicationIntervalSeconds interval between continuous Rsync passes, defaults to 300
+	ReplicationIntervalSeconds int `json:"replicationIntervalSeconds,omitempty"`
+
+	// Set true to run one last Rsync pass and complete a continuous migration
+	Cutover bool `json:"cutover,omitempty"`
+
+	// Set true when the applications using the source volumes are quiesced during the final Rsync pass
+	SourceQuiesced bool `json:"sourceQuiesced,omitempty"`
+
+	// Set true to only report as a warning the verification mismatches of PVCs copied while in use, as their
+	// files may change during the transfer. Mismatches of PVCs copied from a snapshot, or during the final
+	// Rsync pass with the applications quiesced, always fail the migration.
+	TolerateLiveSourceMismatches bool `json:"tolerateLiveSourceMismatches,omitempty"`
+
+	// VerificationSampleSize maximum number of files checksummed when verifying a PVC, larger volumes are sampled.
+	// Defaults to 10000, set -1 to always checksum all files
+	VerificationSampleSize int `json:"verificationSampleSize,omitempty"`
+
+	// TransferPodPolicy placement and resources of the Rsync Pods, defaults to the policy of the MigPlan
+	TransferPodPolicy *TransferPodPolicy `json:"transferPodPolicy,omitempty"`
+
+	// Set true to only check the TLS connectivity and measure the throughput between the source nodes
+	// hosting the PVCs and the destination cluster, no PVC is created and no data is migrated
+	Preflight bool `json:"preflight,omitempty"`
+
+	// TransferMethod tool copying the data of the PVCs, either Rsync or TransferAgent, defaults to Rsync
+	TransferMethod string `json:"transferMethod,omitempty"`
+
+	// BandwidthSchedule time windows limiting the bandwidth of the transfer Pods
+	BandwidthSchedule *BandwidthSchedule `json:"bandwidthSchedule,omitempty"`
+
+	// OwnershipPolicy how the file ownership is kept writable when the UID range of the destination namespace
+	// differs from the source namespace, either Preserve or Remap. Not set, the ownership is copied unchanged
+	OwnershipPolicy string `json:"ownershipPolicy,omitempty"`
+}
+
+// Direct volume transfer methods
+const (
+	// RsyncTransferMethod copies the data with Rsync tunneled through Stunnel
+	RsyncTransferMethod = "Rsync"
+	// TransferAgentMethod copies the data with the transfer agent over mutual TLS
+	TransferAgentMethod = "TransferAgent"
+)
+
+// GetTransferMethod returns the transfer method of the DVM, Rsync when not set
+func (r *DirectVolumeMigration) GetTransferMethod() string {
+	if r.Spec.TransferMethod == "" {
+		return RsyncTransferMethod
+	}
+	return r.Spec.TransferMethod
 }
 
+// File ownership policies
+const (
+	// PreserveOwnershipPolicy sets the UID, supplemental groups and MCS annotations of the source namespaces
+	// on the destination namespaces, including the namespaces that already exist
+	PreserveOwnershipPolicy = "Preserve"
+	// RemapOwnershipPolicy shifts the ownership of the copied files from the UID and supplemental groups
+	// ranges of the source namespace to the ranges of the destination namespace
+	RemapOwnershipPolicy = "Remap"
+)
+
+// DefaultReplicationIntervalSeconds default interval between continuous Rsync passes
+const DefaultReplicationIntervalSeconds = 300
+
+// MaxRsyncPassHistory number of Rsync passes retained in the status of an Rsync operation
+const MaxRsyncPassHistory = 10
+
+// DefaultVerificationSampleSize default maximum number of files checksummed when verifying a PVC
+const DefaultVerificationSampleSize = 10000
+
+// MaxVerificationMismatchedPaths number of mismatched paths retained in the verification status of a PVC
+const MaxVerificationMismatchedPaths = 10
+
 // DirectVolumeMigrationStatus defines the observed state of DirectVolumeMigration
 type DirectVolumeMigrationStatus struct {
 	Conditions       `json:","`
@@ -64,6 +190,94 @@ type DirectVolumeMigrationStatus struct {
 	RunningPods      []*PodProgress    `json:"runningPods,omitempty"`
 	PendingPods      []*PodProgress    `json:"pendingPods,omitempty"`
 	RsyncOperations  []*RsyncOperation `json:"rsyncOperations,omitempty"`
+	// LastPassTimestamp completion time of the most recent continuous Rsync pass
+	LastPassTimestamp *metav1.Time `json:"lastPassTimestamp,omitempty"`
+	// CutoverStarted whether the final Rsync pass of a continuous migration has started
+	CutoverStarted bool `json:"cutoverStarted,omitempty"`
+	// VolumeVerifications results of the post-transfer verification of the PVCs
+	VolumeVerifications []*VolumeVerification `json:"volumeVerifications,omitempty"`
+	// RsyncStats statistics of the Rsync operations of all PVCs
+	RsyncStats *RsyncStats `json:"rsyncStats,omitempty"`
+	// NamespaceRsyncStats statistics of the Rsync operations of the PVCs of each namespace
+	NamespaceRsyncStats []*NamespaceRsyncStats `json:"namespaceRsyncStats,omitempty"`
+	// PreflightResults results of the network preflight check from each source node
+	PreflightResults []*NetworkPreflightResult `json:"preflightResults,omitempty"`
+	// BandwidthLimit bandwidth limit currently applied to the transfer Pods
+	BandwidthLimit *EffectiveBandwidthLimit `json:"bandwidthLimit,omitempty"`
+	// OwnershipRemaps results of the remapping of the file ownership of the PVCs
+	OwnershipRemaps []*OwnershipRemap `json:"ownershipRemaps,omitempty"`
+}
+
+// OwnershipRemap defines the result of the remapping of the file ownership of a PVC
+type OwnershipRemap struct {
+	// PVCReference pvc to which this remap corresponds to
+	PVCReference *kapi.ObjectReference `json:"pvcReference,omitempty"`
+	// UIDMapping source UID range mapped to the destination UID range, as <source start>/<size>:<destination start>
+	UIDMapping string `json:"uidMapping,omitempty"`
+	// GIDMapping source group range mapped to the destination group range, as <source start>/<size>:<destination start>
+	GIDMapping string `json:"gidMapping,omitempty"`
+	// Succeeded whether no file of the destination volume is owned by the source ranges anymore
+	Succeeded bool `json:"succeeded,omitempty"`
+	// Failed whether the remap or its verification failed
+	Failed bool `json:"failed,omitempty"`
+	// Message details about the result
+	Message string `json:"message,omitempty"`
+}
+
+// IsComplete whether the remap completed
+func (r *OwnershipRemap) IsComplete() bool {
+	return r.Succeeded || r.Failed
+}
+
+// NetworkPreflightResult defines the result of the network preflight check from a source node
+type NetworkPreflightResult struct {
+	// Node source node the check ran from
+	Node string `json:"node"`
+	// Connected whether the test data reached the destination through a TLS connection to the transfer endpoint
+	Connected bool `json:"connected"`
+	// Throughput measured throughput in bytes per second
+	Throughput int64 `json:"throughput,omitempty"`
+	// Message reason of a failed check
+	Message string `json:"message,omitempty"`
+}
+
+// NamespaceRsyncStats defines the statistics of the Rsync operations of the PVCs of a namespace
+type NamespaceRsyncStats struct {
+	// Namespace source namespace of the PVCs
+	Namespace  string `json:"namespace"`
+	RsyncStats `json:",inline"`
+}
+
+// GetVolumeVerificationForPVC returns VolumeVerification from status for matching PVC, creates new one if doesn't exist already
+func (ds *DirectVolumeMigrationStatus) GetVolumeVerificationForPVC(pvcRef *kapi.ObjectReference) *VolumeVerification {
+	for i := range ds.VolumeVerifications {
+		verification := ds.VolumeVerifications[i]
+		if verification.PVCReference.Namespace == pvcRef.Namespace &&
+			verification.PVCReference.Name == pvcRef.Name {
+			return verification
+		}
+	}
+	newStatus := &VolumeVerification{
+		PVCReference: pvcRef,
+	}
+	ds.VolumeVerifications = append(ds.VolumeVerifications, newStatus)
+	return newStatus
+}
+
+// GetOwnershipRemapForPVC returns OwnershipRemap from status for matching PVC, creates new one if doesn't exist already
+func (ds *DirectVolumeMigrationStatus) GetOwnershipRemapForPVC(pvcRef *kapi.ObjectReference) *OwnershipRemap {
+	for i := range ds.OwnershipRemaps {
+		remap := ds.OwnershipRemaps[i]
+		if remap.PVCReference.Namespace == pvcRef.Namespace &&
+			remap.PVCReference.Name == pvcRef.Name {
+			return remap
+		}
+	}
+	newStatus := &OwnershipRemap{
+		PVCReference: pvcRef,
+	}
+	ds.OwnershipRemaps = append(ds.OwnershipRemaps, newStatus)
+	return newStatus
 }
 
 // GetRsyncOperationStatusForPVC returns RsyncOperation from status for matching PVC, creates new one if doesn't exist already
@@ -94,6 +308,7 @@ func (ds *DirectVolumeMigrationStatus) AddRsyncOperation(podStatus *RsyncOperati
 			existing.CurrentAttempt = podStatus.CurrentAttempt
 			existing.Failed = podStatus.Failed
 			existing.Succeeded = podStatus.Succeeded
+			existing.Passes = podStatus.Passes
 			return
 		}
 	}
@@ -132,6 +347,7 @@ type PodProgress struct {
 	LastObservedProgressPercent string                `json:"lastObservedProgressPercent,omitempty"`
 	LastObservedTransferRate    string                `json:"lastObservedTransferRate,omitempty"`
 	TotalElapsedTime            *metav1.Duration      `json:"totalElapsedTime,omitempty"`
+	Stats                       *RsyncStats           `json:"stats,omitempty"`
 }
 
 // RsyncOperation defines observed state of an Rsync Operation
@@ -144,6 +360,67 @@ type RsyncOperation struct {
 	Succeeded bool `json:"succeeded,omitempty"`
 	// Failed whether operation as a whole failed
 	Failed bool `json:"failed,omitempty"`
+	// Passes most recent passes of a continuous Rsync operation
+	Passes []RsyncPass `json:"passes,omitempty"`
+}
+
+// RsyncPass defines observed state of one pass of a continuous Rsync operation
+type RsyncPass struct {
+	// Number sequence number of the pass
+	Number int `json:"number"`
+	// StartTimestamp time at which the Rsync container started
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// Duration time taken by the pass
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// DeltaSize total size of the files transferred by the pass as reported by Rsync
+	DeltaSize string `json:"deltaSize,omitempty"`
+	// Final whether this was the final pass run after cutover
+	Final bool `json:"final,omitempty"`
+}
+
+// VolumeVerification defines the result of the checksum verification of a PVC after the transfer
+type VolumeVerification struct {
+	// PVCReference pvc to which this verification corresponds to
+	PVCReference *kapi.ObjectReference `json:"pvcReference,omitempty"`
+	// SampleInterval about one file out of SampleInterval files, selected by a hash of its path, was checksummed, 1 when all files were checksummed
+	SampleInterval int `json:"sampleInterval,omitempty"`
+	// FileCount number of files in the source volume
+	FileCount int64 `json:"fileCount"`
+	// ByteCount total size of the files in the source volume
+	ByteCount int64 `json:"byteCount"`
+	// DestinationFileCount number of files in the destination volume
+	DestinationFileCount int64 `json:"destinationFileCount"`
+	// DestinationByteCount total size of the files in the destination volume
+	DestinationByteCount int64 `json:"destinationByteCount"`
+	// MismatchedFileCount number of files missing or with a different checksum in the destination volume
+	MismatchedFileCount int64 `json:"mismatchedFileCount"`
+	// MismatchedPaths first mismatched paths
+	MismatchedPaths []string `json:"mismatchedPaths,omitempty"`
+	// Succeeded whether the destination volume matches the source volume
+	Succeeded bool `json:"succeeded,omitempty"`
+	// Failed whether the verification failed or found a mismatch
+	Failed bool `json:"failed,omitempty"`
+	// Message details about the result
+	Message string `json:"message,omitempty"`
+	// MismatchTolerated whether the failed verification was only reported as a warning as the source volume was in use
+	MismatchTolerated bool `json:"mismatchTolerated,omitempty"`
+	// CompletionTimestamp time at which the verification completed
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+}
+
+// IsComplete whether the verification completed
+func (v *VolumeVerification) IsComplete() bool {
+	return v.Succeeded || v.Failed
+}
+
+// HasMismatch whether the data of the destination volume was compared and differs from the source volume
+func (v *VolumeVerification) HasMismatch() bool {
+	return v.MismatchedFileCount > 0 || v.ByteCount != v.DestinationByteCount
+}
+
+// IsSampled whether only a sample of the files was checksummed
+func (v *VolumeVerification) IsSampled() bool {
+	return v.SampleInterval > 1
 }
 
 func (x *RsyncOperation) Equal(y *RsyncOperation) bool {
@@ -176,6 +453,61 @@ func (r *RsyncOperation) IsComplete() bool {
 	return r.Failed || r.Succeeded
 }
 
+// AddPass records a completed pass, only the most recent passes are retained
+func (r *RsyncOperation) AddPass(pass RsyncPass) {
+	if n := len(r.Passes); n > 0 {
+		pass.Number = r.Passes[n-1].Number + 1
+	} else {
+		pass.Number = 1
+	}
+	r.Passes = append(r.Passes, pass)
+	if len(r.Passes) > MaxRsyncPassHistory {
+		r.Passes = r.Passes[len(r.Passes)-MaxRsyncPassHistory:]
+	}
+}
+
+// Reset prepares the operation for the next pass
+func (r *RsyncOperation) Reset() {
+	r.CurrentAttempt = 0
+	r.Failed = false
+	r.Succeeded = false
+}
+
+// GetReplicationInterval returns the interval between continuous Rsync passes
+func (r *DirectVolumeMigration) GetReplicationInterval() time.Duration {
+	if r.Spec.ReplicationIntervalSeconds > 0 {
+		return time.Duration(r.Spec.ReplicationIntervalSeconds) * time.Second
+	}
+	return DefaultReplicationIntervalSeconds * time.Second
+}
+
+// IsVerificationMismatchTolerated tells whether a verification mismatch of the PVC is only reported
+// as a warning, when requested and the copied data could change: the source is neither quiesced nor a snapshot
+func (r *DirectVolumeMigration) IsVerificationMismatchTolerated(pvc PVCToMigrate) bool {
+	if !r.Spec.TolerateLiveSourceMismatches || pvc.IsSnapshotSource() {
+		return false
+	}
+	return !r.Spec.SourceQuiesced || !r.IsFinalPass()
+}
+
+// IsFinalPass tells whether the current Rsync pass is the last one
+func (r *DirectVolumeMigration) IsFinalPass() bool {
+	return !r.Spec.Continuous || r.Status.CutoverStarted
+}
+
+// GetVerificationSampleSize returns the maximum number of files checksummed when verifying a PVC
+func (r *DirectVolumeMigration) GetVerificationSampleSize() int {
+	if r.Spec.VerificationSampleSize == 0 {
+		return DefaultVerificationSampleSize
+	}
+	return r.Spec.VerificationSampleSize
+}
+
+// IsIntraCluster returns whether the source and the destination clusters are the same cluster.
+func (r *DirectVolumeMigration) IsIntraCluster() bool {
+	return migref.RefEquals(r.Spec.SrcMigClusterRef, r.Spec.DestMigClusterRef)
+}
+
 func (r *DirectVolumeMigration) GetSourceCluster(client k8sclient.Client) (*MigCluster, error) {
 	return GetCluster(client, r.Spec.SrcMigClusterRef)
 }
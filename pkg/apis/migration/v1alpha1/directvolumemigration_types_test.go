@@ -61,3 +61,59 @@ func TestStorageDirectVolumeMigration(t *testing.T) {
 	g.Expect(c.Delete(context.TODO(), fetched)).NotTo(gomega.HaveOccurred())
 	g.Expect(c.Get(context.TODO(), key, fetched)).To(gomega.HaveOccurred())
 }
+
+func TestDirectVolumeMigration_IsVerificationMismatchTolerated(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     DirectVolumeMigrationSpec
+		cutover  bool
+		snapshot bool
+		want     bool
+	}{
+		{
+			name: "stage copy of a live source by default",
+			spec: DirectVolumeMigrationSpec{},
+			want: false,
+		},
+		{
+			name: "stage copy of a live source when tolerated",
+			spec: DirectVolumeMigrationSpec{TolerateLiveSourceMismatches: true},
+			want: true,
+		},
+		{
+			name: "final copy of a quiesced source when tolerated",
+			spec: DirectVolumeMigrationSpec{SourceQuiesced: true, TolerateLiveSourceMismatches: true},
+			want: false,
+		},
+		{
+			name:     "stage copy from a snapshot when tolerated",
+			spec:     DirectVolumeMigrationSpec{TolerateLiveSourceMismatches: true},
+			snapshot: true,
+			want:     false,
+		},
+		{
+			name: "continuous replication pass before cutover when tolerated",
+			spec: DirectVolumeMigrationSpec{Continuous: true, SourceQuiesced: true, TolerateLiveSourceMismatches: true},
+			want: true,
+		},
+		{
+			name:    "continuous replication final pass of a quiesced source when tolerated",
+			spec:    DirectVolumeMigrationSpec{Continuous: true, SourceQuiesced: true, TolerateLiveSourceMismatches: true},
+			cutover: true,
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dvm := &DirectVolumeMigration{Spec: tt.spec}
+			dvm.Status.CutoverStarted = tt.cutover
+			pvc := PVCToMigrate{}
+			if tt.snapshot {
+				pvc.VolumeSnapshotClass = "csi-snapclass"
+			}
+			if got := dvm.IsVerificationMismatchTolerated(pvc); got != tt.want {
+				t.Errorf("IsVerificationMismatchTolerated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	liberr "github.com/konveyor/controller/pkg/error"
 	kapi "k8s.io/api/core/v1"
@@ -53,7 +54,9 @@ type DirectVolumeMigrationProgressStatus struct {
 	RsyncElapsedTime *metav1.Duration `json:"rsyncElapsedTime,omitempty"`
 	// TotalProgressPercentage cumulative percentage of all Rsync attempts
 	TotalProgressPercentage string `json:"totalProgressPercentage,omitempty"`
-	ObservedDigest          string `json:"observedDigest,omitempty"`
+	// CumulativeStats statistics of all Rsync attempts
+	CumulativeStats *RsyncStats `json:"cumulativeStats,omitempty"`
+	ObservedDigest  string      `json:"observedDigest,omitempty"`
 }
 
 // RsyncPodStatus defines observed state of an Rsync attempt
@@ -74,6 +77,63 @@ type RsyncPodStatus struct {
 	LastObservedTransferRate string `json:"lastObservedTransferRate,omitempty"`
 	// CreationTimestamp pod creation time
 	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
+	// Stats statistics reported by Rsync
+	Stats *RsyncStats `json:"stats,omitempty"`
+}
+
+// RsyncStats defines the statistics reported by Rsync with --info=FLIST2,PROGRESS2,STATS2
+type RsyncStats struct {
+	// TotalFiles number of files considered by Rsync
+	TotalFiles int64 `json:"totalFiles"`
+	// TransferredFiles number of regular files transferred
+	TransferredFiles int64 `json:"transferredFiles"`
+	// DeletedFiles number of files deleted from the destination
+	DeletedFiles int64 `json:"deletedFiles"`
+	// TotalBytes total size of the files considered by Rsync
+	TotalBytes int64 `json:"totalBytes"`
+	// TransferredBytes total size of the files transferred
+	TransferredBytes int64 `json:"transferredBytes"`
+	// SentBytes bytes sent by Rsync
+	SentBytes int64 `json:"sentBytes"`
+	// ReceivedBytes bytes received by Rsync
+	ReceivedBytes int64 `json:"receivedBytes"`
+	// Speedup ratio of the total size to the bytes sent and received
+	Speedup string `json:"speedup,omitempty"`
+	// AverageThroughput average throughput in bytes per second
+	AverageThroughput int64 `json:"averageThroughput"`
+	// EstimatedTimeRemaining time left computed from the progress of a running Rsync
+	EstimatedTimeRemaining *metav1.Duration `json:"estimatedTimeRemaining,omitempty"`
+	// Complete whether the statistics were reported by Rsync on completion
+	Complete bool `json:"complete,omitempty"`
+}
+
+// Add adds the statistics of a concurrent Rsync to the statistics.
+// The throughputs are summed and the longest time remaining is kept.
+func (r *RsyncStats) Add(stats *RsyncStats) {
+	if stats == nil {
+		return
+	}
+	r.TotalFiles += stats.TotalFiles
+	r.TransferredFiles += stats.TransferredFiles
+	r.DeletedFiles += stats.DeletedFiles
+	r.TotalBytes += stats.TotalBytes
+	r.TransferredBytes += stats.TransferredBytes
+	r.SentBytes += stats.SentBytes
+	r.ReceivedBytes += stats.ReceivedBytes
+	r.AverageThroughput += stats.AverageThroughput
+	if stats.EstimatedTimeRemaining != nil &&
+		(r.EstimatedTimeRemaining == nil || stats.EstimatedTimeRemaining.Duration > r.EstimatedTimeRemaining.Duration) {
+		r.EstimatedTimeRemaining = stats.EstimatedTimeRemaining.DeepCopy()
+	}
+	r.UpdateSpeedup()
+}
+
+// UpdateSpeedup computes the speedup from the total size and the bytes sent and received.
+func (r *RsyncStats) UpdateSpeedup() {
+	r.Speedup = ""
+	if exchanged := r.SentBytes + r.ReceivedBytes; exchanged > 0 {
+		r.Speedup = fmt.Sprintf("%.2f", float64(r.TotalBytes)/float64(exchanged))
+	}
 }
 
 // RsyncPodExistsInHistory checks whether Rsync pod status is already part of the history
@@ -0,0 +1,170 @@
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	kapi "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Default repository of a migrated ImageStream in an image registry.
+const DefaultRepositoryTemplate = "{{.Namespace}}/{{.Name}}"
+
+// Keys of the image registry credentials secret.
+const (
+	RegistryUsernameKey = "username"
+	RegistryPasswordKey = "password"
+)
+
+// ImageRegistry an image registry, such as Quay or Harbor, the direct image migration copies the images to
+// instead of the internal registry of the destination cluster.
+type ImageRegistry struct {
+	// URL host of the registry, with optional port and path prefix, for example quay.example.com/migration
+	URL string `json:"url"`
+	// CredentialsSecretRef references a secret holding either the username and password keys or a
+	// .dockerconfigjson key with the credentials of the registry. Images are pushed anonymously when not set.
+	CredentialsSecretRef *kapi.ObjectReference `json:"credentialsSecretRef,omitempty"`
+	// CABundle PEM encoded CA certificates of the registry
+	CABundle []byte `json:"caBundle,omitempty"`
+	// Insecure skips the TLS verification of the registry
+	Insecure bool `json:"insecure,omitempty"`
+	// RepositoryTemplate template of the repository an ImageStream is copied to, relative to the URL.
+	// The template is rendered with the destination .Namespace and the .Name of the ImageStream.
+	// Defaults to {{.Namespace}}/{{.Name}}.
+	RepositoryTemplate string `json:"repositoryTemplate,omitempty"`
+}
+
+// Validate returns an error for the first invalid setting.
+func (r *ImageRegistry) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.URL == "" {
+		return errors.New("url must be set")
+	}
+	if strings.Contains(r.URL, "://") {
+		return errors.New("url must not include a scheme")
+	}
+	repository, err := r.GetRepository("namespace", "name")
+	if err != nil {
+		return err
+	}
+	if _, err := reference.Parse(repository); err != nil {
+		return fmt.Errorf("repositoryTemplate: %v", err)
+	}
+	return nil
+}
+
+// GetHost returns the host of the registry
+func (r *ImageRegistry) GetHost() string {
+	return strings.SplitN(r.URL, "/", 2)[0]
+}
+
+// GetRepository returns the repository of an ImageStream copied into a destination namespace.
+func (r *ImageRegistry) GetRepository(namespace, name string) (string, error) {
+	text := r.RepositoryTemplate
+	if text == "" {
+		text = DefaultRepositoryTemplate
+	}
+	tmpl, err := template.New("repository").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("repositoryTemplate: %v", err)
+	}
+	repository := bytes.Buffer{}
+	err = tmpl.Execute(&repository, struct {
+		Namespace string
+		Name      string
+	}{
+		Namespace: namespace,
+		Name:      name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("repositoryTemplate: %v", err)
+	}
+	repositoryPath := strings.Trim(repository.String(), "/")
+	if repositoryPath == "" {
+		return "", errors.New("repositoryTemplate: empty repository")
+	}
+	return path.Join(strings.TrimSuffix(r.URL, "/"), repositoryPath), nil
+}
+
+// GetCredentials returns the username and password of the registry, empty when no secret is referenced.
+func (r *ImageRegistry) GetCredentials(client k8sclient.Client) (string, string, error) {
+	if r.CredentialsSecretRef == nil {
+		return "", "", nil
+	}
+	secret, err := GetSecret(client, r.CredentialsSecretRef)
+	if err != nil {
+		return "", "", err
+	}
+	if secret == nil {
+		return "", "", fmt.Errorf("credentials secret %s not found",
+			path.Join(r.CredentialsSecretRef.Namespace, r.CredentialsSecretRef.Name))
+	}
+	return r.parseCredentials(secret)
+}
+
+// parseCredentials returns the username and password of the registry held by a secret
+func (r *ImageRegistry) parseCredentials(secret *kapi.Secret) (string, string, error) {
+	if username, found := secret.Data[RegistryUsernameKey]; found {
+		return string(username), string(secret.Data[RegistryPasswordKey]), nil
+	}
+	dockerConfig, found := secret.Data[kapi.DockerConfigJsonKey]
+	if !found {
+		return "", "", fmt.Errorf("credentials secret %s has neither %s nor %s key",
+			path.Join(secret.Namespace, secret.Name), RegistryUsernameKey, kapi.DockerConfigJsonKey)
+	}
+	config := struct {
+		Auths map[string]struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Auth     string `json:"auth"`
+		} `json:"auths"`
+	}{}
+	err := json.Unmarshal(dockerConfig, &config)
+	if err != nil {
+		return "", "", fmt.Errorf("credentials secret %s: %v", path.Join(secret.Namespace, secret.Name), err)
+	}
+	auth, found := config.Auths[r.GetHost()]
+	if !found {
+		return "", "", fmt.Errorf("credentials secret %s has no auth for %s",
+			path.Join(secret.Namespace, secret.Name), r.GetHost())
+	}
+	if auth.Username == "" && auth.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("credentials secret %s: %v", path.Join(secret.Namespace, secret.Name), err)
+		}
+		credentials := strings.SplitN(string(decoded), ":", 2)
+		if len(credentials) != 2 {
+			return "", "", fmt.Errorf("credentials secret %s: invalid auth for %s",
+				path.Join(secret.Namespace, secret.Name), r.GetHost())
+		}
+		return credentials[0], credentials[1], nil
+	}
+	return auth.Username, auth.Password, nil
+}
+
+// getDestinationImageRegistry returns the registry set on a migration resource or else on the destination cluster.
+// Returns nil when images are copied to the internal registry of the destination cluster.
+func getDestinationImageRegistry(client k8sclient.Client, registry *ImageRegistry, clusterRef *kapi.ObjectReference) (*ImageRegistry, error) {
+	if registry != nil {
+		return registry, nil
+	}
+	cluster, err := GetCluster(client, clusterRef)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		return nil, nil
+	}
+	return cluster.Spec.ImageRegistry, nil
+}
@@ -0,0 +1,119 @@
+package v1alpha1
+
+import (
+	"encoding/base64"
+	"testing"
+
+	kapi "k8s.io/api/core/v1"
+)
+
+func TestImageRegistry_GetRepository(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry ImageRegistry
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "default template",
+			registry: ImageRegistry{URL: "registry.example.com:5000"},
+			want:     "registry.example.com:5000/app-ns/app",
+		},
+		{
+			name:     "path prefix and custom template",
+			registry: ImageRegistry{URL: "quay.example.com/migration/", RepositoryTemplate: "{{.Namespace}}-{{.Name}}"},
+			want:     "quay.example.com/migration/app-ns-app",
+		},
+		{
+			name:     "unknown field",
+			registry: ImageRegistry{URL: "quay.example.com", RepositoryTemplate: "{{.Cluster}}/{{.Name}}"},
+			wantErr:  true,
+		},
+		{
+			name:     "empty repository",
+			registry: ImageRegistry{URL: "quay.example.com", RepositoryTemplate: "/"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.registry.GetRepository("app-ns", "app")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRepository() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetRepository() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageRegistry_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry *ImageRegistry
+		wantErr  bool
+	}{
+		{name: "no registry", registry: nil},
+		{name: "valid registry", registry: &ImageRegistry{URL: "quay.example.com/migration"}},
+		{name: "missing url", registry: &ImageRegistry{}, wantErr: true},
+		{name: "url with scheme", registry: &ImageRegistry{URL: "https://quay.example.com"}, wantErr: true},
+		{name: "invalid template", registry: &ImageRegistry{URL: "quay.example.com", RepositoryTemplate: "{{.Name"}, wantErr: true},
+		{name: "invalid repository", registry: &ImageRegistry{URL: "quay.example.com", RepositoryTemplate: "Apps/{{.Name}}"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.registry.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageRegistry_parseCredentials(t *testing.T) {
+	registry := ImageRegistry{URL: "quay.example.com:8443/migration"}
+	auth := base64.StdEncoding.EncodeToString([]byte("robot:token"))
+	tests := []struct {
+		name         string
+		data         map[string][]byte
+		wantUsername string
+		wantPassword string
+		wantErr      bool
+	}{
+		{
+			name:         "username and password",
+			data:         map[string][]byte{"username": []byte("user"), "password": []byte("pass")},
+			wantUsername: "user",
+			wantPassword: "pass",
+		},
+		{
+			name:         "docker config auth",
+			data:         map[string][]byte{kapi.DockerConfigJsonKey: []byte(`{"auths":{"quay.example.com:8443":{"auth":"` + auth + `"}}}`)},
+			wantUsername: "robot",
+			wantPassword: "token",
+		},
+		{
+			name:    "docker config without the registry",
+			data:    map[string][]byte{kapi.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"auth":"` + auth + `"}}}`)},
+			wantErr: true,
+		},
+		{
+			name:    "no credentials",
+			data:    map[string][]byte{"token": []byte("abc")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, password, err := registry.parseCredentials(&kapi.Secret{Data: tt.data})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCredentials() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if username != tt.wantUsername || password != tt.wantPassword {
+				t.Errorf("parseCredentials() = %v, %v, want %v, %v", username, password, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+}
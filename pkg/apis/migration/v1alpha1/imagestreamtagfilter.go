@@ -0,0 +1,127 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ImageStreamTagFilter selects the ImageStream tags and history items copied by the direct image migration.
+// A tag is copied when it matches one of the include patterns, or no include pattern is set, and matches
+// none of the exclude patterns. Patterns are regular expressions matching the whole tag.
+type ImageStreamTagFilter struct {
+	// IncludeTags patterns of the tags to copy, all tags when not set
+	IncludeTags []string `json:"includeTags,omitempty"`
+	// ExcludeTags patterns of the tags not to copy
+	ExcludeTags []string `json:"excludeTags,omitempty"`
+	// MaxHistory number of the newest history items copied per tag, all items when not set
+	MaxHistory *int `json:"maxHistory,omitempty"`
+	// MaxAge maximum age of the copied history items, all items when not set
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// ImageStreamTagFilterResult numbers of tags and history items left out by a filter
+type ImageStreamTagFilterResult struct {
+	// SkippedTags number of tags not copied
+	SkippedTags int `json:"skippedTags,omitempty"`
+	// SkippedImages number of history items not copied, including the items of the skipped tags
+	SkippedImages int `json:"skippedImages,omitempty"`
+}
+
+// IsEmpty returns whether the filter selects all the tags and history items.
+func (r *ImageStreamTagFilter) IsEmpty() bool {
+	return r == nil ||
+		(len(r.IncludeTags) == 0 && len(r.ExcludeTags) == 0 && r.MaxHistory == nil && r.MaxAge == nil)
+}
+
+// Validate returns an error for the first invalid setting.
+func (r *ImageStreamTagFilter) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if _, err := compileTagPatterns(r.IncludeTags); err != nil {
+		return fmt.Errorf("includeTags: %v", err)
+	}
+	if _, err := compileTagPatterns(r.ExcludeTags); err != nil {
+		return fmt.Errorf("excludeTags: %v", err)
+	}
+	if r.MaxHistory != nil && *r.MaxHistory < 1 {
+		return fmt.Errorf("maxHistory must be at least 1")
+	}
+	if r.MaxAge != nil && r.MaxAge.Duration <= 0 {
+		return fmt.Errorf("maxAge must be positive")
+	}
+	return nil
+}
+
+// Apply returns a copy of the ImageStream with the status tags and history items selected by the filter,
+// the history items of a tag being ordered from the newest, and the numbers of tags and items left out.
+// A tag is left out when none of its items are selected.
+func (r *ImageStreamTagFilter) Apply(is imagev1.ImageStream, now time.Time) (imagev1.ImageStream, ImageStreamTagFilterResult, error) {
+	result := ImageStreamTagFilterResult{}
+	filtered := *is.DeepCopy()
+	if r.IsEmpty() {
+		return filtered, result, nil
+	}
+	include, err := compileTagPatterns(r.IncludeTags)
+	if err != nil {
+		return filtered, result, err
+	}
+	exclude, err := compileTagPatterns(r.ExcludeTags)
+	if err != nil {
+		return filtered, result, err
+	}
+	tags := []imagev1.NamedTagEventList{}
+	for _, tag := range filtered.Status.Tags {
+		if (len(include) > 0 && !matchesTagPattern(include, tag.Tag)) || matchesTagPattern(exclude, tag.Tag) {
+			result.SkippedTags++
+			result.SkippedImages += len(tag.Items)
+			continue
+		}
+		items := []imagev1.TagEvent{}
+		for _, item := range tag.Items {
+			if r.MaxHistory != nil && len(items) >= *r.MaxHistory {
+				break
+			}
+			if r.MaxAge != nil && now.Sub(item.Created.Time) > r.MaxAge.Duration {
+				continue
+			}
+			items = append(items, item)
+		}
+		result.SkippedImages += len(tag.Items) - len(items)
+		if len(items) == 0 {
+			result.SkippedTags++
+			continue
+		}
+		tag.Items = items
+		tags = append(tags, tag)
+	}
+	filtered.Status.Tags = tags
+	return filtered, result, nil
+}
+
+// compileTagPatterns compiles the patterns anchored to match whole tags
+func compileTagPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// matchesTagPattern returns whether the tag matches any of the patterns
+func matchesTagPattern(patterns []*regexp.Regexp, tag string) bool {
+	for _, re := range patterns {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestImageStreamTagFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *ImageStreamTagFilter
+		wantErr bool
+	}{
+		{
+			name:    "no filter",
+			filter:  nil,
+			wantErr: false,
+		},
+		{
+			name: "valid filter",
+			filter: &ImageStreamTagFilter{
+				IncludeTags: []string{"v[0-9]+\\..*", "latest"},
+				ExcludeTags: []string{".*-ci"},
+				MaxHistory:  intPtr(3),
+				MaxAge:      &metav1.Duration{Duration: 24 * time.Hour},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "invalid include pattern",
+			filter:  &ImageStreamTagFilter{IncludeTags: []string{"v[0-9"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid exclude pattern",
+			filter:  &ImageStreamTagFilter{ExcludeTags: []string{"(ci"}},
+			wantErr: true,
+		},
+		{
+			name:    "no history",
+			filter:  &ImageStreamTagFilter{MaxHistory: intPtr(0)},
+			wantErr: true,
+		},
+		{
+			name:    "negative age",
+			filter:  &ImageStreamTagFilter{MaxAge: &metav1.Duration{Duration: -time.Hour}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageStreamTagFilter_Apply(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	item := func(name string, age time.Duration) imagev1.TagEvent {
+		return imagev1.TagEvent{
+			Image:   name,
+			Created: metav1.NewTime(now.Add(-age)),
+		}
+	}
+	is := imagev1.ImageStream{
+		Status: imagev1.ImageStreamStatus{
+			Tags: []imagev1.NamedTagEventList{
+				{Tag: "latest", Items: []imagev1.TagEvent{item("a3", time.Hour), item("a2", 48*time.Hour), item("a1", 96*time.Hour)}},
+				{Tag: "v1.0", Items: []imagev1.TagEvent{item("b1", 72*time.Hour)}},
+				{Tag: "pr-42-ci", Items: []imagev1.TagEvent{item("c2", time.Hour), item("c1", 2*time.Hour)}},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		filter     *ImageStreamTagFilter
+		wantTags   map[string][]string
+		wantResult ImageStreamTagFilterResult
+	}{
+		{
+			name:   "no filter selects everything",
+			filter: nil,
+			wantTags: map[string][]string{
+				"latest":   {"a3", "a2", "a1"},
+				"v1.0":     {"b1"},
+				"pr-42-ci": {"c2", "c1"},
+			},
+		},
+		{
+			name:   "include and exclude patterns match whole tags",
+			filter: &ImageStreamTagFilter{IncludeTags: []string{"v1", "v1\\..*", "pr-.*"}, ExcludeTags: []string{".*-ci"}},
+			wantTags: map[string][]string{
+				"v1.0": {"b1"},
+			},
+			wantResult: ImageStreamTagFilterResult{SkippedTags: 2, SkippedImages: 5},
+		},
+		{
+			name:   "max history keeps the newest items",
+			filter: &ImageStreamTagFilter{MaxHistory: intPtr(1)},
+			wantTags: map[string][]string{
+				"latest":   {"a3"},
+				"v1.0":     {"b1"},
+				"pr-42-ci": {"c2"},
+			},
+			wantResult: ImageStreamTagFilterResult{SkippedImages: 3},
+		},
+		{
+			name:   "max age skips old items and tags without recent items",
+			filter: &ImageStreamTagFilter{MaxAge: &metav1.Duration{Duration: 50 * time.Hour}},
+			wantTags: map[string][]string{
+				"latest":   {"a3", "a2"},
+				"pr-42-ci": {"c2", "c1"},
+			},
+			wantResult: ImageStreamTagFilterResult{SkippedTags: 1, SkippedImages: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, result, err := tt.filter.Apply(is, now)
+			if err != nil {
+				t.Errorf("Apply() error = %v", err)
+				return
+			}
+			gotTags := map[string][]string{}
+			for _, tag := range got.Status.Tags {
+				for _, item := range tag.Items {
+					gotTags[tag.Tag] = append(gotTags[tag.Tag], item.Image)
+				}
+			}
+			if !reflect.DeepEqual(gotTags, tt.wantTags) {
+				t.Errorf("Apply() tags = %v, want %v", gotTags, tt.wantTags)
+			}
+			if result != tt.wantResult {
+				t.Errorf("Apply() result = %v, want %v", result, tt.wantResult)
+			}
+			if len(is.Status.Tags) != 3 || len(is.Status.Tags[0].Items) != 3 {
+				t.Errorf("Apply() changed the source ImageStream")
+			}
+		})
+	}
+}
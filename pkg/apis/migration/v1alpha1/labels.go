@@ -68,4 +68,10 @@ const (
 	StagePodLabel = "migration.openshift.io/is-stage-pod"
 	// RsyncPodIdentityLabel identifies sibling Rsync attempts/pods
 	RsyncPodIdentityLabel = "migration.openshift.io/created-for-pvc"
+	// TransferMethodAnnotation transfer method of a DVM client Pod
+	// The value is TransferAgent when set, Rsync Pods are not annotated.
+	TransferMethodAnnotation = "migration.openshift.io/transfer-method"
+	// BandwidthLimitAnnotation bandwidth limit of a DVM client Pod in KiB/s
+	// The value is -1 when the Pod is not limited.
+	BandwidthLimitAnnotation = "migration.openshift.io/bandwidth-limit"
 )
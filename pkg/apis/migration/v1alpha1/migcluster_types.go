@@ -43,7 +43,10 @@ import (
 	kapi "k8s.io/api/core/v1"
 	storageapi "k8s.io/api/storage/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -60,6 +63,7 @@ const (
 	RegistryImageKey              = "REGISTRY_IMAGE"
 	StagePodImageKey              = "STAGE_IMAGE"
 	RsyncTransferImageKey         = "RSYNC_TRANSFER_IMAGE"
+	TransferAgentImageKey         = "TRANSFER_AGENT_IMAGE"
 	ClusterSubdomainKey           = "CLUSTER_SUBDOMAIN"
 	OperatorVersionKey            = "OPERATOR_VERSION"
 	RegistryReadinessProbeTimeout = "REGISTRY_READINESS_TIMEOUT"
@@ -98,6 +102,38 @@ type MigClusterSpec struct {
 
 	// Stores the path of registry route when using direct migration.
 	ExposedRegistryPath string `json:"exposedRegistryPath,omitempty"`
+
+	// Specifies how direct volume migration exposes the Rsync transfer endpoint when this cluster is the destination. Defaults to an OpenShift Route.
+	TransferEndpoint *TransferEndpoint `json:"transferEndpoint,omitempty"`
+
+	// Specifies an image registry, such as Quay or Harbor, that direct image migrations copy images to instead of the internal registry when this cluster is the destination.
+	ImageRegistry *ImageRegistry `json:"imageRegistry,omitempty"`
+}
+
+// Transfer endpoint types.
+const (
+	RouteTransferEndpoint        = "Route"
+	LoadBalancerTransferEndpoint = "LoadBalancer"
+	NodePortTransferEndpoint     = "NodePort"
+	IngressTransferEndpoint      = "Ingress"
+)
+
+// TransferEndpoint defines how the Rsync transfer Service is exposed on a destination cluster.
+type TransferEndpoint struct {
+	// Specifies the endpoint type. Acceptable values are: Route (default), LoadBalancer, NodePort and Ingress.
+	Type string `json:"type,omitempty"`
+
+	// Specifies the node addresses used to reach a NodePort endpoint. Defaults to the external, or else internal, addresses of the cluster nodes.
+	NodeAddresses []string `json:"nodeAddresses,omitempty"`
+
+	// Specifies the subdomain used to generate the host of an Ingress endpoint. Defaults to the CLUSTER_SUBDOMAIN of the cluster config.
+	Subdomain string `json:"subdomain,omitempty"`
+
+	// Specifies the IngressClass of an Ingress endpoint.
+	IngressClassName string `json:"ingressClassName,omitempty"`
+
+	// Specifies annotations added to the LoadBalancer Service or the Ingress, for example to configure a cloud load balancer or the SSL passthrough of an ingress controller.
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // MigClusterStatus defines the observed state of MigCluster
@@ -146,6 +182,26 @@ type StorageClass struct {
 	AccessModes []kapi.PersistentVolumeAccessMode `json:"accessModes,omitempty" protobuf:"bytes,1,rep,name=accessModes,casttype=PersistentVolumeAccessMode"`
 }
 
+// VolumeSnapshotClass is an available CSI volume snapshot class in the cluster
+// Name - the volume snapshot class name
+// Driver - the CSI driver of the volume snapshot class
+// Default - whether or not this volume snapshot class is the default for the driver
+type VolumeSnapshotClass struct {
+	Name    string `json:"name,omitempty"`
+	Driver  string `json:"driver,omitempty"`
+	Default bool   `json:"default,omitempty"`
+}
+
+// CSI snapshot API.
+// The snapshot client library is not vendored, the resources are handled as unstructured.
+var (
+	VolumeSnapshotGroupVersion           = schema.GroupVersion{Group: "snapshot.storage.k8s.io", Version: "v1"}
+	VolumeSnapshotGVK                    = VolumeSnapshotGroupVersion.WithKind("VolumeSnapshot")
+	VolumeSnapshotListGVK                = VolumeSnapshotGroupVersion.WithKind("VolumeSnapshotList")
+	VolumeSnapshotClassListGVK           = VolumeSnapshotGroupVersion.WithKind("VolumeSnapshotClassList")
+	DefaultVolumeSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+)
+
 func init() {
 	SchemeBuilder.Register(&MigCluster{}, &MigClusterList{})
 }
@@ -367,6 +423,31 @@ func (m *MigCluster) GetRsyncTransferImage(c k8sclient.Client) (string, error) {
 	return rsyncImage, nil
 }
 
+// GetTransferAgentImage returns the image of the transfer agent Pods
+func (m *MigCluster) GetTransferAgentImage(c k8sclient.Client) (string, error) {
+	client, err := m.GetClient(c)
+	if err != nil {
+		return "", err
+	}
+	clusterConfig, err := m.GetClusterConfigMap(client)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	agentImage, ok := clusterConfig.Data[TransferAgentImageKey]
+	if !ok {
+		return "", liberr.Wrap(errors.Errorf("configmap key not found: %v", TransferAgentImageKey))
+	}
+	return agentImage, nil
+}
+
+// GetTransferEndpointType returns the type of the DVM transfer endpoint on this cluster.
+func (m *MigCluster) GetTransferEndpointType() string {
+	if m.Spec.TransferEndpoint == nil || m.Spec.TransferEndpoint.Type == "" {
+		return RouteTransferEndpoint
+	}
+	return m.Spec.TransferEndpoint.Type
+}
+
 // GetClusterSubdomain gets a MigCluster specific subdomain value to be used for DVM routes
 func (m *MigCluster) GetClusterSubdomain(c k8sclient.Client) (string, error) {
 	client, err := m.GetClient(c)
@@ -752,6 +833,34 @@ func (r *MigCluster) GetKubeStorageClasses(client k8sclient.Client) ([]storageap
 	return list.Items, nil
 }
 
+// Get the list of CSI VolumeSnapshotClasses from the cluster.
+// Returns an empty list when the snapshot API is not served by the cluster.
+func (r *MigCluster) GetVolumeSnapshotClasses(client k8sclient.Client) ([]VolumeSnapshotClass, error) {
+	list := unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(VolumeSnapshotClassListGVK)
+	err := client.List(
+		context.TODO(),
+		&list,
+		&k8sclient.ListOptions{})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshotClasses []VolumeSnapshotClass
+	for _, item := range list.Items {
+		driver, _, _ := unstructured.NestedString(item.Object, "driver")
+		snapshotClass := VolumeSnapshotClass{
+			Name:   item.GetName(),
+			Driver: driver,
+		}
+		snapshotClass.Default, _ = strconv.ParseBool(item.GetAnnotations()[DefaultVolumeSnapshotClassAnnotation])
+		snapshotClasses = append(snapshotClasses, snapshotClass)
+	}
+	return snapshotClasses, nil
+}
+
 func (r *MigCluster) UpdateProvider(provider pvdr.Provider) {
 	switch provider.GetName() {
 	case pvdr.Azure:
@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -34,22 +36,83 @@ const (
 	PostRestoreHookPhase = "PostRestore"
 )
 
+// Default interpreter used to run a script hook.
+const DefaultHookInterpreter = "/bin/sh"
+
+// Webhook defaults.
+const (
+	DefaultWebhookMethod         = "POST"
+	DefaultWebhookTimeoutSeconds = 30
+	DefaultWebhookBackoffSeconds = 2
+	MaxWebhookTimeoutSeconds     = 300
+	MaxWebhookBackoffSeconds     = 300
+)
+
 // MigHookSpec defines the desired state of MigHook
 type MigHookSpec struct {
 	// Specifies whether the hook is a custom Ansible playbook or a pre-built image. This is a required field.
 	Custom bool `json:"custom"`
 
-	// Specifies the image of the hook to be executed. This is a required field.
-	Image string `json:"image"`
+	// Specifies the image of the hook to be executed. Required unless a webhook is specified.
+	Image string `json:"image,omitempty"`
 
 	// Specifies the contents of the custom Ansible playbook in base64 format, it is used in conjunction with the custom boolean flag.
 	Playbook string `json:"playbook,omitempty"`
 
-	// Specifies the cluster on which the hook is to be executed. This is a required field.
-	TargetCluster string `json:"targetCluster"`
+	// Specifies the contents of a shell or Python script in base64 format. Mutually exclusive with playbook and custom.
+	Script string `json:"script,omitempty"`
+
+	// Specifies the interpreter used to run the script, for example /bin/bash or /usr/bin/python3. Defaults to /bin/sh.
+	Interpreter string `json:"interpreter,omitempty"`
+
+	// Overrides the entrypoint of a custom hook image.
+	Command []string `json:"command,omitempty"`
+
+	// Overrides the arguments passed to a custom hook image.
+	Args []string `json:"args,omitempty"`
+
+	// Specifies additional environment variables for the hook container.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Specifies sources to populate environment variables in the hook container.
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Specifies the compute resources of the hook container.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Specifies the cluster on which the hook is to be executed. Required unless a webhook is specified.
+	TargetCluster string `json:"targetCluster,omitempty"`
 
 	// Specifies the highest amount of time for which the hook will run.
 	ActiveDeadlineSeconds int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Specifies an HTTP call executed directly by the controller instead of a hook Job.
+	Webhook *MigHookWebhook `json:"webhook,omitempty"`
+}
+
+// MigHookWebhook defines an HTTP call made by the controller.
+type MigHookWebhook struct {
+	// Specifies the HTTP method. Defaults to POST.
+	Method string `json:"method,omitempty"`
+
+	// Specifies the URL to call. This is a required field.
+	URL string `json:"url"`
+
+	// References a Secret in the namespace of the MigHook on the host cluster; each key/value pair is sent as an HTTP header.
+	HeadersSecretRef *corev1.ObjectReference `json:"headersSecretRef,omitempty"`
+
+	// Specifies a Go template for the request body, rendered with the plan and migration context.
+	Body string `json:"body,omitempty"`
+
+	// Specifies the HTTP status codes treated as success. Defaults to any 2xx code.
+	SuccessCodes []int `json:"successCodes,omitempty"`
+
+	// Specifies the initial delay between attempts, doubled after every attempt up to 300. Defaults to 2.
+	// The number of attempts is set by the retries of the hook in the MigPlan.
+	BackoffSeconds int `json:"backoffSeconds,omitempty"`
+
+	// Specifies the timeout of each attempt, at most 300. Defaults to 30.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
 }
 
 // MigHookStatus defines the observed state of MigHook
@@ -88,6 +151,69 @@ func init() {
 	SchemeBuilder.Register(&MigHook{}, &MigHookList{})
 }
 
+// Determine if the hook runs an inline script.
+func (r *MigHook) IsScript() bool {
+	return !r.Spec.Custom && r.Spec.Script != ""
+}
+
+// Get the interpreter used to run an inline script.
+func (r *MigHook) GetInterpreter() string {
+	if r.Spec.Interpreter != "" {
+		return r.Spec.Interpreter
+	}
+	return DefaultHookInterpreter
+}
+
+// Determine if the hook is an HTTP call executed by the controller.
+func (r *MigHook) IsWebhook() bool {
+	return r.Spec.Webhook != nil
+}
+
+// Get the HTTP method.
+func (r *MigHookWebhook) GetMethod() string {
+	if r.Method != "" {
+		return strings.ToUpper(r.Method)
+	}
+	return DefaultWebhookMethod
+}
+
+// Get the timeout of a single attempt.
+func (r *MigHookWebhook) GetTimeout() time.Duration {
+	if r.TimeoutSeconds > 0 {
+		return time.Duration(r.TimeoutSeconds) * time.Second
+	}
+	return DefaultWebhookTimeoutSeconds * time.Second
+}
+
+// Get the delay before the specified (0 based) retry.
+// The delay is doubled after every retry up to MaxWebhookBackoffSeconds.
+func (r *MigHookWebhook) GetBackoff(retry int) time.Duration {
+	backoff := DefaultWebhookBackoffSeconds
+	if r.BackoffSeconds > 0 {
+		backoff = r.BackoffSeconds
+	}
+	for i := 0; i < retry && backoff < MaxWebhookBackoffSeconds; i++ {
+		backoff *= 2
+	}
+	if backoff > MaxWebhookBackoffSeconds {
+		backoff = MaxWebhookBackoffSeconds
+	}
+	return time.Duration(backoff) * time.Second
+}
+
+// Determine if the HTTP status code is a success.
+func (r *MigHookWebhook) IsSuccess(code int) bool {
+	if len(r.SuccessCodes) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, successCode := range r.SuccessCodes {
+		if code == successCode {
+			return true
+		}
+	}
+	return false
+}
+
 // Get an existing hook job.
 func (r *MigHook) GetPhaseJob(client k8sclient.Client, phase string, owner string) (*batchv1.Job, error) {
 	list := batchv1.JobList{}
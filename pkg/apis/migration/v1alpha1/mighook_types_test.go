@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/onsi/gomega"
 	"golang.org/x/net/context"
@@ -61,3 +62,26 @@ func TestHookMigHook(t *testing.T) {
 	g.Expect(c.Delete(context.TODO(), fetched)).NotTo(gomega.HaveOccurred())
 	g.Expect(c.Get(context.TODO(), key, fetched)).To(gomega.HaveOccurred())
 }
+
+func TestMigHookWebhook_GetBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff int
+		retry   int
+		want    time.Duration
+	}{
+		{name: "default first retry", retry: 0, want: 2 * time.Second},
+		{name: "doubled", backoff: 5, retry: 3, want: 40 * time.Second},
+		{name: "capped", backoff: 5, retry: 8, want: MaxWebhookBackoffSeconds * time.Second},
+		{name: "capped without overflow", backoff: 5, retry: 70, want: MaxWebhookBackoffSeconds * time.Second},
+		{name: "unvalidated backoff is capped", backoff: 1 << 40, retry: 1, want: MaxWebhookBackoffSeconds * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := MigHookWebhook{BackoffSeconds: tt.backoff}
+			if got := webhook.GetBackoff(tt.retry); got != tt.want {
+				t.Errorf("GetBackoff(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
@@ -54,12 +54,56 @@ type MigMigrationSpec struct {
 type MigMigrationStatus struct {
 	Conditions         `json:",inline"`
 	UnhealthyResources `json:",inline"`
-	ObservedDigest     string       `json:"observedDigest,omitempty"`
-	StartTimestamp     *metav1.Time `json:"startTimestamp,omitempty"`
-	Phase              string       `json:"phase,omitempty"`
-	Pipeline           []*Step      `json:"pipeline,omitempty"`
-	Itinerary          string       `json:"itinerary,omitempty"`
-	Errors             []string     `json:"errors,omitempty"`
+	ObservedDigest     string        `json:"observedDigest,omitempty"`
+	StartTimestamp     *metav1.Time  `json:"startTimestamp,omitempty"`
+	Phase              string        `json:"phase,omitempty"`
+	Pipeline           []*Step       `json:"pipeline,omitempty"`
+	Itinerary          string        `json:"itinerary,omitempty"`
+	Errors             []string      `json:"errors,omitempty"`
+	Hooks              []*HookStatus `json:"hooks,omitempty"`
+}
+
+// Hook execution statuses.
+const (
+	HookRunning   = "Running"
+	HookSucceeded = "Succeeded"
+	HookFailed    = "Failed"
+	HookRetrying  = "Retrying"
+	HookIgnored   = "Ignored"
+)
+
+// HookStatus defines the observed execution of a hook for a phase.
+type HookStatus struct {
+	// The hook phase.
+	Phase string `json:"phase"`
+	// The executed MigHook.
+	Reference *kapi.ObjectReference `json:"reference,omitempty"`
+	// The most recent hook Job.
+	Job *kapi.ObjectReference `json:"job,omitempty"`
+	// The execution status: Running, Succeeded, Failed, Retrying or Ignored.
+	Status string `json:"status,omitempty"`
+	// The number of attempts.
+	Attempts int `json:"attempts,omitempty"`
+	// The time of the most recent attempt.
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+	// The earliest time of the next attempt of a failed webhook.
+	NextAttemptTime *metav1.Time `json:"nextAttemptTime,omitempty"`
+	// The exit code of the hook container.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// The tail of the hook container log.
+	Log string `json:"log,omitempty"`
+	// A human readable description of the outcome.
+	Message string `json:"message,omitempty"`
+}
+
+// FindHook find hook status by phase
+func (s *MigMigrationStatus) FindHook(phase string) *HookStatus {
+	for _, hook := range s.Hooks {
+		if hook.Phase == phase {
+			return hook
+		}
+	}
+	return nil
 }
 
 // FindStep find step by name
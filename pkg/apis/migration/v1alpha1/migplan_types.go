@@ -54,11 +54,35 @@ type MigPlanHook struct {
 	// Indicates the phase when the hooks will be executed. Acceptable values are: PreBackup, PostBackup, PreRestore, and PostRestore.
 	Phase string `json:"phase"`
 
-	// Holds the name of the namespace where hooks should be implemented.
-	ExecutionNamespace string `json:"executionNamespace"`
+	// Holds the name of the namespace where hooks should be implemented. Not used by webhook hooks.
+	ExecutionNamespace string `json:"executionNamespace,omitempty"`
 
-	// Holds the name of the service account to be used for running hooks.
-	ServiceAccount string `json:"serviceAccount"`
+	// Holds the name of the service account to be used for running hooks. Not used by webhook hooks.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// Specifies what happens when the hook fails. Acceptable values are: Fail (default), Ignore and Rollback.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+
+	// Specifies the number of times a failed hook, Job or webhook, is retried before the failure policy applies.
+	Retries int `json:"retries,omitempty"`
+}
+
+// Max number of retries of a hook.
+const MaxHookRetries = 10
+
+// Hook failure policies.
+const (
+	HookFailurePolicyFail     = "Fail"
+	HookFailurePolicyIgnore   = "Ignore"
+	HookFailurePolicyRollback = "Rollback"
+)
+
+// Get the failure policy.
+func (r *MigPlanHook) GetFailurePolicy() string {
+	if r.FailurePolicy == "" {
+		return HookFailurePolicyFail
+	}
+	return r.FailurePolicy
 }
 
 // MigPlanSpec defines the desired state of MigPlan
@@ -90,6 +114,42 @@ type MigPlanSpec struct {
 
 	// If set True, disables direct volume migrations.
 	IndirectVolumeMigration bool `json:"indirectVolumeMigration,omitempty"`
+
+	// If set, a stage migration starts continuous incremental replication of the direct volumes which keeps running until a final migration cuts over.
+	VolumeReplication *VolumeReplication `json:"volumeReplication,omitempty"`
+
+	// If set, defines the tolerations, placement, priority, resources and image pull secrets of the Rsync Pods of direct volume migrations.
+	TransferPodPolicy *TransferPodPolicy `json:"transferPodPolicy,omitempty"`
+
+	// If set True, a network preflight checks the TLS connectivity and measures the throughput between the source nodes hosting direct volumes and the destination cluster. Set Refresh to run the check again.
+	NetworkPreflight bool `json:"networkPreflight,omitempty"`
+
+	// Tool copying the data of direct volume migrations, either Rsync or TransferAgent. Defaults to Rsync.
+	DirectVolumeTransferMethod string `json:"directVolumeTransferMethod,omitempty"`
+
+	// Time windows limiting the bandwidth of the direct volume migration transfer Pods, for example to throttle the transfers during business hours.
+	BandwidthSchedule *BandwidthSchedule `json:"bandwidthSchedule,omitempty"`
+
+	// If set True, the PVCs selected for copy are converted to their selected storage class within the source cluster and namespaces. The workloads are quiesced, their PVC references are swapped to the converted PVCs and the original PVCs are kept for rollback.
+	StorageConversion bool `json:"storageConversion,omitempty"`
+
+	// How the file ownership of direct volumes is kept writable when the UID range of the destination namespace differs from the source namespace. Preserve sets the UID, supplemental groups and MCS annotations of the source namespaces on the destination namespaces. Remap shifts the ownership of the copied files to the ranges of the destination namespace and verifies it.
+	OwnershipPolicy string `json:"ownershipPolicy,omitempty"`
+
+	// If set, selects the ImageStream tags and history items copied by direct image migrations, to leave out stale tags and old images.
+	ImageStreamTagFilter *ImageStreamTagFilter `json:"imageStreamTagFilter,omitempty"`
+
+	// If set, direct image migrations copy images to this registry instead of the image registry of the destination cluster.
+	DestImageRegistry *ImageRegistry `json:"destImageRegistry,omitempty"`
+
+	// If set True, direct image migrations verify that the source signatures of the copied images were copied.
+	VerifyImageSignatures bool `json:"verifyImageSignatures,omitempty"`
+}
+
+// VolumeReplication configures continuous incremental replication of direct volumes.
+type VolumeReplication struct {
+	// Specifies the interval between the end of a replication pass and the start of the next one. Defaults to 300.
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
 }
 
 // MigPlanStatus defines the observed state of MigPlan
@@ -146,6 +206,12 @@ func (r *MigPlan) GetDestinationCluster(client k8sclient.Client) (*MigCluster, e
 	return GetCluster(client, r.Spec.DestMigClusterRef)
 }
 
+// GetDestinationImageRegistry - Get the registry direct image migrations copy images to.
+// Returns `nil` when images are copied to the internal registry of the destination cluster.
+func (r *MigPlan) GetDestinationImageRegistry(client k8sclient.Client) (*ImageRegistry, error) {
+	return getDestinationImageRegistry(client, r.Spec.DestImageRegistry, r.Spec.DestMigClusterRef)
+}
+
 // GetStorage - Get the referenced storage.
 // Returns `nil` when the reference cannot be resolved.
 func (r *MigPlan) GetStorage(client k8sclient.Client) (*MigStorage, error) {
@@ -714,6 +780,12 @@ func (r *MigPlan) IsVolumeMigrationDisabled() bool {
 	return r.IsResourceExcluded(settings.PVResource)
 }
 
+// IsStorageConversion returns whether this MigPlan converts the storage class of its PVCs
+// within the source cluster instead of migrating them to another cluster.
+func (r *MigPlan) IsStorageConversion() bool {
+	return r.Spec.StorageConversion
+}
+
 //
 //
 // PV list
@@ -741,17 +813,19 @@ const (
 // PVC - Associated PVC.
 // NFS - NFS properties.
 // staged - A PV has been explicitly added/updated.
+// VolumeSnapshotClass - The source VolumeSnapshotClass matching the CSI driver of the PV.
 type PV struct {
-	Name              string                `json:"name,omitempty"`
-	Capacity          resource.Quantity     `json:"capacity,omitempty"`
-	StorageClass      string                `json:"storageClass,omitempty"`
-	Supported         Supported             `json:"supported"`
-	Selection         Selection             `json:"selection"`
-	PVC               PVC                   `json:"pvc,omitempty"`
-	NFS               *kapi.NFSVolumeSource `json:"-"`
-	staged            bool                  `json:"-"`
-	CapacityConfirmed bool                  `json:"capacityConfirmed,omitempty"`
-	ProposedCapacity  resource.Quantity     `json:"proposedCapacity,omitempty"`
+	Name                string                `json:"name,omitempty"`
+	Capacity            resource.Quantity     `json:"capacity,omitempty"`
+	StorageClass        string                `json:"storageClass,omitempty"`
+	Supported           Supported             `json:"supported"`
+	Selection           Selection             `json:"selection"`
+	PVC                 PVC                   `json:"pvc,omitempty"`
+	NFS                 *kapi.NFSVolumeSource `json:"-"`
+	staged              bool                  `json:"-"`
+	CapacityConfirmed   bool                  `json:"capacityConfirmed,omitempty"`
+	ProposedCapacity    resource.Quantity     `json:"proposedCapacity,omitempty"`
+	VolumeSnapshotClass string                `json:"volumeSnapshotClass,omitempty"`
 }
 
 // PVC
@@ -760,6 +834,12 @@ type PVC struct {
 	Name         string                            `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
 	AccessModes  []kapi.PersistentVolumeAccessMode `json:"accessModes,omitempty" protobuf:"bytes,1,rep,name=accessModes,casttype=PersistentVolumeAccessMode"`
 	HasReference bool                              `json:"hasReference,omitempty"`
+	VolumeMode   kapi.PersistentVolumeMode         `json:"volumeMode,omitempty"`
+}
+
+// IsBlock returns whether the PVC is a raw block volume.
+func (r *PVC) IsBlock() bool {
+	return r.VolumeMode == kapi.PersistentVolumeBlock
 }
 
 // Supported
@@ -782,6 +862,15 @@ type Selection struct {
 	AccessMode   kapi.PersistentVolumeAccessMode `json:"accessMode,omitempty" protobuf:"bytes,1,rep,name=accessMode,casttype=PersistentVolumeAccessMode"`
 	CopyMethod   string                          `json:"copyMethod,omitempty"`
 	Verify       bool                            `json:"verify,omitempty"`
+	// Include and exclude patterns of the files copied by direct volume migration.
+	Filters *RsyncFilters `json:"filters,omitempty"`
+	// If set, an existing destination PVC matching the selection is reused by direct volume migration and kept on rollback.
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+	// Name of the converted PVC of a storage conversion, defaults to the PVC name suffixed with the storage class.
+	TargetName string `json:"targetName,omitempty"`
+	// If set, a 'snapshot' copy is made by direct volume migration from a CSI VolumeSnapshot of the source
+	// volume instead of the velero snapshot plugin. Requires a VolumeSnapshotClass for the CSI driver of the PV.
+	DirectSnapshot bool `json:"directSnapshot,omitempty"`
 }
 
 // Update the PV with another.
@@ -792,12 +881,39 @@ func (r *PV) Update(pv PV) {
 	r.Capacity = pv.Capacity
 	r.PVC = pv.PVC
 	r.NFS = pv.NFS
+	r.VolumeSnapshotClass = pv.VolumeSnapshotClass
 	if len(r.Supported.Actions) == 1 {
 		r.Selection.Action = r.Supported.Actions[0]
 	}
 	r.staged = true
 }
 
+// IsDirectSnapshotCopy returns whether the PV is copied by direct volume migration
+// from a CSI VolumeSnapshot of the source volume, rather than by a Velero snapshot.
+// The CSI snapshot copy is only made when explicitly selected.
+func (r *PV) IsDirectSnapshotCopy() bool {
+	return r.Selection.Action == PvCopyAction &&
+		r.Selection.CopyMethod == PvSnapshotCopyMethod &&
+		r.Selection.DirectSnapshot &&
+		r.VolumeSnapshotClass != ""
+}
+
+// GetConversionTargetName returns the name of the PVC the PV is converted to by a storage conversion.
+func (r *PV) GetConversionTargetName() string {
+	if r.Selection.TargetName != "" {
+		return r.Selection.TargetName
+	}
+	suffix := r.Selection.StorageClass
+	if suffix == "" {
+		suffix = "converted"
+	}
+	name := fmt.Sprintf("%s-%s", r.PVC.Name, suffix)
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return name
+}
+
 // Collection of PVs
 // List - The collection of PVs.
 // index - List index.
@@ -0,0 +1,189 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxRsyncFilterPatternLength maximum length of an Rsync include or exclude pattern
+const MaxRsyncFilterPatternLength = 1024
+
+// RsyncFilters patterns of the files included in or excluded from the Rsync transfer of a PVC.
+// Patterns follow the Rsync syntax: a pattern without a slash matches the name of a file or
+// directory at any depth, a leading slash anchors the pattern at the root of the volume,
+// a trailing slash matches directories only and `**` matches across slashes.
+type RsyncFilters struct {
+	// Include patterns of the files transferred even when they match an exclude pattern
+	Include []string `json:"include,omitempty"`
+	// Exclude patterns of the files and directories not transferred
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsEmpty returns whether no pattern is set.
+func (r *RsyncFilters) IsEmpty() bool {
+	return r == nil || (len(r.Include) == 0 && len(r.Exclude) == 0)
+}
+
+// Validate returns an error for the first invalid pattern.
+func (r *RsyncFilters) Validate() error {
+	if r == nil {
+		return nil
+	}
+	for _, pattern := range append(append([]string{}, r.Include...), r.Exclude...) {
+		if err := validateRsyncFilterPattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Excludes returns whether the file at the path relative to the root of the volume
+// is left out of the transfer. As Rsync does not descend into excluded directories,
+// a file is excluded when either the file or one of its parent directories is.
+func (r *RsyncFilters) Excludes(filePath string) bool {
+	return r.excludes(filePath, false)
+}
+
+// ExcludesDir returns whether the directory at the path relative to the root of the volume
+// is left out of the transfer together with its content.
+func (r *RsyncFilters) ExcludesDir(dirPath string) bool {
+	return r.excludes(dirPath, true)
+}
+
+func (r *RsyncFilters) excludes(filePath string, dir bool) bool {
+	if r.IsEmpty() {
+		return false
+	}
+	rules := r.getRules()
+	components := strings.Split(strings.Trim(filePath, "/"), "/")
+	for i := range components {
+		isDir := i < len(components)-1 || dir
+		prefix := strings.Join(components[:i+1], "/")
+		for _, rule := range rules {
+			if rule.matches(prefix, isDir) {
+				if !rule.include {
+					return true
+				}
+				break
+			}
+		}
+	}
+	return false
+}
+
+// rsyncFilterRule a pattern converted to a regular expression
+type rsyncFilterRule struct {
+	include  bool
+	dirOnly  bool
+	basename bool
+	regex    *regexp.Regexp
+}
+
+// getRules returns the rules in the order Rsync evaluates them, includes are passed first
+func (r *RsyncFilters) getRules() []rsyncFilterRule {
+	rules := []rsyncFilterRule{}
+	for _, pattern := range r.Include {
+		if rule, err := newRsyncFilterRule(pattern, true); err == nil {
+			rules = append(rules, rule)
+		}
+	}
+	for _, pattern := range r.Exclude {
+		if rule, err := newRsyncFilterRule(pattern, false); err == nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func (r rsyncFilterRule) matches(filePath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.basename {
+		return r.regex.MatchString(filePath[strings.LastIndex(filePath, "/")+1:])
+	}
+	return r.regex.MatchString(filePath)
+}
+
+func newRsyncFilterRule(pattern string, include bool) (rsyncFilterRule, error) {
+	rule := rsyncFilterRule{include: include}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	rule.basename = !anchored && !strings.Contains(pattern, "/") && !strings.Contains(pattern, "**")
+	expr, err := rsyncPatternToRegex(pattern)
+	if err != nil {
+		return rule, err
+	}
+	switch {
+	case rule.basename || anchored:
+		expr = "^" + expr + "$"
+	default:
+		// unanchored patterns match the trailing components of the path
+		expr = "(^|/)" + expr + "$"
+	}
+	rule.regex, err = regexp.Compile(expr)
+	return rule, err
+}
+
+// rsyncPatternToRegex converts the wildcards of an Rsync pattern to a regular expression
+func rsyncPatternToRegex(pattern string) (string, error) {
+	expr := strings.Builder{}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				expr.WriteString(".*")
+				i++
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class in pattern %q", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String(), nil
+}
+
+// validateRsyncFilterPattern patterns are passed to the Rsync command between single quotes
+func validateRsyncFilterPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if len(pattern) > MaxRsyncFilterPatternLength {
+		return fmt.Errorf("pattern longer than %d characters", MaxRsyncFilterPatternLength)
+	}
+	if strings.ContainsRune(pattern, '\'') {
+		return fmt.Errorf("pattern %q contains a single quote", pattern)
+	}
+	for _, c := range pattern {
+		if unicode.IsControl(c) {
+			return fmt.Errorf("pattern %q contains a control character", pattern)
+		}
+	}
+	_, err := newRsyncFilterRule(pattern, false)
+	return err
+}
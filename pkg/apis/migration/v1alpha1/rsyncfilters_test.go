@@ -0,0 +1,93 @@
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestRsyncFilters_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters *RsyncFilters
+		wantErr bool
+	}{
+		{
+			name:    "no filters",
+			filters: nil,
+			wantErr: false,
+		},
+		{
+			name: "valid patterns",
+			filters: &RsyncFilters{
+				Include: []string{"/cache/keep/"},
+				Exclude: []string{"lost+found/", "*.tmp", "/cache/**", "logs/*.[0-9].gz", `file\*name`},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty pattern",
+			filters: &RsyncFilters{Exclude: []string{" "}},
+			wantErr: true,
+		},
+		{
+			name:    "single quote breaking out of the rsync command",
+			filters: &RsyncFilters{Exclude: []string{"*.tmp'; rm -rf /; '"}},
+			wantErr: true,
+		},
+		{
+			name:    "control character",
+			filters: &RsyncFilters{Include: []string{"a\nb"}},
+			wantErr: true,
+		},
+		{
+			name:    "unterminated character class",
+			filters: &RsyncFilters{Exclude: []string{"log[0-9"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filters.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRsyncFilters_Excludes(t *testing.T) {
+	filters := &RsyncFilters{
+		Include: []string{"/cache/keep/", "important.tmp"},
+		Exclude: []string{"lost+found/", "*.tmp", "/cache/", "logs/*.gz", "/data/**/scratch"},
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "lost+found/file", want: true},
+		{path: "lost+found", want: false},
+		{path: "dir/session.tmp", want: true},
+		{path: "dir/important.tmp", want: false},
+		{path: "cache/index", want: true},
+		{path: "cache/keep/file", want: true},
+		{path: "app/cache/index", want: false},
+		{path: "app/logs/2021.gz", want: true},
+		{path: "logs/2021.gz", want: true},
+		{path: "app/logs/sub/2021.gz", want: false},
+		{path: "data/a/b/scratch", want: true},
+		{path: "other/a/scratch", want: false},
+		{path: "dir/file.txt", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := filters.Excludes(tt.path); got != tt.want {
+				t.Errorf("Excludes(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+	if !filters.ExcludesDir("lost+found") || filters.ExcludesDir("app/cache") {
+		t.Errorf("ExcludesDir() does not match directory patterns")
+	}
+	var noFilters *RsyncFilters
+	if noFilters.Excludes("dir/session.tmp") {
+		t.Errorf("Excludes() of nil filters = true, want false")
+	}
+}
@@ -51,6 +51,58 @@ func (in *BackupStorageConfig) DeepCopy() *BackupStorageConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthSchedule) DeepCopyInto(out *BandwidthSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]BandwidthWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthSchedule.
+func (in *BandwidthSchedule) DeepCopy() *BandwidthSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthWindow) DeepCopyInto(out *BandwidthWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodLimit != nil {
+		in, out := &in.PodLimit, &out.PodLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AggregateLimit != nil {
+		in, out := &in.AggregateLimit, &out.AggregateLimit
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthWindow.
+func (in *BandwidthWindow) DeepCopy() *BandwidthWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -171,6 +223,16 @@ func (in *DirectImageMigrationSpec) DeepCopyInto(out *DirectImageMigrationSpec)
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TagFilter != nil {
+		in, out := &in.TagFilter, &out.TagFilter
+		*out = new(ImageStreamTagFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DestImageRegistry != nil {
+		in, out := &in.DestImageRegistry, &out.DestImageRegistry
+		*out = new(ImageRegistry)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectImageMigrationSpec.
@@ -240,6 +302,18 @@ func (in *DirectImageMigrationStatus) DeepCopyInto(out *DirectImageMigrationStat
 			}
 		}
 	}
+	if in.WorkloadImages != nil {
+		in, out := &in.WorkloadImages, &out.WorkloadImages
+		*out = make([]*WorkloadImageListItem, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(WorkloadImageListItem)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	out.ImageVerificationResult = in.ImageVerificationResult
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectImageMigrationStatus.
@@ -329,6 +403,16 @@ func (in *DirectImageStreamMigrationSpec) DeepCopyInto(out *DirectImageStreamMig
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.TagFilter != nil {
+		in, out := &in.TagFilter, &out.TagFilter
+		*out = new(ImageStreamTagFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DestImageRegistry != nil {
+		in, out := &in.DestImageRegistry, &out.DestImageRegistry
+		*out = new(ImageRegistry)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectImageStreamMigrationSpec.
@@ -354,6 +438,30 @@ func (in *DirectImageStreamMigrationStatus) DeepCopyInto(out *DirectImageStreamM
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	out.ImageStreamTagFilterResult = in.ImageStreamTagFilterResult
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]*ImageTagCopy, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ImageTagCopy)
+				**out = **in
+			}
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]*ImageCopy, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ImageCopy)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	out.ImageVerificationResult = in.ImageVerificationResult
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectImageStreamMigrationStatus.
@@ -533,6 +641,11 @@ func (in *DirectVolumeMigrationProgressStatus) DeepCopyInto(out *DirectVolumeMig
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.CumulativeStats != nil {
+		in, out := &in.CumulativeStats, &out.CumulativeStats
+		*out = new(RsyncStats)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationProgressStatus.
@@ -565,6 +678,16 @@ func (in *DirectVolumeMigrationSpec) DeepCopyInto(out *DirectVolumeMigrationSpec
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TransferPodPolicy != nil {
+		in, out := &in.TransferPodPolicy, &out.TransferPodPolicy
+		*out = new(TransferPodPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BandwidthSchedule != nil {
+		in, out := &in.BandwidthSchedule, &out.BandwidthSchedule
+		*out = new(BandwidthSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationSpec.
@@ -645,6 +768,64 @@ func (in *DirectVolumeMigrationStatus) DeepCopyInto(out *DirectVolumeMigrationSt
 			}
 		}
 	}
+	if in.LastPassTimestamp != nil {
+		in, out := &in.LastPassTimestamp, &out.LastPassTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.VolumeVerifications != nil {
+		in, out := &in.VolumeVerifications, &out.VolumeVerifications
+		*out = make([]*VolumeVerification, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(VolumeVerification)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RsyncStats != nil {
+		in, out := &in.RsyncStats, &out.RsyncStats
+		*out = new(RsyncStats)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceRsyncStats != nil {
+		in, out := &in.NamespaceRsyncStats, &out.NamespaceRsyncStats
+		*out = make([]*NamespaceRsyncStats, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(NamespaceRsyncStats)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.PreflightResults != nil {
+		in, out := &in.PreflightResults, &out.PreflightResults
+		*out = make([]*NetworkPreflightResult, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(NetworkPreflightResult)
+				**out = **in
+			}
+		}
+	}
+	if in.BandwidthLimit != nil {
+		in, out := &in.BandwidthLimit, &out.BandwidthLimit
+		*out = new(EffectiveBandwidthLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OwnershipRemaps != nil {
+		in, out := &in.OwnershipRemaps, &out.OwnershipRemaps
+		*out = make([]*OwnershipRemap, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(OwnershipRemap)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationStatus.
@@ -657,6 +838,112 @@ func (in *DirectVolumeMigrationStatus) DeepCopy() *DirectVolumeMigrationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectiveBandwidthLimit) DeepCopyInto(out *EffectiveBandwidthLimit) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(int)
+		**out = **in
+	}
+	if in.NextChange != nil {
+		in, out := &in.NextChange, &out.NextChange
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectiveBandwidthLimit.
+func (in *EffectiveBandwidthLimit) DeepCopy() *EffectiveBandwidthLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectiveBandwidthLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
+	*out = *in
+	if in.Reference != nil {
+		in, out := &in.Reference, &out.Reference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextAttemptTime != nil {
+		in, out := &in.NextAttemptTime, &out.NextAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCopy) DeepCopyInto(out *ImageCopy) {
+	*out = *in
+	if in.NextAttempt != nil {
+		in, out := &in.NextAttempt, &out.NextAttempt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCopy.
+func (in *ImageCopy) DeepCopy() *ImageCopy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCopy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRegistry) DeepCopyInto(out *ImageRegistry) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRegistry.
+func (in *ImageRegistry) DeepCopy() *ImageRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageStreamListItem) DeepCopyInto(out *ImageStreamListItem) {
 	*out = *in
@@ -687,6 +974,86 @@ func (in *ImageStreamListItem) DeepCopy() *ImageStreamListItem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageStreamTagFilter) DeepCopyInto(out *ImageStreamTagFilter) {
+	*out = *in
+	if in.IncludeTags != nil {
+		in, out := &in.IncludeTags, &out.IncludeTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeTags != nil {
+		in, out := &in.ExcludeTags, &out.ExcludeTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxHistory != nil {
+		in, out := &in.MaxHistory, &out.MaxHistory
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageStreamTagFilter.
+func (in *ImageStreamTagFilter) DeepCopy() *ImageStreamTagFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageStreamTagFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageStreamTagFilterResult) DeepCopyInto(out *ImageStreamTagFilterResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageStreamTagFilterResult.
+func (in *ImageStreamTagFilterResult) DeepCopy() *ImageStreamTagFilterResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageStreamTagFilterResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageTagCopy) DeepCopyInto(out *ImageTagCopy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTagCopy.
+func (in *ImageTagCopy) DeepCopy() *ImageTagCopy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageTagCopy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVerificationResult) DeepCopyInto(out *ImageVerificationResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVerificationResult.
+func (in *ImageVerificationResult) DeepCopy() *ImageVerificationResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVerificationResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Incompatible) DeepCopyInto(out *Incompatible) {
 	*out = *in
@@ -1036,6 +1403,16 @@ func (in *MigClusterSpec) DeepCopyInto(out *MigClusterSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.TransferEndpoint != nil {
+		in, out := &in.TransferEndpoint, &out.TransferEndpoint
+		*out = new(TransferEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRegistry != nil {
+		in, out := &in.ImageRegistry, &out.ImageRegistry
+		*out = new(ImageRegistry)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigClusterSpec.
@@ -1069,7 +1446,7 @@ func (in *MigHook) DeepCopyInto(out *MigHook) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -1126,6 +1503,36 @@ func (in *MigHookList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigHookSpec) DeepCopyInto(out *MigHookSpec) {
 	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(MigHookWebhook)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigHookSpec.
@@ -1154,6 +1561,31 @@ func (in *MigHookStatus) DeepCopy() *MigHookStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigHookWebhook) DeepCopyInto(out *MigHookWebhook) {
+	*out = *in
+	if in.HeadersSecretRef != nil {
+		in, out := &in.HeadersSecretRef, &out.HeadersSecretRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.SuccessCodes != nil {
+		in, out := &in.SuccessCodes, &out.SuccessCodes
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigHookWebhook.
+func (in *MigHookWebhook) DeepCopy() *MigHookWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(MigHookWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigMigration) DeepCopyInto(out *MigMigration) {
 	*out = *in
@@ -1258,6 +1690,17 @@ func (in *MigMigrationStatus) DeepCopyInto(out *MigMigrationStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]*HookStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(HookStatus)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigMigrationStatus.
@@ -1380,6 +1823,31 @@ func (in *MigPlanSpec) DeepCopyInto(out *MigPlanSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VolumeReplication != nil {
+		in, out := &in.VolumeReplication, &out.VolumeReplication
+		*out = new(VolumeReplication)
+		**out = **in
+	}
+	if in.TransferPodPolicy != nil {
+		in, out := &in.TransferPodPolicy, &out.TransferPodPolicy
+		*out = new(TransferPodPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BandwidthSchedule != nil {
+		in, out := &in.BandwidthSchedule, &out.BandwidthSchedule
+		*out = new(BandwidthSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageStreamTagFilter != nil {
+		in, out := &in.ImageStreamTagFilter, &out.ImageStreamTagFilter
+		*out = new(ImageStreamTagFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DestImageRegistry != nil {
+		in, out := &in.DestImageRegistry, &out.DestImageRegistry
+		*out = new(ImageRegistry)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigPlanSpec.
@@ -1521,12 +1989,63 @@ func (in *MigStorageStatus) DeepCopy() *MigStorageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceRsyncStats) DeepCopyInto(out *NamespaceRsyncStats) {
+	*out = *in
+	in.RsyncStats.DeepCopyInto(&out.RsyncStats)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceRsyncStats.
+func (in *NamespaceRsyncStats) DeepCopy() *NamespaceRsyncStats {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceRsyncStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPreflightResult) DeepCopyInto(out *NetworkPreflightResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPreflightResult.
+func (in *NetworkPreflightResult) DeepCopy() *NetworkPreflightResult {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPreflightResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OwnershipRemap) DeepCopyInto(out *OwnershipRemap) {
+	*out = *in
+	if in.PVCReference != nil {
+		in, out := &in.PVCReference, &out.PVCReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnershipRemap.
+func (in *OwnershipRemap) DeepCopy() *OwnershipRemap {
+	if in == nil {
+		return nil
+	}
+	out := new(OwnershipRemap)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PV) DeepCopyInto(out *PV) {
 	*out = *in
 	out.Capacity = in.Capacity.DeepCopy()
 	in.Supported.DeepCopyInto(&out.Supported)
-	out.Selection = in.Selection
+	in.Selection.DeepCopyInto(&out.Selection)
 	in.PVC.DeepCopyInto(&out.PVC)
 	if in.NFS != nil {
 		in, out := &in.NFS, &out.NFS
@@ -1579,6 +2098,11 @@ func (in *PVCToMigrate) DeepCopyInto(out *PVCToMigrate) {
 		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(RsyncFilters)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCToMigrate.
@@ -1673,6 +2197,11 @@ func (in *PodProgress) DeepCopyInto(out *PodProgress) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(RsyncStats)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodProgress.
@@ -1685,6 +2214,31 @@ func (in *PodProgress) DeepCopy() *PodProgress {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncFilters) DeepCopyInto(out *RsyncFilters) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncFilters.
+func (in *RsyncFilters) DeepCopy() *RsyncFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncFilters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RsyncOperation) DeepCopyInto(out *RsyncOperation) {
 	*out = *in
@@ -1693,6 +2247,13 @@ func (in *RsyncOperation) DeepCopyInto(out *RsyncOperation) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.Passes != nil {
+		in, out := &in.Passes, &out.Passes
+		*out = make([]RsyncPass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncOperation.
@@ -1705,6 +2266,30 @@ func (in *RsyncOperation) DeepCopy() *RsyncOperation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncPass) DeepCopyInto(out *RsyncPass) {
+	*out = *in
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncPass.
+func (in *RsyncPass) DeepCopy() *RsyncPass {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncPass)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RsyncPodStatus) DeepCopyInto(out *RsyncPodStatus) {
 	*out = *in
@@ -1722,6 +2307,11 @@ func (in *RsyncPodStatus) DeepCopyInto(out *RsyncPodStatus) {
 		in, out := &in.CreationTimestamp, &out.CreationTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(RsyncStats)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncPodStatus.
@@ -1734,9 +2324,34 @@ func (in *RsyncPodStatus) DeepCopy() *RsyncPodStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncStats) DeepCopyInto(out *RsyncStats) {
+	*out = *in
+	if in.EstimatedTimeRemaining != nil {
+		in, out := &in.EstimatedTimeRemaining, &out.EstimatedTimeRemaining
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncStats.
+func (in *RsyncStats) DeepCopy() *RsyncStats {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selection) DeepCopyInto(out *Selection) {
 	*out = *in
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(RsyncFilters)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Selection.
@@ -1838,6 +2453,82 @@ func (in *Timed) DeepCopy() *Timed {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferEndpoint) DeepCopyInto(out *TransferEndpoint) {
+	*out = *in
+	if in.NodeAddresses != nil {
+		in, out := &in.NodeAddresses, &out.NodeAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferEndpoint.
+func (in *TransferEndpoint) DeepCopy() *TransferEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferPodPolicy) DeepCopyInto(out *TransferPodPolicy) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RsyncResources != nil {
+		in, out := &in.RsyncResources, &out.RsyncResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StunnelResources != nil {
+		in, out := &in.StunnelResources, &out.StunnelResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferPodPolicy.
+func (in *TransferPodPolicy) DeepCopy() *TransferPodPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferPodPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnhealthyNamespace) DeepCopyInto(out *UnhealthyNamespace) {
 	*out = *in
@@ -1882,6 +2573,36 @@ func (in *UnhealthyResources) DeepCopy() *UnhealthyResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeReplication) DeepCopyInto(out *VolumeReplication) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeReplication.
+func (in *VolumeReplication) DeepCopy() *VolumeReplication {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeReplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotClass) DeepCopyInto(out *VolumeSnapshotClass) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotClass.
+func (in *VolumeSnapshotClass) DeepCopy() *VolumeSnapshotClass {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSnapshotConfig) DeepCopyInto(out *VolumeSnapshotConfig) {
 	*out = *in
@@ -1902,6 +2623,35 @@ func (in *VolumeSnapshotConfig) DeepCopy() *VolumeSnapshotConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeVerification) DeepCopyInto(out *VolumeVerification) {
+	*out = *in
+	if in.PVCReference != nil {
+		in, out := &in.PVCReference, &out.PVCReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.MismatchedPaths != nil {
+		in, out := &in.MismatchedPaths, &out.MismatchedPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeVerification.
+func (in *VolumeVerification) DeepCopy() *VolumeVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workload) DeepCopyInto(out *Workload) {
 	*out = *in
@@ -1921,3 +2671,23 @@ func (in *Workload) DeepCopy() *Workload {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadImageListItem) DeepCopyInto(out *WorkloadImageListItem) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadImageListItem.
+func (in *WorkloadImageListItem) DeepCopy() *WorkloadImageListItem {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadImageListItem)
+	in.DeepCopyInto(out)
+	return out
+}
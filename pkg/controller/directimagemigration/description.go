@@ -23,7 +23,9 @@ var PhaseDescriptions = map[string]string{
 	MigrationFailed:                   "Direct Image Migration failed.",
 	CreateDestinationNamespaces:       "Creating target cluster namespaces for ImageStreams to be migrated into.",
 	ListImageStreams:                  "Searching source cluster namespaces for ImageStreams to be migrated.",
+	ListWorkloadImages:                "Searching source cluster workloads for internal registry images to be migrated.",
 	CreateDirectImageStreamMigrations: "Launching DirectImageStreamMigrations for all discovered ImageStreams.",
+	MigrateWorkloadImages:             "Copying internal registry images referenced by workloads to the target cluster registry.",
 	WaitingForDirectImageStreamMigrationsToComplete: "Waiting for all DirectImageStreamMigrations to complete.",
 	Completed: "Direct Image Migration completed.",
 }
@@ -151,6 +151,9 @@ func (t *Task) buildDirectImageStreamMigration(is imagev1.ImageStream, destNsNam
 				Name:      is.Name,
 				Namespace: is.Namespace,
 			},
+			TagFilter:         t.Owner.Spec.TagFilter.DeepCopy(),
+			DestImageRegistry: t.Owner.Spec.DestImageRegistry.DeepCopy(),
+			VerifySignatures:  t.Owner.Spec.VerifySignatures,
 		},
 	}
 	migapi.SetOwnerReference(t.Owner, t.Owner, &imageStreamMigration)
@@ -160,6 +163,25 @@ func (t *Task) buildDirectImageStreamMigration(is imagev1.ImageStream, destNsNam
 	return imageStreamMigration
 }
 
+// Report the bytes copied and the images verified by all the DISMs, including the completed ones
+func (t *Task) updateImageCopyResults() error {
+	dismList, err := t.getDirectImageStreamMigrations()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	bytesCopied := int64(0)
+	verification := migapi.ImageVerificationResult{}
+	for _, dism := range dismList {
+		bytesCopied += dism.Status.BytesCopied
+		verification.VerifiedImages += dism.Status.VerifiedImages
+		verification.ConvertedImages += dism.Status.ConvertedImages
+		verification.FailedVerifications += dism.Status.FailedVerifications
+	}
+	t.Owner.Status.BytesCopied = bytesCopied
+	t.Owner.Status.ImageVerificationResult = verification
+	return nil
+}
+
 func (t *Task) checkDISMCompletion() (bool, []string) {
 	newISs := []*migapi.ImageStreamListItem{}
 	for _, item := range t.Owner.Status.NewISs {
@@ -200,6 +222,9 @@ func (t *Task) checkDISMCompletion() (bool, []string) {
 		for _, item := range t.Owner.Status.FailedISs {
 			reasons = append(reasons, item.Errors...)
 		}
+		for _, item := range t.Owner.Status.WorkloadImages {
+			reasons = append(reasons, item.Errors...)
+		}
 	}
 	return completed, reasons
 }
@@ -41,7 +41,9 @@ const (
 	Prepare                                         = "Prepare"
 	CreateDestinationNamespaces                     = "CreateDestinationNamespaces"
 	ListImageStreams                                = "ListImageStreams"
+	ListWorkloadImages                              = "ListWorkloadImages"
 	CreateDirectImageStreamMigrations               = "CreateDirectImageStreamMigrations"
+	MigrateWorkloadImages                           = "MigrateWorkloadImages"
 	WaitingForDirectImageStreamMigrationsToComplete = "WaitingForDirectImageStreamMigrationsToComplete"
 	Completed                                       = "Completed"
 	MigrationFailed                                 = "MigrationFailed"
@@ -85,7 +87,9 @@ var ImageItinerary = Itinerary{
 		{phase: Prepare},
 		{phase: CreateDestinationNamespaces},
 		{phase: ListImageStreams},
+		{phase: ListWorkloadImages},
 		{phase: CreateDirectImageStreamMigrations},
+		{phase: MigrateWorkloadImages},
 		{phase: WaitingForDirectImageStreamMigrationsToComplete},
 		{phase: Completed},
 	},
@@ -180,6 +184,15 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case ListWorkloadImages:
+		// Add the list of internal images referenced by workloads to the dim CR
+		err := t.listWorkloadImages()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case CreateDirectImageStreamMigrations:
 		// Create the DirectImageStreamMigration CRs
 		err := t.createDirectImageStreamMigrations()
@@ -189,7 +202,22 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case MigrateWorkloadImages:
+		// Copy the internal images referenced by workloads, one image per reconcile
+		completed, err := t.migrateWorkloadImages()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if completed {
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+		}
 	case WaitingForDirectImageStreamMigrationsToComplete:
+		err := t.updateImageCopyResults()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
 		completed, reasons := t.checkDISMCompletion()
 
 		if completed {
@@ -42,6 +42,13 @@ const (
 	MissingDestinationClusterRegistryPath = "MissingDestinationClusterRegistryPath"
 	NsListEmpty                           = "NamespaceListEmpty"
 	NsNotFoundOnSourceCluster             = "NamespaceNotFoundOnSourceCluster"
+	InvalidTagFilter                      = "InvalidTagFilter"
+	InvalidDestImageRegistry              = "InvalidDestImageRegistry"
+)
+
+// Reasons
+const (
+	NotSupported = "NotSupported"
 )
 
 // Validate the image migration resource
@@ -64,9 +71,43 @@ func (r ReconcileDirectImageMigration) validate(ctx context.Context, imageMigrat
 	if err != nil {
 		return liberr.Wrap(err)
 	}
+	// Tag filter.
+	r.validateTagFilter(imageMigration)
+	// Destination image registry.
+	r.validateDestImageRegistry(imageMigration)
 	return nil
 }
 
+// Validate the image registry the images are copied to
+func (r ReconcileDirectImageMigration) validateDestImageRegistry(imageMigration *migapi.DirectImageMigration) {
+	err := imageMigration.Spec.DestImageRegistry.Validate()
+	if err == nil {
+		return
+	}
+	imageMigration.Status.SetCondition(migapi.Condition{
+		Type:     InvalidDestImageRegistry,
+		Status:   migapi.True,
+		Reason:   NotSupported,
+		Category: migapi.Critical,
+		Message:  fmt.Sprintf("spec.destImageRegistry is invalid: %s", err.Error()),
+	})
+}
+
+// Validate the filter selecting the copied ImageStream tags
+func (r ReconcileDirectImageMigration) validateTagFilter(imageMigration *migapi.DirectImageMigration) {
+	err := imageMigration.Spec.TagFilter.Validate()
+	if err == nil {
+		return
+	}
+	imageMigration.Status.SetCondition(migapi.Condition{
+		Type:     InvalidTagFilter,
+		Status:   migapi.True,
+		Reason:   NotSupported,
+		Category: migapi.Critical,
+		Message:  fmt.Sprintf("spec.tagFilter is invalid: %s", err.Error()),
+	})
+}
+
 func (r ReconcileDirectImageMigration) validateSrcCluster(ctx context.Context, imageMigration *migapi.DirectImageMigration) error {
 	if opentracing.SpanFromContext(ctx) != nil {
 		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateSrcCluster")
@@ -188,7 +229,10 @@ func (r ReconcileDirectImageMigration) validateDestCluster(ctx context.Context,
 				path.Join(imageMigration.Spec.DestMigClusterRef.Namespace, imageMigration.Spec.DestMigClusterRef.Name)),
 		})
 	}
-	// Exposed registry path
+	// Exposed registry path, not used when images are copied to an image registry
+	if imageMigration.Spec.DestImageRegistry != nil || cluster.Spec.ImageRegistry != nil {
+		return nil
+	}
 	registryPath, err := cluster.GetRegistryPath(r)
 	if err != nil || registryPath == "" {
 		imageMigration.Status.SetCondition(migapi.Condition{
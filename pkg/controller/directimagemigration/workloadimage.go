@@ -0,0 +1,299 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directimagemigration
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/types"
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/controller/directimagestreammigration"
+	"github.com/konveyor/mig-controller/pkg/pods"
+	"github.com/konveyor/openshift-velero-plugin/velero-plugins/imagecopy"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// internalImageReference an image reference of an internal registry,
+// <registry>/<namespace>/<name>[:<tag>|@<digest>]
+type internalImageReference struct {
+	namespace string
+	name      string
+	// tag or digest of the image, including the separator
+	version string
+}
+
+// parseInternalImageReference parses an image reference of the internal registry.
+// Returns: the parsed reference, nil when the image is not served by the internal registry.
+func parseInternalImageReference(image string, internalRegistry string) *internalImageReference {
+	if internalRegistry == "" || !strings.HasPrefix(image, internalRegistry+"/") {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(image, internalRegistry+"/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	ref := &internalImageReference{namespace: parts[0], name: parts[1]}
+	if i := strings.IndexAny(parts[1], "@:"); i > 0 {
+		ref.name = parts[1][:i]
+		ref.version = parts[1][i:]
+	}
+	return ref
+}
+
+// tag returns the tag of the reference, empty when the image is referenced by digest
+func (r *internalImageReference) tag() string {
+	if strings.HasPrefix(r.version, ":") {
+		return strings.TrimPrefix(r.version, ":")
+	}
+	if r.version == "" {
+		return "latest"
+	}
+	return ""
+}
+
+// listWorkloadImages adds to the DIM status the images of the source internal registry referenced by
+// the pod templates of the workloads in the migrated namespaces. Images provided by the ImageStreams
+// migrated by the DISMs are left out, the others are copied into the destination namespace of the workloads.
+func (t *Task) listWorkloadImages() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	srcCluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destCluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	srcInternalRegistry, err := srcCluster.GetInternalRegistryPath(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if srcInternalRegistry == "" {
+		t.Log.Info("Source cluster internal registry path not found, skipping workload images.")
+		return nil
+	}
+	registry, err := t.Owner.GetDestinationImageRegistry(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destInternalRegistry := ""
+	if registry == nil {
+		destInternalRegistry, err = destCluster.GetInternalRegistryPath(t.Client)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if destInternalRegistry == "" {
+			return liberr.Wrap(errors.New("Destination cluster internal registry path not found"))
+		}
+	}
+	migratedISs := map[string]bool{}
+	for _, item := range t.Owner.Status.NewISs {
+		migratedISs[path.Join(item.Namespace, item.Name)] = true
+	}
+	items := []*migapi.WorkloadImageListItem{}
+	for srcNsName, destNsName := range t.Owner.GetNamespaceMapping() {
+		templatePods, err := pods.ListTemplatePods(srcClient, []string{srcNsName})
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		found := map[string]bool{}
+		for _, pod := range templatePods {
+			containers := append([]corev1.Container{}, pod.Spec.InitContainers...)
+			containers = append(containers, pod.Spec.Containers...)
+			for _, container := range containers {
+				ref := parseInternalImageReference(container.Image, srcInternalRegistry)
+				if ref == nil || found[container.Image] || migratedISs[path.Join(ref.namespace, ref.name)] {
+					continue
+				}
+				found[container.Image] = true
+				item := &migapi.WorkloadImageListItem{
+					Namespace:       srcNsName,
+					DestNamespace:   destNsName,
+					SourceReference: container.Image,
+				}
+				if registry != nil {
+					repository, err := registry.GetRepository(destNsName, ref.name)
+					if err != nil {
+						return liberr.Wrap(err)
+					}
+					item.DestReference = repository + ref.version
+				} else {
+					item.DestReference = destInternalRegistry + "/" + path.Join(destNsName, ref.name) + ref.version
+				}
+				// Never overwrite the tags of an ImageStream migrated into the same namespace
+				if migratedISs[path.Join(srcNsName, ref.name)] {
+					item.Errors = append(item.Errors, fmt.Sprintf(
+						"ImageStream %s already migrated into namespace %s", ref.name, destNsName))
+				}
+				items = append(items, item)
+			}
+		}
+	}
+	t.Owner.Status.WorkloadImages = items
+	return nil
+}
+
+// migrateWorkloadImages copies the next pending workload image to the destination registry.
+// Copy errors are reported on the image and do not stop the migration of the other images.
+// Returns: whether all the workload images have been processed.
+func (t *Task) migrateWorkloadImages() (bool, error) {
+	var item *migapi.WorkloadImageListItem
+	for _, workloadImage := range t.Owner.Status.WorkloadImages {
+		if !workloadImage.Copied && len(workloadImage.Errors) == 0 {
+			item = workloadImage
+			break
+		}
+	}
+	if item == nil {
+		return true, nil
+	}
+	srcCluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destCluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	srcInternalRegistry, err := srcCluster.GetInternalRegistryPath(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	srcRegistry, err := srcCluster.GetRegistryPath(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destRegistry, err := destCluster.GetRegistryPath(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	sourceCtx, err := directimagestreammigration.InternalRegistrySystemContext(srcClient)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	ref := parseInternalImageReference(item.SourceReference, srcInternalRegistry)
+	if ref == nil {
+		item.Errors = append(item.Errors, "image is not served by the source cluster internal registry")
+		return false, nil
+	}
+	registry, err := t.Owner.GetDestinationImageRegistry(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if registry != nil {
+		t.copyWorkloadImageToRegistry(item, registry, srcRegistry+"/"+path.Join(ref.namespace, ref.name)+ref.version, sourceCtx)
+		return false, nil
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destinationCtx, err := directimagestreammigration.InternalRegistrySystemContext(destClient)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	t.Log.Info("Copying workload image to destination registry.",
+		"sourceReference", item.SourceReference,
+		"destReference", item.DestReference)
+	err = imagecopy.CopyLocalImageStreamImages(t.buildWorkloadImageStream(ref, item),
+		srcInternalRegistry,
+		srcRegistry,
+		destRegistry,
+		item.DestNamespace,
+		&copy.Options{
+			SourceCtx:      sourceCtx,
+			DestinationCtx: destinationCtx,
+		},
+		t.Log,
+		false)
+	if err != nil {
+		t.Log.Info("Failed to copy workload image.",
+			"sourceReference", item.SourceReference,
+			"error", err.Error())
+		item.Errors = append(item.Errors, err.Error())
+		return false, nil
+	}
+	item.Copied = true
+	return false, nil
+}
+
+// copyWorkloadImageToRegistry copies a workload image to the image registry set on the migration
+func (t *Task) copyWorkloadImageToRegistry(item *migapi.WorkloadImageListItem, registry *migapi.ImageRegistry,
+	srcReference string, sourceCtx *types.SystemContext) {
+	t.Log.Info("Copying workload image to image registry.",
+		"sourceReference", item.SourceReference,
+		"destReference", item.DestReference)
+	destinationCtx, err := directimagestreammigration.RegistrySystemContext(t.Client, registry)
+	if err == nil {
+		_, _, err = directimagestreammigration.CopyImage(srcReference, item.DestReference, sourceCtx, destinationCtx)
+	}
+	if err != nil {
+		t.Log.Info("Failed to copy workload image.",
+			"sourceReference", item.SourceReference,
+			"error", err.Error())
+		item.Errors = append(item.Errors, err.Error())
+		return
+	}
+	item.Copied = true
+}
+
+// buildWorkloadImageStream returns an ImageStream holding the workload image so that it is copied
+// as an ImageStream image. Images referenced by digest are copied without tag.
+func (t *Task) buildWorkloadImageStream(ref *internalImageReference, item *migapi.WorkloadImageListItem) imagev1.ImageStream {
+	tag := ref.tag()
+	imageStream := imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.name,
+			Namespace: item.Namespace,
+		},
+		Status: imagev1.ImageStreamStatus{
+			Tags: []imagev1.NamedTagEventList{
+				{
+					Tag: tag,
+					Items: []imagev1.TagEvent{
+						{DockerImageReference: item.SourceReference},
+					},
+				},
+			},
+		},
+	}
+	if tag == "" {
+		imageStream.Spec.Tags = []imagev1.TagReference{
+			{
+				From: &corev1.ObjectReference{
+					Kind: "DockerImage",
+					Name: item.SourceReference,
+				},
+			},
+		}
+	}
+	return imageStream
+}
@@ -1,90 +1,542 @@
 package directimagestreammigration
 
 import (
+	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/signature"
+	"github.com/containers/image/v5/transports/alltransports"
 	"github.com/containers/image/v5/types"
 	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/compat"
-	"github.com/konveyor/openshift-velero-plugin/velero-plugins/imagecopy"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	imagev1 "github.com/openshift/api/image/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (t *Task) migrateInternalImages() error {
-	imageStream, err := t.Owner.GetImageStream(t.Client)
+const (
+	// imageCopyBackoff delay before the second attempt to copy an image, doubled after each failed attempt
+	imageCopyBackoff = 5 * time.Second
+	// imageCopyMaxBackoff maximum delay between two attempts to copy an image
+	imageCopyMaxBackoff = 5 * time.Minute
+)
+
+// migrateInternalImages copies the next images of the ImageStream, the images of different tags
+// in parallel. Images failing to copy are retried with backoff on the next reconciles.
+// Returns: whether all the images have been processed.
+func (t *Task) migrateInternalImages() (bool, error) {
+	registry, err := t.Owner.GetDestinationImageRegistry(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	images, result, err := t.getImageCopies(registry)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if t.Owner.Status.Tags == nil {
+		t.Owner.Status.ImageStreamTagFilterResult = result
+		if result.SkippedTags > 0 || result.SkippedImages > 0 {
+			t.Log.Info("Tag filter left out ImageStream tags and images.",
+				"skippedTags", result.SkippedTags,
+				"skippedImages", result.SkippedImages)
+		}
+		t.Owner.Status.Tags = listImageTagCopies(images)
+	}
+	tagImages := groupImageCopies(images)
+	updateImageTagCopies(t.Owner.Status.Tags, tagImages)
+
+	now := time.Now()
+	next := nextImageCopies(&t.Owner.Status, tagImages, now, settings.Settings.DimOpts.ImageCopyConcurrency)
+	if len(next) == 0 {
+		for _, tag := range t.Owner.Status.Tags {
+			if !tag.IsDone() {
+				// Waiting for the backoff of failed images
+				t.Requeue = PollReQ
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	sourceCtx, err := InternalRegistrySystemContext(srcClient)
 	if err != nil {
-		return liberr.Wrap(err)
+		return false, liberr.Wrap(err)
 	}
+
+	destinationCtx, err := t.getDestinationSystemContext(registry)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, image := range next {
+		wg.Add(1)
+		go func(image *migapi.ImageCopy, tagHead bool) {
+			defer wg.Done()
+			t.copyImage(image, tagHead, sourceCtx, destinationCtx, now)
+		}(image, isNextTagHead(t.Owner.Status.Tags, image))
+	}
+	wg.Wait()
+
+	t.Owner.Status.Images = updateProcessedImageCopies(&t.Owner.Status, next)
+	bytesCopied := int64(0)
+	for _, tag := range t.Owner.Status.Tags {
+		bytesCopied += tag.BytesCopied
+	}
+	for _, image := range t.Owner.Status.Images {
+		if !image.IsDone() {
+			bytesCopied += image.BytesCopied
+		}
+	}
+	t.Owner.Status.BytesCopied = bytesCopied
+	return false, nil
+}
+
+// getImageCopies returns the images of the ImageStream to copy, in copy order, and the numbers of tags and
+// images left out by the tag filter. The history items created since the migration started are left out
+// so that the same images are listed on every reconcile.
+func (t *Task) getImageCopies(registry *migapi.ImageRegistry) ([]*migapi.ImageCopy, migapi.ImageStreamTagFilterResult, error) {
+	result := migapi.ImageStreamTagFilterResult{}
 	srcCluster, err := t.Owner.GetSourceCluster(t.Client)
 	if err != nil {
-		return liberr.Wrap(err)
+		return nil, result, err
 	}
 	destCluster, err := t.Owner.GetDestinationCluster(t.Client)
 	if err != nil {
-		return liberr.Wrap(err)
+		return nil, result, err
 	}
 
 	srcInternalRegistry, err := srcCluster.GetInternalRegistryPath(t.Client)
 	if err != nil {
-		return liberr.Wrap(err)
+		return nil, result, err
 	}
 	if srcInternalRegistry == "" {
-		return liberr.Wrap(errors.New("Source cluster internal registry path not found"))
+		return nil, result, errors.New("Source cluster internal registry path not found")
 	}
 
 	srcRegistry, err := srcCluster.GetRegistryPath(t.Client)
 	if err != nil {
-		return liberr.Wrap(err)
+		return nil, result, err
 	}
 	if srcRegistry == "" {
-		return liberr.Wrap(errors.New("Source cluster registry path not found"))
+		return nil, result, errors.New("Source cluster registry path not found")
 	}
 
-	destRegistry, err := destCluster.GetRegistryPath(t.Client)
+	imageStream, err := t.Owner.GetImageStream(t.Client)
 	if err != nil {
-		return liberr.Wrap(err)
+		return nil, result, err
 	}
-	if destRegistry == "" {
-		return liberr.Wrap(errors.New("Source cluster registry path not found"))
+	started := time.Now()
+	if t.Owner.Status.StartTimestamp != nil {
+		started = t.Owner.Status.StartTimestamp.Rfc3339Copy().Time
 	}
+	filteredImageStream, result, err := t.Owner.Spec.TagFilter.Apply(removeHistorySince(*imageStream, started), started)
+	if err != nil {
+		return nil, result, err
+	}
+	destRepository, err := t.getDestinationRepository(destCluster, registry, imageStream.Name)
+	if err != nil {
+		return nil, result, err
+	}
+	images := listImageCopies(filteredImageStream,
+		srcInternalRegistry,
+		srcRegistry,
+		destRepository)
+	return images, result, nil
+}
 
+// getDestinationRepository returns the repository the images of the ImageStream are copied to,
+// in the registry set on the migration or else in the exposed registry of the destination cluster.
+func (t *Task) getDestinationRepository(destCluster *migapi.MigCluster, registry *migapi.ImageRegistry, name string) (string, error) {
 	destNamespace := t.Owner.GetDestinationNamespace()
 	if destNamespace == "" {
-		return liberr.Wrap(errors.New("Destination namespace not found"))
+		return "", errors.New("Destination namespace not found")
+	}
+	if registry != nil {
+		return registry.GetRepository(destNamespace, name)
 	}
+	destRegistry, err := destCluster.GetRegistryPath(t.Client)
+	if err != nil {
+		return "", err
+	}
+	if destRegistry == "" {
+		return "", errors.New("Destination cluster registry path not found")
+	}
+	return fmt.Sprintf("%s/%s/%s", destRegistry, destNamespace, name), nil
+}
 
-	srcClient, err := t.getSourceClient()
+// listImageCopies returns the images of the internal registry held by the ImageStream tags, in copy order.
+// The history items of a tag are copied from the oldest so that the destination tag references the newest.
+// Images are copied by tag unless the tag references an image from another namespace or registry.
+func listImageCopies(imageStream imagev1.ImageStream,
+	internalRegistry, srcRegistry, destRepository string) []*migapi.ImageCopy {
+	images := []*migapi.ImageCopy{}
+	for _, tag := range imageStream.Status.Tags {
+		copyToTag := true
+		for _, specTag := range imageStream.Spec.Tags {
+			if specTag.Name != tag.Tag || specTag.From == nil {
+				continue
+			}
+			if !(specTag.From.Kind == "ImageStreamImage" &&
+				(specTag.From.Namespace == "" || specTag.From.Namespace == imageStream.Namespace)) {
+				copyToTag = false
+			}
+		}
+		destReference := destRepository
+		if copyToTag {
+			destReference += ":" + tag.Tag
+		}
+		for i := len(tag.Items) - 1; i >= 0; i-- {
+			dockerImageReference := tag.Items[i].DockerImageReference
+			if !strings.HasPrefix(dockerImageReference, internalRegistry) {
+				continue
+			}
+			images = append(images, &migapi.ImageCopy{
+				Tag:             tag.Tag,
+				Digest:          tag.Items[i].Image,
+				SourceReference: srcRegistry + strings.TrimPrefix(dockerImageReference, internalRegistry),
+				DestReference:   destReference,
+				Phase:           migapi.ImageCopyPending,
+			})
+		}
+	}
+	return images
+}
+
+// removeHistorySince returns a copy of the ImageStream without the history items created after a time,
+// so that the images to copy listed on every reconcile are those of the ImageStream when the copy started.
+func removeHistorySince(imageStream imagev1.ImageStream, since time.Time) imagev1.ImageStream {
+	removed := *imageStream.DeepCopy()
+	for i, tag := range removed.Status.Tags {
+		items := []imagev1.TagEvent{}
+		for _, item := range tag.Items {
+			if !item.Created.Time.After(since) {
+				items = append(items, item)
+			}
+		}
+		removed.Status.Tags[i].Items = items
+	}
+	return removed
+}
+
+// listImageTagCopies returns the copy status of the tags of the images, in copy order
+func listImageTagCopies(images []*migapi.ImageCopy) []*migapi.ImageTagCopy {
+	tags := []*migapi.ImageTagCopy{}
+	found := map[string]*migapi.ImageTagCopy{}
+	for _, image := range images {
+		tag, exists := found[image.Tag]
+		if !exists {
+			tag = &migapi.ImageTagCopy{Tag: image.Tag}
+			found[image.Tag] = tag
+			tags = append(tags, tag)
+		}
+		tag.Items++
+	}
+	return tags
+}
+
+// groupImageCopies returns the images by tag, in copy order
+func groupImageCopies(images []*migapi.ImageCopy) map[string][]*migapi.ImageCopy {
+	grouped := map[string][]*migapi.ImageCopy{}
+	for _, image := range images {
+		grouped[image.Tag] = append(grouped[image.Tag], image)
+	}
+	return grouped
+}
+
+// updateImageTagCopies updates the numbers of history items of the tags, and locates the next item
+// of a tag whose history changed by the digest of its last processed item.
+func updateImageTagCopies(tags []*migapi.ImageTagCopy, images map[string][]*migapi.ImageCopy) {
+	for _, tag := range tags {
+		items := images[tag.Tag]
+		tag.Items = len(items)
+		tag.Processed = resumeIndex(items, tag.Processed, tag.LastDigest)
+	}
+}
+
+// resumeIndex returns the index of the history item following the last processed item, located by
+// its digest when the item is no longer at the index, the index unchanged when not found.
+func resumeIndex(items []*migapi.ImageCopy, index int, lastDigest string) int {
+	if lastDigest == "" || (index > 0 && index <= len(items) && items[index-1].Digest == lastDigest) {
+		return index
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].Digest == lastDigest {
+			return i + 1
+		}
+	}
+	return index
+}
+
+// nextImageCopies returns up to limit images to copy in parallel, at most one per tag so that the images
+// of a tag are copied in order. The next image of a tag is added to the images of the status, where it is
+// kept until copied. Images waiting for their backoff hold their tag.
+func nextImageCopies(status *migapi.DirectImageStreamMigrationStatus,
+	images map[string][]*migapi.ImageCopy, now time.Time, limit int) []*migapi.ImageCopy {
+	next := []*migapi.ImageCopy{}
+	for _, tag := range status.Tags {
+		if len(next) >= limit {
+			break
+		}
+		image := findPendingImageCopy(status.Images, tag.Tag)
+		if image == nil {
+			if tag.IsDone() {
+				continue
+			}
+			image = images[tag.Tag][tag.Processed]
+			status.Images = append(status.Images, image)
+		}
+		if image.NextAttempt != nil && now.Before(image.NextAttempt.Time) {
+			continue
+		}
+		next = append(next, image)
+	}
+	return next
+}
+
+// findPendingImageCopy returns the image of a tag being copied, nil when none
+func findPendingImageCopy(images []*migapi.ImageCopy, tag string) *migapi.ImageCopy {
+	for _, image := range images {
+		if image.Tag == tag && !image.IsDone() {
+			return image
+		}
+	}
+	return nil
+}
+
+// findImageTagCopy returns the copy status of a tag, nil when not found
+func findImageTagCopy(tags []*migapi.ImageTagCopy, name string) *migapi.ImageTagCopy {
+	for _, tag := range tags {
+		if tag.Tag == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+// isNextTagHead returns whether the next image of its tag is the newest image of the tag
+func isNextTagHead(tags []*migapi.ImageTagCopy, image *migapi.ImageCopy) bool {
+	tag := findImageTagCopy(tags, image.Tag)
+	return tag != nil && tag.Processed == tag.Items-1
+}
+
+// updateProcessedImageCopies counts the processed images in the status of their tag.
+// Returns: the images of the status without the copied images no longer needed, those neither the
+// newest image of their tag nor converted on copy.
+func updateProcessedImageCopies(status *migapi.DirectImageStreamMigrationStatus, processed []*migapi.ImageCopy) []*migapi.ImageCopy {
+	removed := map[*migapi.ImageCopy]bool{}
+	for _, image := range processed {
+		tag := findImageTagCopy(status.Tags, image.Tag)
+		if tag == nil || !image.IsDone() {
+			continue
+		}
+		tag.Processed++
+		tag.LastDigest = image.Digest
+		tag.BytesCopied += image.BytesCopied
+		switch image.Phase {
+		case migapi.ImageCopyCopied:
+			tag.Copied++
+		case migapi.ImageCopySkippedExisting:
+			tag.SkippedExisting++
+		case migapi.ImageCopyFailed:
+			tag.Failed++
+		}
+		if image.IsCopied() && !tag.IsDone() && image.CopiedDigest == "" {
+			removed[image] = true
+		}
+	}
+	images := []*migapi.ImageCopy{}
+	for _, image := range status.Images {
+		if !removed[image] {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// isTagHead returns whether the image is the newest image of its tag
+func isTagHead(images []*migapi.ImageCopy, image *migapi.ImageCopy) bool {
+	head := false
+	for _, item := range images {
+		if item.Tag == image.Tag {
+			head = item == image
+		}
+	}
+	return head
+}
+
+// getImageCopyBackoff returns the delay before the next attempt to copy an image
+func getImageCopyBackoff(attempts int) time.Duration {
+	backoff := imageCopyBackoff
+	for i := 1; i < attempts && backoff < imageCopyMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > imageCopyMaxBackoff {
+		backoff = imageCopyMaxBackoff
+	}
+	return backoff
+}
+
+// getDestReference returns the reference of an image on the destination registry, the destination tag
+// for the newest image of a tag, the reference by digest otherwise.
+func getDestReference(image *migapi.ImageCopy, tagHead bool) string {
+	if tagHead {
+		return image.DestReference
+	}
+	return image.GetDestDigestReference()
+}
+
+// copyImage copies an image to the destination registry unless it is already there, and updates its status.
+// The newest image of a tag is there when the destination tag references it, other images when the
+// destination repository holds their digest.
+func (t *Task) copyImage(image *migapi.ImageCopy, tagHead bool, sourceCtx, destinationCtx *types.SystemContext, now time.Time) {
+	if digest, _, err := inspectImage(destinationCtx, getDestReference(image, tagHead), false); err == nil && digest == image.GetDestDigest() {
+		t.Log.Info("Image already exists on destination registry, skipping.",
+			"tag", image.Tag,
+			"digest", image.Digest)
+		image.Phase = migapi.ImageCopySkippedExisting
+		image.NextAttempt = nil
+		image.Error = ""
+		return
+	}
+
+	image.Attempts++
+	t.Log.Info("Copying image to destination registry.",
+		"tag", image.Tag,
+		"digest", image.Digest,
+		"sourceReference", image.SourceReference,
+		"destReference", image.DestReference,
+		"attempt", image.Attempts)
+	bytesCopied, copiedDigest, err := CopyImage(image.SourceReference, image.DestReference, sourceCtx, destinationCtx)
+	image.BytesCopied += bytesCopied
+	if err == nil {
+		if copiedDigest != image.Digest {
+			t.Log.Info("Image manifest converted on copy to destination registry.",
+				"tag", image.Tag,
+				"digest", image.Digest,
+				"copiedDigest", copiedDigest)
+			image.CopiedDigest = copiedDigest
+		}
+		image.Phase = migapi.ImageCopyCopied
+		image.NextAttempt = nil
+		image.Error = ""
+		return
+	}
+	image.Error = err.Error()
+	if image.Attempts >= settings.Settings.DimOpts.ImageCopyAttempts {
+		t.Log.Info("Image copy failed.",
+			"tag", image.Tag,
+			"digest", image.Digest,
+			"error", image.Error)
+		image.Phase = migapi.ImageCopyFailed
+		image.NextAttempt = nil
+		return
+	}
+	backoff := getImageCopyBackoff(image.Attempts)
+	t.Log.Info("Image copy attempt failed, retrying.",
+		"tag", image.Tag,
+		"digest", image.Digest,
+		"error", image.Error,
+		"backoff", backoff)
+	image.NextAttempt = &metav1.Time{Time: now.Add(backoff)}
+}
+
+// CopyImage copies an image between registries. Blobs already on the destination registry,
+// including the blobs copied by a previous failed attempt, are not copied again.
+// Returns: the number of bytes copied and the digest of the manifest written to the destination,
+// which differs from the source digest when the manifest was converted.
+func CopyImage(src, dest string, sourceCtx, destinationCtx *types.SystemContext) (int64, string, error) {
+	policyContext, err := signature.NewPolicyContext(&signature.Policy{
+		Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()},
+	})
 	if err != nil {
-		return liberr.Wrap(err)
+		return 0, "", err
 	}
-	sourceCtx, err := internalRegistrySystemContext(srcClient)
+	defer policyContext.Destroy()
+	srcRef, err := alltransports.ParseImageName("docker://" + src)
 	if err != nil {
-		return liberr.Wrap(err)
+		return 0, "", fmt.Errorf("invalid source name %s: %v", src, err)
 	}
-
-	destClient, err := t.getDestinationClient()
+	destRef, err := alltransports.ParseImageName("docker://" + dest)
 	if err != nil {
-		return liberr.Wrap(err)
+		return 0, "", fmt.Errorf("invalid destination name %s: %v", dest, err)
 	}
-	destinationCtx, err := internalRegistrySystemContext(destClient)
+	bytesCopied := int64(0)
+	progress := make(chan types.ProgressProperties)
+	done := make(chan struct{})
+	go func() {
+		for event := range progress {
+			if event.Event == types.ProgressEventDone {
+				bytesCopied += int64(event.Offset)
+			}
+		}
+		close(done)
+	}()
+	copiedManifest, err := copy.Image(context.TODO(), policyContext, destRef, srcRef, &copy.Options{
+		SourceCtx:        sourceCtx,
+		DestinationCtx:   destinationCtx,
+		ProgressInterval: time.Second,
+		Progress:         progress,
+	})
+	close(progress)
+	<-done
 	if err != nil {
-		return liberr.Wrap(err)
+		return bytesCopied, "", err
 	}
+	digest, err := manifest.Digest(copiedManifest)
+	if err != nil {
+		return bytesCopied, "", err
+	}
+	return bytesCopied, string(digest), nil
+}
 
-	return imagecopy.CopyLocalImageStreamImages(*imageStream,
-		srcInternalRegistry,
-		srcRegistry,
-		destRegistry,
-		destNamespace,
-		&copy.Options{
-			SourceCtx:      sourceCtx,
-			DestinationCtx: destinationCtx,
-		},
-		t.Log,
-		false)
+// inspectImage returns the digest of the manifest of an image and, when requested, its signatures
+func inspectImage(ctx *types.SystemContext, reference string, signatures bool) (string, [][]byte, error) {
+	ref, err := alltransports.ParseImageName("docker://" + reference)
+	if err != nil {
+		return "", nil, err
+	}
+	src, err := ref.NewImageSource(context.TODO(), ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	defer src.Close()
+	rawManifest, _, err := src.GetManifest(context.TODO(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	digest, err := manifest.Digest(rawManifest)
+	if err != nil {
+		return "", nil, err
+	}
+	if !signatures {
+		return string(digest), nil, nil
+	}
+	imageSignatures, err := src.GetSignatures(context.TODO(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(digest), imageSignatures, nil
 }
 
-func internalRegistrySystemContext(c compat.Client) (*types.SystemContext, error) {
+// InternalRegistrySystemContext returns the context authenticating with the internal registry of a cluster
+func InternalRegistrySystemContext(c compat.Client) (*types.SystemContext, error) {
 	config := c.RestConfig()
 	if config.BearerToken == "" {
 		return nil, errors.New("BearerToken not found, can't authenticate with registry")
@@ -100,3 +552,72 @@ func internalRegistrySystemContext(c compat.Client) (*types.SystemContext, error
 	}
 	return ctx, nil
 }
+
+// getDestinationSystemContext returns the context authenticating with the registry the images are copied to
+func (t *Task) getDestinationSystemContext(registry *migapi.ImageRegistry) (*types.SystemContext, error) {
+	if registry != nil {
+		return RegistrySystemContext(t.Client, registry)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return nil, err
+	}
+	return InternalRegistrySystemContext(destClient)
+}
+
+// RegistrySystemContext returns the context authenticating with an image registry using
+// the credentials and CA bundle of its configuration
+func RegistrySystemContext(client k8sclient.Client, registry *migapi.ImageRegistry) (*types.SystemContext, error) {
+	ctx := &types.SystemContext{
+		DockerDisableDestSchema1MIMETypes: true,
+	}
+	if registry.Insecure {
+		ctx.DockerDaemonInsecureSkipTLSVerify = true
+		ctx.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	} else if len(registry.CABundle) > 0 {
+		certPath, err := writeRegistryCABundle(registry.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		ctx.DockerCertPath = certPath
+	}
+	username, password, err := registry.GetCredentials(client)
+	if err != nil {
+		return nil, err
+	}
+	if username != "" || password != "" {
+		ctx.DockerAuthConfig = &types.DockerAuthConfig{
+			Username: username,
+			Password: password,
+		}
+	}
+	return ctx, nil
+}
+
+// writeRegistryCABundle writes a CA bundle into a certificates directory named after its digest.
+// Returns: the directory.
+func writeRegistryCABundle(caBundle []byte) (string, error) {
+	certPath := filepath.Join(os.TempDir(), "mig-registry-certs", fmt.Sprintf("%x", sha256.Sum256(caBundle)))
+	caPath := filepath.Join(certPath, "ca.crt")
+	if _, err := os.Stat(caPath); err == nil {
+		return certPath, nil
+	}
+	err := os.MkdirAll(certPath, 0700)
+	if err != nil {
+		return "", err
+	}
+	file, err := ioutil.TempFile(certPath, "ca-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(caBundle)
+	file.Close()
+	if err == nil {
+		err = os.Rename(file.Name(), caPath)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return certPath, nil
+}
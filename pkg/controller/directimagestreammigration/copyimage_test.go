@@ -0,0 +1,289 @@
+package directimagestreammigration
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	internalRegistry = "image-registry.openshift-image-registry.svc:5000"
+	srcRegistry      = "registry-src.apps.example.com"
+	destRegistry     = "registry-dest.apps.example.com"
+)
+
+func Test_listImageCopies(t *testing.T) {
+	imageStream := imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "src"},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{Name: "base", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/konveyor/base:1"}},
+			},
+		},
+		Status: imagev1.ImageStreamStatus{
+			Tags: []imagev1.NamedTagEventList{
+				{
+					Tag: "latest",
+					Items: []imagev1.TagEvent{
+						{Image: "sha256:new", DockerImageReference: internalRegistry + "/src/app@sha256:new"},
+						{Image: "sha256:old", DockerImageReference: internalRegistry + "/src/app@sha256:old"},
+					},
+				},
+				{
+					Tag: "base",
+					Items: []imagev1.TagEvent{
+						{Image: "sha256:base", DockerImageReference: internalRegistry + "/src/app@sha256:base"},
+						{Image: "sha256:ext", DockerImageReference: "quay.io/konveyor/base@sha256:ext"},
+					},
+				},
+			},
+		},
+	}
+	want := []*migapi.ImageCopy{
+		{
+			Tag:             "latest",
+			Digest:          "sha256:old",
+			SourceReference: srcRegistry + "/src/app@sha256:old",
+			DestReference:   destRegistry + "/dest/app:latest",
+			Phase:           migapi.ImageCopyPending,
+		},
+		{
+			Tag:             "latest",
+			Digest:          "sha256:new",
+			SourceReference: srcRegistry + "/src/app@sha256:new",
+			DestReference:   destRegistry + "/dest/app:latest",
+			Phase:           migapi.ImageCopyPending,
+		},
+		{
+			Tag:             "base",
+			Digest:          "sha256:base",
+			SourceReference: srcRegistry + "/src/app@sha256:base",
+			DestReference:   destRegistry + "/dest/app",
+			Phase:           migapi.ImageCopyPending,
+		},
+	}
+	got := listImageCopies(imageStream, internalRegistry, srcRegistry, destRegistry+"/dest/app")
+	if !reflect.DeepEqual(got, want) {
+		for _, image := range got {
+			t.Logf("got %+v", *image)
+		}
+		t.Errorf("listImageCopies() returned unexpected images")
+	}
+}
+
+func Test_nextImageCopies(t *testing.T) {
+	now := time.Now()
+	later := metav1.NewTime(now.Add(time.Minute))
+	images := map[string][]*migapi.ImageCopy{
+		"v1": {
+			{Tag: "v1", Digest: "a", Phase: migapi.ImageCopyPending},
+			{Tag: "v1", Digest: "b", Phase: migapi.ImageCopyPending},
+			{Tag: "v1", Digest: "c", Phase: migapi.ImageCopyPending},
+		},
+		"v2": {
+			{Tag: "v2", Digest: "d", Phase: migapi.ImageCopyPending},
+			{Tag: "v2", Digest: "e", Phase: migapi.ImageCopyPending},
+		},
+		"v3": {{Tag: "v3", Digest: "f", Phase: migapi.ImageCopyPending}},
+		"v4": {{Tag: "v4", Digest: "g", Phase: migapi.ImageCopyPending}},
+		"v5": {{Tag: "v5", Digest: "h", Phase: migapi.ImageCopyPending}},
+	}
+	tests := []struct {
+		name       string
+		limit      int
+		want       []string
+		wantStatus []string
+	}{
+		{
+			name:       "one image per tag, tags waiting for backoff are held",
+			limit:      5,
+			want:       []string{"b", "g", "h"},
+			wantStatus: []string{"f", "d", "b", "g", "h"},
+		},
+		{
+			name:       "limit is applied",
+			limit:      2,
+			want:       []string{"b", "g"},
+			wantStatus: []string{"f", "d", "b", "g"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &migapi.DirectImageStreamMigrationStatus{
+				Tags: []*migapi.ImageTagCopy{
+					{Tag: "v1", Items: 3, Processed: 1},
+					{Tag: "v2", Items: 2},
+					{Tag: "v3", Items: 1, Processed: 1, Failed: 1},
+					{Tag: "v4", Items: 1},
+					{Tag: "v5", Items: 1},
+				},
+				Images: []*migapi.ImageCopy{
+					{Tag: "v3", Digest: "f", Phase: migapi.ImageCopyFailed},
+					{Tag: "v2", Digest: "d", Phase: migapi.ImageCopyPending, NextAttempt: &later},
+				},
+			}
+			got := []string{}
+			for _, image := range nextImageCopies(status, images, now, tt.limit) {
+				got = append(got, image.Digest)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nextImageCopies() = %v, want %v", got, tt.want)
+			}
+			gotStatus := []string{}
+			for _, image := range status.Images {
+				gotStatus = append(gotStatus, image.Digest)
+			}
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("nextImageCopies() status images = %v, want %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_updateImageTagCopies(t *testing.T) {
+	images := groupImageCopies([]*migapi.ImageCopy{
+		{Tag: "v1", Digest: "b"},
+		{Tag: "v1", Digest: "c"},
+		{Tag: "v1", Digest: "d"},
+	})
+	tests := []struct {
+		name string
+		tag  migapi.ImageTagCopy
+		want int
+	}{
+		{
+			name: "not started",
+			tag:  migapi.ImageTagCopy{Tag: "v1", Items: 3},
+			want: 0,
+		},
+		{
+			name: "unchanged history",
+			tag:  migapi.ImageTagCopy{Tag: "v1", Items: 3, Processed: 2, LastDigest: "c"},
+			want: 2,
+		},
+		{
+			name: "oldest history item pruned",
+			tag:  migapi.ImageTagCopy{Tag: "v1", Items: 4, Processed: 3, LastDigest: "c"},
+			want: 2,
+		},
+		{
+			name: "last processed item pruned",
+			tag:  migapi.ImageTagCopy{Tag: "v1", Items: 4, Processed: 1, LastDigest: "a"},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := tt.tag
+			updateImageTagCopies([]*migapi.ImageTagCopy{&tag}, images)
+			if tag.Items != 3 {
+				t.Errorf("updateImageTagCopies() items = %d, want 3", tag.Items)
+			}
+			if tag.Processed != tt.want {
+				t.Errorf("updateImageTagCopies() processed = %d, want %d", tag.Processed, tt.want)
+			}
+		})
+	}
+}
+
+func Test_updateProcessedImageCopies(t *testing.T) {
+	copied := &migapi.ImageCopy{Tag: "v1", Digest: "a", Phase: migapi.ImageCopyCopied, BytesCopied: 10}
+	converted := &migapi.ImageCopy{Tag: "v2", Digest: "b", CopiedDigest: "b2", Phase: migapi.ImageCopyCopied, BytesCopied: 20}
+	failed := &migapi.ImageCopy{Tag: "v2", Digest: "c", Phase: migapi.ImageCopyFailed}
+	head := &migapi.ImageCopy{Tag: "v1", Digest: "d", Phase: migapi.ImageCopySkippedExisting}
+	pending := &migapi.ImageCopy{Tag: "v3", Digest: "e", Phase: migapi.ImageCopyPending, BytesCopied: 5}
+	status := &migapi.DirectImageStreamMigrationStatus{
+		Tags: []*migapi.ImageTagCopy{
+			{Tag: "v1", Items: 2},
+			{Tag: "v2", Items: 3},
+			{Tag: "v3", Items: 1},
+		},
+		Images: []*migapi.ImageCopy{copied, converted, failed, pending},
+	}
+	images := updateProcessedImageCopies(status, []*migapi.ImageCopy{copied, converted, pending})
+	status.Images = append(images, head)
+	images = updateProcessedImageCopies(status, []*migapi.ImageCopy{head, failed})
+	want := []*migapi.ImageCopy{converted, failed, pending, head}
+	if !reflect.DeepEqual(images, want) {
+		for _, image := range images {
+			t.Logf("got %+v", *image)
+		}
+		t.Errorf("updateProcessedImageCopies() returned unexpected images")
+	}
+	wantTags := []migapi.ImageTagCopy{
+		{Tag: "v1", Items: 2, Processed: 2, LastDigest: "d", Copied: 1, SkippedExisting: 1, BytesCopied: 10},
+		{Tag: "v2", Items: 3, Processed: 2, LastDigest: "c", Copied: 1, Failed: 1, BytesCopied: 20},
+		{Tag: "v3", Items: 1},
+	}
+	for i, tag := range status.Tags {
+		if !reflect.DeepEqual(*tag, wantTags[i]) {
+			t.Errorf("updateProcessedImageCopies() tag = %+v, want %+v", *tag, wantTags[i])
+		}
+	}
+}
+
+func Test_removeHistorySince(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	imageStream := imagev1.ImageStream{
+		Status: imagev1.ImageStreamStatus{
+			Tags: []imagev1.NamedTagEventList{
+				{
+					Tag: "latest",
+					Items: []imagev1.TagEvent{
+						{Image: "sha256:pushed", Created: metav1.NewTime(start.Add(time.Second))},
+						{Image: "sha256:new", Created: metav1.NewTime(start)},
+						{Image: "sha256:old", Created: metav1.NewTime(start.Add(-time.Hour))},
+					},
+				},
+			},
+		},
+	}
+	removed := removeHistorySince(imageStream, start)
+	got := []string{}
+	for _, item := range removed.Status.Tags[0].Items {
+		got = append(got, item.Image)
+	}
+	want := []string{"sha256:new", "sha256:old"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("removeHistorySince() = %v, want %v", got, want)
+	}
+	if len(imageStream.Status.Tags[0].Items) != 3 {
+		t.Errorf("removeHistorySince() modified the ImageStream")
+	}
+}
+
+func Test_isTagHead(t *testing.T) {
+	images := []*migapi.ImageCopy{
+		{Tag: "v1", Digest: "a"},
+		{Tag: "v2", Digest: "b"},
+		{Tag: "v1", Digest: "c"},
+	}
+	want := []bool{false, true, true}
+	for i, image := range images {
+		if got := isTagHead(images, image); got != want[i] {
+			t.Errorf("isTagHead(%s) = %v, want %v", image.Digest, got, want[i])
+		}
+	}
+}
+
+func Test_getImageCopyBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 5 * time.Second},
+		{attempts: 2, want: 10 * time.Second},
+		{attempts: 4, want: 40 * time.Second},
+		{attempts: 20, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := getImageCopyBackoff(tt.attempts); got != tt.want {
+			t.Errorf("getImageCopyBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
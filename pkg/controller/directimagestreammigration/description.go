@@ -22,6 +22,7 @@ var PhaseDescriptions = map[string]string{
 	Started:            "DirectImageStreamMigration started.",
 	Prepare:            "Preparing for DirectImageStreamMigration.",
 	MigrateImageStream: "Migrating internal images found in ImageStreams from source to target cluster.",
+	VerifyImages:       "Verifying the manifest digests and signatures of the copied images against their source.",
 	MigrationFailed:    "Migration failed.",
 	Completed:          "Migration completed.",
 }
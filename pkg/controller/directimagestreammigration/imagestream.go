@@ -0,0 +1,124 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directimagestreammigration
+
+import (
+	"context"
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateDestinationImageStream creates or updates the destination ImageStream so that its tags reference
+// the images copied to an image registry, which the destination internal registry pulls through.
+// Skipped when images are copied to the internal registry, or the destination cluster does not
+// serve the ImageStream API.
+func (t *Task) updateDestinationImageStream() error {
+	registry, err := t.Owner.GetDestinationImageRegistry(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if registry == nil {
+		return nil
+	}
+	tags := buildDestinationTags(t.Owner.Status.Images, registry.Insecure)
+	if len(tags) == 0 {
+		return nil
+	}
+	client, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	imageStream := imagev1.ImageStream{}
+	err = client.Get(
+		context.TODO(),
+		k8sclient.ObjectKey{
+			Namespace: t.Owner.GetDestinationNamespace(),
+			Name:      t.Owner.Spec.ImageStreamRef.Name,
+		},
+		&imageStream)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			t.Log.Info("ImageStream API not found on destination cluster, skipping destination ImageStream.")
+			return nil
+		}
+		if !k8serror.IsNotFound(err) {
+			return liberr.Wrap(err)
+		}
+		imageStream = imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: t.Owner.GetDestinationNamespace(),
+				Name:      t.Owner.Spec.ImageStreamRef.Name,
+			},
+			Spec: imagev1.ImageStreamSpec{Tags: tags},
+		}
+		t.Log.Info("Creating destination ImageStream referencing the image registry.",
+			"imageStream", path.Join(imageStream.Namespace, imageStream.Name))
+		return liberr.Wrap(client.Create(context.TODO(), &imageStream))
+	}
+	imageStream.Spec.Tags = mergeTagReferences(imageStream.Spec.Tags, tags)
+	t.Log.Info("Updating destination ImageStream to reference the image registry.",
+		"imageStream", path.Join(imageStream.Namespace, imageStream.Name))
+	return liberr.Wrap(client.Update(context.TODO(), &imageStream))
+}
+
+// buildDestinationTags returns the tags of the destination ImageStream, each referencing by digest
+// the newest image of the tag copied to the image registry.
+func buildDestinationTags(images []*migapi.ImageCopy, insecure bool) []imagev1.TagReference {
+	tags := []imagev1.TagReference{}
+	for _, image := range images {
+		if !isTagHead(images, image) || !image.IsCopied() {
+			continue
+		}
+		tags = append(tags, imagev1.TagReference{
+			Name: image.Tag,
+			From: &corev1.ObjectReference{
+				Kind: "DockerImage",
+				Name: image.GetDestDigestReference(),
+			},
+			ImportPolicy: imagev1.TagImportPolicy{
+				Insecure: insecure,
+			},
+			ReferencePolicy: imagev1.TagReferencePolicy{
+				Type: imagev1.LocalTagReferencePolicy,
+			},
+		})
+	}
+	return tags
+}
+
+// mergeTagReferences replaces the tags of the same name and adds the others
+func mergeTagReferences(existing, tags []imagev1.TagReference) []imagev1.TagReference {
+	merged := []imagev1.TagReference{}
+	replaced := map[string]bool{}
+	for _, tag := range tags {
+		replaced[tag.Name] = true
+	}
+	for _, tag := range existing {
+		if !replaced[tag.Name] {
+			merged = append(merged, tag)
+		}
+	}
+	return append(merged, tags...)
+}
@@ -0,0 +1,43 @@
+package directimagestreammigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_buildDestinationTags(t *testing.T) {
+	images := []*migapi.ImageCopy{
+		{Tag: "latest", Digest: "sha256:old", DestReference: "quay.example.com/dest/app:latest", Phase: migapi.ImageCopyCopied},
+		{Tag: "latest", Digest: "sha256:new", DestReference: "quay.example.com/dest/app:latest", Phase: migapi.ImageCopySkippedExisting},
+		{Tag: "base", Digest: "sha256:base", DestReference: "quay.example.com/dest/app", Phase: migapi.ImageCopyCopied},
+		{Tag: "broken", Digest: "sha256:broken", DestReference: "quay.example.com/dest/app:broken", Phase: migapi.ImageCopyFailed},
+	}
+	tag := func(name, reference string) imagev1.TagReference {
+		return imagev1.TagReference{
+			Name:            name,
+			From:            &corev1.ObjectReference{Kind: "DockerImage", Name: reference},
+			ImportPolicy:    imagev1.TagImportPolicy{Insecure: true},
+			ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.LocalTagReferencePolicy},
+		}
+	}
+	want := []imagev1.TagReference{
+		tag("latest", "quay.example.com/dest/app@sha256:new"),
+		tag("base", "quay.example.com/dest/app@sha256:base"),
+	}
+	if got := buildDestinationTags(images, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildDestinationTags() = %v, want %v", got, want)
+	}
+}
+
+func Test_mergeTagReferences(t *testing.T) {
+	existing := []imagev1.TagReference{{Name: "latest"}, {Name: "stable"}}
+	tags := []imagev1.TagReference{{Name: "latest", Generation: new(int64)}, {Name: "v1"}}
+	want := []imagev1.TagReference{{Name: "stable"}, {Name: "latest", Generation: new(int64)}, {Name: "v1"}}
+	if got := mergeTagReferences(existing, tags); !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeTagReferences() = %v, want %v", got, want)
+	}
+}
@@ -40,6 +40,7 @@ const (
 	Started            = "Started"
 	Prepare            = "Prepare"
 	MigrateImageStream = "MigrateImageStream"
+	VerifyImages       = "VerifyImages"
 	Completed          = "Completed"
 	MigrationFailed    = "MigrationFailed"
 )
@@ -81,6 +82,7 @@ var ImageItinerary = Itinerary{
 		{phase: Started},
 		{phase: Prepare},
 		{phase: MigrateImageStream},
+		{phase: VerifyImages},
 		{phase: Completed},
 	},
 }
@@ -157,9 +159,29 @@ func (t *Task) Run(ctx context.Context) error {
 		}
 	case MigrateImageStream:
 		// Migrate internal images in the imagestream
-		err := t.migrateInternalImages()
+		completed, err := t.migrateInternalImages()
+		if err == nil && !completed {
+			break
+		}
+		if err != nil {
+			t.fail(MigrationFailed, []string{err.Error()})
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case VerifyImages:
+		// Verify the copied images against their source
+		completed, err := t.verifyImages()
+		if err == nil && !completed {
+			break
+		}
+		if err == nil {
+			err = t.updateDestinationImageStream()
+		}
 		if err != nil {
 			t.fail(MigrationFailed, []string{err.Error()})
+		} else if reasons := t.Owner.GetImageCopyErrors(); len(reasons) > 0 {
+			t.fail(MigrationFailed, reasons)
 		}
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
@@ -194,7 +194,10 @@ func (r ReconcileDirectImageStreamMigration) validateDestCluster(ctx context.Con
 				path.Join(imageStreamMigration.Spec.DestMigClusterRef.Namespace, imageStreamMigration.Spec.DestMigClusterRef.Name)),
 		})
 	}
-	// Exposed registry path
+	// Exposed registry path, not used when images are copied to an image registry
+	if imageStreamMigration.Spec.DestImageRegistry != nil || cluster.Spec.ImageRegistry != nil {
+		return nil
+	}
 	registryPath, err := cluster.GetRegistryPath(r)
 	if err != nil || registryPath == "" {
 		imageStreamMigration.Status.SetCondition(migapi.Condition{
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directimagestreammigration
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/containers/image/v5/types"
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+)
+
+// imageVerification a copied image to verify
+// image - The image, listed in the images of the status or else a history item of its tag.
+// tagHead - Whether the image is the newest image of its tag.
+// listed - Whether the image is listed in the images of the status.
+type imageVerification struct {
+	image   *migapi.ImageCopy
+	tagHead bool
+	listed  bool
+}
+
+// verifyImages verifies the next copied images against their source, in parallel.
+// Images not matching their source, or failing to verify, are marked failed.
+// Returns: whether all the copied images have been verified.
+func (t *Task) verifyImages() (bool, error) {
+	registry, err := t.Owner.GetDestinationImageRegistry(t.Client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	images, _, err := t.getImageCopies(registry)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	next := nextImageVerifications(&t.Owner.Status, groupImageCopies(images), settings.Settings.DimOpts.ImageCopyConcurrency)
+	if len(next) == 0 {
+		return true, nil
+	}
+
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	sourceCtx, err := InternalRegistrySystemContext(srcClient)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destinationCtx, err := t.getDestinationSystemContext(registry)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, verification := range next {
+		wg.Add(1)
+		go func(image *migapi.ImageCopy, tagHead bool) {
+			defer wg.Done()
+			t.verifyImage(image, tagHead, sourceCtx, destinationCtx)
+		}(verification.image, verification.tagHead)
+	}
+	wg.Wait()
+
+	t.Owner.Status.Images = updateVerifiedImageCopies(&t.Owner.Status, next)
+	return false, nil
+}
+
+// nextImageVerifications returns up to limit copied images not verified yet, the history items of a tag
+// from the oldest. Images listed in the status are verified with their status, the images that failed
+// to copy are passed over.
+func nextImageVerifications(status *migapi.DirectImageStreamMigrationStatus,
+	images map[string][]*migapi.ImageCopy, limit int) []imageVerification {
+	next := []imageVerification{}
+	for _, tag := range status.Tags {
+		items := images[tag.Tag]
+		tag.Verified = resumeIndex(items, tag.Verified, tag.LastVerifiedDigest)
+		for ; tag.Verified < len(items) && tag.Verified < tag.Processed; tag.Verified++ {
+			if len(next) >= limit {
+				return next
+			}
+			item := items[tag.Verified]
+			tag.LastVerifiedDigest = item.Digest
+			verification := imageVerification{
+				image:   findImageCopy(status.Images, item.Tag, item.Digest),
+				tagHead: tag.Verified == len(items)-1,
+				listed:  true,
+			}
+			if verification.image == nil {
+				// Not listed images were copied
+				image := *item
+				image.Phase = migapi.ImageCopyCopied
+				verification.image = &image
+				verification.listed = false
+			}
+			if !verification.image.IsCopied() || verification.image.Verification != "" {
+				continue
+			}
+			next = append(next, verification)
+		}
+	}
+	return next
+}
+
+// findImageCopy returns the newest image of a tag with a digest listed in the images, nil when not found
+func findImageCopy(images []*migapi.ImageCopy, tag, digest string) *migapi.ImageCopy {
+	for i := len(images) - 1; i >= 0; i-- {
+		if images[i].Tag == tag && images[i].Digest == digest {
+			return images[i]
+		}
+	}
+	return nil
+}
+
+// updateVerifiedImageCopies adds the results of the verified images to the status.
+// Returns: the images of the status with the images not listed that failed to verify,
+// listed before the newest image of their tag.
+func updateVerifiedImageCopies(status *migapi.DirectImageStreamMigrationStatus, verified []imageVerification) []*migapi.ImageCopy {
+	images := status.Images
+	verifiedImages := []*migapi.ImageCopy{}
+	for _, verification := range verified {
+		verifiedImages = append(verifiedImages, verification.image)
+		if verification.listed || isImageVerified(verification.image.Verification) {
+			continue
+		}
+		images = insertImageCopy(images, verification.image)
+	}
+	result := getImageVerificationResult(verifiedImages)
+	status.VerifiedImages += result.VerifiedImages
+	status.ConvertedImages += result.ConvertedImages
+	status.FailedVerifications += result.FailedVerifications
+	return images
+}
+
+// insertImageCopy inserts an image before the last image of its tag, appends it when its tag has none.
+func insertImageCopy(images []*migapi.ImageCopy, image *migapi.ImageCopy) []*migapi.ImageCopy {
+	for i := len(images) - 1; i >= 0; i-- {
+		if images[i].Tag != image.Tag {
+			continue
+		}
+		inserted := append([]*migapi.ImageCopy{}, images[:i]...)
+		inserted = append(inserted, image)
+		return append(inserted, images[i:]...)
+	}
+	return append(images, image)
+}
+
+// getImageVerificationResult returns the numbers of verified and failed images
+func getImageVerificationResult(images []*migapi.ImageCopy) migapi.ImageVerificationResult {
+	result := migapi.ImageVerificationResult{}
+	for _, image := range images {
+		switch image.Verification {
+		case "":
+		case migapi.ImageVerified:
+			result.VerifiedImages++
+		case migapi.ImageManifestConverted:
+			result.VerifiedImages++
+			result.ConvertedImages++
+		default:
+			result.FailedVerifications++
+		}
+	}
+	return result
+}
+
+// verifyImage compares the manifest digest, and optionally the signatures, of a copied image on
+// the destination registry with the source image, and updates its status.
+func (t *Task) verifyImage(image *migapi.ImageCopy, tagHead bool, sourceCtx, destinationCtx *types.SystemContext) {
+	verifySignatures := t.Owner.Spec.VerifySignatures
+	var message string
+	srcDigest, srcSignatures, err := inspectImage(sourceCtx, image.SourceReference, verifySignatures)
+	if err == nil {
+		var destSignatures [][]byte
+		image.DestDigest, destSignatures, err = inspectImage(destinationCtx, getDestReference(image, tagHead), verifySignatures)
+		if err == nil {
+			image.Verification, message = getVerification(srcDigest, image.CopiedDigest, image.DestDigest, srcSignatures, destSignatures)
+		}
+	}
+	if err != nil {
+		image.Verification = migapi.ImageVerificationFailed
+		message = fmt.Sprintf("verification failed: %s", err.Error())
+	}
+	if isImageVerified(image.Verification) {
+		return
+	}
+	t.Log.Info("Image verification failed.",
+		"tag", image.Tag,
+		"digest", image.Digest,
+		"verification", image.Verification,
+		"error", message)
+	image.Phase = migapi.ImageCopyFailed
+	image.Error = message
+}
+
+// isImageVerified returns whether the verification result is a success
+func isImageVerified(verification string) bool {
+	return verification == migapi.ImageVerified || verification == migapi.ImageManifestConverted
+}
+
+// getVerification compares an image on the destination registry with its source. The manifest of
+// an image converted on copy is compared with the manifest written to the destination registry.
+// Returns: the verification result and, unless verified, its error message.
+func getVerification(srcDigest, copiedDigest, destDigest string, srcSignatures, destSignatures [][]byte) (string, string) {
+	converted := copiedDigest != "" && copiedDigest != srcDigest
+	if converted && copiedDigest != destDigest {
+		return migapi.ImageDigestMismatch,
+			fmt.Sprintf("destination manifest digest %s does not match converted digest %s", destDigest, copiedDigest)
+	}
+	if !converted && srcDigest != destDigest {
+		return migapi.ImageDigestMismatch,
+			fmt.Sprintf("destination manifest digest %s does not match source digest %s", destDigest, srcDigest)
+	}
+	missing := 0
+	for _, srcSignature := range srcSignatures {
+		found := false
+		for _, destSignature := range destSignatures {
+			if bytes.Equal(srcSignature, destSignature) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return migapi.ImageSignaturesMissing,
+			fmt.Sprintf("%d of %d source signatures not found on destination registry", missing, len(srcSignatures))
+	}
+	if converted {
+		return migapi.ImageManifestConverted, ""
+	}
+	return migapi.ImageVerified, ""
+}
@@ -0,0 +1,168 @@
+package directimagestreammigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+func Test_getVerification(t *testing.T) {
+	tests := []struct {
+		name           string
+		srcDigest      string
+		copiedDigest   string
+		destDigest     string
+		srcSignatures  [][]byte
+		destSignatures [][]byte
+		want           string
+	}{
+		{
+			name:       "matching digests",
+			srcDigest:  "sha256:a",
+			destDigest: "sha256:a",
+			want:       migapi.ImageVerified,
+		},
+		{
+			name:       "mismatching digests",
+			srcDigest:  "sha256:a",
+			destDigest: "sha256:b",
+			want:       migapi.ImageDigestMismatch,
+		},
+		{
+			name:         "converted manifest",
+			srcDigest:    "sha256:a",
+			copiedDigest: "sha256:c",
+			destDigest:   "sha256:c",
+			want:         migapi.ImageManifestConverted,
+		},
+		{
+			name:         "converted manifest mismatching digests",
+			srcDigest:    "sha256:a",
+			copiedDigest: "sha256:c",
+			destDigest:   "sha256:a",
+			want:         migapi.ImageDigestMismatch,
+		},
+		{
+			name:           "source signatures copied",
+			srcDigest:      "sha256:a",
+			destDigest:     "sha256:a",
+			srcSignatures:  [][]byte{[]byte("sig1")},
+			destSignatures: [][]byte{[]byte("sig0"), []byte("sig1")},
+			want:           migapi.ImageVerified,
+		},
+		{
+			name:           "source signatures missing",
+			srcDigest:      "sha256:a",
+			destDigest:     "sha256:a",
+			srcSignatures:  [][]byte{[]byte("sig1"), []byte("sig2")},
+			destSignatures: [][]byte{[]byte("sig1")},
+			want:           migapi.ImageSignaturesMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, message := getVerification(tt.srcDigest, tt.copiedDigest, tt.destDigest, tt.srcSignatures, tt.destSignatures)
+			if got != tt.want {
+				t.Errorf("getVerification() = %v, want %v", got, tt.want)
+			}
+			if (message == "") != isImageVerified(tt.want) {
+				t.Errorf("getVerification() message = %q", message)
+			}
+		})
+	}
+}
+
+func Test_nextImageVerifications(t *testing.T) {
+	images := groupImageCopies([]*migapi.ImageCopy{
+		{Tag: "v1", Digest: "a", Phase: migapi.ImageCopyPending},
+		{Tag: "v1", Digest: "b", Phase: migapi.ImageCopyPending},
+		{Tag: "v1", Digest: "c", Phase: migapi.ImageCopyPending},
+		{Tag: "v1", Digest: "d", Phase: migapi.ImageCopyPending},
+		{Tag: "v2", Digest: "e", Phase: migapi.ImageCopyPending},
+	})
+	status := &migapi.DirectImageStreamMigrationStatus{
+		Tags: []*migapi.ImageTagCopy{
+			{Tag: "v1", Items: 4, Processed: 4, Verified: 1, LastVerifiedDigest: "a"},
+			{Tag: "v2", Items: 1, Processed: 1},
+		},
+		Images: []*migapi.ImageCopy{
+			{Tag: "v1", Digest: "b", Phase: migapi.ImageCopyFailed},
+			{Tag: "v1", Digest: "d", Phase: migapi.ImageCopySkippedExisting},
+			{Tag: "v2", Digest: "e", Phase: migapi.ImageCopyCopied, CopiedDigest: "e2"},
+		},
+	}
+	type verification struct {
+		Digest  string
+		Phase   string
+		TagHead bool
+		Listed  bool
+	}
+	got := []verification{}
+	for _, next := range nextImageVerifications(status, images, 2) {
+		got = append(got, verification{next.image.Digest, next.image.Phase, next.tagHead, next.listed})
+	}
+	want := []verification{
+		{Digest: "c", Phase: migapi.ImageCopyCopied},
+		{Digest: "d", Phase: migapi.ImageCopySkippedExisting, TagHead: true, Listed: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nextImageVerifications() = %v, want %v", got, want)
+	}
+	if tag := status.Tags[0]; tag.Verified != 4 || tag.LastVerifiedDigest != "d" {
+		t.Errorf("nextImageVerifications() verified = %d, last = %s", tag.Verified, tag.LastVerifiedDigest)
+	}
+	got = []verification{}
+	for _, next := range nextImageVerifications(status, images, 2) {
+		got = append(got, verification{next.image.Digest, next.image.Phase, next.tagHead, next.listed})
+	}
+	want = []verification{{Digest: "e", Phase: migapi.ImageCopyCopied, TagHead: true, Listed: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nextImageVerifications() = %v, want %v", got, want)
+	}
+	if next := nextImageVerifications(status, images, 2); len(next) != 0 {
+		t.Errorf("nextImageVerifications() returned %d images, want none", len(next))
+	}
+}
+
+func Test_updateVerifiedImageCopies(t *testing.T) {
+	head := &migapi.ImageCopy{Tag: "v1", Digest: "c", Phase: migapi.ImageCopyCopied, Verification: migapi.ImageVerified}
+	other := &migapi.ImageCopy{Tag: "v2", Digest: "d", Phase: migapi.ImageCopyCopied, Verification: migapi.ImageManifestConverted}
+	verified := &migapi.ImageCopy{Tag: "v1", Digest: "a", Phase: migapi.ImageCopyCopied, Verification: migapi.ImageVerified}
+	mismatch := &migapi.ImageCopy{Tag: "v1", Digest: "b", Phase: migapi.ImageCopyFailed, Verification: migapi.ImageDigestMismatch}
+	status := &migapi.DirectImageStreamMigrationStatus{
+		Images:                  []*migapi.ImageCopy{head, other},
+		ImageVerificationResult: migapi.ImageVerificationResult{VerifiedImages: 1},
+	}
+	images := updateVerifiedImageCopies(status, []imageVerification{
+		{image: verified},
+		{image: mismatch},
+		{image: head, tagHead: true, listed: true},
+		{image: other, tagHead: true, listed: true},
+	})
+	if want := []*migapi.ImageCopy{mismatch, head, other}; !reflect.DeepEqual(images, want) {
+		for _, image := range images {
+			t.Logf("got %+v", *image)
+		}
+		t.Errorf("updateVerifiedImageCopies() returned unexpected images")
+	}
+	want := migapi.ImageVerificationResult{VerifiedImages: 4, ConvertedImages: 1, FailedVerifications: 1}
+	if status.ImageVerificationResult != want {
+		t.Errorf("updateVerifiedImageCopies() result = %v, want %v", status.ImageVerificationResult, want)
+	}
+}
+
+func Test_getImageVerificationResult(t *testing.T) {
+	images := []*migapi.ImageCopy{
+		{Verification: migapi.ImageVerified},
+		{Verification: migapi.ImageVerified},
+		{Verification: migapi.ImageManifestConverted},
+		{Verification: migapi.ImageDigestMismatch},
+		{Verification: migapi.ImageVerificationFailed},
+		{},
+	}
+	want := migapi.ImageVerificationResult{VerifiedImages: 3, ConvertedImages: 1, FailedVerifications: 2}
+	if got := getImageVerificationResult(images); got != want {
+		t.Errorf("getImageVerificationResult() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// adoptDestinationPVC validates an existing destination PVC against the PVC that would be created
+// and labels it as adopted so that it is not deleted on rollback. Returns whether the PVC was
+// adopted and the reasons why it cannot be adopted. PVCs created by the migration are not adopted.
+func (t *Task) adoptDestinationPVC(client compat.Client, desired corev1.PersistentVolumeClaim) (bool, []string, error) {
+	existing := corev1.PersistentVolumeClaim{}
+	err := client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, &existing)
+	if err != nil {
+		if k8serror.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, liberr.Wrap(err)
+	}
+	if t.isCreatedByMigration(existing) {
+		return false, nil, nil
+	}
+	if existing.Labels[AdoptedByDirectVolumeMigration] == string(t.Owner.UID) {
+		return true, nil, nil
+	}
+	reasons := getAdoptionMismatches(existing, desired)
+	if len(reasons) > 0 {
+		return false, reasons, nil
+	}
+	if existing.Labels == nil {
+		existing.Labels = map[string]string{}
+	}
+	existing.Labels[AdoptedByDirectVolumeMigration] = string(t.Owner.UID)
+	t.Log.Info("Adopting existing PVC on destination MigCluster",
+		"destPersistentVolumeClaim", path.Join(existing.Namespace, existing.Name))
+	err = client.Update(context.TODO(), &existing)
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	return true, nil, nil
+}
+
+// isCreatedByMigration returns whether the destination PVC was created by this plan or DVM
+func (t *Task) isCreatedByMigration(pvc corev1.PersistentVolumeClaim) bool {
+	if t.PlanResources != nil && t.PlanResources.MigPlan != nil &&
+		pvc.Labels[migapi.MigPlanLabel] == string(t.PlanResources.MigPlan.UID) {
+		return true
+	}
+	return t.Owner.UID != "" && pvc.Labels[MigratedByDirectVolumeMigration] == string(t.Owner.UID)
+}
+
+// getAdoptionMismatches compares an existing destination PVC with the PVC that would be created,
+// the storage class, the volume mode and the access modes must match and the existing PVC
+// must be at least as large
+func getAdoptionMismatches(existing corev1.PersistentVolumeClaim, desired corev1.PersistentVolumeClaim) []string {
+	reasons := []string{}
+	name := path.Join(existing.Namespace, existing.Name)
+	if existing.DeletionTimestamp != nil {
+		return append(reasons, fmt.Sprintf("Destination PVC %s cannot be adopted: it is being deleted", name))
+	}
+	existingClass, desiredClass := "", ""
+	if existing.Spec.StorageClassName != nil {
+		existingClass = *existing.Spec.StorageClassName
+	}
+	if desired.Spec.StorageClassName != nil {
+		desiredClass = *desired.Spec.StorageClassName
+	}
+	if desiredClass != "" && existingClass != desiredClass {
+		reasons = append(reasons, fmt.Sprintf(
+			"Destination PVC %s cannot be adopted: storage class %s does not match the selected storage class %s",
+			name, existingClass, desiredClass))
+	}
+	if getVolumeMode(existing) != getVolumeMode(desired) {
+		reasons = append(reasons, fmt.Sprintf(
+			"Destination PVC %s cannot be adopted: volume mode %s does not match the source volume mode %s",
+			name, getVolumeMode(existing), getVolumeMode(desired)))
+	}
+	for _, accessMode := range desired.Spec.AccessModes {
+		found := false
+		for _, existingMode := range existing.Spec.AccessModes {
+			if existingMode == accessMode {
+				found = true
+				break
+			}
+		}
+		if !found {
+			reasons = append(reasons, fmt.Sprintf(
+				"Destination PVC %s cannot be adopted: access mode %s is not supported", name, accessMode))
+		}
+	}
+	// the capacity of a bound PVC may be larger than requested
+	existingSize := existing.Spec.Resources.Requests[corev1.ResourceStorage]
+	if capacity, found := existing.Status.Capacity[corev1.ResourceStorage]; found {
+		existingSize = capacity
+	}
+	desiredSize := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+	if existingSize.Cmp(desiredSize) < 0 {
+		reasons = append(reasons, fmt.Sprintf(
+			"Destination PVC %s cannot be adopted: capacity %s is smaller than the required capacity %s",
+			name, existingSize.String(), desiredSize.String()))
+	}
+	return reasons
+}
+
+// getVolumeMode returns the volume mode of the PVC, Filesystem when not set
+func getVolumeMode(pvc corev1.PersistentVolumeClaim) corev1.PersistentVolumeMode {
+	if pvc.Spec.VolumeMode == nil {
+		return corev1.PersistentVolumeFilesystem
+	}
+	return *pvc.Spec.VolumeMode
+}
@@ -0,0 +1,98 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func getTestDestinationPVC(storageClass string, size string, labels map[string]string,
+	accessModes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "ns", Labels: labels},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			AccessModes:      accessModes,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func TestTask_adoptDestinationPVC(t *testing.T) {
+	desired := getTestDestinationPVC("gp2", "10Gi", nil, corev1.ReadWriteOnce)
+	tests := []struct {
+		name        string
+		existing    *corev1.PersistentVolumeClaim
+		wantAdopted bool
+		wantReasons int
+		wantLabel   bool
+	}{
+		{
+			name:        "no existing PVC",
+			existing:    nil,
+			wantAdopted: false,
+		},
+		{
+			name:        "matching PVC larger than required",
+			existing:    getTestDestinationPVC("gp2", "20Gi", nil, corev1.ReadWriteOnce, corev1.ReadWriteMany),
+			wantAdopted: true,
+			wantLabel:   true,
+		},
+		{
+			name:        "PVC with a different storage class, access mode and a smaller size",
+			existing:    getTestDestinationPVC("standard", "5Gi", nil, corev1.ReadWriteMany),
+			wantAdopted: false,
+			wantReasons: 3,
+		},
+		{
+			name: "PVC created by a previous migration of the plan",
+			existing: getTestDestinationPVC("standard", "5Gi",
+				map[string]string{migapi.MigPlanLabel: "plan-uid"}, corev1.ReadWriteOnce),
+			wantAdopted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakecompat.NewFakeClient()
+			if tt.existing != nil {
+				client = fakecompat.NewFakeClient(tt.existing)
+			}
+			task := &Task{
+				Log:   log,
+				Owner: &migapi.DirectVolumeMigration{ObjectMeta: metav1.ObjectMeta{UID: "dvm-uid"}},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{ObjectMeta: metav1.ObjectMeta{UID: "plan-uid"}},
+				},
+			}
+			adopted, reasons, err := task.adoptDestinationPVC(client, *desired)
+			if err != nil {
+				t.Fatalf("adoptDestinationPVC() error = %v", err)
+			}
+			if adopted != tt.wantAdopted || len(reasons) != tt.wantReasons {
+				t.Errorf("adoptDestinationPVC() = %v, %v, want %v with %d reasons", adopted, reasons, tt.wantAdopted, tt.wantReasons)
+			}
+			if tt.existing == nil {
+				return
+			}
+			pvc := corev1.PersistentVolumeClaim{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: "pvc", Namespace: "ns"}, &pvc)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got := pvc.Labels[AdoptedByDirectVolumeMigration] == "dvm-uid"; got != tt.wantLabel {
+				t.Errorf("adoptDestinationPVC() adopted label = %v, want %v", got, tt.wantLabel)
+			}
+			if _, found := pvc.Labels[migapi.MigPlanLabel]; found && tt.wantLabel {
+				t.Errorf("adoptDestinationPVC() adopted PVC would be deleted on rollback")
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"strconv"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DirectVolumeMigrationTransferAgent name of the transfer agent server container
+	DirectVolumeMigrationTransferAgent = "transfer-agent"
+	// TransferAgentBinary path of the transfer agent in its image
+	TransferAgentBinary = "/transfer-agent"
+	// transferAgentCertsPath path at which the Stunnel certs are mounted in the transfer agent containers
+	transferAgentCertsPath = "/etc/transfer/certs"
+)
+
+// isTransferAgent returns whether the data is copied by the transfer agent instead of Rsync
+func (t *Task) isTransferAgent() bool {
+	return t.Owner.GetTransferMethod() == migapi.TransferAgentMethod
+}
+
+// getTransferAgentCertsVolume returns the volume of the per-migration certs authenticating
+// both ends of the transfer, the certs generated for Stunnel are reused
+func getTransferAgentCertsVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: "transfer-certs",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: DirectVolumeMigrationStunnelCerts,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  "tls.crt",
+						Path: "tls.crt",
+					},
+					{
+						Key:  "ca.crt",
+						Path: "ca.crt",
+					},
+					{
+						Key:  "tls.key",
+						Path: "tls.key",
+					},
+				},
+			},
+		},
+	}
+}
+
+// setTransferAgentServer replaces the rsyncd and Stunnel containers of a transfer Pod with
+// the transfer agent server, which serves the PVCs mounted by the rsyncd container
+func setTransferAgentServer(spec *corev1.PodSpec, namespace string, image string) {
+	var rsyncd corev1.Container
+	for _, container := range spec.Containers {
+		if container.Name == "rsyncd" {
+			rsyncd = container
+		}
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "transfer-certs",
+			MountPath: transferAgentCertsPath,
+		},
+	}
+	for _, mount := range rsyncd.VolumeMounts {
+		if mount.Name != "rsyncd-conf" && mount.Name != "rsync-creds" {
+			volumeMounts = append(volumeMounts, mount)
+		}
+	}
+	volumes := []corev1.Volume{getTransferAgentCertsVolume()}
+	for _, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			volumes = append(volumes, volume)
+		}
+	}
+	spec.Volumes = volumes
+	spec.Containers = []corev1.Container{
+		{
+			Name:  DirectVolumeMigrationTransferAgent,
+			Image: image,
+			Command: []string{
+				TransferAgentBinary,
+				"server",
+				fmt.Sprintf("--listen=:%d", DirectVolumeMigrationRsyncTransferPort),
+				fmt.Sprintf("--root=/mnt/%s", namespace),
+			},
+			Ports: []corev1.ContainerPort{
+				{
+					Name:          DirectVolumeMigrationStunnel,
+					Protocol:      corev1.ProtocolTCP,
+					ContainerPort: int32(DirectVolumeMigrationRsyncTransferPort),
+				},
+			},
+			VolumeMounts:    volumeMounts,
+			VolumeDevices:   rsyncd.VolumeDevices,
+			SecurityContext: rsyncd.SecurityContext,
+			Resources:       rsyncd.Resources,
+		},
+	}
+}
+
+// getTransferAgentOptions returns the options of the transfer agent client shared by the PVCs,
+// the Rsync settings of the MigrationController CR apply to the transfer agent as well
+func getTransferAgentOptions(bwLimit int64) []string {
+	options := []string{
+		fmt.Sprintf("--streams=%d", settings.Settings.DvmOpts.TransferAgentStreams),
+	}
+	rsyncOptions := settings.Settings.DvmOpts.RsyncOpts
+	if bwLimit != -1 {
+		options = append(options, fmt.Sprintf("--bwlimit=%d", bwLimit))
+	}
+	if rsyncOptions.Delete {
+		options = append(options, "--delete")
+	}
+	if settings.Settings.DvmOpts.StunnelTCPProxy != "" {
+		options = append(options, fmt.Sprintf("--proxy=%s", settings.Settings.DvmOpts.StunnelTCPProxy))
+	}
+	return options
+}
+
+// getTransferAgentClientOptions returns the options of the transfer agent client of a PVC
+func getTransferAgentClientOptions(pvInfo PVCWithSecurityContext, namespace string, addresses []string, bwLimit int64) []string {
+	options := getTransferAgentOptions(bwLimit)
+	if pvInfo.block {
+		options = append(options, "--block", fmt.Sprintf("--source=%s", getBlockDevicePath(namespace, pvInfo.pvcHash)))
+	} else {
+		options = append(options, fmt.Sprintf("--source=/mnt/%s/%s", namespace, pvInfo.pvcHash))
+	}
+	options = append(options, fmt.Sprintf("--volume=%s", pvInfo.pvcHash))
+	for _, address := range addresses {
+		options = append(options, fmt.Sprintf("--address=%s", address))
+	}
+	if pvInfo.verify {
+		options = append(options, "--checksum")
+	}
+	if !pvInfo.block && !pvInfo.filters.IsEmpty() && pvInfo.filters.Validate() == nil {
+		for _, pattern := range pvInfo.filters.Include {
+			options = append(options, fmt.Sprintf("--include=%s", pattern))
+		}
+		for _, pattern := range pvInfo.filters.Exclude {
+			options = append(options, fmt.Sprintf("--exclude=%s", pattern))
+		}
+	}
+	return options
+}
+
+// getTransferAgentClientPod returns the client Pod of a PVC copied by the transfer agent.
+// The container keeps the name of the Rsync client container so that the status of the
+// Pod is reported the same way, the annotation tells the progress controller how to read the logs.
+func (req rsyncClientPodRequirements) getTransferAgentClientPod() corev1.Pod {
+	runAsUser := int64(0)
+	trueBool := true
+	isPrivileged := req.privileged
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "transfer-certs",
+			MountPath: transferAgentCertsPath,
+		},
+	}
+	volumeDevices := []corev1.VolumeDevice{}
+	if req.pvInfo.block {
+		volumeDevices = append(volumeDevices, corev1.VolumeDevice{
+			Name:       req.pvInfo.pvcHash,
+			DevicePath: getBlockDevicePath(req.namespace, req.pvInfo.pvcHash),
+		})
+	} else {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      req.pvInfo.pvcHash,
+			MountPath: fmt.Sprintf("/mnt/%s/%s", req.namespace, req.pvInfo.pvcHash),
+		})
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: req.pvInfo.pvcHash,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: req.pvInfo.getClaimName(),
+				},
+			},
+		},
+		getTransferAgentCertsVolume(),
+	}
+	labels := map[string]string{
+		"app":                   DirectVolumeMigrationRsyncTransfer,
+		"directvolumemigration": DirectVolumeMigrationRsyncClient,
+		migapi.PartOfLabel:      migapi.Application,
+	}
+	labels = Union(labels, GetRsyncPodSelector(req.pvInfo.name))
+	clientPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "dvm-rsync-",
+			Namespace:    req.namespace,
+			Labels:       labels,
+			Annotations: map[string]string{
+				migapi.RsyncPodIdentityLabel:    req.pvInfo.name,
+				migapi.TransferMethodAnnotation: migapi.TransferAgentMethod,
+				migapi.BandwidthLimitAnnotation: strconv.FormatInt(req.bwLimit, 10),
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Volumes:       volumes,
+			Containers: []corev1.Container{
+				{
+					Name:                     DirectVolumeMigrationRsyncClient,
+					Image:                    req.image,
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					Command:                  append([]string{TransferAgentBinary, "client"}, req.agentOptions...),
+					VolumeMounts:             volumeMounts,
+					VolumeDevices:            volumeDevices,
+					SecurityContext: &corev1.SecurityContext{
+						Privileged:             &isPrivileged,
+						RunAsUser:              &runAsUser,
+						ReadOnlyRootFilesystem: &trueBool,
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"MKNOD", "SETPCAP"},
+						},
+					},
+					Resources: req.rsyncResourceReq,
+				},
+			},
+			NodeName: req.nodeName,
+			SecurityContext: &corev1.PodSecurityContext{
+				SupplementalGroups: req.pvInfo.supplementalGroups,
+				FSGroup:            req.pvInfo.fsGroup,
+				SELinuxOptions:     req.pvInfo.seLinuxOptions,
+			},
+		},
+	}
+	applyTransferPodPolicy(&clientPod.Spec, req.policy)
+	return clientPod
+}
@@ -0,0 +1,123 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_getTransferAgentClientOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		pvInfo    PVCWithSecurityContext
+		bwLimit   int64
+		delete    bool
+		proxy     string
+		addresses []string
+		want      []string
+	}{
+		{
+			name:      "filesystem volume with filters",
+			pvInfo:    PVCWithSecurityContext{pvcHash: "hash", verify: true, filters: &migapi.RsyncFilters{Include: []string{"*.db"}, Exclude: []string{"/cache/"}}},
+			bwLimit:   -1,
+			delete:    true,
+			addresses: []string{"route.example.com:443", "10.0.0.1:2222"},
+			want: []string{
+				"--streams=4", "--delete", "--source=/mnt/ns/hash", "--volume=hash",
+				"--address=route.example.com:443", "--address=10.0.0.1:2222", "--checksum",
+				"--include=*.db", "--exclude=/cache/",
+			},
+		},
+		{
+			name:      "block volume through a proxy",
+			pvInfo:    PVCWithSecurityContext{pvcHash: "hash", block: true, filters: &migapi.RsyncFilters{Exclude: []string{"*.tmp"}}},
+			bwLimit:   1024,
+			proxy:     "http://proxy:3128",
+			addresses: []string{"route.example.com:443"},
+			want: []string{
+				"--streams=4", "--bwlimit=1024", "--proxy=http://proxy:3128", "--block",
+				"--source=/mnt/ns/hash/disk", "--volume=hash", "--address=route.example.com:443",
+			},
+		},
+	}
+	defaultOpts := settings.Settings.DvmOpts
+	defer func() { settings.Settings.DvmOpts = defaultOpts }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings.Settings.DvmOpts.TransferAgentStreams = 4
+			settings.Settings.DvmOpts.RsyncOpts.Delete = tt.delete
+			settings.Settings.DvmOpts.StunnelTCPProxy = tt.proxy
+			got := getTransferAgentClientOptions(tt.pvInfo, "ns", tt.addresses, tt.bwLimit)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getTransferAgentClientOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setTransferAgentServer(t *testing.T) {
+	spec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "stunnel-conf"},
+			{Name: "rsync-creds"},
+			{Name: "hash", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"}}},
+		},
+		Containers: []corev1.Container{
+			{
+				Name: "rsyncd",
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "hash", MountPath: "/mnt/ns/hash"},
+					{Name: "rsyncd-conf", MountPath: "/etc/rsyncd.conf"},
+					{Name: "rsync-creds", MountPath: "/etc/rsyncd.secrets"},
+				},
+			},
+			{Name: DirectVolumeMigrationStunnel},
+		},
+	}
+	setTransferAgentServer(&spec, "ns", "agent-image")
+	if len(spec.Containers) != 1 || spec.Containers[0].Name != DirectVolumeMigrationTransferAgent ||
+		spec.Containers[0].Image != "agent-image" {
+		t.Fatalf("setTransferAgentServer() containers = %+v", spec.Containers)
+	}
+	container := spec.Containers[0]
+	if !reflect.DeepEqual(container.Command, []string{TransferAgentBinary, "server", "--listen=:2222", "--root=/mnt/ns"}) {
+		t.Errorf("setTransferAgentServer() command = %v", container.Command)
+	}
+	mounts := []string{}
+	for _, mount := range container.VolumeMounts {
+		mounts = append(mounts, mount.Name)
+	}
+	if !reflect.DeepEqual(mounts, []string{"transfer-certs", "hash"}) {
+		t.Errorf("setTransferAgentServer() mounts = %v", mounts)
+	}
+	volumes := []string{}
+	for _, volume := range spec.Volumes {
+		volumes = append(volumes, volume.Name)
+	}
+	if !reflect.DeepEqual(volumes, []string{"transfer-certs", "hash"}) {
+		t.Errorf("setTransferAgentServer() volumes = %v", volumes)
+	}
+}
+
+func Test_getTransferAgentClientPod(t *testing.T) {
+	req := rsyncClientPodRequirements{
+		pvInfo:       PVCWithSecurityContext{name: "pvc", pvcHash: "hash"},
+		namespace:    "ns",
+		image:        "agent-image",
+		agentOptions: []string{"--volume=hash"},
+	}
+	pod := req.getRsyncClientPodTemplate()
+	if pod.Annotations[migapi.TransferMethodAnnotation] != migapi.TransferAgentMethod ||
+		pod.Annotations[migapi.RsyncPodIdentityLabel] != "pvc" {
+		t.Errorf("getRsyncClientPodTemplate() annotations = %v", pod.Annotations)
+	}
+	if len(pod.Spec.Containers) != 1 || pod.Spec.Containers[0].Name != DirectVolumeMigrationRsyncClient {
+		t.Fatalf("getRsyncClientPodTemplate() containers = %+v", pod.Spec.Containers)
+	}
+	if !reflect.DeepEqual(pod.Spec.Containers[0].Command, []string{TransferAgentBinary, "client", "--volume=hash"}) {
+		t.Errorf("getRsyncClientPodTemplate() command = %v", pod.Spec.Containers[0].Command)
+	}
+}
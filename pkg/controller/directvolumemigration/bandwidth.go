@@ -0,0 +1,54 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// getBandwidthLimit returns the bandwidth limit currently applied to each of the Rsync Pods,
+// the Rsync bandwidth limit of the MigrationController applies outside of the schedule windows
+func (t *Task) getBandwidthLimit() migapi.EffectiveBandwidthLimit {
+	defaultLimit := int64(settings.Settings.DvmOpts.RsyncOpts.BwLimit)
+	return t.Owner.Spec.BandwidthSchedule.GetEffectiveLimit(
+		time.Now(), len(t.Owner.Spec.PersistentVolumeClaims), defaultLimit)
+}
+
+// isBandwidthLimitChanged returns whether a Rsync Pod was started with another bandwidth limit
+// than the one currently in effect. Pods created without the annotation are never restarted.
+func isBandwidthLimitChanged(pod *corev1.Pod, req *rsyncClientPodRequirements) bool {
+	value, exists := pod.Annotations[migapi.BandwidthLimitAnnotation]
+	if !exists {
+		return false
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return limit != req.bwLimit
+}
+
+// restartPodForOperation replaces the Rsync Pod of the current attempt with a Pod using the
+// bandwidth limit in effect. The new Pod keeps the attempt of the Pod it replaces so that
+// the restart does not count towards the backoff limit.
+func (t *Task) restartPodForOperation(client compat.Client, req *rsyncClientPodRequirements,
+	operation migapi.RsyncOperation, pod *corev1.Pod) error {
+	t.Log.Info("Restarting Rsync Pod to apply the bandwidth limit of the schedule",
+		"pod", path.Join(pod.Namespace, pod.Name),
+		"pvc", operation,
+		"bandwidthLimit", req.bwLimit)
+	err := client.Delete(context.TODO(), pod)
+	if err != nil && !k8serror.IsNotFound(err) {
+		return liberr.Wrap(err)
+	}
+	operation.CurrentAttempt -= 1
+	return t.createNewPodForOperation(client, req, operation)
+}
@@ -0,0 +1,87 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konveyor/controller/pkg/logging"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func getTestRsyncPodWithBandwidthLimit(podName string, phase corev1.PodPhase, limit string) *corev1.Pod {
+	pod := getTestRsyncPodWithStatusForPVC(podName, "pvc-1", "ns-1", "1", phase, time.Now())
+	if limit != "" {
+		pod.Annotations = map[string]string{migapi.BandwidthLimitAnnotation: limit}
+	}
+	return pod
+}
+
+func TestTask_ensureRsyncOperationsBandwidthLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		pod         *corev1.Pod
+		bwLimit     int64
+		wantPending bool
+		wantLimit   string
+	}{
+		{
+			name:      "running pod started with the limit in effect is kept",
+			pod:       getTestRsyncPodWithBandwidthLimit("pod-1", corev1.PodRunning, "1024"),
+			bwLimit:   1024,
+			wantLimit: "1024",
+		},
+		{
+			name:        "running pod started with another limit is restarted with the same attempt",
+			pod:         getTestRsyncPodWithBandwidthLimit("pod-1", corev1.PodRunning, "1024"),
+			bwLimit:     -1,
+			wantPending: true,
+			wantLimit:   "-1",
+		},
+		{
+			name:      "running pod started without the annotation is kept",
+			pod:       getTestRsyncPodWithBandwidthLimit("pod-1", corev1.PodRunning, ""),
+			bwLimit:   512,
+			wantLimit: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakecompat.NewFakeClient(tt.pod)
+			tr := &Task{
+				Log: logging.WithName("bandwidth-test"),
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{
+						BackOffLimit: 2,
+					},
+				},
+			}
+			req := getRsyncClientPodRequirements("pvc-1", "ns-1")
+			req.bwLimit = tt.bwLimit
+			got, _ := tr.ensureRsyncOperations(client, []rsyncClientPodRequirements{req})
+			if (got.Pending() > 0) != tt.wantPending {
+				t.Errorf("ensureRsyncOperations() got %d pending operations, want pending %v", got.Pending(), tt.wantPending)
+			}
+			operation := tr.Owner.Status.GetRsyncOperationStatusForPVC(&corev1.ObjectReference{Name: "pvc-1", Namespace: "ns-1"})
+			if operation.CurrentAttempt != 1 {
+				t.Errorf("ensureRsyncOperations() attempt = %d, want 1", operation.CurrentAttempt)
+			}
+			pods := corev1.PodList{}
+			err := client.List(context.TODO(), &pods,
+				k8sclient.InNamespace("ns-1"),
+				k8sclient.MatchingLabels{RsyncAttemptLabel: "1"})
+			if err != nil {
+				t.Fatalf("failed listing pods: %v", err)
+			}
+			if len(pods.Items) != 1 {
+				t.Fatalf("ensureRsyncOperations() got %d pods, want 1", len(pods.Items))
+			}
+			if limit := pods.Items[0].Annotations[migapi.BandwidthLimitAnnotation]; limit != tt.wantLimit {
+				t.Errorf("ensureRsyncOperations() pod bandwidth limit = %q, want %q", limit, tt.wantLimit)
+			}
+		})
+	}
+}
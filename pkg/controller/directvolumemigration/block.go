@@ -0,0 +1,82 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DirectVolumeMigrationBlockDevice name of the device file of a block volume in the Rsync Pods
+const DirectVolumeMigrationBlockDevice = "disk"
+
+// rsyncDevicesUnsupportedMessage termination message of an Rsync container whose
+// rsync binary does not support the options needed to sync block devices
+const rsyncDevicesUnsupportedMessage = "rsync in the transfer image does not support the %s option required to migrate block volumes"
+
+// getBlockDevicePath returns the path at which a block volume is attached in the Rsync Pods.
+// The device is placed inside the directory served by the rsyncd module of the PVC so that
+// the module configuration is identical for filesystem and block volumes.
+func getBlockDevicePath(namespace string, pvcHash string) string {
+	return fmt.Sprintf("/mnt/%s/%s/%s", namespace, pvcHash, DirectVolumeMigrationBlockDevice)
+}
+
+// getBlockRsyncOptions returns the Rsync options needed to sync the content of a block device.
+// The device is read as a regular file and written in place into the destination device,
+// the delta-transfer algorithm is forced so that only changed regions are sent over the tunnel.
+func getBlockRsyncOptions() []string {
+	return []string{
+		"--copy-devices",
+		"--write-devices",
+		"--inplace",
+		"--no-whole-file",
+	}
+}
+
+// getBlockRsyncCheckScript returns a shell snippet verifying that the rsync binary of the
+// transfer image supports the device options, which are missing from older rsync releases.
+// On failure, the container exits with a termination message identifying the option.
+func getBlockRsyncCheckScript() string {
+	checks := []string{}
+	for _, option := range []string{"--copy-devices", "--write-devices"} {
+		message := fmt.Sprintf(rsyncDevicesUnsupportedMessage, option)
+		checks = append(checks, fmt.Sprintf(
+			"if ! rsync --help | grep -q -- '%s'; then echo '%s' | tee /dev/termination-log; exit 1; fi;",
+			option, message))
+	}
+	return strings.Join(checks, " ")
+}
+
+// getRsyncdCommand returns the command of the rsyncd container of the transfer Pod. With block
+// volumes, the container fails before accepting any transfer when rsync cannot write into devices.
+func getRsyncdCommand(block bool) []string {
+	command := []string{"/usr/bin/rsync", "--daemon", "--no-detach", "--port=22", "-vvv"}
+	if !block {
+		return command
+	}
+	return []string{
+		"/bin/bash",
+		"-c",
+		fmt.Sprintf("%s exec %s", getBlockRsyncCheckScript(), strings.Join(command, " ")),
+	}
+}
+
+// isRsyncDevicesUnsupported returns whether the message reports an rsync binary
+// without support for the device options.
+func isRsyncDevicesUnsupported(message string) bool {
+	return strings.HasPrefix(message, strings.Split(rsyncDevicesUnsupportedMessage, "%s")[0])
+}
+
+// getRsyncDevicesUnsupportedMessage returns the termination message of a container of the
+// Pod that exited because its rsync binary does not support the device options.
+func getRsyncDevicesUnsupportedMessage(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		for _, terminated := range []*corev1.ContainerStateTerminated{
+			status.State.Terminated, status.LastTerminationState.Terminated} {
+			if terminated != nil && isRsyncDevicesUnsupported(terminated.Message) {
+				return strings.TrimSpace(terminated.Message)
+			}
+		}
+	}
+	return ""
+}
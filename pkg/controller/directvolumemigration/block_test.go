@@ -0,0 +1,146 @@
+package directvolumemigration
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_getRsyncClientPodTemplate_volumeMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		block       bool
+		wantSource  string
+		wantDevices int
+		wantMounts  int
+	}{
+		{
+			name:        "filesystem volume is mounted",
+			block:       false,
+			wantSource:  "/mnt/ns/pvc-hash/ ",
+			wantDevices: 0,
+			wantMounts:  2,
+		},
+		{
+			name:        "block volume is attached as a device",
+			block:       true,
+			wantSource:  "/mnt/ns/pvc-hash/disk ",
+			wantDevices: 1,
+			wantMounts:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := rsyncClientPodRequirements{
+				pvInfo:    PVCWithSecurityContext{name: "pvc", pvcHash: "pvc-hash", block: tt.block},
+				namespace: "ns",
+				destIP:    "localhost",
+			}
+			if tt.block {
+				req.rsyncOptions = getBlockRsyncOptions()
+			}
+			pod := req.getRsyncClientPodTemplate()
+			container := pod.Spec.Containers[0]
+			if len(container.VolumeDevices) != tt.wantDevices {
+				t.Errorf("getRsyncClientPodTemplate() volumeDevices = %v, want %d", container.VolumeDevices, tt.wantDevices)
+			}
+			if len(container.VolumeMounts) != tt.wantMounts {
+				t.Errorf("getRsyncClientPodTemplate() volumeMounts = %v, want %d", container.VolumeMounts, tt.wantMounts)
+			}
+			command := container.Command[2]
+			if !strings.Contains(command, tt.wantSource) {
+				t.Errorf("getRsyncClientPodTemplate() command = %s, want source %s", command, tt.wantSource)
+			}
+			if strings.Contains(command, "rsync --help") != tt.block {
+				t.Errorf("getRsyncClientPodTemplate() command = %s, want device options check %v", command, tt.block)
+			}
+			if tt.block && !strings.Contains(command, "--copy-devices --write-devices --inplace") {
+				t.Errorf("getRsyncClientPodTemplate() command = %s, want block options", command)
+			}
+			if tt.block && container.VolumeDevices[0].DevicePath != getBlockDevicePath("ns", "pvc-hash") {
+				t.Errorf("getRsyncClientPodTemplate() devicePath = %s", container.VolumeDevices[0].DevicePath)
+			}
+		})
+	}
+}
+
+func Test_rsyncConfigTemplate_block(t *testing.T) {
+	var tpl bytes.Buffer
+	temp := template.Must(template.New("config").Parse(rsyncConfigTemplate))
+	err := temp.Execute(&tpl, rsyncConfig{
+		SshUser:   "root",
+		Namespace: "ns",
+		PVCList:   []pvc{{Name: "fs-hash"}, {Name: "block-hash", Block: true}},
+	})
+	if err != nil {
+		t.Fatalf("rsyncConfigTemplate error = %v", err)
+	}
+	modules := strings.Split(tpl.String(), "[")
+	if len(modules) != 3 {
+		t.Fatalf("rsyncConfigTemplate = %s, want 2 modules", tpl.String())
+	}
+	refuse := "        refuse options = !copy-devices !write-devices\n"
+	if strings.Contains(modules[1], refuse) {
+		t.Errorf("rsyncConfigTemplate filesystem module = %s, want device options refused", modules[1])
+	}
+	if !strings.Contains(modules[2], "read only = false\n"+refuse) {
+		t.Errorf("rsyncConfigTemplate block module = %s, want device options accepted", modules[2])
+	}
+}
+
+func Test_getRsyncdCommand(t *testing.T) {
+	if got := getRsyncdCommand(false); got[0] != "/usr/bin/rsync" {
+		t.Errorf("getRsyncdCommand(false) = %v, want rsyncd", got)
+	}
+	got := getRsyncdCommand(true)
+	if len(got) != 3 || !strings.HasPrefix(got[2], "if ! rsync --help | grep -q -- '--copy-devices'") ||
+		!strings.HasSuffix(got[2], "exec /usr/bin/rsync --daemon --no-detach --port=22 -vvv") {
+		t.Errorf("getRsyncdCommand(true) = %v, want device options check", got)
+	}
+}
+
+func Test_getRsyncDevicesUnsupportedMessage(t *testing.T) {
+	message := fmt.Sprintf(rsyncDevicesUnsupportedMessage, "--write-devices")
+	tests := []struct {
+		name   string
+		status corev1.ContainerStatus
+		want   string
+	}{
+		{
+			name: "running container",
+			status: corev1.ContainerStatus{
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			},
+		},
+		{
+			name: "restarted container without device options",
+			status: corev1.ContainerStatus{
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: message + "\n"},
+				},
+			},
+			want: message,
+		},
+		{
+			name: "container failed for another reason",
+			status: corev1.ContainerStatus{
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "connection refused"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{tt.status}}}
+			if got := getRsyncDevicesUnsupportedMessage(pod); got != tt.want {
+				t.Errorf("getRsyncDevicesUnsupportedMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -26,16 +26,30 @@ var phaseDescriptions = map[string]string{
 	DestinationNamespacesCreated:         "Checking if the target namespaces have been created.",
 	CreateDestinationPVCs:                "Creating PVCs in the target namespaces",
 	DestinationPVCsCreated:               "Checking whether the created PVCs are bound",
-	CreateRsyncRoute:                     "Creating one route for each namespace for Rsync on the target cluster",
+	CreateRsyncRoute:                     "Creating one transfer endpoint for each namespace for Rsync on the target cluster",
 	CreateRsyncConfig:                    "Creating a config map and secrets on both the source and target clusters for Rsync configuration",
 	CreateStunnelConfig:                  "Creating a config map and secrets for Stunnel to connect to Rsync on the source and target clusters",
+	CreateNetworkPolicies:                "Creating temporary network policies allowing the Rsync traffic in namespaces restricted by network policies",
 	CreatePVProgressCRs:                  "Creating a Direct Volume Migration Progress CR to get progress percentage and transfer rate",
 	CreateRsyncTransferPods:              "Creating Rsync daemon pods on the target cluster",
 	WaitForRsyncTransferPodsRunning:      "Waiting for the Rsync daemon pod to run",
-	EnsureRsyncRouteAdmitted:             "Waiting for Rsync route to be admitted.",
+	EnsureRsyncRouteAdmitted:             "Waiting for Rsync transfer endpoint to be ready.",
 	DeleteRsyncResources:                 "Deleting Rsync resources created by this migration",
 	WaitForRsyncResourcesTerminated:      "Waiting for Rsync resources to terminate",
+	CreateSourceSnapshots:                "Creating CSI snapshots of the source PVCs copied from a snapshot",
+	CreateSnapshotClones:                 "Provisioning temporary PVCs from the CSI snapshots of the source PVCs",
 	RunRsyncOperations:                   "Running Rsync Pods to migrate Persistent Volume data",
+	WaitForNextRsyncPass:                 "Waiting for the next incremental Rsync pass or the cutover",
+	VerifyTransferredData:                "Verifying the checksums of the transferred data on the source and target clusters",
+	RemapOwnership:                       "Remapping the ownership of the transferred files to the UID and group ranges of the target namespaces",
+	CreatePreflightEndpoint:              "Creating the transfer endpoint of the network preflight check on the target cluster",
+	EnsurePreflightEndpointReady:         "Waiting for the transfer endpoint of the network preflight check to be ready",
+	CreatePreflightServer:                "Creating the Stunnel server pod of the network preflight check on the target cluster",
+	WaitForPreflightServerRunning:        "Waiting for the Stunnel server pod of the network preflight check to run",
+	CreatePreflightClients:               "Creating one network preflight pod on each source node hosting PVCs",
+	WaitForPreflightClientsCompleted:     "Waiting for the network preflight pods to measure the connectivity and the throughput",
+	DeletePreflightResources:             "Deleting the resources created by the network preflight check",
+	WaitForPreflightResourcesTerminated:  "Waiting for the resources of the network preflight check to terminate",
 	MigrationFailed:                      "The migration attempt failed, please see errors for more details",
 	Completed:                            "Complete",
 }
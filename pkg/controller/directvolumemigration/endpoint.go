@@ -0,0 +1,498 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	migevent "github.com/konveyor/mig-controller/pkg/event"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// DirectVolumeMigrationRsyncTransferPort port of the Stunnel server in the Rsync transfer Pod
+	DirectVolumeMigrationRsyncTransferPort = 2222
+	// DirectVolumeMigrationRsyncTransferTLSPort port of the TLS passthrough Route and Ingress endpoints
+	DirectVolumeMigrationRsyncTransferTLSPort = 443
+	// IngressSSLPassthroughAnnotation enables SSL passthrough on the NGINX ingress controller
+	IngressSSLPassthroughAnnotation = "nginx.ingress.kubernetes.io/ssl-passthrough"
+)
+
+// transferEndpoint exposes the Rsync transfer Service of a namespace on the destination cluster
+type transferEndpoint interface {
+	// serviceType type of the Rsync transfer Service
+	serviceType() corev1.ServiceType
+	// create creates the resources exposing the Rsync transfer Service
+	create(client compat.Client, namespace string, labels map[string]string) error
+	// getAddresses returns the host:port addresses of the endpoint once ready,
+	// otherwise returns the reason the endpoint is not ready
+	getAddresses(client compat.Client, namespace string) ([]string, string, error)
+}
+
+// getTransferEndpoint returns the transfer endpoint configured on the destination MigCluster
+func (t *Task) getTransferEndpoint() (transferEndpoint, error) {
+	cluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if cluster == nil {
+		return nil, liberr.Wrap(fmt.Errorf("destination cluster not found"))
+	}
+	config := migapi.TransferEndpoint{}
+	if cluster.Spec.TransferEndpoint != nil {
+		config = *cluster.Spec.TransferEndpoint
+	}
+	switch cluster.GetTransferEndpointType() {
+	case migapi.RouteTransferEndpoint:
+		// Ignore error since this is optional config and won't break
+		// anything if it doesn't exist
+		subdomain, _ := cluster.GetClusterSubdomain(t.Client)
+		return &routeEndpoint{subdomain: subdomain}, nil
+	case migapi.LoadBalancerTransferEndpoint:
+		return &loadBalancerEndpoint{annotations: config.Annotations}, nil
+	case migapi.NodePortTransferEndpoint:
+		return &nodePortEndpoint{nodeAddresses: config.NodeAddresses}, nil
+	case migapi.IngressTransferEndpoint:
+		subdomain := config.Subdomain
+		if subdomain == "" {
+			subdomain, _ = cluster.GetClusterSubdomain(t.Client)
+		}
+		if subdomain == "" {
+			return nil, liberr.Wrap(
+				fmt.Errorf("a subdomain is required for the Ingress transfer endpoint of cluster %s", cluster.Name))
+		}
+		return &ingressEndpoint{
+			subdomain:        subdomain,
+			ingressClassName: config.IngressClassName,
+			annotations:      config.Annotations,
+		}, nil
+	default:
+		return nil, liberr.Wrap(
+			fmt.Errorf("unsupported transfer endpoint type %s on cluster %s",
+				cluster.GetTransferEndpointType(), cluster.Name))
+	}
+}
+
+// createRsyncTransferEndpoints creates the Rsync transfer Service and its endpoint in all destination namespaces
+func (t *Task) createRsyncTransferEndpoints() error {
+	// Get client for destination
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return err
+	}
+	endpoint, err := t.getTransferEndpoint()
+	if err != nil {
+		return err
+	}
+	pvcMap := t.getPVCNamespaceMap()
+	dvmLabels := t.buildDVMLabels()
+	dvmLabels["purpose"] = DirectVolumeMigrationRsync
+
+	for bothNs := range pvcMap {
+		labels := t.Owner.GetCorrelationLabels()
+		labels["app"] = DirectVolumeMigrationRsyncTransfer
+		err = t.createTransferEndpoint(destClient, endpoint, getDestNs(bothNs), dvmLabels, labels)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTransferEndpoint creates the transfer Service selecting the Stunnel server Pod and its endpoint in a namespace
+func (t *Task) createTransferEndpoint(destClient compat.Client, endpoint transferEndpoint, ns string,
+	selector map[string]string, labels map[string]string) error {
+	svc := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DirectVolumeMigrationRsyncTransferSvc,
+			Namespace: ns,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       DirectVolumeMigrationStunnel,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(DirectVolumeMigrationRsyncTransferPort),
+					TargetPort: intstr.IntOrString{Type: intstr.Int, IntVal: DirectVolumeMigrationRsyncTransferPort},
+				},
+			},
+			Selector: selector,
+			Type:     endpoint.serviceType(),
+		},
+	}
+	if lb, cast := endpoint.(*loadBalancerEndpoint); cast {
+		svc.Annotations = lb.annotations
+	}
+
+	t.Log.Info("Creating Rsync Transfer Service for Stunnel connection "+
+		"on destination MigCluster ",
+		"service", path.Join(svc.Namespace, svc.Name),
+		"type", svc.Spec.Type)
+	err := destClient.Create(context.TODO(), &svc)
+	if k8serror.IsAlreadyExists(err) {
+		t.Log.Info("Rsync transfer svc already exists on destination",
+			"service", path.Join(svc.Namespace, svc.Name))
+	} else if err != nil {
+		return err
+	}
+
+	return endpoint.create(destClient, ns, labels)
+}
+
+// areRsyncTransferEndpointsReady checks whether the endpoints in all destination namespaces are ready
+// and resolves their addresses into t.RsyncRoutes
+func (t *Task) areRsyncTransferEndpointsReady() (bool, []string, error) {
+	messages := []string{}
+	// Get client for destination
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, messages, err
+	}
+	endpoint, err := t.getTransferEndpoint()
+	if err != nil {
+		return false, messages, err
+	}
+	nsMap := t.getPVCNamespaceMap()
+	for bothNs := range nsMap {
+		namespace := getDestNs(bothNs)
+		addresses, reason, err := endpoint.getAddresses(destClient, namespace)
+		if err != nil {
+			return false, messages, err
+		}
+		if len(addresses) == 0 {
+			t.Log.Info("Rsync Transfer endpoint is not ready.",
+				"namespace", namespace, "reason", reason)
+			messages = append(messages, reason)
+			continue
+		}
+		t.RsyncRoutes[namespace] = addresses[0]
+	}
+	if len(messages) > 0 {
+		return false, messages, nil
+	}
+	return true, []string{}, nil
+}
+
+// getRsyncTransferAddresses returns the addresses of the endpoint in the destination namespace
+func (t *Task) getRsyncTransferAddresses(namespace string) ([]string, error) {
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := t.getTransferEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	addresses, reason, err := endpoint.getAddresses(destClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, liberr.Wrap(
+			fmt.Errorf("Rsync transfer endpoint in namespace %s is not ready: %s", namespace, reason))
+	}
+	t.RsyncRoutes[namespace] = addresses[0]
+	return addresses, nil
+}
+
+// getRsyncTransferService returns the Rsync transfer Service
+func getRsyncTransferService(client compat.Client, namespace string) (*corev1.Service, error) {
+	svc := corev1.Service{}
+	key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferSvc, Namespace: namespace}
+	err := client.Get(context.TODO(), key, &svc)
+	if err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+// joinHostPort returns a host:port address
+func joinHostPort(host string, port int32) string {
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// routeEndpoint exposes the Service through an OpenShift Route with TLS passthrough
+type routeEndpoint struct {
+	subdomain string
+}
+
+func (r *routeEndpoint) serviceType() corev1.ServiceType {
+	return corev1.ServiceTypeClusterIP
+}
+
+func (r *routeEndpoint) create(client compat.Client, namespace string, labels map[string]string) error {
+	route := routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DirectVolumeMigrationRsyncTransferRoute,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: routev1.RouteSpec{
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: DirectVolumeMigrationRsyncTransferSvc,
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.IntOrString{Type: intstr.Int, IntVal: DirectVolumeMigrationRsyncTransferPort},
+			},
+			TLS: &routev1.TLSConfig{
+				Termination: routev1.TLSTerminationPassthrough,
+			},
+		},
+	}
+	// This is a backdoor setting to help guarantee DVM can still function if a
+	// user is migrating namespaces that are 60+ characters
+	// NOTE: We do no validation of this subdomain value. User is expected to
+	// set this properly and it's only used for the unlikely case a user needs
+	// to migrate namespaces with very long names.
+	if r.subdomain != "" {
+		route.Spec.Host = getTransferEndpointHost(namespace, r.subdomain)
+	}
+	err := client.Create(context.TODO(), &route)
+	if k8serror.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *routeEndpoint) getAddresses(client compat.Client, namespace string) ([]string, string, error) {
+	route := routev1.Route{}
+	key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: namespace}
+	err := client.Get(context.TODO(), key, &route)
+	if err != nil {
+		return nil, "", err
+	}
+	// Logs abnormal events related to route if any are found
+	migevent.LogAbnormalEventsForResource(
+		client, log,
+		"Found abnormal event for Rsync Route on destination cluster",
+		types.NamespacedName{Namespace: route.Namespace, Name: route.Name},
+		route.UID, "Route")
+
+	message := "no status condition available for the route"
+	// Check if we can find the admitted condition for the route
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type != routev1.RouteAdmitted {
+				continue
+			}
+			if condition.Status == corev1.ConditionTrue && route.Spec.Host != "" {
+				return []string{joinHostPort(route.Spec.Host, DirectVolumeMigrationRsyncTransferTLSPort)}, "", nil
+			}
+			message = condition.Message
+		}
+	}
+	return nil, message, nil
+}
+
+// loadBalancerEndpoint exposes the Service through a cloud load balancer
+type loadBalancerEndpoint struct {
+	annotations map[string]string
+}
+
+func (r *loadBalancerEndpoint) serviceType() corev1.ServiceType {
+	return corev1.ServiceTypeLoadBalancer
+}
+
+func (r *loadBalancerEndpoint) create(client compat.Client, namespace string, labels map[string]string) error {
+	return nil
+}
+
+func (r *loadBalancerEndpoint) getAddresses(client compat.Client, namespace string) ([]string, string, error) {
+	svc, err := getRsyncTransferService(client, namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	addresses := []string{}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		host := ingress.IP
+		if ingress.Hostname != "" {
+			host = ingress.Hostname
+		}
+		if host != "" {
+			addresses = append(addresses, joinHostPort(host, DirectVolumeMigrationRsyncTransferPort))
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Sprintf("load balancer for service %s/%s has not been provisioned", svc.Namespace, svc.Name), nil
+	}
+	return addresses, "", nil
+}
+
+// nodePortEndpoint exposes the Service on a port of every node
+type nodePortEndpoint struct {
+	nodeAddresses []string
+}
+
+func (r *nodePortEndpoint) serviceType() corev1.ServiceType {
+	return corev1.ServiceTypeNodePort
+}
+
+func (r *nodePortEndpoint) create(client compat.Client, namespace string, labels map[string]string) error {
+	return nil
+}
+
+func (r *nodePortEndpoint) getAddresses(client compat.Client, namespace string) ([]string, string, error) {
+	svc, err := getRsyncTransferService(client, namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	var nodePort int32
+	for _, port := range svc.Spec.Ports {
+		if port.Name == DirectVolumeMigrationStunnel {
+			nodePort = port.NodePort
+		}
+	}
+	if nodePort == 0 {
+		return nil, fmt.Sprintf("node port for service %s/%s has not been allocated", svc.Namespace, svc.Name), nil
+	}
+	hosts := r.nodeAddresses
+	if len(hosts) == 0 {
+		hosts, err = getNodeAddresses(client)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, "no node address found for the node port service", nil
+	}
+	addresses := []string{}
+	for _, host := range hosts {
+		addresses = append(addresses, joinHostPort(host, nodePort))
+	}
+	return addresses, "", nil
+}
+
+// getNodeAddresses returns the external addresses of the ready nodes,
+// the internal addresses are used when no node has an external address
+func getNodeAddresses(client compat.Client) ([]string, error) {
+	nodeList := corev1.NodeList{}
+	err := client.List(context.TODO(), &nodeList)
+	if err != nil {
+		return nil, err
+	}
+	external, internal := []string{}, []string{}
+	for _, node := range nodeList.Items {
+		if !isNodeReady(&node) || node.Spec.Unschedulable {
+			continue
+		}
+		for _, address := range node.Status.Addresses {
+			switch address.Type {
+			case corev1.NodeExternalIP:
+				external = append(external, address.Address)
+			case corev1.NodeInternalIP:
+				internal = append(internal, address.Address)
+			}
+		}
+	}
+	if len(external) > 0 {
+		return external, nil
+	}
+	return internal, nil
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// ingressEndpoint exposes the Service through an Ingress with SSL passthrough
+type ingressEndpoint struct {
+	subdomain        string
+	ingressClassName string
+	annotations      map[string]string
+}
+
+func (r *ingressEndpoint) serviceType() corev1.ServiceType {
+	return corev1.ServiceTypeClusterIP
+}
+
+func (r *ingressEndpoint) create(client compat.Client, namespace string, labels map[string]string) error {
+	annotations := map[string]string{
+		IngressSSLPassthroughAnnotation: "true",
+	}
+	for key, value := range r.annotations {
+		annotations[key] = value
+	}
+	pathType := networkingv1.PathTypeImplementationSpecific
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        DirectVolumeMigrationRsyncTransferRoute,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: getTransferEndpointHost(namespace, r.subdomain),
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: DirectVolumeMigrationRsyncTransferSvc,
+											Port: networkingv1.ServiceBackendPort{
+												Number: DirectVolumeMigrationRsyncTransferPort,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if r.ingressClassName != "" {
+		ingress.Spec.IngressClassName = &r.ingressClassName
+	}
+	err := client.Create(context.TODO(), &ingress)
+	if k8serror.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *ingressEndpoint) getAddresses(client compat.Client, namespace string) ([]string, string, error) {
+	ingress := networkingv1.Ingress{}
+	key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: namespace}
+	err := client.Get(context.TODO(), key, &ingress)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(ingress.Status.LoadBalancer.Ingress) == 0 || len(ingress.Spec.Rules) == 0 {
+		return nil, fmt.Sprintf("ingress %s/%s has not been admitted by an ingress controller", ingress.Namespace, ingress.Name), nil
+	}
+	return []string{joinHostPort(ingress.Spec.Rules[0].Host, DirectVolumeMigrationRsyncTransferTLSPort)}, "", nil
+}
+
+// getTransferEndpointHost returns the host of the endpoint in the namespace
+func getTransferEndpointHost(namespace string, subdomain string) string {
+	// Ensure that the host prefix will not exceed 63 chars
+	prefix := fmt.Sprintf("%s-%s", DirectVolumeMigrationRsyncTransferRoute, getMD5Hash(namespace))
+	if len(prefix) > 62 {
+		prefix = prefix[0:62]
+	}
+	return fmt.Sprintf("%s.%s", prefix, subdomain)
+}
@@ -0,0 +1,172 @@
+package directvolumemigration
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/konveyor/mig-controller/pkg/compat"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getTestRsyncTransferService(svcType corev1.ServiceType, nodePort int32, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: DirectVolumeMigrationRsyncTransferSvc, Namespace: "ns"},
+		Spec: corev1.ServiceSpec{
+			Type: svcType,
+			Ports: []corev1.ServicePort{
+				{Name: DirectVolumeMigrationStunnel, Port: DirectVolumeMigrationRsyncTransferPort, NodePort: nodePort},
+			},
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}},
+	}
+}
+
+func getTestNode(name string, ready corev1.ConditionStatus, addresses ...corev1.NodeAddress) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			Addresses:  addresses,
+		},
+	}
+}
+
+func Test_transferEndpoint_getAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint transferEndpoint
+		client   compat.Client
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "load balancer not provisioned",
+			endpoint: &loadBalancerEndpoint{},
+			client:   fakecompat.NewFakeClient(getTestRsyncTransferService(corev1.ServiceTypeLoadBalancer, 0)),
+			want:     nil,
+		},
+		{
+			name:     "load balancer with ip and hostname",
+			endpoint: &loadBalancerEndpoint{},
+			client: fakecompat.NewFakeClient(getTestRsyncTransferService(corev1.ServiceTypeLoadBalancer, 0,
+				corev1.LoadBalancerIngress{IP: "10.0.0.1"},
+				corev1.LoadBalancerIngress{Hostname: "lb.example.com"})),
+			want: []string{"10.0.0.1:2222", "lb.example.com:2222"},
+		},
+		{
+			name:     "service not created",
+			endpoint: &loadBalancerEndpoint{},
+			client:   fakecompat.NewFakeClient(),
+			wantErr:  true,
+		},
+		{
+			name:     "node port with configured addresses",
+			endpoint: &nodePortEndpoint{nodeAddresses: []string{"192.168.1.10", "fd00::1"}},
+			client:   fakecompat.NewFakeClient(getTestRsyncTransferService(corev1.ServiceTypeNodePort, 31222)),
+			want:     []string{"192.168.1.10:31222", "[fd00::1]:31222"},
+		},
+		{
+			name:     "node port prefers external addresses of ready nodes",
+			endpoint: &nodePortEndpoint{},
+			client: fakecompat.NewFakeClient(
+				getTestRsyncTransferService(corev1.ServiceTypeNodePort, 31222),
+				getTestNode("node-1", corev1.ConditionTrue,
+					corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+					corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "1.2.3.4"}),
+				getTestNode("node-2", corev1.ConditionFalse,
+					corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "1.2.3.5"})),
+			want: []string{"1.2.3.4:31222"},
+		},
+		{
+			name:     "node port falls back to internal addresses",
+			endpoint: &nodePortEndpoint{},
+			client: fakecompat.NewFakeClient(
+				getTestRsyncTransferService(corev1.ServiceTypeNodePort, 31222),
+				getTestNode("node-1", corev1.ConditionTrue,
+					corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.1"})),
+			want: []string{"10.0.0.1:31222"},
+		},
+		{
+			name:     "node port not allocated",
+			endpoint: &nodePortEndpoint{nodeAddresses: []string{"192.168.1.10"}},
+			client:   fakecompat.NewFakeClient(getTestRsyncTransferService(corev1.ServiceTypeNodePort, 0)),
+			want:     nil,
+		},
+		{
+			name:     "ingress not admitted",
+			endpoint: &ingressEndpoint{subdomain: "apps.example.com"},
+			client: fakecompat.NewFakeClient(&networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: "ns"},
+				Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "dvm.apps.example.com"}}},
+			}),
+			want: nil,
+		},
+		{
+			name:     "ingress admitted",
+			endpoint: &ingressEndpoint{subdomain: "apps.example.com"},
+			client: fakecompat.NewFakeClient(&networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: "ns"},
+				Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "dvm.apps.example.com"}}},
+				Status: networkingv1.IngressStatus{LoadBalancer: corev1.LoadBalancerStatus{
+					Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+				}},
+			}),
+			want: []string{"dvm.apps.example.com:443"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason, err := tt.endpoint.getAddresses(tt.client, "ns")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getAddresses() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getAddresses() got = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && len(got) == 0 && reason == "" {
+				t.Errorf("getAddresses() expected a reason for the endpoint not being ready")
+			}
+		})
+	}
+}
+
+func Test_ingressEndpoint_create(t *testing.T) {
+	client := fakecompat.NewFakeClient()
+	endpoint := &ingressEndpoint{
+		subdomain:        "apps.example.com",
+		ingressClassName: "nginx",
+		annotations:      map[string]string{"example.com/extra": "value"},
+	}
+	err := endpoint.create(client, "ns", map[string]string{"app": DirectVolumeMigrationRsyncTransfer})
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	// Creating an existing ingress is not an error
+	err = endpoint.create(client, "ns", map[string]string{"app": DirectVolumeMigrationRsyncTransfer})
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	ingressList := networkingv1.IngressList{}
+	err = client.List(context.TODO(), &ingressList)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(ingressList.Items) != 1 {
+		t.Fatalf("create() created %d ingresses, want 1", len(ingressList.Items))
+	}
+	ingress := ingressList.Items[0]
+	if ingress.Annotations[IngressSSLPassthroughAnnotation] != "true" || ingress.Annotations["example.com/extra"] != "value" {
+		t.Errorf("create() annotations = %v", ingress.Annotations)
+	}
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx" {
+		t.Errorf("create() ingressClassName = %v", ingress.Spec.IngressClassName)
+	}
+	if host := ingress.Spec.Rules[0].Host; host != getTransferEndpointHost("ns", "apps.example.com") {
+		t.Errorf("create() host = %v", host)
+	}
+}
@@ -89,6 +89,10 @@ func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, direct *mi
 
 // fetches DVM Migration object and Migplan resources if DVM has an owner reference
 func (r *ReconcileDirectVolumeMigration) getDVMPlanResources(direct *migapi.DirectVolumeMigration) (*migapi.PlanResources, error) {
+	// Network preflight DVMs are owned by the MigPlan and carry their own settings
+	if direct.Spec.Preflight {
+		return &migapi.PlanResources{}, nil
+	}
 
 	if len(direct.OwnerReferences) > 0 {
 
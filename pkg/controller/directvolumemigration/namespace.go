@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -65,6 +66,13 @@ func (t *Task) ensureDestinationNamespaces() error {
 				return err
 			}
 		}
+		// the ranges allocated to an existing namespace differ from the source namespace
+		if t.Owner.Spec.OwnershipPolicy == migapi.PreserveOwnershipPolicy {
+			err = t.preserveNamespaceSecurityAnnotations(destClient, &srcNS, destNsName)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
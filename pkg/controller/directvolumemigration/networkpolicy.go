@@ -0,0 +1,252 @@
+package directvolumemigration
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DirectVolumeMigrationTransferIngressPolicy name of the NetworkPolicy admitting the Stunnel traffic to the Rsync transfer Pod
+	DirectVolumeMigrationTransferIngressPolicy = "directvolumemigration-rsync-transfer-ingress"
+	// DirectVolumeMigrationClientEgressPolicy name of the NetworkPolicy letting the Rsync client Pods reach the transfer endpoint
+	DirectVolumeMigrationClientEgressPolicy = "directvolumemigration-rsync-client-egress"
+	// dnsPort port of the cluster DNS resolving the transfer endpoint
+	dnsPort = 53
+)
+
+// getRsyncClientPodLabels returns the labels shared by all the Rsync client Pods
+func getRsyncClientPodLabels() map[string]string {
+	return map[string]string{
+		"app":                   DirectVolumeMigrationRsyncTransfer,
+		"directvolumemigration": DirectVolumeMigrationRsyncClient,
+	}
+}
+
+// getRsyncTransferPodLabels returns the labels selecting the Rsync transfer Pod, as the transfer Service does
+func (t *Task) getRsyncTransferPodLabels() map[string]string {
+	dvmLabels := t.buildDVMLabels()
+	dvmLabels["purpose"] = DirectVolumeMigrationRsync
+	return dvmLabels
+}
+
+// isRestrictedByNetworkPolicy returns whether a NetworkPolicy other than the ones created by the
+// migration selects Pods with the labels for the policy type, in which case any traffic of that
+// type not explicitly allowed by a policy is denied
+func isRestrictedByNetworkPolicy(policies []networkingv1.NetworkPolicy, podLabels map[string]string,
+	policyType networkingv1.PolicyType) bool {
+	for _, policy := range policies {
+		if policy.Labels["app"] == DirectVolumeMigrationRsyncTransfer {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil || !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+		policyTypes := policy.Spec.PolicyTypes
+		// policies without types always apply to ingress, and to egress when they have egress rules
+		if len(policyTypes) == 0 {
+			policyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+			if len(policy.Spec.Egress) > 0 {
+				policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+			}
+		}
+		for _, restricted := range policyTypes {
+			if restricted == policyType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getNetworkPolicyPorts returns the TCP ports of the host:port addresses
+func getNetworkPolicyPorts(addresses []string) []networkingv1.NetworkPolicyPort {
+	ports := []networkingv1.NetworkPolicyPort{}
+	seen := map[int]bool{}
+	for _, address := range addresses {
+		_, portString, err := net.SplitHostPort(address)
+		if err != nil {
+			continue
+		}
+		port, err := strconv.Atoi(portString)
+		if err != nil || seen[port] {
+			continue
+		}
+		seen[port] = true
+		ports = append(ports, getNetworkPolicyPort(corev1.ProtocolTCP, port))
+	}
+	return ports
+}
+
+// getNetworkPolicyPort returns a NetworkPolicy port
+func getNetworkPolicyPort(protocol corev1.Protocol, port int) networkingv1.NetworkPolicyPort {
+	portValue := intstr.FromInt(port)
+	return networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &portValue,
+	}
+}
+
+// getTransferIngressPolicy returns the NetworkPolicy admitting the Stunnel traffic to the Rsync
+// transfer Pod of a destination namespace. Routes are reached through the router, other
+// endpoints may be reached from outside of the cluster.
+func (t *Task) getTransferIngressPolicy(namespace string, endpoint transferEndpoint) networkingv1.NetworkPolicy {
+	rule := networkingv1.NetworkPolicyIngressRule{
+		Ports: []networkingv1.NetworkPolicyPort{
+			getNetworkPolicyPort(corev1.ProtocolTCP, DirectVolumeMigrationRsyncTransferPort),
+		},
+	}
+	if _, isRoute := endpoint.(*routeEndpoint); isRoute {
+		rule.From = []networkingv1.NetworkPolicyPeer{
+			{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"network.openshift.io/policy-group": "ingress"},
+				},
+			},
+			{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"policy-group.network.openshift.io/ingress": ""},
+				},
+			},
+		}
+	}
+	policyLabels := t.Owner.GetCorrelationLabels()
+	policyLabels["app"] = DirectVolumeMigrationRsyncTransfer
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DirectVolumeMigrationTransferIngressPolicy,
+			Namespace: namespace,
+			Labels:    policyLabels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: t.getRsyncTransferPodLabels(),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{rule},
+		},
+	}
+}
+
+// getClientEgressPolicy returns the NetworkPolicy letting the Rsync client Pods of a source
+// namespace resolve and reach the transfer endpoint, or the TCP proxy when one is configured
+func (t *Task) getClientEgressPolicy(namespace string, addresses []string) networkingv1.NetworkPolicy {
+	if proxy := settings.Settings.DvmOpts.StunnelTCPProxy; proxy != "" {
+		if proxyURL, err := url.Parse(proxy); err == nil {
+			addresses = []string{proxyURL.Host}
+		}
+	}
+	policyLabels := t.Owner.GetCorrelationLabels()
+	policyLabels["app"] = DirectVolumeMigrationRsyncTransfer
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DirectVolumeMigrationClientEgressPolicy,
+			Namespace: namespace,
+			Labels:    policyLabels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: getRsyncClientPodLabels(),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					Ports: getNetworkPolicyPorts(addresses),
+				},
+				{
+					Ports: []networkingv1.NetworkPolicyPort{
+						getNetworkPolicyPort(corev1.ProtocolUDP, dnsPort),
+						getNetworkPolicyPort(corev1.ProtocolTCP, dnsPort),
+					},
+				},
+			},
+		},
+	}
+}
+
+// listNetworkPolicies returns the NetworkPolicies of a namespace
+func listNetworkPolicies(client compat.Client, namespace string) ([]networkingv1.NetworkPolicy, error) {
+	list := networkingv1.NetworkPolicyList{}
+	err := client.List(context.TODO(), &list, k8sclient.InNamespace(namespace))
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	return list.Items, nil
+}
+
+// createNetworkPolicy creates a temporary NetworkPolicy of the migration
+func (t *Task) createNetworkPolicy(client compat.Client, policy *networkingv1.NetworkPolicy) error {
+	t.Log.Info("Creating temporary NetworkPolicy for Rsync traffic",
+		"networkPolicy", path.Join(policy.Namespace, policy.Name))
+	err := client.Create(context.TODO(), policy)
+	if k8serror.IsAlreadyExists(err) {
+		t.Log.Info("NetworkPolicy already exists",
+			"networkPolicy", path.Join(policy.Namespace, policy.Name))
+	} else if err != nil {
+		return liberr.Wrap(err)
+	}
+	return nil
+}
+
+// ensureNetworkPolicies creates temporary NetworkPolicies allowing the Stunnel traffic between the
+// Rsync client Pods and the transfer endpoint in the namespaces where existing NetworkPolicies
+// restrict the traffic of these Pods. The policies are deleted along with the other Rsync resources.
+func (t *Task) ensureNetworkPolicies() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	endpoint, err := t.getTransferEndpoint()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for bothNs := range t.getPVCNamespaceMap() {
+		srcNs := getSourceNs(bothNs)
+		destNs := getDestNs(bothNs)
+		destPolicies, err := listNetworkPolicies(destClient, destNs)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if isRestrictedByNetworkPolicy(destPolicies, t.getRsyncTransferPodLabels(), networkingv1.PolicyTypeIngress) {
+			policy := t.getTransferIngressPolicy(destNs, endpoint)
+			err = t.createNetworkPolicy(destClient, &policy)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		srcPolicies, err := listNetworkPolicies(srcClient, srcNs)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if isRestrictedByNetworkPolicy(srcPolicies, getRsyncClientPodLabels(), networkingv1.PolicyTypeEgress) {
+			addresses, err := t.getRsyncTransferAddresses(destNs)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+			policy := t.getClientEgressPolicy(srcNs, addresses)
+			err = t.createNetworkPolicy(srcClient, &policy)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
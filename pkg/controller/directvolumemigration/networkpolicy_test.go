@@ -0,0 +1,95 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_isRestrictedByNetworkPolicy(t *testing.T) {
+	denyAll := networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-deny"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+	ingressOnly := networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-ingress"},
+	}
+	otherPods := networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-database"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "database"}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+	migration := networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   DirectVolumeMigrationClientEgressPolicy,
+			Labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		},
+	}
+	tests := []struct {
+		name       string
+		policies   []networkingv1.NetworkPolicy
+		policyType networkingv1.PolicyType
+		want       bool
+	}{
+		{
+			name:       "no policies",
+			policyType: networkingv1.PolicyTypeIngress,
+			want:       false,
+		},
+		{
+			name:       "default deny restricts egress",
+			policies:   []networkingv1.NetworkPolicy{denyAll},
+			policyType: networkingv1.PolicyTypeEgress,
+			want:       true,
+		},
+		{
+			name:       "policy without types restricts ingress",
+			policies:   []networkingv1.NetworkPolicy{ingressOnly},
+			policyType: networkingv1.PolicyTypeIngress,
+			want:       true,
+		},
+		{
+			name:       "policy without types nor egress rules does not restrict egress",
+			policies:   []networkingv1.NetworkPolicy{ingressOnly},
+			policyType: networkingv1.PolicyTypeEgress,
+			want:       false,
+		},
+		{
+			name:       "policy selecting other pods does not restrict",
+			policies:   []networkingv1.NetworkPolicy{otherPods},
+			policyType: networkingv1.PolicyTypeIngress,
+			want:       false,
+		},
+		{
+			name:       "policy of the migration is ignored",
+			policies:   []networkingv1.NetworkPolicy{migration},
+			policyType: networkingv1.PolicyTypeEgress,
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRestrictedByNetworkPolicy(tt.policies, getRsyncClientPodLabels(), tt.policyType); got != tt.want {
+				t.Errorf("isRestrictedByNetworkPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getNetworkPolicyPorts(t *testing.T) {
+	ports := getNetworkPolicyPorts([]string{"dvm-ns.apps.example.com:443", "10.0.0.1:31000", "10.0.0.2:31000", "invalid"})
+	if len(ports) != 2 {
+		t.Fatalf("getNetworkPolicyPorts() got %d ports, want 2", len(ports))
+	}
+	if ports[0].Port.IntValue() != 443 || ports[1].Port.IntValue() != 31000 {
+		t.Errorf("getNetworkPolicyPorts() = %v, %v, want 443, 31000", ports[0].Port, ports[1].Port)
+	}
+}
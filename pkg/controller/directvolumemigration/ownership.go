@@ -0,0 +1,396 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// UIDRangeAnnotation UID range allocated to the namespace by OpenShift
+	UIDRangeAnnotation = "openshift.io/sa.scc.uid-range"
+	// SupplementalGroupsAnnotation supplemental groups allocated to the namespace by OpenShift
+	SupplementalGroupsAnnotation = "openshift.io/sa.scc.supplemental-groups"
+	// MCSAnnotation SELinux MCS labels allocated to the namespace by OpenShift
+	MCSAnnotation = "openshift.io/sa.scc.mcs"
+	// DirectVolumeMigrationOwnershipRemap name of the remap container and purpose of the remap Pods
+	DirectVolumeMigrationOwnershipRemap = "ownership-remap"
+)
+
+// ownershipRemapScript shifts the owner and the group of the files owned by the source ranges
+// to the destination ranges, then verifies that no file is owned by the source ranges anymore.
+// Symbolic links are changed rather than followed.
+const ownershipRemapScript = `set -o pipefail
+cd ` + DirectVolumeMigrationVerificationMountPath + ` || exit 1
+remap() {
+  find . -xdev -printf "$1\n" | sort -un | while read -r id; do
+    if [ "$id" -ge "$4" ] && [ "$id" -lt "$5" ]; then
+      find . -xdev "$2" "$id" -exec "$3" -h "$(( id - $4 + $6 ))" {} + || exit 1
+    fi
+  done
+}
+remaining() {
+  find . -xdev -printf "$1\n" | sort -un | awk -v s="$2" -v e="$3" '$1 >= s && $1 < e' | wc -l
+}
+remap '%U' -uid chown "$SRC_UID_START" "$SRC_UID_END" "$DEST_UID_START" || exit 1
+remap '%G' -gid chgrp "$SRC_GID_START" "$SRC_GID_END" "$DEST_GID_START" || exit 1
+uids=$(remaining '%U' "$SRC_UID_START" "$SRC_UID_END") || exit 1
+gids=$(remaining '%G' "$SRC_GID_START" "$SRC_GID_END") || exit 1
+if [ "$uids" -gt 0 ] || [ "$gids" -gt 0 ]; then
+  echo "$uids UIDs and $gids GIDs of the source ranges still own files"
+  exit 1
+fi
+`
+
+// idRange range of UIDs or GIDs allocated to a namespace
+type idRange struct {
+	start int64
+	size  int64
+}
+
+// parseIDRange parses the first range of an OpenShift range annotation,
+// either <start>/<size> or <start>-<end>
+func parseIDRange(value string) (*idRange, error) {
+	value = strings.TrimSpace(strings.Split(value, ",")[0])
+	if parts := strings.Split(value, "/"); len(parts) == 2 {
+		start, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		size, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		if size < 1 {
+			return nil, liberr.Wrap(fmt.Errorf("invalid range %q", value))
+		}
+		return &idRange{start: start, size: size}, nil
+	}
+	if parts := strings.Split(value, "-"); len(parts) == 2 {
+		start, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		end, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		if end < start {
+			return nil, liberr.Wrap(fmt.Errorf("invalid range %q", value))
+		}
+		return &idRange{start: start, size: end - start + 1}, nil
+	}
+	return nil, liberr.Wrap(fmt.Errorf("invalid range %q", value))
+}
+
+// idMapping maps a source range to a destination range, nil when the ownership is left unchanged
+type idMapping struct {
+	source      idRange
+	destination idRange
+}
+
+// String returns the mapping as <source start>/<size>:<destination start>
+func (m *idMapping) String() string {
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d:%d", m.source.start, m.source.size, m.destination.start)
+}
+
+// getEnv returns the environment variables of the remap script for the mapping
+func (m *idMapping) getEnv(prefix string) []corev1.EnvVar {
+	start, end, destStart := int64(0), int64(0), int64(0)
+	if m != nil {
+		start = m.source.start
+		end = m.source.start + m.source.size
+		destStart = m.destination.start
+	}
+	return []corev1.EnvVar{
+		{Name: fmt.Sprintf("SRC_%s_START", prefix), Value: strconv.FormatInt(start, 10)},
+		{Name: fmt.Sprintf("SRC_%s_END", prefix), Value: strconv.FormatInt(end, 10)},
+		{Name: fmt.Sprintf("DEST_%s_START", prefix), Value: strconv.FormatInt(destStart, 10)},
+	}
+}
+
+// getIDMapping returns the mapping between the ranges of an annotation of the source and destination
+// namespaces. The ownership is left unchanged when the source namespace has no range or both ranges
+// start at the same ID, overlapping ranges cannot be shifted without remapping some files twice, and
+// a destination range smaller than the source range cannot hold all the shifted IDs.
+func getIDMapping(srcNs, destNs *corev1.Namespace, annotation string) (*idMapping, error) {
+	srcValue, found := srcNs.Annotations[annotation]
+	if !found {
+		return nil, nil
+	}
+	source, err := parseIDRange(srcValue)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	destValue, found := destNs.Annotations[annotation]
+	if !found {
+		return nil, liberr.Wrap(fmt.Errorf("annotation %s not found on destination namespace %s", annotation, destNs.Name))
+	}
+	destination, err := parseIDRange(destValue)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if source.start == destination.start {
+		return nil, nil
+	}
+	if source.start < destination.start+destination.size && destination.start < source.start+source.size {
+		return nil, liberr.Wrap(fmt.Errorf("the %s ranges %s and %s of namespaces %s and %s overlap, use the %s ownership policy",
+			annotation, srcValue, destValue, srcNs.Name, destNs.Name, migapi.PreserveOwnershipPolicy))
+	}
+	if destination.size < source.size {
+		return nil, liberr.Wrap(fmt.Errorf("the %s range %s of namespace %s is smaller than the range %s of namespace %s, use the %s ownership policy",
+			annotation, destValue, destNs.Name, srcValue, srcNs.Name, migapi.PreserveOwnershipPolicy))
+	}
+	return &idMapping{source: *source, destination: *destination}, nil
+}
+
+// preserveNamespaceSecurityAnnotations sets the UID, supplemental groups and MCS annotations of the
+// source namespace on an existing destination namespace so that the copied files stay writable
+func (t *Task) preserveNamespaceSecurityAnnotations(client compat.Client, srcNs *corev1.Namespace, destNsName string) error {
+	destNs := corev1.Namespace{}
+	err := client.Get(context.TODO(), types.NamespacedName{Name: destNsName}, &destNs)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if destNs.Annotations == nil {
+		destNs.Annotations = map[string]string{}
+	}
+	updated := false
+	for _, annotation := range []string{UIDRangeAnnotation, SupplementalGroupsAnnotation, MCSAnnotation} {
+		value, found := srcNs.Annotations[annotation]
+		if !found || destNs.Annotations[annotation] == value {
+			continue
+		}
+		destNs.Annotations[annotation] = value
+		updated = true
+	}
+	if !updated {
+		return nil
+	}
+	t.Log.Info("Preserving UID, supplemental groups and MCS annotations of source namespace on destination namespace",
+		"namespace", destNs.Name)
+	err = client.Update(context.TODO(), &destNs)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	return nil
+}
+
+// remapOwnership runs a remap Pod for the filesystem PVCs in their destination namespace to shift
+// the ownership of the copied files to the ranges of the destination namespace, records the results
+// in the status, returns whether all remaps completed and the reasons of the failed ones
+func (t *Task) remapOwnership() (bool, []string, error) {
+	reasons := []string{}
+	if t.Owner.Spec.OwnershipPolicy != migapi.RemapOwnershipPolicy {
+		return true, reasons, nil
+	}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, reasons, liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, reasons, liberr.Wrap(err)
+	}
+	destCluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return false, reasons, liberr.Wrap(err)
+	}
+	image, err := destCluster.GetRsyncTransferImage(t.Client)
+	if err != nil {
+		return false, reasons, liberr.Wrap(err)
+	}
+	allCompleted := true
+	for i := range t.Owner.Spec.PersistentVolumeClaims {
+		pvc := t.Owner.Spec.PersistentVolumeClaims[i]
+		if pvc.IsBlock() {
+			continue
+		}
+		remap := t.Owner.Status.GetOwnershipRemapForPVC(pvc.ObjectReference)
+		if !remap.IsComplete() {
+			completed, err := t.runOwnershipRemap(srcClient, destClient, image, pvc, remap)
+			if err != nil {
+				return false, reasons, liberr.Wrap(err)
+			}
+			if !completed {
+				allCompleted = false
+				continue
+			}
+		}
+		if remap.Failed {
+			reasons = append(reasons, fmt.Sprintf("Ownership remap of PVC %s failed: %s",
+				path.Join(pvc.Namespace, pvc.Name), remap.Message))
+		}
+	}
+	return allCompleted, reasons, nil
+}
+
+// runOwnershipRemap ensures the remap Pod of a PVC is created and records its result once completed
+func (t *Task) runOwnershipRemap(srcClient, destClient compat.Client, image string,
+	pvc migapi.PVCToMigrate, remap *migapi.OwnershipRemap) (bool, error) {
+	destNsName := pvc.Namespace
+	if pvc.TargetNamespace != "" {
+		destNsName = pvc.TargetNamespace
+	}
+	srcNs := corev1.Namespace{}
+	err := srcClient.Get(context.TODO(), types.NamespacedName{Name: pvc.Namespace}, &srcNs)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destNs := corev1.Namespace{}
+	err = destClient.Get(context.TODO(), types.NamespacedName{Name: destNsName}, &destNs)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	uidMapping, err := getIDMapping(&srcNs, &destNs, UIDRangeAnnotation)
+	if err != nil {
+		completeOwnershipRemap(remap, false, err.Error())
+		return true, nil
+	}
+	gidMapping, err := getIDMapping(&srcNs, &destNs, SupplementalGroupsAnnotation)
+	if err != nil {
+		completeOwnershipRemap(remap, false, err.Error())
+		return true, nil
+	}
+	remap.UIDMapping = uidMapping.String()
+	remap.GIDMapping = gidMapping.String()
+	if uidMapping == nil && gidMapping == nil {
+		completeOwnershipRemap(remap, true, "the source and destination ranges are the same")
+		return true, nil
+	}
+	pod, err := t.ensureOwnershipRemapPod(destClient, pvc, destNsName, image, uidMapping, gidMapping)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		completeOwnershipRemap(remap, true, "")
+	case corev1.PodFailed:
+		message := fmt.Sprintf("remap Pod %s failed", path.Join(pod.Namespace, pod.Name))
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.Message != "" {
+				message = strings.TrimSpace(status.State.Terminated.Message)
+			}
+		}
+		completeOwnershipRemap(remap, false, message)
+	default:
+		t.Log.Info("Waiting for ownership remap Pod to complete.",
+			"pod", path.Join(pod.Namespace, pod.Name),
+			"podPhase", pod.Status.Phase)
+		return false, nil
+	}
+	t.Log.Info("Completed ownership remap of PVC.",
+		"persistentVolumeClaim", path.Join(pvc.Namespace, pvc.Name),
+		"uidMapping", remap.UIDMapping,
+		"gidMapping", remap.GIDMapping,
+		"succeeded", remap.Succeeded)
+	return true, nil
+}
+
+// completeOwnershipRemap records the outcome of a remap
+func completeOwnershipRemap(remap *migapi.OwnershipRemap, succeeded bool, message string) {
+	remap.Succeeded = succeeded
+	remap.Failed = !succeeded
+	remap.Message = message
+}
+
+// ensureOwnershipRemapPod creates the remap Pod of a PVC in its destination namespace if it doesn't exist
+func (t *Task) ensureOwnershipRemapPod(client compat.Client, pvc migapi.PVCToMigrate, namespace string,
+	image string, uidMapping, gidMapping *idMapping) (*corev1.Pod, error) {
+	claimName := pvc.GetTargetName()
+	pod := corev1.Pod{}
+	key := types.NamespacedName{Name: getOwnershipRemapPodName(claimName), Namespace: namespace}
+	err := client.Get(context.TODO(), key, &pod)
+	if err == nil {
+		return &pod, nil
+	}
+	if !k8serror.IsNotFound(err) {
+		return nil, liberr.Wrap(err)
+	}
+	isPrivileged, err := isRsyncPrivileged(client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	// RWO volumes are attached to the node of the Rsync transfer Pod
+	nodeName, err := getDestinationVerificationNode(client, namespace)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	newPod := t.buildOwnershipRemapPod(claimName, namespace, nodeName, image, isPrivileged, uidMapping, gidMapping)
+	t.Log.Info("Creating ownership remap Pod.",
+		"pod", path.Join(newPod.Namespace, newPod.Name),
+		"persistentVolumeClaim", path.Join(namespace, claimName))
+	err = client.Create(context.TODO(), newPod)
+	if err != nil && !k8serror.IsAlreadyExists(err) {
+		return nil, liberr.Wrap(err)
+	}
+	return newPod, nil
+}
+
+// getOwnershipRemapPodName returns the name of the remap Pod of a claim
+func getOwnershipRemapPodName(claimName string) string {
+	return fmt.Sprintf("dvm-remap-%s", getMD5Hash(claimName))
+}
+
+// buildOwnershipRemapPod returns the Pod running the remap script on a destination PVC
+func (t *Task) buildOwnershipRemapPod(claimName string, namespace string, nodeName string, image string,
+	isPrivileged bool, uidMapping, gidMapping *idMapping) *corev1.Pod {
+	runAsUser := int64(0)
+	volumeName := getMD5Hash(claimName)
+	labels := t.Owner.GetCorrelationLabels()
+	labels["app"] = DirectVolumeMigrationRsyncTransfer
+	labels["purpose"] = DirectVolumeMigrationOwnershipRemap
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getOwnershipRemapPodName(claimName),
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			NodeName:      nodeName,
+			Containers: []corev1.Container{
+				{
+					Name:                     DirectVolumeMigrationOwnershipRemap,
+					Image:                    image,
+					Command:                  []string{"/bin/bash", "-c", ownershipRemapScript},
+					Env:                      append(uidMapping.getEnv("UID"), gidMapping.getEnv("GID")...),
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &isPrivileged,
+						RunAsUser:  &runAsUser,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      volumeName,
+							MountPath: DirectVolumeMigrationVerificationMountPath,
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claimName,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_parseIDRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    idRange
+		wantErr bool
+	}{
+		{
+			name:  "given a start/size range, should parse start and size",
+			value: "1000620000/10000",
+			want:  idRange{start: 1000620000, size: 10000},
+		},
+		{
+			name:  "given a start-end range, should compute the size",
+			value: "1000620000-1000629999",
+			want:  idRange{start: 1000620000, size: 10000},
+		},
+		{
+			name:  "given several ranges, should parse the first one",
+			value: "1000620000/10000,1000800000/10000",
+			want:  idRange{start: 1000620000, size: 10000},
+		},
+		{
+			name:    "given an invalid range, should return an error",
+			value:   "s0:c26,c5",
+			wantErr: true,
+		},
+		{
+			name:    "given a non numeric size, should return an error",
+			value:   "1000620000/all",
+			wantErr: true,
+		},
+		{
+			name:    "given an empty range, should return an error",
+			value:   "1000620000/0",
+			wantErr: true,
+		},
+		{
+			name:    "given a range ending before its start, should return an error",
+			value:   "1000629999-1000620000",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIDRange(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseIDRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("parseIDRange() = %v, want %v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getIDMapping(t *testing.T) {
+	getNamespace := func(name string, uidRange string) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if uidRange != "" {
+			ns.Annotations = map[string]string{UIDRangeAnnotation: uidRange}
+		}
+		return ns
+	}
+	tests := []struct {
+		name    string
+		srcNs   *corev1.Namespace
+		destNs  *corev1.Namespace
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "given a source namespace without range, should leave the ownership unchanged",
+			srcNs:  getNamespace("src", ""),
+			destNs: getNamespace("dest", "1000800000/10000"),
+			want:   "",
+		},
+		{
+			name:   "given ranges starting at the same ID, should leave the ownership unchanged",
+			srcNs:  getNamespace("src", "1000620000/10000"),
+			destNs: getNamespace("dest", "1000620000/10000"),
+			want:   "",
+		},
+		{
+			name:   "given disjoint ranges, should map the source range to the destination range",
+			srcNs:  getNamespace("src", "1000620000/10000"),
+			destNs: getNamespace("dest", "1000800000/10000"),
+			want:   "1000620000/10000:1000800000",
+		},
+		{
+			name:    "given overlapping ranges, should return an error",
+			srcNs:   getNamespace("src", "1000620000/10000"),
+			destNs:  getNamespace("dest", "1000625000/10000"),
+			wantErr: true,
+		},
+		{
+			name:    "given a destination range smaller than the source range, should return an error",
+			srcNs:   getNamespace("src", "1000620000/10000"),
+			destNs:  getNamespace("dest", "1000800000/5000"),
+			wantErr: true,
+		},
+		{
+			name:   "given a destination range larger than the source range, should map the source range",
+			srcNs:  getNamespace("src", "1000620000/10000"),
+			destNs: getNamespace("dest", "1000800000/20000"),
+			want:   "1000620000/10000:1000800000",
+		},
+		{
+			name:    "given a destination namespace without range, should return an error",
+			srcNs:   getNamespace("src", "1000620000/10000"),
+			destNs:  getNamespace("dest", ""),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getIDMapping(tt.srcNs, tt.destNs, UIDRangeAnnotation)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getIDMapping() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("getIDMapping() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,691 @@
+package directvolumemigration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PreflightTimeout time limit of the endpoint, the server Pod and the client Pods of the network preflight check
+	PreflightTimeout = 10 * time.Minute
+	// PreflightTransferBytes size of the test data sent from each source node
+	PreflightTransferBytes = 64 * 1024 * 1024
+	// PreflightReadTimeoutSeconds time the client waits for the destination to acknowledge the test data
+	PreflightReadTimeoutSeconds = 300
+)
+
+// The network preflight runs in the migration namespace of both clusters. A Stunnel server exposed through
+// the transfer endpoint of the destination cluster counts the bytes it receives and acknowledges them,
+// a client on each source node sends the test data through a Stunnel client and measures the throughput.
+const stunnelPreflightServerConfigTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    purpose: stunnel-config
+data:
+  stunnel.conf: |
+    foreground = yes
+    pid =
+    socket = l:TCP_NODELAY=1
+    socket = r:TCP_NODELAY=1
+    debug = 7
+    sslVersion = TLSv1.2
+
+    [preflight]
+    accept = {{ .StunnelPort }}
+    exec = /bin/bash
+    execArgs = bash /etc/stunnel/sink.sh
+    key = /etc/stunnel/certs/tls.key
+    cert = /etc/stunnel/certs/tls.crt
+    TIMEOUTclose = 0
+  sink.sh: |
+    received=$(head -c {{ .Bytes }} | wc -c)
+    echo "preflight-ack ${received}"
+`
+
+// preflightClientScript sends the test data through the local Stunnel client and prints the result line
+const preflightClientScript = `trap "touch /usr/share/rsync-stunnel-mgmt/rsync-client-container-done" EXIT SIGINT SIGTERM
+report() { echo "preflight connected=$1 bytes=$2 nanoseconds=$3" | tee /dev/termination-log; }
+SECONDS=0
+until nc -z localhost %[3]d; do
+  if [ $SECONDS -ge 60 ]; then report false 0 0; exit 1; fi
+  sleep 1
+done
+start=$(date +%%s%%N)
+exec 3<>/dev/tcp/localhost/%[3]d || { report false 0 0; exit 1; }
+head -c %[1]d /dev/zero >&3
+read -t %[2]d -r ack received <&3
+end=$(date +%%s%%N)
+if [ "$ack" = "preflight-ack" ] && [ "$received" = "%[1]d" ]; then
+  report true %[1]d $((end-start))
+  exit 0
+fi
+report false ${received:-0} $((end-start))
+exit 1
+`
+
+var preflightResultRegex = regexp.MustCompile(`preflight connected=(true|false) bytes=(\d+) nanoseconds=(\d+)`)
+
+// getPreflightLabels returns the labels of the resources created by the network preflight check
+func (t *Task) getPreflightLabels() map[string]string {
+	preflightLabels := t.Owner.GetCorrelationLabels()
+	preflightLabels["app"] = DirectVolumeMigrationPreflight
+	return preflightLabels
+}
+
+// isPreflightTimedOut returns whether the current phase of the network preflight check has exceeded PreflightTimeout
+func (t *Task) isPreflightTimedOut() bool {
+	t.Owner.Status.StageCondition(Running)
+	cond := t.Owner.Status.FindCondition(Running)
+	if cond == nil {
+		return false
+	}
+	return time.Now().UTC().Sub(cond.LastTransitionTime.Time.UTC()) > PreflightTimeout
+}
+
+// createPreflightEndpoint creates the transfer endpoint of the network preflight check in the migration namespace
+// of the destination cluster. Returns true when the endpoint is used by another network preflight check.
+func (t *Task) createPreflightEndpoint() (bool, error) {
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	endpoint, err := t.getTransferEndpoint()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	busy, err := t.isPreflightEndpointBusy(destClient)
+	if err != nil || busy {
+		return busy, liberr.Wrap(err)
+	}
+	selector := t.getPreflightLabels()
+	selector["purpose"] = DirectVolumeMigrationPreflightServer
+	err = t.createTransferEndpoint(destClient, endpoint, migapi.OpenshiftMigrationNamespace, selector, t.getPreflightLabels())
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return false, nil
+}
+
+// isPreflightEndpointBusy returns whether the transfer Service of the migration namespace belongs to
+// another network preflight check, the resources of a check whose DVM no longer runs are deleted
+func (t *Task) isPreflightEndpointBusy(destClient compat.Client) (bool, error) {
+	svc, err := getRsyncTransferService(destClient, migapi.OpenshiftMigrationNamespace)
+	if err != nil {
+		if k8serror.IsNotFound(err) {
+			return false, nil
+		}
+		return false, liberr.Wrap(err)
+	}
+	key, value := t.Owner.GetCorrelationLabel()
+	owner := svc.Labels[key]
+	if owner == value {
+		return false, nil
+	}
+	dvmList := migapi.DirectVolumeMigrationList{}
+	err = t.Client.List(context.TODO(), &dvmList, k8sclient.InNamespace(migapi.OpenshiftMigrationNamespace))
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	for _, dvm := range dvmList.Items {
+		if string(dvm.UID) == owner && dvm.Spec.Preflight && dvm.Status.Phase != Completed {
+			return true, nil
+		}
+	}
+	t.Log.Info("Deleting stale network preflight resources on the destination and source MigClusters",
+		"service", path.Join(svc.Namespace, svc.Name))
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	selector := labels.SelectorFromSet(map[string]string{"app": DirectVolumeMigrationPreflight, key: owner})
+	for _, client := range []compat.Client{destClient, srcClient} {
+		err = t.findAndDeleteNsResources(client, migapi.OpenshiftMigrationNamespace, selector)
+		if err != nil {
+			return false, liberr.Wrap(err)
+		}
+	}
+	return true, nil
+}
+
+// isPreflightEndpointReady checks whether the transfer endpoint of the network preflight check is ready
+func (t *Task) isPreflightEndpointReady() (bool, []string, error) {
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	endpoint, err := t.getTransferEndpoint()
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	addresses, reason, err := endpoint.getAddresses(destClient, migapi.OpenshiftMigrationNamespace)
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	if len(addresses) == 0 {
+		t.Log.Info("Network preflight transfer endpoint is not ready.", "reason", reason)
+		return false, []string{reason}, nil
+	}
+	return true, nil, nil
+}
+
+// ensurePreflightCerts creates the Stunnel certificates on both clusters, the certificates
+// of the destination cluster are reused when they already exist
+func (t *Task) ensurePreflightCerts(srcClient compat.Client, destClient compat.Client) error {
+	destSecret := corev1.Secret{}
+	err := destClient.Get(context.TODO(), types.NamespacedName{
+		Namespace: migapi.OpenshiftMigrationNamespace,
+		Name:      DirectVolumeMigrationPreflightCerts,
+	}, &destSecret)
+	if err != nil && !k8serror.IsNotFound(err) {
+		return liberr.Wrap(err)
+	}
+	certData := destSecret.Data
+	if k8serror.IsNotFound(err) {
+		certData, err = t.generateStunnelCerts()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	for _, client := range []compat.Client{destClient, srcClient} {
+		secret := corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: migapi.OpenshiftMigrationNamespace,
+				Name:      DirectVolumeMigrationPreflightCerts,
+				Labels:    t.getPreflightLabels(),
+			},
+			Data: certData,
+		}
+		err = client.Create(context.TODO(), &secret)
+		if err != nil && !k8serror.IsAlreadyExists(err) {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// createPreflightConfigMap renders a Stunnel config template into a ConfigMap of the migration namespace
+func (t *Task) createPreflightConfigMap(client compat.Client, name string, configTemplate string, data interface{}) error {
+	var tpl bytes.Buffer
+	temp, err := template.New("config").Parse(configTemplate)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = temp.Execute(&tpl, data)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	configMap := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: migapi.OpenshiftMigrationNamespace,
+			Name:      name,
+		},
+	}
+	configMap.Labels = t.getPreflightLabels()
+	err = yaml.Unmarshal(tpl.Bytes(), &configMap)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	t.Log.Info("Creating network preflight Stunnel ConfigMap.",
+		"configMap", path.Join(configMap.Namespace, configMap.Name))
+	err = client.Create(context.TODO(), &configMap)
+	if err != nil && !k8serror.IsAlreadyExists(err) {
+		return liberr.Wrap(err)
+	}
+	return nil
+}
+
+// createPreflightServer creates the certificates on both clusters and the Stunnel server Pod on the destination cluster
+func (t *Task) createPreflightServer() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = t.ensurePreflightCerts(srcClient, destClient)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = t.createPreflightConfigMap(destClient, DirectVolumeMigrationPreflightServer,
+		stunnelPreflightServerConfigTemplate, map[string]int{
+			"StunnelPort": DirectVolumeMigrationRsyncTransferPort,
+			"Bytes":       PreflightTransferBytes,
+		})
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	cluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	image, err := cluster.GetRsyncTransferImage(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	privileged, err := isRsyncPrivileged(destClient)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	podLabels := t.getPreflightLabels()
+	podLabels["purpose"] = DirectVolumeMigrationPreflightServer
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DirectVolumeMigrationPreflightServer,
+			Namespace: migapi.OpenshiftMigrationNamespace,
+			Labels:    podLabels,
+		},
+		Spec: corev1.PodSpec{
+			Volumes: getPreflightVolumes(DirectVolumeMigrationPreflightServer),
+			Containers: []corev1.Container{
+				{
+					Name:    DirectVolumeMigrationStunnel,
+					Image:   image,
+					Command: []string{"/bin/stunnel", "/etc/stunnel/stunnel.conf"},
+					Ports: []corev1.ContainerPort{
+						{
+							Name:          DirectVolumeMigrationStunnel,
+							Protocol:      corev1.ProtocolTCP,
+							ContainerPort: int32(DirectVolumeMigrationRsyncTransferPort),
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "stunnel-conf",
+							MountPath: "/etc/stunnel/stunnel.conf",
+							SubPath:   "stunnel.conf",
+						},
+						{
+							Name:      "stunnel-conf",
+							MountPath: "/etc/stunnel/sink.sh",
+							SubPath:   "sink.sh",
+						},
+						{
+							Name:      "stunnel-certs",
+							MountPath: "/etc/stunnel/certs",
+						},
+					},
+					SecurityContext: getPreflightSecurityContext(privileged),
+				},
+			},
+		},
+	}
+	applyTransferPodPolicy(&pod.Spec, t.getTransferPodPolicy())
+	t.Log.Info("Creating network preflight Stunnel server Pod on destination cluster.",
+		"pod", path.Join(pod.Namespace, pod.Name))
+	err = destClient.Create(context.TODO(), &pod)
+	if err != nil && !k8serror.IsAlreadyExists(err) {
+		return liberr.Wrap(err)
+	}
+	return nil
+}
+
+// isPreflightServerRunning returns whether the Stunnel server Pod of the network preflight check is running
+func (t *Task) isPreflightServerRunning() (bool, error) {
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	pod := corev1.Pod{}
+	err = destClient.Get(context.TODO(), types.NamespacedName{
+		Namespace: migapi.OpenshiftMigrationNamespace,
+		Name:      DirectVolumeMigrationPreflightServer,
+	}, &pod)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return pod.Status.Phase == corev1.PodRunning, nil
+}
+
+// createPreflightClients creates the Stunnel client config and one client Pod on each source node hosting PVCs
+func (t *Task) createPreflightClients() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	endpoint, err := t.getTransferEndpoint()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	addresses, reason, err := endpoint.getAddresses(destClient, migapi.OpenshiftMigrationNamespace)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if len(addresses) == 0 {
+		return liberr.Wrap(fmt.Errorf("network preflight transfer endpoint is not ready: %s", reason))
+	}
+	proxyConfig, err := t.generateStunnelProxyConfig()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = t.createPreflightConfigMap(srcClient, DirectVolumeMigrationPreflightClient, stunnelClientConfigTemplate,
+		stunnelConfig{
+			StunnelPort:        DirectVolumeMigrationRsyncTransferPort,
+			RsyncRoute:         addresses[0],
+			RsyncAddresses:     addresses,
+			stunnelProxyConfig: proxyConfig,
+			VerifyCA:           settings.Settings.StunnelVerifyCA,
+			VerifyCALevel:      settings.Settings.StunnelVerifyCALevel,
+		})
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	cluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	image, err := cluster.GetRsyncTransferImage(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	privileged, err := isRsyncPrivileged(srcClient)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	pvcNodeMap, err := t.getPVCNodeNameMap()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for _, node := range getPreflightNodes(t.Owner.Spec.PersistentVolumeClaims, pvcNodeMap) {
+		pod := t.getPreflightClientPod(node, image, privileged)
+		t.Log.Info("Creating network preflight client Pod on source cluster.",
+			"pod", path.Join(pod.Namespace, pod.Name),
+			"node", node)
+		err = srcClient.Create(context.TODO(), &pod)
+		if err != nil && !k8serror.IsAlreadyExists(err) {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// getPreflightNodes returns the source nodes hosting the PVCs, an empty node name stands for the PVCs
+// not mounted by a running Pod whose Rsync client Pods go through the scheduler
+func getPreflightNodes(pvcs []migapi.PVCToMigrate, pvcNodeMap map[string]string) []string {
+	found := map[string]bool{}
+	nodes := []string{}
+	for _, pvc := range pvcs {
+		if pvc.ObjectReference == nil {
+			continue
+		}
+		node := pvcNodeMap[pvc.Namespace+"/"+pvc.Name]
+		if pvc.IsSnapshotSource() {
+			node = ""
+		}
+		if !found[node] {
+			found[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// getPreflightClientPod returns the network preflight client Pod of a source node
+func (t *Task) getPreflightClientPod(node string, image string, privileged bool) corev1.Pod {
+	podLabels := t.getPreflightLabels()
+	podLabels["purpose"] = DirectVolumeMigrationPreflightClient
+	ipcMount := corev1.VolumeMount{
+		Name:      "rsync-stunnel-ipc",
+		MountPath: "/usr/share/rsync-stunnel-mgmt",
+	}
+	volumes := append(getPreflightVolumes(DirectVolumeMigrationPreflightClient), corev1.Volume{
+		Name: "rsync-stunnel-ipc",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "dvm-preflight-" + getMD5Hash(node),
+			Namespace:   migapi.OpenshiftMigrationNamespace,
+			Labels:      podLabels,
+			Annotations: map[string]string{PreflightNodeAnnotation: node},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			NodeName:      node,
+			Volumes:       volumes,
+			Containers: []corev1.Container{
+				{
+					Name:  DirectVolumeMigrationPreflightClient,
+					Image: image,
+					Command: []string{"/bin/bash", "-c", fmt.Sprintf(preflightClientScript,
+						PreflightTransferBytes, PreflightReadTimeoutSeconds, DirectVolumeMigrationRsyncTransferPort)},
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					VolumeMounts:             []corev1.VolumeMount{ipcMount},
+					SecurityContext:          getPreflightSecurityContext(privileged),
+				},
+				{
+					Name:  DirectVolumeMigrationStunnel,
+					Image: image,
+					Command: []string{
+						"/bin/bash",
+						"-c",
+						`/bin/stunnel /etc/stunnel/stunnel.conf
+         while true
+         do test -f /usr/share/rsync-stunnel-mgmt/rsync-client-container-done
+         if [ $? -eq 0 ]
+         then
+         break
+         fi
+         done
+         exit 0`,
+					},
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "stunnel-conf",
+							MountPath: "/etc/stunnel/stunnel.conf",
+							SubPath:   "stunnel.conf",
+						},
+						{
+							Name:      "stunnel-certs",
+							MountPath: "/etc/stunnel/certs",
+						},
+						ipcMount,
+					},
+					SecurityContext: getPreflightSecurityContext(privileged),
+				},
+			},
+		},
+	}
+	applyTransferPodPolicy(&pod.Spec, t.getTransferPodPolicy())
+	return pod
+}
+
+// getPreflightVolumes returns the Stunnel config and certificate volumes of the network preflight Pods
+func getPreflightVolumes(configMapName string) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: "stunnel-conf",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: configMapName,
+					},
+				},
+			},
+		},
+		{
+			Name: "stunnel-certs",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: DirectVolumeMigrationPreflightCerts,
+				},
+			},
+		},
+	}
+}
+
+func getPreflightSecurityContext(privileged bool) *corev1.SecurityContext {
+	runAsUser := int64(0)
+	trueBool := true
+	return &corev1.SecurityContext{
+		Privileged:             &privileged,
+		RunAsUser:              &runAsUser,
+		ReadOnlyRootFilesystem: &trueBool,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"MKNOD", "SETPCAP"},
+		},
+	}
+}
+
+// collectPreflightResults sets the results of the completed client Pods on the status,
+// returns whether all client Pods have completed or timed out
+func (t *Task) collectPreflightResults() (bool, error) {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	podLabels := t.getPreflightLabels()
+	podLabels["purpose"] = DirectVolumeMigrationPreflightClient
+	podList := corev1.PodList{}
+	err = srcClient.List(context.TODO(), &podList,
+		k8sclient.InNamespace(migapi.OpenshiftMigrationNamespace),
+		k8sclient.MatchingLabels(podLabels))
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	completed := true
+	results := []*migapi.NetworkPreflightResult{}
+	for i := range podList.Items {
+		result := getPreflightResult(&podList.Items[i], time.Now())
+		if result == nil {
+			completed = false
+			continue
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Node < results[j].Node
+	})
+	t.Owner.Status.PreflightResults = results
+	return completed, nil
+}
+
+// getPreflightResult returns the result of a client Pod, nil while the Pod runs within PreflightTimeout
+func getPreflightResult(pod *corev1.Pod, now time.Time) *migapi.NetworkPreflightResult {
+	node := pod.Annotations[PreflightNodeAnnotation]
+	if node == "" {
+		node = pod.Spec.NodeName
+	}
+	result := &migapi.NetworkPreflightResult{Node: node}
+	podName := path.Join(pod.Namespace, pod.Name)
+	if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+		if now.Sub(pod.CreationTimestamp.Time) <= PreflightTimeout {
+			return nil
+		}
+		result.Message = fmt.Sprintf("The network preflight pod %s has not completed within %v", podName, PreflightTimeout)
+		return result
+	}
+	output := ""
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == DirectVolumeMigrationPreflightClient && status.State.Terminated != nil {
+			output = status.State.Terminated.Message
+		}
+	}
+	connected, throughput, found := parsePreflightOutput(output)
+	if !found {
+		result.Message = fmt.Sprintf("The network preflight pod %s terminated without a result", podName)
+		return result
+	}
+	result.Connected = connected
+	result.Throughput = throughput
+	if !connected {
+		result.Message = fmt.Sprintf("The test data did not reach the destination cluster through the transfer "+
+			"endpoint, check the logs of the stunnel container of the pod %s", podName)
+	}
+	return result
+}
+
+// parsePreflightOutput parses the last result line printed by the client, returns
+// whether the data was acknowledged, the throughput in bytes per second and whether a result was found
+func parsePreflightOutput(output string) (bool, int64, bool) {
+	matches := preflightResultRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false, 0, false
+	}
+	match := matches[len(matches)-1]
+	connected := match[1] == "true"
+	transferred, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return false, 0, false
+	}
+	nanoseconds, err := strconv.ParseInt(match[3], 10, 64)
+	if err != nil {
+		return false, 0, false
+	}
+	if !connected || nanoseconds <= 0 {
+		return connected, 0, true
+	}
+	return true, int64(float64(transferred) * float64(time.Second) / float64(nanoseconds)), true
+}
+
+// deletePreflightResources deletes the resources of the network preflight check on both clusters
+func (t *Task) deletePreflightResources() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	selector := labels.SelectorFromSet(t.getPreflightLabels())
+	for _, client := range []compat.Client{srcClient, destClient} {
+		err = t.findAndDeleteNsResources(client, migapi.OpenshiftMigrationNamespace, selector)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// arePreflightResourcesDeleted returns whether the resources of the network preflight check are deleted on both clusters
+func (t *Task) arePreflightResourcesDeleted() (bool, error) {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	selector := labels.SelectorFromSet(t.getPreflightLabels())
+	for _, client := range []compat.Client{srcClient, destClient} {
+		err, deleted := t.areRsyncNsResourcesDeleted(client, migapi.OpenshiftMigrationNamespace, selector)
+		if err != nil {
+			return false, liberr.Wrap(err)
+		}
+		if !deleted {
+			return false, nil
+		}
+	}
+	return true, nil
+}
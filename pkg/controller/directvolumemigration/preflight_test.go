@@ -0,0 +1,151 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_parsePreflightOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantConnected  bool
+		wantThroughput int64
+		wantFound      bool
+	}{
+		{
+			name:           "data acknowledged in half a second",
+			output:         "preflight connected=true bytes=67108864 nanoseconds=500000000\n",
+			wantConnected:  true,
+			wantThroughput: 134217728,
+			wantFound:      true,
+		},
+		{
+			name:      "data not acknowledged",
+			output:    "preflight connected=false bytes=0 nanoseconds=300000000000\n",
+			wantFound: true,
+		},
+		{
+			name:           "last result line wins",
+			output:         "preflight connected=false bytes=0 nanoseconds=0\npreflight connected=true bytes=1000 nanoseconds=1000000000",
+			wantConnected:  true,
+			wantThroughput: 1000,
+			wantFound:      true,
+		},
+		{
+			name:      "no result line",
+			output:    "bash: nc: command not found",
+			wantFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connected, throughput, found := parsePreflightOutput(tt.output)
+			if connected != tt.wantConnected || throughput != tt.wantThroughput || found != tt.wantFound {
+				t.Errorf("parsePreflightOutput() = %v, %v, %v, want %v, %v, %v",
+					connected, throughput, found, tt.wantConnected, tt.wantThroughput, tt.wantFound)
+			}
+		})
+	}
+}
+
+func getTestPreflightPod(phase corev1.PodPhase, message string, created time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "dvm-preflight-1",
+			Namespace:         migapi.OpenshiftMigrationNamespace,
+			Annotations:       map[string]string{PreflightNodeAnnotation: "node-1"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	if message != "" {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{
+				Name: DirectVolumeMigrationPreflightClient,
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Message: message},
+				},
+			},
+		}
+	}
+	return pod
+}
+
+func Test_getPreflightResult(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name           string
+		pod            *corev1.Pod
+		wantNil        bool
+		wantConnected  bool
+		wantThroughput int64
+		wantMessage    bool
+	}{
+		{
+			name:    "running pod",
+			pod:     getTestPreflightPod(corev1.PodRunning, "", now.Add(-time.Minute)),
+			wantNil: true,
+		},
+		{
+			name:        "running pod timed out",
+			pod:         getTestPreflightPod(corev1.PodRunning, "", now.Add(-PreflightTimeout-time.Minute)),
+			wantMessage: true,
+		},
+		{
+			name:           "succeeded pod",
+			pod:            getTestPreflightPod(corev1.PodSucceeded, "preflight connected=true bytes=2000 nanoseconds=1000000000", now),
+			wantConnected:  true,
+			wantThroughput: 2000,
+		},
+		{
+			name:        "failed pod",
+			pod:         getTestPreflightPod(corev1.PodFailed, "preflight connected=false bytes=0 nanoseconds=0", now),
+			wantMessage: true,
+		},
+		{
+			name:        "failed pod without a result",
+			pod:         getTestPreflightPod(corev1.PodFailed, "", now),
+			wantMessage: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPreflightResult(tt.pod, now)
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("getPreflightResult() = %v, want nil %v", got, tt.wantNil)
+			}
+			if got == nil {
+				return
+			}
+			if got.Node != "node-1" || got.Connected != tt.wantConnected || got.Throughput != tt.wantThroughput ||
+				(got.Message != "") != tt.wantMessage {
+				t.Errorf("getPreflightResult() = %+v", got)
+			}
+		})
+	}
+}
+
+func Test_getPreflightNodes(t *testing.T) {
+	pvcs := []migapi.PVCToMigrate{
+		{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"}},
+		{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-2"}},
+		{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-3"}},
+		{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-4"}, VolumeSnapshotClass: "csi"},
+	}
+	nodeMap := map[string]string{
+		"ns/pvc-1": "node-b",
+		"ns/pvc-2": "node-a",
+		"ns/pvc-3": "node-b",
+		"ns/pvc-4": "node-c",
+	}
+	want := []string{"", "node-a", "node-b"}
+	if got := getPreflightNodes(pvcs, nodeMap); !reflect.DeepEqual(got, want) {
+		t.Errorf("getPreflightNodes() = %v, want %v", got, want)
+	}
+}
@@ -19,22 +19,25 @@ func (t *Task) areSourcePVCsUnattached() error {
 	return nil
 }
 
-func (t *Task) createDestinationPVCs() error {
+// createDestinationPVCs creates the PVCs on the destination cluster, existing PVCs are adopted
+// when requested. Returns the reasons why existing PVCs cannot be adopted.
+func (t *Task) createDestinationPVCs() ([]string, error) {
+	failureReasons := []string{}
 	// Get client for destination
 	destClient, err := t.getDestinationClient()
 	if err != nil {
-		return err
+		return failureReasons, err
 	}
 
 	// Get client for source
 	srcClient, err := t.getSourceClient()
 	if err != nil {
-		return err
+		return failureReasons, err
 	}
 
 	migration, err := t.Owner.GetMigrationForDVM(t.Client)
 	if err != nil {
-		return liberr.Wrap(err)
+		return failureReasons, liberr.Wrap(err)
 	}
 	migrationUID := ""
 	if migration != nil {
@@ -47,7 +50,7 @@ func (t *Task) createDestinationPVCs() error {
 		key := types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}
 		err = srcClient.Get(context.TODO(), key, &srcPVC)
 		if err != nil {
-			return err
+			return failureReasons, err
 		}
 
 		plan := t.PlanResources.MigPlan
@@ -100,26 +103,36 @@ func (t *Task) createDestinationPVCs() error {
 		// Create pvc on destination with same metadata + spec
 		destPVC := corev1.PersistentVolumeClaim{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      pvc.Name,
+				Name:      pvc.GetTargetName(),
 				Namespace: destNs,
 				Labels:    pvcLabels,
 			},
 			Spec: newSpec,
 		}
+		if pvc.AdoptExisting {
+			adopted, reasons, err := t.adoptDestinationPVC(destClient, destPVC)
+			if err != nil {
+				return failureReasons, liberr.Wrap(err)
+			}
+			failureReasons = append(failureReasons, reasons...)
+			if adopted || len(reasons) > 0 {
+				continue
+			}
+		}
 		t.Log.Info("Creating PVC on destination MigCluster",
 			"persistentVolumeClaim", path.Join(pvc.Namespace, pvc.Name),
-			"destPersistentVolumeClaim", path.Join(destNs, pvc.Name),
+			"destPersistentVolumeClaim", path.Join(destNs, pvc.GetTargetName()),
 			"pvcStorageClassName", destPVC.Spec.StorageClassName,
 			"pvcAccessModes", destPVC.Spec.AccessModes,
 			"pvcRequests", destPVC.Spec.Resources.Requests)
 		err = destClient.Create(context.TODO(), &destPVC)
 		if k8serror.IsAlreadyExists(err) {
-			t.Log.Info("PVC already exists on destination", "name", pvc.Name)
+			t.Log.Info("PVC already exists on destination", "name", pvc.GetTargetName())
 		} else if err != nil {
-			return err
+			return failureReasons, err
 		}
 	}
-	return nil
+	return failureReasons, nil
 }
 
 func (t *Task) getDestinationPVCs() error {
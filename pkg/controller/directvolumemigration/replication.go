@@ -0,0 +1,127 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RsyncPassLogTailLines number of log lines read to find the Rsync transfer stats
+const RsyncPassLogTailLines = 20
+
+// matches the total size reported by Rsync with --stats or --info=STATS2
+var rsyncDeltaSizeRegex = regexp.MustCompile(`Total transferred file size: ([^ ]+) bytes`)
+
+// getNextRsyncPassDelay returns the time left until the next continuous Rsync pass
+func (t *Task) getNextRsyncPassDelay() time.Duration {
+	if t.Owner.Spec.Cutover || t.Owner.Status.LastPassTimestamp == nil {
+		return 0
+	}
+	return t.Owner.GetReplicationInterval() - time.Since(t.Owner.Status.LastPassTimestamp.Time)
+}
+
+// startRsyncPass deletes the Rsync client Pods of the previous pass and resets all Rsync operations,
+// the Rsync transfer Pods and Routes on the destination are kept running
+func (t *Task) startRsyncPass() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		podList, err := t.getAllPodsForOperation(srcClient, *operation)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for i := range podList.Items {
+			pod := podList.Items[i]
+			err = srcClient.Delete(context.TODO(), &pod)
+			if err != nil && !k8serror.IsNotFound(err) {
+				return liberr.Wrap(err)
+			}
+		}
+		operation.Reset()
+	}
+	err = t.deleteSnapshotSources(srcClient)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if t.Owner.Spec.Cutover {
+		t.Owner.Status.CutoverStarted = true
+	}
+	t.Log.Info("Starting continuous Rsync pass.", "cutover", t.Owner.Status.CutoverStarted)
+	return nil
+}
+
+// getRsyncPass returns the pass information of a succeeded Rsync Pod
+func (t *Task) getRsyncPass(client compat.Client, pod *corev1.Pod) migapi.RsyncPass {
+	pass := migapi.RsyncPass{
+		Final: t.Owner.Status.CutoverStarted,
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != DirectVolumeMigrationRsyncClient {
+			continue
+		}
+		if terminated := containerStatus.State.Terminated; terminated != nil {
+			pass.StartTimestamp = &terminated.StartedAt
+			pass.Duration = &metav1.Duration{
+				Duration: terminated.FinishedAt.Sub(terminated.StartedAt.Time).Round(time.Second),
+			}
+		}
+	}
+	podLog, err := t.getRsyncClientPodLog(pod)
+	if err != nil {
+		t.Log.Info("Unable to read the Rsync transfer stats.",
+			"pod", path.Join(pod.Namespace, pod.Name), "error", err.Error())
+		return pass
+	}
+	pass.DeltaSize = getRsyncDeltaSize(podLog)
+	return pass
+}
+
+// getRsyncClientPodLog returns the tail of the log of the Rsync client container
+func (t *Task) getRsyncClientPodLog(pod *corev1.Pod) (string, error) {
+	cluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	tailLines := int64(RsyncPassLogTailLines)
+	return t.getPodLog(cluster, pod, DirectVolumeMigrationRsyncClient, &tailLines)
+}
+
+// getPodLog returns the log of a container of a Pod running on the cluster
+func (t *Task) getPodLog(cluster *migapi.MigCluster, pod *corev1.Pod, container string, tailLines *int64) (string, error) {
+	config, err := cluster.BuildRestConfig(t.Client)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	content, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: tailLines,
+	}).DoRaw(context.TODO())
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return string(content), nil
+}
+
+// getRsyncDeltaSize returns the total size of the transferred files reported by Rsync
+func getRsyncDeltaSize(log string) string {
+	matches := rsyncDeltaSizeRegex.FindAllStringSubmatch(log, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
@@ -0,0 +1,105 @@
+package directvolumemigration
+
+import (
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getRsyncDeltaSize(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want string
+	}{
+		{
+			name: "stats present",
+			log: `Number of regular files transferred: 12
+Total file size: 4.21G bytes
+Total transferred file size: 112.45M bytes
+Literal data: 112.45M bytes`,
+			want: "112.45M",
+		},
+		{
+			name: "nothing transferred",
+			log:  "Total transferred file size: 0 bytes",
+			want: "0",
+		},
+		{
+			name: "stats missing",
+			log:  "sent 1.23K bytes  received 35 bytes",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRsyncDeltaSize(tt.log); got != tt.want {
+				t.Errorf("getRsyncDeltaSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getNextRsyncPassDelay(t *testing.T) {
+	recent := metav1.NewTime(time.Now().Add(-1 * time.Minute))
+	old := metav1.NewTime(time.Now().Add(-1 * time.Hour))
+	tests := []struct {
+		name     string
+		spec     migapi.DirectVolumeMigrationSpec
+		lastPass *metav1.Time
+		wantWait bool
+	}{
+		{
+			name:     "interval not elapsed",
+			spec:     migapi.DirectVolumeMigrationSpec{Continuous: true},
+			lastPass: &recent,
+			wantWait: true,
+		},
+		{
+			name:     "interval elapsed",
+			spec:     migapi.DirectVolumeMigrationSpec{Continuous: true, ReplicationIntervalSeconds: 600},
+			lastPass: &old,
+			wantWait: false,
+		},
+		{
+			name:     "cutover requested",
+			spec:     migapi.DirectVolumeMigrationSpec{Continuous: true, Cutover: true},
+			lastPass: &recent,
+			wantWait: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec:   tt.spec,
+					Status: migapi.DirectVolumeMigrationStatus{LastPassTimestamp: tt.lastPass},
+				},
+			}
+			if got := task.getNextRsyncPassDelay(); (got > 0) != tt.wantWait {
+				t.Errorf("getNextRsyncPassDelay() = %v, wantWait %v", got, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestRsyncOperation_AddPass(t *testing.T) {
+	operation := migapi.RsyncOperation{}
+	for i := 0; i < migapi.MaxRsyncPassHistory+2; i++ {
+		operation.AddPass(migapi.RsyncPass{DeltaSize: "1K"})
+	}
+	if len(operation.Passes) != migapi.MaxRsyncPassHistory {
+		t.Errorf("AddPass() retained %d passes, want %d", len(operation.Passes), migapi.MaxRsyncPassHistory)
+	}
+	if last := operation.Passes[len(operation.Passes)-1]; last.Number != migapi.MaxRsyncPassHistory+2 {
+		t.Errorf("AddPass() last pass number = %d, want %d", last.Number, migapi.MaxRsyncPassHistory+2)
+	}
+	operation.Succeeded = true
+	operation.CurrentAttempt = 3
+	operation.Reset()
+	if operation.IsComplete() || operation.CurrentAttempt != 0 || len(operation.Passes) == 0 {
+		t.Errorf("Reset() = %v", operation)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"path"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,17 +28,20 @@ import (
 	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type pvc struct {
 	Name string
+	// Block the rsyncd module accepts the device options, refused by default by rsyncd
+	Block bool
 }
 
 type rsyncConfig struct {
@@ -109,6 +113,9 @@ data:
         auth users = {{ $.SshUser }}
         secrets file = /etc/rsyncd.secrets
         read only = false
+        {{- if $pvc.Block }}
+        refuse options = !copy-devices !write-devices
+        {{- end }}
    {{ end }}
 `
 
@@ -143,7 +150,8 @@ func (t *Task) areRsyncTransferPodsRunning() (arePodsRunning bool, nonRunningPod
 			return false, nil, nil
 		}
 		for _, pod := range pods.Items {
-			if pod.Status.Phase != corev1.PodRunning {
+			// a restarting rsyncd container keeps the Pod Running
+			if pod.Status.Phase != corev1.PodRunning || getRsyncDevicesUnsupportedMessage(&pod) != "" {
 				// Log abnormal events for Rsync transfer Pod if any are found
 				migevent.LogAbnormalEventsForResource(
 					destClient, t.Log,
@@ -241,7 +249,7 @@ func (t *Task) createRsyncConfig() error {
 		pvcList := []pvc{}
 		for _, vol := range vols {
 			pvcHash := getMD5Hash(vol.Name)
-			pvcList = append(pvcList, pvc{Name: pvcHash})
+			pvcList = append(pvcList, pvc{Name: pvcHash, Block: vol.Block})
 		}
 		// Generate template
 		rsyncConf := rsyncConfig{
@@ -303,7 +311,7 @@ func (t *Task) createRsyncConfig() error {
 		srcSecret := corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: srcNs,
-				Name:      DirectVolumeMigrationRsyncCreds,
+				Name:      t.getRsyncClientCredsName(srcNs, destNs),
 			},
 			Data: map[string][]byte{
 				"RSYNC_PASSWORD": []byte(password),
@@ -351,111 +359,6 @@ func (t *Task) createRsyncConfig() error {
 	return nil
 }
 
-// Create rsync transfer route
-func (t *Task) createRsyncTransferRoute() error {
-	// Get client for destination
-	destClient, err := t.getDestinationClient()
-	if err != nil {
-		return err
-	}
-	pvcMap := t.getPVCNamespaceMap()
-	dvmLabels := t.buildDVMLabels()
-	dvmLabels["purpose"] = DirectVolumeMigrationRsync
-
-	for bothNs, _ := range pvcMap {
-		ns := getDestNs(bothNs)
-		svc := corev1.Service{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      DirectVolumeMigrationRsyncTransferSvc,
-				Namespace: ns,
-			},
-			Spec: corev1.ServiceSpec{
-				Ports: []corev1.ServicePort{
-					{
-						Name:       DirectVolumeMigrationStunnel,
-						Protocol:   corev1.ProtocolTCP,
-						Port:       int32(2222),
-						TargetPort: intstr.IntOrString{Type: intstr.Int, IntVal: 2222},
-					},
-				},
-				Selector: dvmLabels,
-				Type:     corev1.ServiceTypeClusterIP,
-			},
-		}
-		svc.Labels = t.Owner.GetCorrelationLabels()
-		svc.Labels["app"] = DirectVolumeMigrationRsyncTransfer
-
-		t.Log.Info("Creating Rsync Transfer Service for Stunnel connection "+
-			"on destination MigCluster ",
-			"service", path.Join(svc.Namespace, svc.Name))
-		err = destClient.Create(context.TODO(), &svc)
-		if k8serror.IsAlreadyExists(err) {
-			t.Log.Info("Rsync transfer svc already exists on destination",
-				"service", path.Join(svc.Namespace, svc.Name))
-		} else if err != nil {
-			return err
-		}
-		route := routev1.Route{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      DirectVolumeMigrationRsyncTransferRoute,
-				Namespace: ns,
-			},
-			Spec: routev1.RouteSpec{
-				To: routev1.RouteTargetReference{
-					Kind: "Service",
-					Name: DirectVolumeMigrationRsyncTransferSvc,
-				},
-				Port: &routev1.RoutePort{
-					TargetPort: intstr.IntOrString{Type: intstr.Int, IntVal: 2222},
-				},
-				TLS: &routev1.TLSConfig{
-					Termination: routev1.TLSTerminationPassthrough,
-				},
-			},
-		}
-		route.Labels = t.Owner.GetCorrelationLabels()
-		route.Labels["app"] = DirectVolumeMigrationRsyncTransfer
-
-		// Get cluster subdomain if it exists
-		cluster, err := t.Owner.GetDestinationCluster(t.Client)
-		if err != nil {
-			return err
-		}
-		// Ignore error since this is optional config and won't break
-		// anything if it doesn't exist
-		subdomain, _ := cluster.GetClusterSubdomain(t.Client)
-
-		// This is a backdoor setting to help guarantee DVM can still function if a
-		// user is migrating namespaces that are 60+ characters
-		// NOTE: We do no validation of this subdomain value. User is expected to
-		// set this properly and it's only used for the unlikely case a user needs
-		// to migrate namespaces with very long names.
-		if subdomain != "" {
-			// Ensure that route prefix will not exceed 63 chars
-			// Route gen will add `-` between name + ns so need to ensure below is <62 chars
-			// NOTE: only do this if we actually get a configured subdomain,
-			// otherwise just use the name and hope for the best
-			prefix := fmt.Sprintf("%s-%s", DirectVolumeMigrationRsyncTransferRoute, getMD5Hash(ns))
-			if len(prefix) > 62 {
-				prefix = prefix[0:62]
-			}
-			host := fmt.Sprintf("%s.%s", prefix, subdomain)
-			route.Spec.Host = host
-		}
-		t.Log.Info("Creating Rsync Transfer Route for Stunnel connection "+
-			"on destination MigCluster ",
-			"route", path.Join(route.Namespace, route.Name))
-		err = destClient.Create(context.TODO(), &route)
-		if k8serror.IsAlreadyExists(err) {
-			t.Log.Info("Rsync transfer route already exists on destination", "namespace", ns)
-		} else if err != nil {
-			return err
-		}
-		t.RsyncRoutes[ns] = route.Spec.Host
-	}
-	return nil
-}
-
 // Transfer pod which runs rsyncd
 func (t *Task) createRsyncTransferPods() error {
 	// Ensure SSH Keys exist
@@ -480,6 +383,14 @@ func (t *Task) createRsyncTransferPods() error {
 	if err != nil {
 		return err
 	}
+	agentImage := ""
+	if t.isTransferAgent() {
+		t.Log.Info("Getting transfer agent image from ConfigMap.")
+		agentImage, err = cluster.GetTransferAgentImage(t.Client)
+		if err != nil {
+			return err
+		}
+	}
 	t.Log.Info("Getting Rsync Transfer Pod limits and requests from ConfigMap.")
 	limits, requests, err := t.getPodResourceLists(TRANSFER_POD_CPU_LIMIT, TRANSFER_POD_MEMORY_LIMIT, TRANSFER_POD_CPU_REQUEST, TRANSFER_POD_MEMORY_REQUEST)
 	if err != nil {
@@ -578,18 +489,28 @@ func (t *Task) createRsyncTransferPods() error {
 		trueBool := true
 		runAsUser := int64(0)
 
-		// Add PVC volume mounts
+		// Add PVC volume mounts, block volumes are attached as devices
+		volumeDevices := []corev1.VolumeDevice{}
+		hasBlock := false
 		for _, vol := range vols {
 			pvcHash := getMD5Hash(vol.Name)
-			volumeMounts = append(volumeMounts, corev1.VolumeMount{
-				Name:      pvcHash,
-				MountPath: fmt.Sprintf("/mnt/%s/%s", ns, pvcHash),
-			})
+			if vol.Block {
+				volumeDevices = append(volumeDevices, corev1.VolumeDevice{
+					Name:       pvcHash,
+					DevicePath: getBlockDevicePath(ns, pvcHash),
+				})
+				hasBlock = true
+			} else {
+				volumeMounts = append(volumeMounts, corev1.VolumeMount{
+					Name:      pvcHash,
+					MountPath: fmt.Sprintf("/mnt/%s/%s", ns, pvcHash),
+				})
+			}
 			volumes = append(volumes, corev1.Volume{
 				Name: pvcHash,
 				VolumeSource: corev1.VolumeSource{
 					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-						ClaimName: vol.Name,
+						ClaimName: vol.TargetName,
 					},
 				},
 			})
@@ -628,7 +549,7 @@ func (t *Task) createRsyncTransferPods() error {
 								Value: string(pubKeyBytes),
 							},
 						},
-						Command: []string{"/usr/bin/rsync", "--daemon", "--no-detach", "--port=22", "-vvv"},
+						Command: getRsyncdCommand(hasBlock),
 						Ports: []corev1.ContainerPort{
 							{
 								Name:          "rsyncd",
@@ -636,7 +557,8 @@ func (t *Task) createRsyncTransferPods() error {
 								ContainerPort: int32(22),
 							},
 						},
-						VolumeMounts: volumeMounts,
+						VolumeMounts:  volumeMounts,
+						VolumeDevices: volumeDevices,
 						SecurityContext: &corev1.SecurityContext{
 							Privileged:             &isRsyncPrivileged,
 							RunAsUser:              &runAsUser,
@@ -684,6 +606,10 @@ func (t *Task) createRsyncTransferPods() error {
 				},
 			},
 		}
+		if t.isTransferAgent() {
+			setTransferAgentServer(&transferPod.Spec, ns, agentImage)
+		}
+		applyTransferPodPolicy(&transferPod.Spec, t.getTransferPodPolicy())
 		t.Log.Info("Creating Rsync Transfer Pod with containers [rsyncd, stunnel] on destination cluster.",
 			"pod", path.Join(transferPod.Namespace, transferPod.Name))
 		err = destClient.Create(context.TODO(), &transferPod)
@@ -734,8 +660,26 @@ func (t *Task) getPodResourceLists(cpuLimit string, memoryLimit string, cpuReque
 }
 
 type pvcMapElement struct {
-	Name   string
-	Verify bool
+	Name       string
+	TargetName string
+	Verify     bool
+	Block      bool
+	Snapshot   bool
+	Filters    *migapi.RsyncFilters
+}
+
+// isIntraNamespace returns whether the Rsync client and transfer Pods of a namespace pair run in the
+// same namespace of the same cluster, as in a storage conversion
+func (t *Task) isIntraNamespace(srcNs, destNs string) bool {
+	return srcNs == destNs && t.Owner.IsIntraCluster()
+}
+
+// getRsyncClientCredsName returns the name of the Rsync password Secret of the source namespace
+func (t *Task) getRsyncClientCredsName(srcNs, destNs string) string {
+	if t.isIntraNamespace(srcNs, destNs) {
+		return DirectVolumeMigrationRsyncClientCreds
+	}
+	return DirectVolumeMigrationRsyncCreds
 }
 
 // With namespace mapping, the destination cluster namespace may be different than that in the source cluster.
@@ -750,10 +694,10 @@ func (t *Task) getPVCNamespaceMap() map[string][]pvcMapElement {
 		}
 		bothNs := srcNs + ":" + destNs
 		if vols, exists := nsMap[bothNs]; exists {
-			vols = append(vols, pvcMapElement{Name: pvc.Name, Verify: pvc.Verify})
+			vols = append(vols, pvcMapElement{Name: pvc.Name, TargetName: pvc.GetTargetName(), Verify: pvc.Verify, Block: pvc.IsBlock(), Snapshot: pvc.IsSnapshotSource(), Filters: pvc.Filters})
 			nsMap[bothNs] = vols
 		} else {
-			nsMap[bothNs] = []pvcMapElement{{Name: pvc.Name, Verify: pvc.Verify}}
+			nsMap[bothNs] = []pvcMapElement{{Name: pvc.Name, TargetName: pvc.GetTargetName(), Verify: pvc.Verify, Block: pvc.IsBlock(), Snapshot: pvc.IsSnapshotSource(), Filters: pvc.Filters}}
 		}
 	}
 	return nsMap
@@ -773,76 +717,6 @@ func getDestNs(bothNs string) string {
 	}
 }
 
-func (t *Task) getRsyncRoute(namespace string) (string, error) {
-	// Get client for destination
-	destClient, err := t.getDestinationClient()
-	if err != nil {
-		return "", err
-	}
-	route := routev1.Route{}
-
-	key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: namespace}
-	err = destClient.Get(context.TODO(), key, &route)
-	if err != nil {
-		return "", err
-	}
-	return route.Spec.Host, nil
-}
-
-func (t *Task) areRsyncRoutesAdmitted() (bool, []string, error) {
-	messages := []string{}
-	// Get client for destination
-	destClient, err := t.getDestinationClient()
-	if err != nil {
-		return false, messages, err
-	}
-	nsMap := t.getPVCNamespaceMap()
-	for bothNs, _ := range nsMap {
-		namespace := getDestNs(bothNs)
-		route := routev1.Route{}
-
-		key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: namespace}
-		err = destClient.Get(context.TODO(), key, &route)
-		if err != nil {
-			return false, messages, err
-		}
-		// Logs abnormal events related to route if any are found
-		migevent.LogAbnormalEventsForResource(
-			destClient, t.Log,
-			"Found abnormal event for Rsync Route on destination cluster",
-			types.NamespacedName{Namespace: route.Namespace, Name: route.Name},
-			route.UID, "Route")
-
-		admitted := false
-		message := "no status condition available for the route"
-		// Check if we can find the admitted condition for the route
-		for _, ingress := range route.Status.Ingress {
-			for _, condition := range ingress.Conditions {
-				if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionFalse {
-					t.Log.Info("Rsync Transfer Route has not been admitted.",
-						"route", path.Join(route.Namespace, route.Name))
-					admitted = false
-					message = condition.Message
-					break
-				}
-				if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
-					t.Log.Info("Rsync Transfer Route has been admitted successfully.",
-						"route", path.Join(route.Namespace, route.Name))
-					admitted = true
-					break
-				}
-			}
-		}
-		if !admitted {
-			messages = append(messages, message)
-		}
-	}
-	if len(messages) > 0 {
-		return false, messages, nil
-	}
-	return true, []string{}, nil
-}
-
 func (t *Task) createRsyncPassword() (string, error) {
 	var letters = []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 	random.Seed(time.Now().UnixNano())
@@ -963,8 +837,30 @@ func (t *Task) filterRsyncExtraOptions(options []string) (validatedOptions []str
 	return
 }
 
+// getRsyncFilterOptions generates the include and exclude options of a PVC, includes are
+// passed first as Rsync applies the first matching pattern. Patterns are single quoted
+// to be passed as-is through the shell of the Rsync client container.
+func (t *Task) getRsyncFilterOptions(filters *migapi.RsyncFilters) []string {
+	options := []string{}
+	if filters.IsEmpty() {
+		return options
+	}
+	if err := filters.Validate(); err != nil {
+		t.Log.Info(fmt.Sprintf("Invalid Rsync filters passed: %s", err))
+		return options
+	}
+	for _, pattern := range filters.Include {
+		options = append(options, fmt.Sprintf("--include='%s'", pattern))
+	}
+	for _, pattern := range filters.Exclude {
+		options = append(options, fmt.Sprintf("--exclude='%s'", pattern))
+	}
+	return options
+}
+
 // generates Rsync options based on custom options provided by the user in MigrationController CR
-func (t *Task) getRsyncOptions() []string {
+// and on the bandwidth limit in KiB/s of the Rsync Pods, -1 when not limited
+func (t *Task) getRsyncOptions(bwLimit int64) []string {
 	var rsyncOpts []string
 	defaultInfoOpts := "COPY2,DEL2,REMOVE2,SKIP2,FLIST2,PROGRESS2,STATS2"
 	defaultExtraOpts := []string{
@@ -973,9 +869,9 @@ func (t *Task) getRsyncOptions() []string {
 		"--log-file", "/dev/stdout",
 	}
 	rsyncOptions := settings.Settings.DvmOpts.RsyncOpts
-	if rsyncOptions.BwLimit != -1 {
+	if bwLimit != -1 {
 		rsyncOpts = append(rsyncOpts,
-			fmt.Sprintf("--bwlimit=%d", rsyncOptions.BwLimit))
+			fmt.Sprintf("--bwlimit=%d", bwLimit))
 	}
 	if rsyncOptions.Archive {
 		rsyncOpts = append(rsyncOpts, "--archive")
@@ -1011,6 +907,9 @@ type PVCWithSecurityContext struct {
 	supplementalGroups []int64
 	seLinuxOptions     *corev1.SELinuxOptions
 	verify             bool
+	block              bool
+	snapshot           bool
+	filters            *migapi.RsyncFilters
 
 	// TODO:
 	// add capabilities for dvm controller to handle case the source
@@ -1067,6 +966,9 @@ func (t *Task) getfsGroupMapForNamespace() (map[string][]PVCWithSecurityContext,
 			pss, exists := pvcSecurityContextMapForNamespace[claim.Name]
 			if exists {
 				pss.verify = claim.Verify
+				pss.block = claim.Block
+				pss.snapshot = claim.Snapshot
+				pss.filters = claim.Filters
 				pvcSecurityContextMap[ns] = append(pvcSecurityContextMap[ns], pss)
 				continue
 			}
@@ -1079,12 +981,24 @@ func (t *Task) getfsGroupMapForNamespace() (map[string][]PVCWithSecurityContext,
 				supplementalGroups: nil,
 				seLinuxOptions:     nil,
 				verify:             claim.Verify,
+				block:              claim.Block,
+				snapshot:           claim.Snapshot,
+				filters:            claim.Filters,
 			})
 		}
 	}
 	return pvcSecurityContextMap, nil
 }
 
+// getClaimName returns the name of the PVC attached to the Rsync client Pod,
+// PVCs copied from a snapshot are read from the clone of the snapshot
+func (p PVCWithSecurityContext) getClaimName() string {
+	if p.snapshot {
+		return getSnapshotCloneName(p.name)
+	}
+	return p.name
+}
+
 func isClaimUsedByPod(claimName string, p *corev1.Pod) bool {
 	for _, vol := range p.Spec.Volumes {
 		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == claimName {
@@ -1239,6 +1153,7 @@ func (t *Task) hasAllProgressReportingCompleted() (bool, error) {
 				LastObservedProgressPercent: dvmp.Status.TotalProgressPercentage,
 				LastObservedTransferRate:    dvmp.Status.LastObservedTransferRate,
 				TotalElapsedTime:            dvmp.Status.RsyncElapsedTime,
+				Stats:                       dvmp.Status.CumulativeStats,
 			}
 			switch {
 			case dvmp.Status.PodPhase == corev1.PodRunning:
@@ -1261,6 +1176,12 @@ func (t *Task) hasAllProgressReportingCompleted() (bool, error) {
 			}
 		}
 	}
+	t.Owner.Status.RsyncStats, t.Owner.Status.NamespaceRsyncStats = getRsyncStatsRollup(
+		t.Owner.Status.RunningPods,
+		t.Owner.Status.FailedPods,
+		t.Owner.Status.SuccessfulPods,
+		t.Owner.Status.PendingPods,
+		unknownPods)
 
 	isCompleted := len(t.Owner.Status.SuccessfulPods)+len(t.Owner.Status.FailedPods) == len(t.Owner.Spec.PersistentVolumeClaims)
 	isAnyPending := len(t.Owner.Status.PendingPods) > 0
@@ -1280,6 +1201,47 @@ func (t *Task) hasAllProgressReportingCompleted() (bool, error) {
 	return !isAnyRunning && !isAnyPending && !isAnyUnknown && isCompleted, nil
 }
 
+// getRsyncStatsRollup sums the Rsync statistics of all PVCs for the migration and for each namespace
+func getRsyncStatsRollup(progressLists ...[]*migapi.PodProgress) (*migapi.RsyncStats, []*migapi.NamespaceRsyncStats) {
+	var total *migapi.RsyncStats
+	namespaceStats := map[string]*migapi.NamespaceRsyncStats{}
+	for _, progressList := range progressLists {
+		for _, podProgress := range progressList {
+			if podProgress.Stats == nil {
+				continue
+			}
+			if total == nil {
+				total = &migapi.RsyncStats{Complete: true}
+			}
+			total.Add(podProgress.Stats)
+			total.Complete = total.Complete && podProgress.Stats.Complete
+			nsStats, exists := namespaceStats[podProgress.PVCReference.Namespace]
+			if !exists {
+				nsStats = &migapi.NamespaceRsyncStats{
+					Namespace:  podProgress.PVCReference.Namespace,
+					RsyncStats: migapi.RsyncStats{Complete: true},
+				}
+				namespaceStats[podProgress.PVCReference.Namespace] = nsStats
+			}
+			nsStats.Add(podProgress.Stats)
+			nsStats.Complete = nsStats.Complete && podProgress.Stats.Complete
+		}
+	}
+	if total == nil {
+		return nil, nil
+	}
+	namespaces := []string{}
+	for ns := range namespaceStats {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	rollup := []*migapi.NamespaceRsyncStats{}
+	for _, ns := range namespaces {
+		rollup = append(rollup, namespaceStats[ns])
+	}
+	return total, rollup
+}
+
 func (t *Task) hasAllRsyncClientPodsTimedOut() (bool, error) {
 	for bothNs, vols := range t.getPVCNamespaceMap() {
 		ns := getSourceNs(bothNs)
@@ -1327,6 +1289,48 @@ func (t *Task) isAllRsyncClientPodsNoRouteToHost() (bool, error) {
 	return true, nil
 }
 
+// getRsyncClientDevicesUnsupportedMessage returns the message of a failed Rsync client Pod of a
+// block volume whose rsync binary does not support the device options.
+func (t *Task) getRsyncClientDevicesUnsupportedMessage() (string, error) {
+	for bothNs, vols := range t.getPVCNamespaceMap() {
+		ns := getSourceNs(bothNs)
+		for _, vol := range vols {
+			if !vol.Block {
+				continue
+			}
+			dvmp := migapi.DirectVolumeMigrationProgress{}
+			err := t.Client.Get(context.TODO(), types.NamespacedName{
+				Name:      getMD5Hash(t.Owner.Name + vol.Name + ns),
+				Namespace: migapi.OpenshiftMigrationNamespace,
+			}, &dvmp)
+			if err != nil {
+				return "", err
+			}
+			if dvmp.Status.PodPhase == corev1.PodFailed && isRsyncDevicesUnsupported(dvmp.Status.LogMessage) {
+				return strings.TrimSpace(dvmp.Status.LogMessage), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// setRsyncDevicesUnsupported sets the critical condition reporting a transfer image
+// of the cluster whose rsync binary cannot migrate block volumes.
+func (t *Task) setRsyncDevicesUnsupported(message string, cluster string) {
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     RsyncDevicesUnsupported,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: migapi.Critical,
+		Message: fmt.Sprintf("The Rsync transfer image of the %s cluster cannot migrate block volumes: %s. "+
+			"Use a transfer image with rsync 3.2 or later.", cluster, message),
+		Durable: true,
+	})
+	t.Log.Info("Rsync of the transfer image does not support block devices.",
+		"cluster", cluster,
+		"message", message)
+}
+
 // Delete rsync resources
 func (t *Task) deleteRsyncResources() error {
 	// Get client for source + destination
@@ -1407,6 +1411,13 @@ func (t *Task) areRsyncResourcesDeleted(srcClient, destClient compat.Client, pvc
 		if !areDeleted {
 			return nil, false
 		}
+		err, areDeleted = t.areSnapshotResourcesDeleted(srcClient, srcNs)
+		if err != nil {
+			return err, false
+		}
+		if !areDeleted {
+			return nil, false
+		}
 		t.Log.Info("Searching destination namespace for leftover Rsync Pods, ConfigMaps, "+
 			"Services, Secrets, Routes with label.",
 			"searchNamespace", destNs,
@@ -1428,6 +1439,8 @@ func (t *Task) areRsyncNsResourcesDeleted(client compat.Client, ns string, selec
 	svcList := corev1.ServiceList{}
 	secretList := corev1.SecretList{}
 	routeList := routev1.RouteList{}
+	ingressList := networkingv1.IngressList{}
+	policyList := networkingv1.NetworkPolicyList{}
 
 	// Get Pod list
 	err := client.List(
@@ -1495,7 +1508,7 @@ func (t *Task) areRsyncNsResourcesDeleted(client compat.Client, ns string, selec
 		return nil, false
 	}
 
-	// Get route list
+	// Get route list, the Route API is not served on non-OpenShift clusters
 	err = client.List(
 		context.TODO(),
 		&routeList,
@@ -1503,7 +1516,7 @@ func (t *Task) areRsyncNsResourcesDeleted(client compat.Client, ns string, selec
 			Namespace:     ns,
 			LabelSelector: selector,
 		})
-	if err != nil {
+	if err != nil && !meta.IsNoMatchError(err) {
 		return err, false
 	}
 	if len(routeList.Items) > 0 {
@@ -1511,6 +1524,40 @@ func (t *Task) areRsyncNsResourcesDeleted(client compat.Client, ns string, selec
 			"route", path.Join(routeList.Items[0].Namespace, routeList.Items[0].Name))
 		return nil, false
 	}
+
+	// Get ingress list
+	err = client.List(
+		context.TODO(),
+		&ingressList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err, false
+	}
+	if len(ingressList.Items) > 0 {
+		t.Log.Info("Found stale Rsync Ingress.",
+			"ingress", path.Join(ingressList.Items[0].Namespace, ingressList.Items[0].Name))
+		return nil, false
+	}
+
+	// Get network policy list
+	err = client.List(
+		context.TODO(),
+		&policyList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+	if err != nil {
+		return err, false
+	}
+	if len(policyList.Items) > 0 {
+		t.Log.Info("Found stale Rsync NetworkPolicy.",
+			"networkPolicy", path.Join(policyList.Items[0].Namespace, policyList.Items[0].Name))
+		return nil, false
+	}
 	return nil, true
 }
 
@@ -1527,6 +1574,10 @@ func (t *Task) findAndDeleteResources(srcClient, destClient compat.Client, pvcMa
 		if err != nil {
 			return err
 		}
+		err = t.findAndDeleteSnapshotResources(srcClient, srcNs)
+		if err != nil {
+			return err
+		}
 		err = t.findAndDeleteNsResources(destClient, destNs, selector)
 		if err != nil {
 			return err
@@ -1540,6 +1591,8 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 	svcList := corev1.ServiceList{}
 	secretList := corev1.SecretList{}
 	routeList := routev1.RouteList{}
+	ingressList := networkingv1.IngressList{}
+	policyList := networkingv1.NetworkPolicyList{}
 
 	// Get Pod list
 	err := client.List(
@@ -1588,7 +1641,7 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 		return err
 	}
 
-	// Get route list
+	// Get route list, the Route API is not served on non-OpenShift clusters
 	err = client.List(
 		context.TODO(),
 		&routeList,
@@ -1596,6 +1649,30 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 			Namespace:     ns,
 			LabelSelector: selector,
 		})
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	// Get ingress list
+	err = client.List(
+		context.TODO(),
+		&ingressList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	// Get network policy list
+	err = client.List(
+		context.TODO(),
+		&policyList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
 	if err != nil {
 		return err
 	}
@@ -1630,6 +1707,26 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 		}
 	}
 
+	// Delete ingresses
+	for _, ingress := range ingressList.Items {
+		t.Log.Info("Deleting stale DVM Ingress",
+			"ingress", path.Join(ingress.Namespace, ingress.Name))
+		err = client.Delete(context.TODO(), &ingress, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !k8serror.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// Delete network policies
+	for _, policy := range policyList.Items {
+		t.Log.Info("Deleting stale DVM NetworkPolicy",
+			"networkPolicy", path.Join(policy.Namespace, policy.Name))
+		err = client.Delete(context.TODO(), &policy, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !k8serror.IsNotFound(err) {
+			return err
+		}
+	}
+
 	// Delete svcs
 	for _, svc := range svcList.Items {
 		t.Log.Info("Deleting stale DVM Service",
@@ -1697,20 +1794,47 @@ type rsyncClientPodRequirements struct {
 	destIP string
 	// rsyncOptions rsync command to execute
 	rsyncOptions []string
+	// policy placement and resources of the Rsync Pod
+	policy *migapi.TransferPodPolicy
+	// agentOptions transfer agent client options, set when the PVC is copied by the transfer agent
+	agentOptions []string
+	// bwLimit bandwidth limit of the Rsync Pod in KiB/s, -1 when not limited
+	bwLimit int64
+	// stunnelConfig name of the Stunnel client ConfigMap, defaults to DirectVolumeMigrationStunnelConfig
+	stunnelConfig string
+}
+
+// getStunnelConfigName returns the name of the Stunnel client ConfigMap mounted by the Rsync Pod
+func (req rsyncClientPodRequirements) getStunnelConfigName() string {
+	if req.stunnelConfig != "" {
+		return req.stunnelConfig
+	}
+	return DirectVolumeMigrationStunnelConfig
 }
 
 // getRsyncClientPodTemplate given RsyncClientPodRequirements, returns a Pod template
 func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
+	if req.agentOptions != nil {
+		return req.getTransferAgentClientPod()
+	}
 	runAsUser := int64(0)
 	trueBool := true
 	isPrivileged := req.privileged
 	volumes := []corev1.Volume{}
 	rsyncVolumeMounts := []corev1.VolumeMount{}
+	rsyncVolumeDevices := []corev1.VolumeDevice{}
 	containers := []corev1.Container{}
-	rsyncVolumeMounts = append(rsyncVolumeMounts, corev1.VolumeMount{
-		Name:      req.pvInfo.pvcHash,
-		MountPath: fmt.Sprintf("/mnt/%s/%s", req.namespace, req.pvInfo.pvcHash),
-	})
+	if req.pvInfo.block {
+		rsyncVolumeDevices = append(rsyncVolumeDevices, corev1.VolumeDevice{
+			Name:       req.pvInfo.pvcHash,
+			DevicePath: getBlockDevicePath(req.namespace, req.pvInfo.pvcHash),
+		})
+	} else {
+		rsyncVolumeMounts = append(rsyncVolumeMounts, corev1.VolumeMount{
+			Name:      req.pvInfo.pvcHash,
+			MountPath: fmt.Sprintf("/mnt/%s/%s", req.namespace, req.pvInfo.pvcHash),
+		})
+	}
 
 	// shared volumeMount for inter-process communication between rsync and stunnel
 	rsyncVolumeMounts = append(rsyncVolumeMounts, corev1.VolumeMount{
@@ -1738,7 +1862,7 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 		Name: req.pvInfo.pvcHash,
 		VolumeSource: corev1.VolumeSource{
 			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-				ClaimName: req.pvInfo.name,
+				ClaimName: req.pvInfo.getClaimName(),
 			},
 		},
 	})
@@ -1749,7 +1873,7 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 		VolumeSource: corev1.VolumeSource{
 			ConfigMap: &corev1.ConfigMapVolumeSource{
 				LocalObjectReference: corev1.LocalObjectReference{
-					Name: DirectVolumeMigrationStunnelConfig,
+					Name: req.getStunnelConfigName(),
 				},
 			},
 		},
@@ -1789,11 +1913,19 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 
 	rsyncCommand := []string{"rsync"}
 	rsyncCommand = append(rsyncCommand, req.rsyncOptions...)
-	rsyncCommand = append(rsyncCommand, fmt.Sprintf("/mnt/%s/%s/", req.namespace, req.pvInfo.pvcHash))
+	if req.pvInfo.block {
+		rsyncCommand = append(rsyncCommand, getBlockDevicePath(req.namespace, req.pvInfo.pvcHash))
+	} else {
+		rsyncCommand = append(rsyncCommand, fmt.Sprintf("/mnt/%s/%s/", req.namespace, req.pvInfo.pvcHash))
+	}
 	rsyncCommand = append(rsyncCommand, fmt.Sprintf("rsync://root@%s/%s", req.destIP, req.pvInfo.pvcHash))
 
 	rsyncCommandStr := strings.Join(rsyncCommand, " ")
-	rsyncCommandBashScript := fmt.Sprintf("trap \"touch /usr/share/rsync-stunnel-mgmt/rsync-client-container-done\" EXIT SIGINT SIGTERM; timeout=600; SECONDS=0; while [ $SECONDS -lt $timeout ]; do nc -z localhost 2222; rc=$?; if [ $rc -eq 0 ]; then %s; rc=$?; break; fi; done; exit $rc;", rsyncCommandStr)
+	rsyncCheckScript := ""
+	if req.pvInfo.block {
+		rsyncCheckScript = getBlockRsyncCheckScript() + " "
+	}
+	rsyncCommandBashScript := fmt.Sprintf("trap \"touch /usr/share/rsync-stunnel-mgmt/rsync-client-container-done\" EXIT SIGINT SIGTERM; %stimeout=600; SECONDS=0; while [ $SECONDS -lt $timeout ]; do nc -z localhost 2222; rc=$?; if [ $rc -eq 0 ]; then %s; rc=$?; break; fi; done; exit $rc;", rsyncCheckScript, rsyncCommandStr)
 	rsyncContainerCommand := []string{
 		"/bin/bash",
 		"-c",
@@ -1838,7 +1970,8 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 				ContainerPort: int32(22),
 			},
 		},
-		VolumeMounts: rsyncVolumeMounts,
+		VolumeMounts:  rsyncVolumeMounts,
+		VolumeDevices: rsyncVolumeDevices,
 		SecurityContext: &corev1.SecurityContext{
 			Privileged:             &isPrivileged,
 			RunAsUser:              &runAsUser,
@@ -1880,7 +2013,10 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 			GenerateName: "dvm-rsync-",
 			Namespace:    req.namespace,
 			Labels:       labels,
-			Annotations:  map[string]string{migapi.RsyncPodIdentityLabel: req.pvInfo.name},
+			Annotations: map[string]string{
+				migapi.RsyncPodIdentityLabel:    req.pvInfo.name,
+				migapi.BandwidthLimitAnnotation: strconv.FormatInt(req.bwLimit, 10),
+			},
 		},
 		Spec: corev1.PodSpec{
 			RestartPolicy: corev1.RestartPolicyNever,
@@ -1894,6 +2030,7 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 			},
 		},
 	}
+	applyTransferPodPolicy(&clientPod.Spec, req.policy)
 	return clientPod
 }
 
@@ -1908,6 +2045,13 @@ func (t *Task) prepareRsyncPodRequirements(srcClient compat.Client) ([]rsyncClie
 	if err != nil {
 		return req, liberr.Wrap(err)
 	}
+	if t.isTransferAgent() {
+		t.Log.V(4).Info("Getting image for transfer agent client Pods that will be created on source MigCluster")
+		transferImage, err = cluster.GetTransferAgentImage(t.Client)
+		if err != nil {
+			return req, liberr.Wrap(err)
+		}
+	}
 	t.Log.V(4).Info("Getting [NS => PVCWithSecurityContext] mappings for PVCs to be migrated")
 	pvcMap, err := t.getfsGroupMapForNamespace()
 	if err != nil {
@@ -1935,13 +2079,38 @@ func (t *Task) prepareRsyncPodRequirements(srcClient compat.Client) ([]rsyncClie
 	}
 	isPrivileged, _ := isRsyncPrivileged(srcClient)
 	t.Log.V(4).Info(fmt.Sprintf("Rsync client Pods will be created with privileged=[%v]", isPrivileged))
+	// the bandwidth limit in effect is reported in the status, Pods started under another limit are restarted
+	bwLimit := t.getBandwidthLimit()
+	t.Owner.Status.BandwidthLimit = &bwLimit
+	destNamespaces := map[string]string{}
+	for bothNs := range t.getPVCNamespaceMap() {
+		destNamespaces[getSourceNs(bothNs)] = getDestNs(bothNs)
+	}
 	for ns, vols := range pvcMap {
+		var addresses []string
+		if t.isTransferAgent() && len(vols) > 0 {
+			addresses, err = t.getRsyncTransferAddresses(destNamespaces[ns])
+			if err != nil {
+				return req, liberr.Wrap(err)
+			}
+		}
 		// Add PVC volume mounts
 		for _, vol := range vols {
-			rsyncOptions := t.getRsyncOptions()
+			rsyncOptions := t.getRsyncOptions(bwLimit.PodLimit)
 			if vol.verify {
 				rsyncOptions = append(rsyncOptions, "--checksum")
 			}
+			if vol.block {
+				rsyncOptions = append(rsyncOptions, getBlockRsyncOptions()...)
+			} else {
+				rsyncOptions = append(rsyncOptions, t.getRsyncFilterOptions(vol.filters)...)
+			}
+			// the clone of a snapshot is not attached to any node yet, with WaitForFirstConsumer
+			// binding the volume is provisioned only when the Pod goes through the scheduler
+			nodeName := pvcNodeMap[ns+"/"+vol.name]
+			if vol.snapshot {
+				nodeName = ""
+			}
 			podRequirements := rsyncClientPodRequirements{
 				pvInfo:    vol,
 				namespace: ns,
@@ -1956,9 +2125,16 @@ func (t *Task) prepareRsyncPodRequirements(srcClient compat.Client) ([]rsyncClie
 					Requests: stunnelRequests,
 				},
 				privileged:   isPrivileged,
-				nodeName:     pvcNodeMap[ns+"/"+vol.name],
+				nodeName:     nodeName,
 				destIP:       "localhost",
 				rsyncOptions: rsyncOptions,
+				policy:       t.getTransferPodPolicy(),
+				bwLimit:      bwLimit.PodLimit,
+				// the ConfigMap of the transfer Pod shares the namespace in a storage conversion
+				stunnelConfig: t.getStunnelClientConfigName(ns, destNamespaces[ns]),
+			}
+			if t.isTransferAgent() {
+				podRequirements.agentOptions = getTransferAgentClientOptions(vol, ns, addresses, bwLimit.PodLimit)
 			}
 			req = append(req, podRequirements)
 		}
@@ -2097,6 +2273,16 @@ func (t *Task) reportAdvancedErrorHeuristics() ([]string, error) {
 		reasons = append(reasons, "All the source cluster Rsync Pods have timed out, look at error condition for more details")
 		return reasons, nil
 	}
+	// check if the pods are failing because rsync of the transfer image cannot sync block devices
+	unsupportedMessage, err := t.getRsyncClientDevicesUnsupportedMessage()
+	if err != nil {
+		return reasons, liberr.Wrap(err)
+	}
+	if unsupportedMessage != "" {
+		t.setRsyncDevicesUnsupported(unsupportedMessage, "source")
+		reasons = append(reasons, "The Rsync transfer image of the source cluster cannot migrate block volumes, look at error condition for more details")
+		return reasons, nil
+	}
 	// check if the pods are failing due to 'No route to host' error
 	isNoRouteToHost, err := t.isAllRsyncClientPodsNoRouteToHost()
 	if err != nil {
@@ -2366,8 +2552,16 @@ func (t *Task) reconcileRsyncOperationState(client compat.Client, req *rsyncClie
 		if pod != nil {
 			operation.CurrentAttempt, _ = strconv.Atoi(pod.Labels[RsyncAttemptLabel])
 			currentStatus.failed, currentStatus.succeeded, currentStatus.running, currentStatus.pending = t.analyzeRsyncPodStatus(pod)
-			// when pod failed and backoff limit is not reached, create a new pod
-			if currentStatus.failed && operation.CurrentAttempt < GetRsyncPodBackOffLimit(*t.Owner) {
+			// when the bandwidth schedule changed the limit, restart the pod with the new limit
+			if (currentStatus.running || currentStatus.pending) && isBandwidthLimitChanged(pod, req) {
+				err := t.restartPodForOperation(client, req, operation, pod)
+				if err != nil {
+					currentStatus.AddError(err)
+				}
+				currentStatus.running = false
+				currentStatus.pending = true
+			} else if currentStatus.failed && operation.CurrentAttempt < GetRsyncPodBackOffLimit(*t.Owner) {
+				// when pod failed and backoff limit is not reached, create a new pod
 				err := t.createNewPodForOperation(client, req, operation)
 				if err != nil {
 					currentStatus.AddError(err)
@@ -2380,6 +2574,9 @@ func (t *Task) reconcileRsyncOperationState(client compat.Client, req *rsyncClie
 			} else {
 				operation.Failed = currentStatus.failed
 				operation.Succeeded = currentStatus.succeeded
+				if operation.Succeeded && t.Owner.Spec.Continuous {
+					operation.AddPass(t.getRsyncPass(client, pod))
+				}
 				if operation.IsComplete() {
 					t.Log.Info(
 						fmt.Sprintf("Rsync operation completed after %d attempts", operation.CurrentAttempt),
@@ -2442,6 +2639,10 @@ func (t *Task) getLatestPodForOperation(client compat.Client, operation migapi.R
 		// if expected attempt label is not found on the pod or its value is not an integer,
 		// there is no way to associate this pod with an Rsync attempt we made, we skip this pod
 		pod := podList.Items[i]
+		// pods of a previous continuous pass are being deleted
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
 		if val, exists := pod.Labels[RsyncAttemptLabel]; !exists {
 			continue
 		} else if _, err := strconv.Atoi(val); err != nil {
@@ -1155,3 +1155,92 @@ func TestTask_processRsyncOperationStatus(t *testing.T) {
 		})
 	}
 }
+
+func Test_getRsyncStatsRollup(t *testing.T) {
+	podProgress := func(ns string, name string, stats *migapi.RsyncStats) *migapi.PodProgress {
+		return &migapi.PodProgress{
+			ObjectReference: &corev1.ObjectReference{Namespace: ns, Name: "rsync-" + name},
+			PVCReference:    &corev1.ObjectReference{Namespace: ns, Name: name},
+			Stats:           stats,
+		}
+	}
+	running := []*migapi.PodProgress{
+		podProgress("ns-2", "pvc-3", &migapi.RsyncStats{TotalFiles: 5, TransferredBytes: 100, AverageThroughput: 10,
+			EstimatedTimeRemaining: &metav1.Duration{Duration: time.Minute}}),
+		podProgress("ns-1", "pvc-4", nil),
+	}
+	successful := []*migapi.PodProgress{
+		podProgress("ns-1", "pvc-1", &migapi.RsyncStats{TotalFiles: 10, TotalBytes: 1000, TransferredBytes: 1000,
+			SentBytes: 900, ReceivedBytes: 100, Complete: true}),
+		podProgress("ns-1", "pvc-2", &migapi.RsyncStats{TotalFiles: 2, TotalBytes: 1000, TransferredBytes: 0,
+			SentBytes: 40, ReceivedBytes: 10, Complete: true}),
+	}
+	total, namespaces := getRsyncStatsRollup(running, successful)
+	wantTotal := &migapi.RsyncStats{
+		TotalFiles:             17,
+		TotalBytes:             2000,
+		TransferredBytes:       1100,
+		SentBytes:              940,
+		ReceivedBytes:          110,
+		AverageThroughput:      10,
+		EstimatedTimeRemaining: &metav1.Duration{Duration: time.Minute},
+		Speedup:                "1.90",
+	}
+	if !reflect.DeepEqual(total, wantTotal) {
+		t.Errorf("getRsyncStatsRollup() total = %v, want %v", total, wantTotal)
+	}
+	wantNamespaces := []*migapi.NamespaceRsyncStats{
+		{
+			Namespace: "ns-1",
+			RsyncStats: migapi.RsyncStats{TotalFiles: 12, TotalBytes: 2000, TransferredBytes: 1000,
+				SentBytes: 940, ReceivedBytes: 110, Speedup: "1.90", Complete: true},
+		},
+		{
+			Namespace: "ns-2",
+			RsyncStats: migapi.RsyncStats{TotalFiles: 5, TransferredBytes: 100, AverageThroughput: 10,
+				EstimatedTimeRemaining: &metav1.Duration{Duration: time.Minute}},
+		},
+	}
+	if !reflect.DeepEqual(namespaces, wantNamespaces) {
+		t.Errorf("getRsyncStatsRollup() namespaces = %v, want %v", namespaces, wantNamespaces)
+	}
+	total, namespaces = getRsyncStatsRollup(running[1:])
+	if total != nil || namespaces != nil {
+		t.Errorf("getRsyncStatsRollup() = %v, %v, want no statistics", total, namespaces)
+	}
+}
+
+func TestTask_getRsyncFilterOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters *migapi.RsyncFilters
+		want    []string
+	}{
+		{
+			name:    "no filters",
+			filters: nil,
+			want:    []string{},
+		},
+		{
+			name: "includes are passed before excludes",
+			filters: &migapi.RsyncFilters{
+				Include: []string{"important.tmp"},
+				Exclude: []string{"lost+found/", "*.tmp"},
+			},
+			want: []string{"--include='important.tmp'", "--exclude='lost+found/'", "--exclude='*.tmp'"},
+		},
+		{
+			name:    "invalid filters are dropped",
+			filters: &migapi.RsyncFilters{Exclude: []string{"*.tmp' /etc '"}},
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Log: log}
+			if got := task.getRsyncFilterOptions(tt.filters); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRsyncFilterOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
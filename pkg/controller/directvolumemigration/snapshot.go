@@ -0,0 +1,325 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DirectVolumeMigrationSnapshotSource purpose of the source VolumeSnapshots and of the PVCs cloned from them
+const DirectVolumeMigrationSnapshotSource = "snapshot-source"
+
+// getSourceSnapshotName returns the name of the VolumeSnapshot of a source PVC
+func getSourceSnapshotName(pvcName string) string {
+	return fmt.Sprintf("dvm-snapshot-%s", getMD5Hash(pvcName))
+}
+
+// getSnapshotCloneName returns the name of the PVC cloned from the VolumeSnapshot of a source PVC
+func getSnapshotCloneName(pvcName string) string {
+	return fmt.Sprintf("dvm-clone-%s", getMD5Hash(pvcName))
+}
+
+// getSourceClaimName returns the name of the PVC read by the Rsync client Pod of a source PVC.
+// PVCs copied from a snapshot are read from the clone so that the application keeps running.
+func getSourceClaimName(pvc migapi.PVCToMigrate) string {
+	if pvc.IsSnapshotSource() {
+		return getSnapshotCloneName(pvc.Name)
+	}
+	return pvc.Name
+}
+
+// getSnapshotSourceLabels returns the labels of the VolumeSnapshots and clones created by the DVM
+func (t *Task) getSnapshotSourceLabels() map[string]string {
+	labels := t.Owner.GetCorrelationLabels()
+	labels["app"] = DirectVolumeMigrationRsyncTransfer
+	labels["purpose"] = DirectVolumeMigrationSnapshotSource
+	return labels
+}
+
+// getSnapshotSourceSelector returns the selector of the VolumeSnapshots and clones created by DVMs
+func getSnapshotSourceSelector() labels.Selector {
+	return labels.SelectorFromSet(map[string]string{
+		"app":     DirectVolumeMigrationRsyncTransfer,
+		"purpose": DirectVolumeMigrationSnapshotSource,
+	})
+}
+
+// hasSnapshotSources returns whether any PVC of the DVM is copied from a snapshot
+func (t *Task) hasSnapshotSources() bool {
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.IsSnapshotSource() {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureSourceSnapshots creates a VolumeSnapshot of each source PVC copied from a snapshot,
+// returns whether all of them are ready to use along with the errors reported by the snapshotter
+func (t *Task) ensureSourceSnapshots() (bool, []string, error) {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	allReady := true
+	reasons := []string{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if !pvc.IsSnapshotSource() {
+			continue
+		}
+		snapshot := unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(migapi.VolumeSnapshotGVK)
+		key := types.NamespacedName{Name: getSourceSnapshotName(pvc.Name), Namespace: pvc.Namespace}
+		err := srcClient.Get(context.TODO(), key, &snapshot)
+		if k8serror.IsNotFound(err) {
+			newSnapshot := t.buildSourceSnapshot(pvc)
+			t.Log.Info("Creating VolumeSnapshot of source PVC.",
+				"volumeSnapshot", path.Join(newSnapshot.GetNamespace(), newSnapshot.GetName()),
+				"persistentVolumeClaim", path.Join(pvc.Namespace, pvc.Name),
+				"volumeSnapshotClass", pvc.VolumeSnapshotClass)
+			err = srcClient.Create(context.TODO(), newSnapshot)
+			if err != nil && !k8serror.IsAlreadyExists(err) {
+				return false, nil, liberr.Wrap(err)
+			}
+			allReady = false
+			continue
+		}
+		if err != nil {
+			return false, nil, liberr.Wrap(err)
+		}
+		// The snapshot of a previous pass is still being deleted
+		if snapshot.GetDeletionTimestamp() != nil {
+			allReady = false
+			continue
+		}
+		ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		if !ready {
+			allReady = false
+			message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+			if found && message != "" {
+				reasons = append(reasons,
+					fmt.Sprintf("VolumeSnapshot %s: %s", path.Join(snapshot.GetNamespace(), snapshot.GetName()), message))
+			}
+		}
+	}
+	return allReady, reasons, nil
+}
+
+// buildSourceSnapshot builds the VolumeSnapshot of a source PVC
+func (t *Task) buildSourceSnapshot(pvc migapi.PVCToMigrate) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(migapi.VolumeSnapshotGVK)
+	snapshot.SetName(getSourceSnapshotName(pvc.Name))
+	snapshot.SetNamespace(pvc.Namespace)
+	snapshot.SetLabels(t.getSnapshotSourceLabels())
+	_ = unstructured.SetNestedField(snapshot.Object, pvc.VolumeSnapshotClass, "spec", "volumeSnapshotClassName")
+	_ = unstructured.SetNestedField(snapshot.Object, pvc.Name, "spec", "source", "persistentVolumeClaimName")
+	return snapshot
+}
+
+// ensureSnapshotClones provisions a PVC from the VolumeSnapshot of each source PVC copied from a snapshot,
+// returns whether all clones are created. The clones are not bound until the Rsync client Pods consume them.
+func (t *Task) ensureSnapshotClones() (bool, error) {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	allCreated := true
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if !pvc.IsSnapshotSource() {
+			continue
+		}
+		clone := corev1.PersistentVolumeClaim{}
+		key := types.NamespacedName{Name: getSnapshotCloneName(pvc.Name), Namespace: pvc.Namespace}
+		err := srcClient.Get(context.TODO(), key, &clone)
+		if err == nil {
+			// The clone of a previous pass is still being deleted
+			if clone.DeletionTimestamp != nil {
+				allCreated = false
+			}
+			continue
+		}
+		if !k8serror.IsNotFound(err) {
+			return false, liberr.Wrap(err)
+		}
+		srcPVC := corev1.PersistentVolumeClaim{}
+		err = srcClient.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, &srcPVC)
+		if err != nil {
+			return false, liberr.Wrap(err)
+		}
+		snapshot := unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(migapi.VolumeSnapshotGVK)
+		err = srcClient.Get(context.TODO(),
+			types.NamespacedName{Name: getSourceSnapshotName(pvc.Name), Namespace: pvc.Namespace}, &snapshot)
+		if err != nil {
+			return false, liberr.Wrap(err)
+		}
+		newClone := t.buildSnapshotClone(srcPVC, snapshot)
+		t.Log.Info("Creating PVC from VolumeSnapshot of source PVC.",
+			"persistentVolumeClaim", path.Join(newClone.Namespace, newClone.Name),
+			"volumeSnapshot", path.Join(snapshot.GetNamespace(), snapshot.GetName()))
+		err = srcClient.Create(context.TODO(), newClone)
+		if err != nil && !k8serror.IsAlreadyExists(err) {
+			return false, liberr.Wrap(err)
+		}
+	}
+	return allCreated, nil
+}
+
+// buildSnapshotClone builds a PVC provisioned from the VolumeSnapshot of a source PVC.
+// The clone requests at least the restore size of the snapshot.
+func (t *Task) buildSnapshotClone(srcPVC corev1.PersistentVolumeClaim, snapshot unstructured.Unstructured) *corev1.PersistentVolumeClaim {
+	apiGroup := migapi.VolumeSnapshotGVK.Group
+	resources := *srcPVC.Spec.Resources.DeepCopy()
+	if resources.Requests == nil {
+		resources.Requests = corev1.ResourceList{}
+	}
+	restoreSize, found, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize")
+	if found {
+		size, err := resource.ParseQuantity(restoreSize)
+		if err == nil && size.Cmp(resources.Requests[corev1.ResourceStorage]) > 0 {
+			resources.Requests[corev1.ResourceStorage] = size
+		}
+	}
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getSnapshotCloneName(srcPVC.Name),
+			Namespace: srcPVC.Namespace,
+			Labels:    t.getSnapshotSourceLabels(),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      srcPVC.Spec.AccessModes,
+			StorageClassName: srcPVC.Spec.StorageClassName,
+			VolumeMode:       srcPVC.Spec.VolumeMode,
+			Resources:        resources,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     migapi.VolumeSnapshotGVK.Kind,
+				Name:     snapshot.GetName(),
+			},
+		},
+	}
+}
+
+// deleteSnapshotSources deletes the VolumeSnapshots and clones of the source PVCs
+// so that the next Rsync pass copies a new snapshot
+func (t *Task) deleteSnapshotSources(client compat.Client) error {
+	namespaces := map[string]bool{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.IsSnapshotSource() {
+			namespaces[pvc.Namespace] = true
+		}
+	}
+	for ns := range namespaces {
+		err := t.findAndDeleteSnapshotResources(client, ns)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// findAndDeleteSnapshotResources deletes the clones and VolumeSnapshots created by DVMs in the namespace
+func (t *Task) findAndDeleteSnapshotResources(client compat.Client, ns string) error {
+	selector := getSnapshotSourceSelector()
+	pvcList := corev1.PersistentVolumeClaimList{}
+	err := client.List(
+		context.TODO(),
+		&pvcList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+	if err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := pvcList.Items[i]
+		t.Log.Info("Deleting PVC cloned from VolumeSnapshot.",
+			"persistentVolumeClaim", path.Join(pvc.Namespace, pvc.Name))
+		err = client.Delete(context.TODO(), &pvc, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !k8serror.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// The snapshot API is not served on clusters without a CSI snapshot controller
+	snapshotList := unstructured.UnstructuredList{}
+	snapshotList.SetGroupVersionKind(migapi.VolumeSnapshotListGVK)
+	err = client.List(
+		context.TODO(),
+		&snapshotList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	for i := range snapshotList.Items {
+		snapshot := snapshotList.Items[i]
+		t.Log.Info("Deleting VolumeSnapshot of source PVC.",
+			"volumeSnapshot", path.Join(snapshot.GetNamespace(), snapshot.GetName()))
+		err = client.Delete(context.TODO(), &snapshot, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !k8serror.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// areSnapshotResourcesDeleted returns whether the clones and VolumeSnapshots created by DVMs
+// in the namespace are deleted
+func (t *Task) areSnapshotResourcesDeleted(client compat.Client, ns string) (error, bool) {
+	selector := getSnapshotSourceSelector()
+	pvcList := corev1.PersistentVolumeClaimList{}
+	err := client.List(
+		context.TODO(),
+		&pvcList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+	if err != nil {
+		return err, false
+	}
+	if len(pvcList.Items) > 0 {
+		t.Log.Info("Found stale PVC cloned from VolumeSnapshot.",
+			"persistentVolumeClaim", path.Join(pvcList.Items[0].Namespace, pvcList.Items[0].Name))
+		return nil, false
+	}
+	snapshotList := unstructured.UnstructuredList{}
+	snapshotList.SetGroupVersionKind(migapi.VolumeSnapshotListGVK)
+	err = client.List(
+		context.TODO(),
+		&snapshotList,
+		&k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err, false
+	}
+	if len(snapshotList.Items) > 0 {
+		t.Log.Info("Found stale VolumeSnapshot.",
+			"volumeSnapshot", path.Join(snapshotList.Items[0].GetNamespace(), snapshotList.Items[0].GetName()))
+		return nil, false
+	}
+	return nil, true
+}
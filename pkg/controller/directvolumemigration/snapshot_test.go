@@ -0,0 +1,160 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestTask_buildSnapshotClone(t *testing.T) {
+	storageClass := "csi-rbd"
+	tests := []struct {
+		name        string
+		restoreSize string
+		wantSize    string
+	}{
+		{
+			name:     "snapshot without restore size",
+			wantSize: "1Gi",
+		},
+		{
+			name:        "restore size smaller than the request",
+			restoreSize: "512Mi",
+			wantSize:    "1Gi",
+		},
+		{
+			name:        "restore size larger than the request",
+			restoreSize: "2Gi",
+			wantSize:    "2Gi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Owner: &migapi.DirectVolumeMigration{}}
+			srcPVC := corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "ns"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: &storageClass,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			snapshot := task.buildSourceSnapshot(migapi.PVCToMigrate{
+				ObjectReference:     &corev1.ObjectReference{Name: "pvc", Namespace: "ns"},
+				VolumeSnapshotClass: "csi-rbd-snapclass",
+			})
+			if tt.restoreSize != "" {
+				_ = unstructured.SetNestedField(snapshot.Object, tt.restoreSize, "status", "restoreSize")
+			}
+			clone := task.buildSnapshotClone(srcPVC, *snapshot)
+			if clone.Name != getSnapshotCloneName("pvc") || clone.Namespace != "ns" {
+				t.Errorf("buildSnapshotClone() name = %s", clone.Name)
+			}
+			size := clone.Spec.Resources.Requests[corev1.ResourceStorage]
+			if size.Cmp(resource.MustParse(tt.wantSize)) != 0 {
+				t.Errorf("buildSnapshotClone() size = %s, want %s", size.String(), tt.wantSize)
+			}
+			if clone.Spec.DataSource == nil || clone.Spec.DataSource.Kind != "VolumeSnapshot" ||
+				clone.Spec.DataSource.Name != getSourceSnapshotName("pvc") {
+				t.Errorf("buildSnapshotClone() dataSource = %v", clone.Spec.DataSource)
+			}
+			if *clone.Spec.StorageClassName != storageClass {
+				t.Errorf("buildSnapshotClone() storageClassName = %s", *clone.Spec.StorageClassName)
+			}
+			// The request of the source PVC must not be modified
+			srcSize := srcPVC.Spec.Resources.Requests[corev1.ResourceStorage]
+			if srcSize.Cmp(resource.MustParse("1Gi")) != 0 {
+				t.Errorf("buildSnapshotClone() modified the source PVC request: %s", srcSize.String())
+			}
+		})
+	}
+}
+
+func TestTask_findAndDeleteSnapshotResources(t *testing.T) {
+	task := &Task{Log: log, Owner: &migapi.DirectVolumeMigration{}}
+	appPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "ns"},
+	}
+	clone := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getSnapshotCloneName("pvc"),
+			Namespace: "ns",
+			Labels:    task.getSnapshotSourceLabels(),
+		},
+	}
+	// The fake client needs the snapshot API registered to serve unstructured VolumeSnapshots
+	scheme.Scheme.AddKnownTypeWithName(migapi.VolumeSnapshotGVK, &unstructured.Unstructured{})
+	scheme.Scheme.AddKnownTypeWithName(migapi.VolumeSnapshotListGVK, &unstructured.UnstructuredList{})
+	snapshot := task.buildSourceSnapshot(migapi.PVCToMigrate{
+		ObjectReference:     &corev1.ObjectReference{Name: "pvc", Namespace: "ns"},
+		VolumeSnapshotClass: "csi-rbd-snapclass",
+	})
+	client := fakecompat.NewFakeClient(appPVC, clone, snapshot)
+	err, deleted := task.areSnapshotResourcesDeleted(client, "ns")
+	if err != nil || deleted {
+		t.Fatalf("areSnapshotResourcesDeleted() = %v, %v, want clone found", err, deleted)
+	}
+	err = task.findAndDeleteSnapshotResources(client, "ns")
+	if err != nil {
+		t.Fatalf("findAndDeleteSnapshotResources() error = %v", err)
+	}
+	err, deleted = task.areSnapshotResourcesDeleted(client, "ns")
+	if err != nil || !deleted {
+		t.Errorf("areSnapshotResourcesDeleted() = %v, %v, want deleted", err, deleted)
+	}
+	// The PVC of the application is left untouched
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "pvc", Namespace: "ns"}, &corev1.PersistentVolumeClaim{})
+	if err != nil {
+		t.Errorf("findAndDeleteSnapshotResources() deleted the application PVC: %v", err)
+	}
+	snapshotList := unstructured.UnstructuredList{}
+	snapshotList.SetGroupVersionKind(migapi.VolumeSnapshotListGVK)
+	err = client.List(context.TODO(), &snapshotList)
+	if err != nil || len(snapshotList.Items) != 0 {
+		t.Errorf("findAndDeleteSnapshotResources() snapshots = %v, %v, want none", snapshotList.Items, err)
+	}
+}
+
+func Test_getRsyncClientPodTemplate_snapshotSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshot  bool
+		wantClaim string
+	}{
+		{
+			name:      "source PVC is attached",
+			wantClaim: "pvc",
+		},
+		{
+			name:      "clone of the snapshot is attached",
+			snapshot:  true,
+			wantClaim: getSnapshotCloneName("pvc"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := rsyncClientPodRequirements{
+				pvInfo:    PVCWithSecurityContext{name: "pvc", pvcHash: "pvc-hash", snapshot: tt.snapshot},
+				namespace: "ns",
+				destIP:    "localhost",
+			}
+			pod := req.getRsyncClientPodTemplate()
+			if claim := pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName; claim != tt.wantClaim {
+				t.Errorf("getRsyncClientPodTemplate() claimName = %s, want %s", claim, tt.wantClaim)
+			}
+			if pod.Spec.Volumes[0].Name != "pvc-hash" {
+				t.Errorf("getRsyncClientPodTemplate() volume name = %s, want pvc-hash", pod.Spec.Volumes[0].Name)
+			}
+		})
+	}
+}
@@ -29,13 +29,14 @@ import (
 )
 
 type stunnelConfig struct {
-	Name          string
-	Namespace     string
-	StunnelPort   int32
-	RsyncRoute    string
-	RsyncPort     int32
-	VerifyCA      bool
-	VerifyCALevel string
+	Name           string
+	Namespace      string
+	StunnelPort    int32
+	RsyncRoute     string
+	RsyncAddresses []string
+	RsyncPort      int32
+	VerifyCA       bool
+	VerifyCALevel  string
 	stunnelProxyConfig
 }
 
@@ -66,7 +67,7 @@ data:
 {{ if not (eq .ProxyHost "") }}
     protocol = connect
     connect = {{ .ProxyHost }}
-    protocolHost = {{ .RsyncRoute }}:443
+    protocolHost = {{ .RsyncRoute }}
 {{ if not (eq .ProxyUsername "") }}
     protocolUsername = {{ .ProxyUsername }}
 {{ end }}
@@ -74,7 +75,9 @@ data:
     protocolPassword = {{ .ProxyPassword }}
 {{ end }}
 {{ else }}
-    connect = {{ .RsyncRoute }}:443
+{{ range .RsyncAddresses }}
+    connect = {{ . }}
+{{ end }}
 {{ end }}
 {{ if .VerifyCA }}
     verify = {{ .VerifyCALevel }}
@@ -105,6 +108,14 @@ data:
     TIMEOUTclose = 0
 `
 
+// getStunnelClientConfigName returns the name of the Stunnel ConfigMap of the source namespace
+func (t *Task) getStunnelClientConfigName(srcNs, destNs string) string {
+	if t.isIntraNamespace(srcNs, destNs) {
+		return DirectVolumeMigrationStunnelClientConfig
+	}
+	return DirectVolumeMigrationStunnelConfig
+}
+
 // generateStunnelProxyConfig loads stunnel proxy configuration from app settings
 func (t *Task) generateStunnelProxyConfig() (stunnelProxyConfig, error) {
 	var proxyConfig stunnelProxyConfig
@@ -185,15 +196,17 @@ func (t *Task) createStunnelConfig() error {
 		srcNs := getSourceNs(bothNs)
 		destNs := getDestNs(bothNs)
 		// Declare config
-		rsyncRoute, err := t.getRsyncRoute(destNs)
+		rsyncAddresses, err := t.getRsyncTransferAddresses(destNs)
 		if err != nil {
 			return err
 		}
+		rsyncRoute := rsyncAddresses[0]
 		srcStunnelConf := stunnelConfig{
 			Namespace:          srcNs,
 			StunnelPort:        2222,
 			RsyncPort:          22,
 			RsyncRoute:         rsyncRoute,
+			RsyncAddresses:     rsyncAddresses,
 			stunnelProxyConfig: srcStunnelProxyConfig,
 			VerifyCA:           settings.Settings.StunnelVerifyCA,
 			VerifyCALevel:      settings.Settings.StunnelVerifyCALevel,
@@ -232,7 +245,7 @@ func (t *Task) createStunnelConfig() error {
 		clientConfigMap := corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: srcNs,
-				Name:      DirectVolumeMigrationStunnelConfig,
+				Name:      t.getStunnelClientConfigName(srcNs, destNs),
 			},
 		}
 		clientConfigMap.Labels = t.Owner.GetCorrelationLabels()
@@ -302,59 +315,7 @@ func (t *Task) setupCerts() error {
 	// Skip CAbundle generation if configmap already exists
 	// TODO: Need to handle case where configmap gets deleted and 2 versions of
 	// CA bundle exist
-	t.Log.Info("Generating CA Bundle for Stunnel")
-	caPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
-	if err != nil {
-		return err
-	}
-
-	subj := pkix.Name{
-		CommonName:         "openshift.io",
-		Country:            []string{"US"},
-		Province:           []string{"NC"},
-		Locality:           []string{"RDU"},
-		Organization:       []string{"Migration Engineering"},
-		OrganizationalUnit: []string{"Engineering"},
-	}
-
-	certTemp := x509.Certificate{
-		SerialNumber:          big.NewInt(2020),
-		Subject:               subj,
-		NotBefore:             time.Now(),
-		NotAfter:              time.Now().AddDate(10, 0, 0),
-		IsCA:                  true,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		BasicConstraintsValid: true,
-	}
-
-	caBytes, err := x509.CreateCertificate(
-		rand.Reader,
-		&certTemp,
-		&certTemp,
-		&caPrivKey.PublicKey,
-		caPrivKey,
-	)
-	if err != nil {
-		return err
-	}
-
-	t.Log.Info("Generating ca.crt/tls.crt for Stunnel")
-	caPEM := new(bytes.Buffer)
-	err = pem.Encode(caPEM, &pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: caBytes,
-	})
-	if err != nil {
-		return err
-	}
-
-	t.Log.Info("Generating tls.key for Stunnel")
-	caPrivKeyPEM := new(bytes.Buffer)
-	err = pem.Encode(caPrivKeyPEM, &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(caPrivKey),
-	})
+	certData, err := t.generateStunnelCerts()
 	if err != nil {
 		return err
 	}
@@ -378,11 +339,7 @@ func (t *Task) setupCerts() error {
 					"app": DirectVolumeMigrationRsyncTransfer,
 				},
 			},
-			Data: map[string][]byte{
-				"tls.crt": caPEM.Bytes(),
-				"ca.crt":  caPEM.Bytes(),
-				"tls.key": caPrivKeyPEM.Bytes(),
-			},
+			Data: certData,
 		}
 		destSecret := corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -392,11 +349,7 @@ func (t *Task) setupCerts() error {
 					"app": DirectVolumeMigrationRsyncTransfer,
 				},
 			},
-			Data: map[string][]byte{
-				"tls.crt": caPEM.Bytes(),
-				"ca.crt":  caPEM.Bytes(),
-				"tls.key": caPrivKeyPEM.Bytes(),
-			},
+			Data: certData,
 		}
 		t.Log.Info("Creating Stunnel CA Bundle and Cert/Key Secret on source cluster",
 			"secret", path.Join(srcSecret.Namespace, srcSecret.Name))
@@ -419,3 +372,69 @@ func (t *Task) setupCerts() error {
 	}
 	return nil
 }
+
+// generateStunnelCerts generates the CA bundle, certificate and key mounted by the Stunnel containers
+func (t *Task) generateStunnelCerts() (map[string][]byte, error) {
+	t.Log.Info("Generating CA Bundle for Stunnel")
+	caPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		return nil, err
+	}
+
+	subj := pkix.Name{
+		CommonName:         "openshift.io",
+		Country:            []string{"US"},
+		Province:           []string{"NC"},
+		Locality:           []string{"RDU"},
+		Organization:       []string{"Migration Engineering"},
+		OrganizationalUnit: []string{"Engineering"},
+	}
+
+	certTemp := x509.Certificate{
+		SerialNumber:          big.NewInt(2020),
+		Subject:               subj,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		IsCA:                  true,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	caBytes, err := x509.CreateCertificate(
+		rand.Reader,
+		&certTemp,
+		&certTemp,
+		&caPrivKey.PublicKey,
+		caPrivKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Log.Info("Generating ca.crt/tls.crt for Stunnel")
+	caPEM := new(bytes.Buffer)
+	err = pem.Encode(caPEM, &pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: caBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.Log.Info("Generating tls.key for Stunnel")
+	caPrivKeyPEM := new(bytes.Buffer)
+	err = pem.Encode(caPrivKeyPEM, &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(caPrivKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"tls.crt": caPEM.Bytes(),
+		"ca.crt":  caPEM.Bytes(),
+		"tls.key": caPrivKeyPEM.Bytes(),
+	}, nil
+}
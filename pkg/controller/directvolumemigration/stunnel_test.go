@@ -8,6 +8,7 @@ import (
 	"github.com/go-logr/logr"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -118,3 +119,55 @@ func TestTask_generateStunnelProxyConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestTask_getStunnelClientConfigName(t *testing.T) {
+	cluster := &corev1.ObjectReference{Name: "cluster-1", Namespace: "openshift-migration"}
+	otherCluster := &corev1.ObjectReference{Name: "cluster-2", Namespace: "openshift-migration"}
+	tests := []struct {
+		name       string
+		srcCluster *corev1.ObjectReference
+		destNs     string
+		wantConfig string
+		wantCreds  string
+	}{
+		{
+			name:       "same namespace of another cluster uses the shared names",
+			srcCluster: otherCluster,
+			destNs:     "ns-1",
+			wantConfig: DirectVolumeMigrationStunnelConfig,
+			wantCreds:  DirectVolumeMigrationRsyncCreds,
+		},
+		{
+			name:       "another namespace of the same cluster uses the shared names",
+			srcCluster: cluster,
+			destNs:     "ns-2",
+			wantConfig: DirectVolumeMigrationStunnelConfig,
+			wantCreds:  DirectVolumeMigrationRsyncCreds,
+		},
+		{
+			name:       "same namespace of the same cluster uses the client names",
+			srcCluster: cluster,
+			destNs:     "ns-1",
+			wantConfig: DirectVolumeMigrationStunnelClientConfig,
+			wantCreds:  DirectVolumeMigrationRsyncClientCreds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{
+						SrcMigClusterRef:  tt.srcCluster,
+						DestMigClusterRef: cluster,
+					},
+				},
+			}
+			if got := task.getStunnelClientConfigName("ns-1", tt.destNs); got != tt.wantConfig {
+				t.Errorf("getStunnelClientConfigName() = %v, want %v", got, tt.wantConfig)
+			}
+			if got := task.getRsyncClientCredsName("ns-1", tt.destNs); got != tt.wantCreds {
+				t.Errorf("getRsyncClientCredsName() = %v, want %v", got, tt.wantCreds)
+			}
+		})
+	}
+}
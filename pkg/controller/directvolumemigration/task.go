@@ -12,6 +12,7 @@ import (
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/compat"
 	"github.com/opentracing/opentracing-go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -32,12 +33,26 @@ const (
 	DestinationPVCsCreated               = "DestinationPVCsCreated"
 	CreateStunnelConfig                  = "CreateStunnelConfig"
 	CreateRsyncConfig                    = "CreateRsyncConfig"
+	CreateNetworkPolicies                = "CreateNetworkPolicies"
 	CreateRsyncRoute                     = "CreateRsyncRoute"
 	EnsureRsyncRouteAdmitted             = "EnsureRsyncRouteAdmitted"
 	CreateRsyncTransferPods              = "CreateRsyncTransferPods"
 	WaitForRsyncTransferPodsRunning      = "WaitForRsyncTransferPodsRunning"
+	CreateSourceSnapshots                = "CreateSourceSnapshots"
+	CreateSnapshotClones                 = "CreateSnapshotClones"
 	CreatePVProgressCRs                  = "CreatePVProgressCRs"
 	RunRsyncOperations                   = "RunRsyncOperations"
+	WaitForNextRsyncPass                 = "WaitForNextRsyncPass"
+	VerifyTransferredData                = "VerifyTransferredData"
+	RemapOwnership                       = "RemapOwnership"
+	CreatePreflightEndpoint              = "CreatePreflightEndpoint"
+	EnsurePreflightEndpointReady         = "EnsurePreflightEndpointReady"
+	CreatePreflightServer                = "CreatePreflightServer"
+	WaitForPreflightServerRunning        = "WaitForPreflightServerRunning"
+	CreatePreflightClients               = "CreatePreflightClients"
+	WaitForPreflightClientsCompleted     = "WaitForPreflightClientsCompleted"
+	DeletePreflightResources             = "DeletePreflightResources"
+	WaitForPreflightResourcesTerminated  = "WaitForPreflightResourcesTerminated"
 	DeleteRsyncResources                 = "DeleteRsyncResources"
 	WaitForRsyncResourcesTerminated      = "WaitForRsyncResourcesTerminated"
 	WaitForStaleRsyncResourcesTerminated = "WaitForStaleRsyncResourcesTerminated"
@@ -47,20 +62,28 @@ const (
 
 // labels
 const (
-	DirectVolumeMigration                   = "directvolumemigration"
-	DirectVolumeMigrationRsyncTransfer      = "directvolumemigration-rsync-transfer"
-	DirectVolumeMigrationRsyncConfig        = "directvolumemigration-rsync-config"
-	DirectVolumeMigrationRsyncCreds         = "directvolumemigration-rsync-creds"
-	DirectVolumeMigrationRsyncTransferSvc   = "directvolumemigration-rsync-transfer-svc"
-	DirectVolumeMigrationRsyncTransferRoute = "dvm"
-	DirectVolumeMigrationStunnelConfig      = "directvolumemigration-stunnel-config"
-	DirectVolumeMigrationStunnelCerts       = "directvolumemigration-stunnel-certs"
-	DirectVolumeMigrationRsyncPass          = "directvolumemigration-rsync-pass"
-	DirectVolumeMigrationStunnelTransfer    = "directvolumemigration-stunnel-transfer"
-	DirectVolumeMigrationRsync              = "rsync"
-	DirectVolumeMigrationRsyncClient        = "rsync-client"
-	DirectVolumeMigrationStunnel            = "stunnel"
-	MigratedByDirectVolumeMigration         = "migration.openshift.io/migrated-by-directvolumemigration" // (dvm UID)
+	DirectVolumeMigration                    = "directvolumemigration"
+	DirectVolumeMigrationRsyncTransfer       = "directvolumemigration-rsync-transfer"
+	DirectVolumeMigrationRsyncConfig         = "directvolumemigration-rsync-config"
+	DirectVolumeMigrationRsyncCreds          = "directvolumemigration-rsync-creds"
+	DirectVolumeMigrationRsyncClientCreds    = "directvolumemigration-rsync-client-creds"
+	DirectVolumeMigrationRsyncTransferSvc    = "directvolumemigration-rsync-transfer-svc"
+	DirectVolumeMigrationRsyncTransferRoute  = "dvm"
+	DirectVolumeMigrationStunnelConfig       = "directvolumemigration-stunnel-config"
+	DirectVolumeMigrationStunnelClientConfig = "directvolumemigration-stunnel-client-config"
+	DirectVolumeMigrationStunnelCerts        = "directvolumemigration-stunnel-certs"
+	DirectVolumeMigrationRsyncPass           = "directvolumemigration-rsync-pass"
+	DirectVolumeMigrationStunnelTransfer     = "directvolumemigration-stunnel-transfer"
+	DirectVolumeMigrationRsync               = "rsync"
+	DirectVolumeMigrationRsyncClient         = "rsync-client"
+	DirectVolumeMigrationStunnel             = "stunnel"
+	MigratedByDirectVolumeMigration          = "migration.openshift.io/migrated-by-directvolumemigration" // (dvm UID)
+	AdoptedByDirectVolumeMigration           = "migration.openshift.io/adopted-by-directvolumemigration"  // (dvm UID)
+	DirectVolumeMigrationPreflight           = "directvolumemigration-preflight"
+	DirectVolumeMigrationPreflightServer     = "directvolumemigration-preflight-server"
+	DirectVolumeMigrationPreflightClient     = "directvolumemigration-preflight-client"
+	DirectVolumeMigrationPreflightCerts      = "directvolumemigration-preflight-certs"
+	PreflightNodeAnnotation                  = "migration.openshift.io/preflight-node"
 )
 
 // Flags
@@ -115,10 +138,16 @@ var VolumeMigration = Itinerary{
 		{phase: EnsureRsyncRouteAdmitted},
 		{phase: CreateRsyncConfig},
 		{phase: CreateStunnelConfig},
+		{phase: CreateNetworkPolicies},
 		{phase: CreatePVProgressCRs},
 		{phase: CreateRsyncTransferPods},
 		{phase: WaitForRsyncTransferPodsRunning},
+		{phase: CreateSourceSnapshots},
+		{phase: CreateSnapshotClones},
 		{phase: RunRsyncOperations},
+		{phase: WaitForNextRsyncPass},
+		{phase: VerifyTransferredData},
+		{phase: RemapOwnership},
 		{phase: DeleteRsyncResources},
 		{phase: WaitForRsyncResourcesTerminated},
 		{phase: Completed},
@@ -133,6 +162,34 @@ var FailedItinerary = Itinerary{
 	},
 }
 
+var NetworkPreflight = Itinerary{
+	Name: "NetworkPreflight",
+	Steps: []Step{
+		{phase: Created},
+		{phase: Started},
+		{phase: Prepare},
+		{phase: CreatePreflightEndpoint},
+		{phase: EnsurePreflightEndpointReady},
+		{phase: CreatePreflightServer},
+		{phase: WaitForPreflightServerRunning},
+		{phase: CreatePreflightClients},
+		{phase: WaitForPreflightClientsCompleted},
+		{phase: DeletePreflightResources},
+		{phase: WaitForPreflightResourcesTerminated},
+		{phase: Completed},
+	},
+}
+
+var FailedPreflightItinerary = Itinerary{
+	Name: "NetworkPreflightFailed",
+	Steps: []Step{
+		{phase: MigrationFailed},
+		{phase: DeletePreflightResources},
+		{phase: WaitForPreflightResourcesTerminated},
+		{phase: Completed},
+	},
+}
+
 // A task that provides the complete migration workflow.
 // Log - A controller's logger.
 // Client - A controller's (local) client.
@@ -167,9 +224,14 @@ type sshKeys struct {
 func (t *Task) init() error {
 	t.RsyncRoutes = make(map[string]string)
 	t.Requeue = FastReQ
-	if t.failed() {
+	switch {
+	case t.failed() && t.Owner.Spec.Preflight:
+		t.Itinerary = FailedPreflightItinerary
+	case t.failed():
 		t.Itinerary = FailedItinerary
-	} else {
+	case t.Owner.Spec.Preflight:
+		t.Itinerary = NetworkPreflight
+	default:
 		t.Itinerary = VolumeMigration
 	}
 	if t.Itinerary.Name != t.Owner.Status.Itinerary {
@@ -241,11 +303,23 @@ func (t *Task) Run(ctx context.Context) error {
 		}
 	case CreateDestinationPVCs:
 		// Create the PVCs on the destination
-		err := t.createDestinationPVCs()
+		failureReasons, err := t.createDestinationPVCs()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
 		t.Requeue = NoReQ
+		if len(failureReasons) > 0 {
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     DestinationPVCAdoptionFailed,
+				Status:   True,
+				Reason:   Mismatch,
+				Category: Warn,
+				Message:  "One or more existing destination PVCs do not match the selection and cannot be adopted.",
+				Durable:  true,
+			})
+			t.fail(MigrationFailed, failureReasons)
+			return nil
+		}
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
@@ -260,7 +334,7 @@ func (t *Task) Run(ctx context.Context) error {
 			return liberr.Wrap(err)
 		}
 	case CreateRsyncRoute:
-		err := t.createRsyncTransferRoute()
+		err := t.createRsyncTransferEndpoints()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
@@ -269,7 +343,7 @@ func (t *Task) Run(ctx context.Context) error {
 			return liberr.Wrap(err)
 		}
 	case EnsureRsyncRouteAdmitted:
-		admitted, reasons, err := t.areRsyncRoutesAdmitted()
+		admitted, reasons, err := t.areRsyncTransferEndpointsReady()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
@@ -279,7 +353,7 @@ func (t *Task) Run(ctx context.Context) error {
 				return liberr.Wrap(err)
 			}
 		} else {
-			t.Log.Info("Some Rsync Transfer Routes have not yet been admitted. Waiting.")
+			t.Log.Info("Some Rsync Transfer endpoints are not yet ready. Waiting.")
 			t.Requeue = PollReQ
 			t.Owner.Status.StageCondition(Running)
 			cond := t.Owner.Status.FindCondition(Running)
@@ -287,7 +361,7 @@ func (t *Task) Run(ctx context.Context) error {
 				return fmt.Errorf("unable to find running condition")
 			}
 			now := time.Now().UTC()
-			msg := fmt.Sprintf("Rsync Transfer Routes have failed to be admitted within 3 minutes on "+
+			msg := fmt.Sprintf("Rsync Transfer endpoints have failed to become ready within 3 minutes on "+
 				"destination cluster. Errors: %v", reasons)
 			t.Log.Info(msg)
 			if now.Sub(cond.LastTransitionTime.Time.UTC()) > 3*time.Minute {
@@ -320,6 +394,14 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case CreateNetworkPolicies:
+		err := t.ensureNetworkPolicies()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case CreateRsyncTransferPods:
 		err := t.createRsyncTransferPods()
 		if err != nil {
@@ -347,6 +429,14 @@ func (t *Task) Run(ctx context.Context) error {
 			}
 		} else {
 			t.Requeue = PollReQ
+			for _, nonRunningPod := range nonRunningPods {
+				if message := getRsyncDevicesUnsupportedMessage(nonRunningPod); message != "" {
+					t.setRsyncDevicesUnsupported(message, "destination")
+					t.fail(MigrationFailed, []string{
+						"The Rsync transfer image of the destination cluster cannot migrate block volumes, look at error condition for more details"})
+					return nil
+				}
+			}
 			t.Owner.Status.StageCondition(Running)
 			cond := t.Owner.Status.FindCondition(Running)
 			if cond == nil {
@@ -379,6 +469,44 @@ func (t *Task) Run(ctx context.Context) error {
 				)
 			}
 		}
+	case CreateSourceSnapshots:
+		ready, reasons, err := t.ensureSourceSnapshots()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if ready {
+			t.Owner.Status.DeleteCondition(SourceSnapshotsNotReady)
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+			break
+		}
+		t.Requeue = PollReQ
+		if len(reasons) > 0 {
+			t.Owner.Status.SetCondition(
+				migapi.Condition{
+					Type:     SourceSnapshotsNotReady,
+					Status:   True,
+					Reason:   migapi.NotReady,
+					Category: Warn,
+					Message:  fmt.Sprintf("VolumeSnapshots of the source PVCs are not ready. Errors: %v", reasons),
+				},
+			)
+		}
+	case CreateSnapshotClones:
+		created, err := t.ensureSnapshotClones()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !created {
+			t.Requeue = PollReQ
+			break
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case RunRsyncOperations:
 		allCompleted, anyFailed, failureReasons, err := t.runRsyncOperations()
 		if err != nil {
@@ -391,10 +519,102 @@ func (t *Task) Run(ctx context.Context) error {
 				t.fail(MigrationFailed, failureReasons)
 				return nil
 			}
+			if t.Owner.Spec.Continuous {
+				now := metav1.Now()
+				t.Owner.Status.LastPassTimestamp = &now
+			}
 			if err = t.next(); err != nil {
 				return liberr.Wrap(err)
 			}
 		}
+	case WaitForNextRsyncPass:
+		if t.Owner.IsFinalPass() {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+			break
+		}
+		wait := t.getNextRsyncPassDelay()
+		if wait > 0 {
+			t.Log.Info("Waiting for the next continuous Rsync pass.", "wait", wait.Round(time.Second))
+			t.Requeue = wait
+			break
+		}
+		err := t.startRsyncPass()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		t.Phase = RunRsyncOperations
+		if t.hasSnapshotSources() {
+			// each pass copies a new snapshot of the source PVCs
+			t.Phase = CreateSourceSnapshots
+		}
+		t.PhaseDescription = phaseDescriptions[t.Phase]
+	case VerifyTransferredData:
+		allCompleted, failureReasons, warningReasons, err := t.verifyTransferredData()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !allCompleted {
+			t.Requeue = PollReQ
+			break
+		}
+		t.Requeue = NoReQ
+		if len(failureReasons) > 0 {
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     VolumeVerificationFailed,
+				Status:   True,
+				Reason:   Mismatch,
+				Category: Warn,
+				Message:  "The data of one or more destination volumes does not match the source volumes. See: volumeVerifications.",
+				Durable:  true,
+			})
+			t.fail(MigrationFailed, failureReasons)
+			return nil
+		}
+		// mismatches of source volumes in use during the transfer are tolerated
+		if len(warningReasons) > 0 {
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     VolumeVerificationFailed,
+				Status:   True,
+				Reason:   Mismatch,
+				Category: Warn,
+				Message: "The data of one or more destination volumes does not match the source volumes, which were" +
+					" in use during the transfer. Mismatches are tolerated by tolerateLiveSourceMismatches. See: volumeVerifications.",
+				Items:   warningReasons,
+				Durable: true,
+			})
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RemapOwnership:
+		allCompleted, failureReasons, err := t.remapOwnership()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !allCompleted {
+			t.Requeue = PollReQ
+			break
+		}
+		t.Requeue = NoReQ
+		if len(failureReasons) > 0 {
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     OwnershipRemapFailed,
+				Status:   True,
+				Reason:   Mismatch,
+				Category: Warn,
+				Message:  "The ownership of the files of one or more destination volumes could not be remapped. See: ownershipRemaps.",
+				Durable:  true,
+			})
+			t.fail(MigrationFailed, failureReasons)
+			return nil
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case CreatePVProgressCRs:
 		err := t.createPVProgressCR()
 		if err != nil {
@@ -426,6 +646,118 @@ func (t *Task) Run(ctx context.Context) error {
 		}
 		t.Log.Info("Stale Rsync resources are still terminating. Waiting.")
 		t.Requeue = PollReQ
+	case CreatePreflightEndpoint:
+		busy, err := t.createPreflightEndpoint()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if busy {
+			t.Log.Info("Another network preflight check is running on the destination cluster. Waiting.")
+			t.Requeue = PollReQ
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     NetworkPreflightPending,
+				Status:   True,
+				Reason:   NotReady,
+				Category: Warn,
+				Message:  "Waiting for another network preflight check to complete on the destination cluster.",
+			})
+			break
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case EnsurePreflightEndpointReady:
+		ready, reasons, err := t.isPreflightEndpointReady()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if ready {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+			break
+		}
+		t.Requeue = PollReQ
+		if t.isPreflightTimedOut() {
+			t.fail(MigrationFailed, []string{
+				fmt.Sprintf("The transfer endpoint of the network preflight check failed to become ready "+
+					"within %v on the destination cluster. Errors: %v", PreflightTimeout, reasons)})
+		}
+	case CreatePreflightServer:
+		err := t.createPreflightServer()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case WaitForPreflightServerRunning:
+		running, err := t.isPreflightServerRunning()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if running {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+			break
+		}
+		t.Requeue = PollReQ
+		if t.isPreflightTimedOut() {
+			t.fail(MigrationFailed, []string{
+				fmt.Sprintf("The Stunnel server pod of the network preflight check has not started running "+
+					"within %v. Run this command to check Pod warning events: oc describe pod %s -n %s",
+					PreflightTimeout, DirectVolumeMigrationPreflightServer, migapi.OpenshiftMigrationNamespace)})
+		}
+	case CreatePreflightClients:
+		err := t.createPreflightClients()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case WaitForPreflightClientsCompleted:
+		completed, err := t.collectPreflightResults()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !completed {
+			t.Requeue = PollReQ
+			break
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case DeletePreflightResources:
+		err := t.deletePreflightResources()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case WaitForPreflightResourcesTerminated:
+		deleted, err := t.arePreflightResourcesDeleted()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !deleted {
+			t.Log.Info("Network preflight resources are still terminating. Waiting.")
+			t.Requeue = PollReQ
+			break
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case Completed:
 	default:
 		t.Requeue = NoReQ
@@ -0,0 +1,78 @@
+package directvolumemigration
+
+import (
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// getTransferPodPolicy returns the policy of the Rsync Pods set on the DVM,
+// falls back to the policy of the MigPlan
+func (t *Task) getTransferPodPolicy() *migapi.TransferPodPolicy {
+	if t.Owner.Spec.TransferPodPolicy != nil {
+		return t.Owner.Spec.TransferPodPolicy
+	}
+	if t.PlanResources != nil && t.PlanResources.MigPlan != nil {
+		return t.PlanResources.MigPlan.Spec.TransferPodPolicy
+	}
+	return nil
+}
+
+// applyTransferPodPolicy applies the policy to the spec of an Rsync Pod.
+// Pods pinned to a node with nodeName bypass the scheduler, nodeSelector
+// and affinity are only applied to the Pods going through the scheduler.
+func applyTransferPodPolicy(spec *corev1.PodSpec, policy *migapi.TransferPodPolicy) {
+	if policy == nil {
+		return
+	}
+	if spec.NodeName == "" {
+		if len(policy.NodeSelector) > 0 {
+			spec.NodeSelector = map[string]string{}
+			for key, value := range policy.NodeSelector {
+				spec.NodeSelector[key] = value
+			}
+		}
+		if policy.Affinity != nil {
+			spec.Affinity = policy.Affinity.DeepCopy()
+		}
+	}
+	for _, toleration := range policy.Tolerations {
+		spec.Tolerations = append(spec.Tolerations, *toleration.DeepCopy())
+	}
+	if policy.PriorityClassName != "" {
+		spec.PriorityClassName = policy.PriorityClassName
+	}
+	spec.ImagePullSecrets = append(spec.ImagePullSecrets, policy.ImagePullSecrets...)
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if container.Name == DirectVolumeMigrationStunnel {
+			mergeResourceRequirements(&container.Resources, policy.StunnelResources)
+		} else {
+			mergeResourceRequirements(&container.Resources, policy.RsyncResources)
+		}
+	}
+}
+
+// mergeResourceRequirements overrides the limits and requests set in the policy
+func mergeResourceRequirements(resources *corev1.ResourceRequirements, override *corev1.ResourceRequirements) {
+	if override == nil {
+		return
+	}
+	resources.Limits = mergeResourceList(resources.Limits, override.Limits)
+	resources.Requests = mergeResourceList(resources.Requests, override.Requests)
+}
+
+// mergeResourceList returns a copy of the list with the quantities of the override,
+// the list may be shared by several Pods and is never modified
+func mergeResourceList(list corev1.ResourceList, override corev1.ResourceList) corev1.ResourceList {
+	if len(override) == 0 {
+		return list
+	}
+	merged := corev1.ResourceList{}
+	for name, quantity := range list {
+		merged[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range override {
+		merged[name] = quantity.DeepCopy()
+	}
+	return merged
+}
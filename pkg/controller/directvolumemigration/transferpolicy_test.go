@@ -0,0 +1,151 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_applyTransferPodPolicy(t *testing.T) {
+	policy := &migapi.TransferPodPolicy{
+		NodeSelector: map[string]string{"node-role.kubernetes.io/migration": ""},
+		Tolerations: []corev1.Toleration{
+			{Key: "migration", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		},
+		Affinity: &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		PriorityClassName: "migration-high",
+		RsyncResources: &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+		},
+		StunnelResources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+		},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-creds"}},
+	}
+	tests := []struct {
+		name             string
+		nodeName         string
+		policy           *migapi.TransferPodPolicy
+		wantNodeSelector map[string]string
+		wantAffinity     bool
+		wantPriority     string
+		wantRsyncCPU     string
+	}{
+		{
+			name:         "no policy",
+			policy:       nil,
+			wantRsyncCPU: "1",
+		},
+		{
+			name:             "pod going through the scheduler",
+			policy:           policy,
+			wantNodeSelector: policy.NodeSelector,
+			wantAffinity:     true,
+			wantPriority:     "migration-high",
+			wantRsyncCPU:     "4",
+		},
+		{
+			name:         "pod pinned to the node of the source volume",
+			nodeName:     "node-1",
+			policy:       policy,
+			wantPriority: "migration-high",
+			wantRsyncCPU: "4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sharedLimits := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+			spec := corev1.PodSpec{
+				NodeName: tt.nodeName,
+				Containers: []corev1.Container{
+					{Name: DirectVolumeMigrationRsyncClient, Resources: corev1.ResourceRequirements{Limits: sharedLimits}},
+					{Name: DirectVolumeMigrationStunnel},
+				},
+			}
+			applyTransferPodPolicy(&spec, tt.policy)
+			if !reflect.DeepEqual(spec.NodeSelector, tt.wantNodeSelector) {
+				t.Errorf("applyTransferPodPolicy() nodeSelector = %v, want %v", spec.NodeSelector, tt.wantNodeSelector)
+			}
+			if (spec.Affinity != nil) != tt.wantAffinity {
+				t.Errorf("applyTransferPodPolicy() affinity = %v, want %v", spec.Affinity, tt.wantAffinity)
+			}
+			if spec.PriorityClassName != tt.wantPriority {
+				t.Errorf("applyTransferPodPolicy() priorityClassName = %s, want %s", spec.PriorityClassName, tt.wantPriority)
+			}
+			cpu := spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+			if cpu.Cmp(resource.MustParse(tt.wantRsyncCPU)) != 0 {
+				t.Errorf("applyTransferPodPolicy() rsync cpu limit = %s, want %s", cpu.String(), tt.wantRsyncCPU)
+			}
+			// The resource lists shared by all Rsync Pods are never modified
+			sharedCPU := sharedLimits[corev1.ResourceCPU]
+			if sharedCPU.Cmp(resource.MustParse("1")) != 0 {
+				t.Errorf("applyTransferPodPolicy() modified the shared limits: %s", sharedCPU.String())
+			}
+			if tt.policy == nil {
+				return
+			}
+			if len(spec.Tolerations) != 1 || len(spec.ImagePullSecrets) != 1 {
+				t.Errorf("applyTransferPodPolicy() tolerations = %v, imagePullSecrets = %v", spec.Tolerations, spec.ImagePullSecrets)
+			}
+			memory := spec.Containers[1].Resources.Requests[corev1.ResourceMemory]
+			if memory.Cmp(resource.MustParse("128Mi")) != 0 {
+				t.Errorf("applyTransferPodPolicy() stunnel memory request = %s", memory.String())
+			}
+		})
+	}
+}
+
+func TestTask_getTransferPodPolicy(t *testing.T) {
+	planPolicy := &migapi.TransferPodPolicy{PriorityClassName: "plan"}
+	dvmPolicy := &migapi.TransferPodPolicy{PriorityClassName: "dvm"}
+	tests := []struct {
+		name      string
+		dvmPolicy *migapi.TransferPodPolicy
+		plan      *migapi.MigPlan
+		want      *migapi.TransferPodPolicy
+	}{
+		{
+			name: "no policy",
+			want: nil,
+		},
+		{
+			name: "policy of the plan",
+			plan: &migapi.MigPlan{Spec: migapi.MigPlanSpec{TransferPodPolicy: planPolicy}},
+			want: planPolicy,
+		},
+		{
+			name:      "policy of the DVM overrides the plan",
+			dvmPolicy: dvmPolicy,
+			plan:      &migapi.MigPlan{Spec: migapi.MigPlanSpec{TransferPodPolicy: planPolicy}},
+			want:      dvmPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{TransferPodPolicy: tt.dvmPolicy},
+				},
+				PlanResources: &migapi.PlanResources{MigPlan: tt.plan},
+			}
+			if got := task.getTransferPodPolicy(); got != tt.want {
+				t.Errorf("getTransferPodPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
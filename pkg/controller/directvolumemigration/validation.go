@@ -2,6 +2,8 @@ package directvolumemigration
 
 import (
 	"context"
+	"fmt"
+	"path"
 	"reflect"
 
 	liberr "github.com/konveyor/controller/pkg/error"
@@ -33,6 +35,15 @@ const (
 	SourceToDestinationNetworkError = "SourceToDestinationNetworkError"
 	FailedCreatingRsyncPods         = "FailedCreatingRsyncPods"
 	FailedDeletingRsyncPods         = "FailedDeletingRsyncPods"
+	VolumeVerificationFailed        = "VolumeVerificationFailed"
+	SourceSnapshotsNotReady         = "SourceSnapshotsNotReady"
+	InvalidRsyncFilters             = "InvalidRsyncFilters"
+	DestinationPVCAdoptionFailed    = "DestinationPVCAdoptionFailed"
+	NetworkPreflightPending         = "NetworkPreflightPending"
+	InvalidBandwidthSchedule        = "InvalidBandwidthSchedule"
+	InvalidOwnershipPolicy          = "InvalidOwnershipPolicy"
+	OwnershipRemapFailed            = "OwnershipRemapFailed"
+	RsyncDevicesUnsupported         = "RsyncDevicesUnsupported"
 )
 
 // Reasons
@@ -43,6 +54,8 @@ const (
 	NotReady           = "NotReady"
 	RsyncTimeout       = "RsyncTimedOut"
 	RsyncNoRouteToHost = "RsyncNoRouteToHost"
+	Mismatch           = "Mismatch"
+	NotSupported       = "NotSupported"
 )
 
 // Messages
@@ -57,8 +70,11 @@ const (
 	SourceClusterNotReadyMessage              = "The source cluster is not ready"
 	DestinationClusterNotReadyMessage         = "The destination cluster is not ready"
 	PVCsNotFoundOnSourceClusterMessage        = "The set of pvcs were not found on source cluster"
+	InvalidRsyncFiltersMessage                = "The Rsync include or exclude patterns of the pvcs [] are invalid"
 	SucceededMessage                          = "The migration has succeeded"
 	FailedMessage                             = "The migration has failed.  See: Errors."
+	InvalidBandwidthScheduleMessage           = "The bandwidth schedule is invalid: %s"
+	InvalidOwnershipPolicyMessage             = "The ownership policy must be either Preserve or Remap"
 )
 
 // Categories
@@ -93,9 +109,41 @@ func (r ReconcileDirectVolumeMigration) validate(ctx context.Context, direct *mi
 	if err != nil {
 		return liberr.Wrap(err)
 	}
+	r.validateBandwidthSchedule(direct)
+	r.validateOwnershipPolicy(direct)
 	return nil
 }
 
+// Validate the time windows limiting the bandwidth of the Rsync Pods
+func (r ReconcileDirectVolumeMigration) validateBandwidthSchedule(direct *migapi.DirectVolumeMigration) {
+	err := direct.Spec.BandwidthSchedule.Validate()
+	if err == nil {
+		return
+	}
+	direct.Status.SetCondition(migapi.Condition{
+		Type:     InvalidBandwidthSchedule,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message:  fmt.Sprintf(InvalidBandwidthScheduleMessage, err.Error()),
+	})
+}
+
+// Validate the policy keeping the file ownership writable on the destination
+func (r ReconcileDirectVolumeMigration) validateOwnershipPolicy(direct *migapi.DirectVolumeMigration) {
+	switch direct.Spec.OwnershipPolicy {
+	case "", migapi.PreserveOwnershipPolicy, migapi.RemapOwnershipPolicy:
+		return
+	}
+	direct.Status.SetCondition(migapi.Condition{
+		Type:     InvalidOwnershipPolicy,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message:  InvalidOwnershipPolicyMessage,
+	})
+}
+
 func (r ReconcileDirectVolumeMigration) validateSrcCluster(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
 	if opentracing.SpanFromContext(ctx) != nil {
 		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateSrcCluster")
@@ -233,6 +281,23 @@ func (r ReconcileDirectVolumeMigration) validatePVCs(ctx context.Context, direct
 		})
 		return nil
 	}
+	// Check the include and exclude patterns of the PVCs
+	invalidFilters := make([]string, 0)
+	for _, specPVC := range allPVCs {
+		if err := specPVC.Filters.Validate(); err != nil {
+			invalidFilters = append(invalidFilters, path.Join(specPVC.Namespace, specPVC.Name))
+		}
+	}
+	if len(invalidFilters) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncFilters,
+			Status:   True,
+			Category: Critical,
+			Message:  InvalidRsyncFiltersMessage,
+			Items:    invalidFilters,
+		})
+		return nil
+	}
 	// Get source cluster client
 	cluster, err := direct.GetSourceCluster(r)
 	if err != nil {
@@ -0,0 +1,556 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/pods"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DirectVolumeMigrationVerification name of the verification container and purpose of the verification Pods
+	DirectVolumeMigrationVerification = "verification"
+	// DirectVolumeMigrationVerificationMountPath path at which the PVC is attached in the verification Pods
+	DirectVolumeMigrationVerificationMountPath = "/mnt/volume"
+	// DirectVolumeMigrationVerificationDataPath path of the scratch volume holding the manifests in the verification Pods
+	DirectVolumeMigrationVerificationDataPath = "/verification"
+)
+
+// verificationScript writes a manifest of the checksums of the files of a volume into the scratch
+// volume, prints its summary and keeps the Pod running until deleted so that the manifests can be
+// compared. When the volume has more than SAMPLE_SIZE files, only the files whose path hashes to 0
+// modulo the interval are checksummed. The interval computed on the source volume is passed as
+// INTERVAL to the destination so that both sides select the same files regardless of the files
+// added or deleted on either side. With RSYNC_FILTERS, the files are listed by a dry run of Rsync
+// so that the files left out of the transfer are left out of the manifests too.
+const verificationScript = `set -o pipefail
+cd ` + DirectVolumeMigrationVerificationMountPath + ` || exit 1
+data=` + DirectVolumeMigrationVerificationDataPath + `
+mkdir -p $data/empty || exit 1
+if [ "$BLOCK" = "true" ]; then
+  bytes=$(blockdev --getsize64 ./` + DirectVolumeMigrationBlockDevice + `) || exit 1
+  sha256sum ./` + DirectVolumeMigrationBlockDevice + ` > $data/manifest || exit 1
+  count=1
+  interval=1
+else
+  if [ -n "$RSYNC_FILTERS" ]; then
+    eval "rsync -r --dry-run --out-format='%l %n' $RSYNC_FILTERS ./ $data/empty/" |
+      awk 'substr($0, length($0)) != "/"' | tr '\n' '\0' > $data/files || exit 1
+  else
+    find . -type f -printf '%s %P\0' > $data/files || exit 1
+  fi
+  count=$(tr -dc '\000' < $data/files | wc -c) || exit 1
+  bytes=$(LC_ALL=C awk -v RS='\0' '{s+=$1} END {printf "%d", s}' $data/files) || exit 1
+  interval=${INTERVAL:-0}
+  if [ "$interval" -le 0 ]; then
+    interval=1
+    if [ "$SAMPLE_SIZE" -gt 0 ] && [ "$count" -gt "$SAMPLE_SIZE" ]; then
+      interval=$(( (count + SAMPLE_SIZE - 1) / SAMPLE_SIZE ))
+    fi
+  fi
+  LC_ALL=C awk -v RS='\0' -v ORS='\0' -v n="$interval" '
+BEGIN { for (i = 1; i < 256; i++) ord[sprintf("%c", i)] = i }
+{ p = substr($0, index($0, " ") + 1); h = 0; for (i = 1; i <= length(p); i++) h = (h * 31 + ord[substr(p, i, 1)]) % 1000000007 }
+h % n == 0 { print p }' $data/files | xargs -0 -r sha256sum -- > $data/manifest || exit 1
+fi
+echo "# files=$count bytes=$bytes interval=$interval"
+echo "# ready"
+trap 'exit 0' TERM
+while true; do sleep 1; done
+`
+
+// verificationCompareScript runs in the destination verification Pod, compares the source manifest
+// read from the standard input with the destination manifest and prints the number of mismatched
+// files followed by the first mismatched paths
+var verificationCompareScript = `set -o pipefail
+data=` + DirectVolumeMigrationVerificationDataPath + `
+cat > $data/source || exit 1
+LC_ALL=C awk '
+{ line = $0; sub(/^\\/, "", line); sum = substr(line, 1, 64); p = substr(line, 67) }
+FILENAME == ARGV[1] { src[p] = sum; next }
+!(p in src) || src[p] != sum { print p }
+{ delete src[p] }
+END { for (p in src) print p }' $data/source $data/manifest | LC_ALL=C sort > $data/mismatched || exit 1
+echo "# mismatched=$(wc -l < $data/mismatched)"
+head -n ` + strconv.Itoa(migapi.MaxVerificationMismatchedPaths) + ` $data/mismatched
+`
+
+// matches the summary printed by a verification Pod once its manifest is written
+var verificationHeaderRegex = regexp.MustCompile(`(?m)^# files=(\d+) bytes=(\d+) interval=(\d+)$`)
+
+// matches the header of the output of the comparison of the manifests
+var verificationMismatchedRegex = regexp.MustCompile(`^# mismatched=\s*(\d+)$`)
+
+// verificationSummary counts of the files of a volume and sampling of its manifest
+type verificationSummary struct {
+	files    int64
+	bytes    int64
+	interval int
+}
+
+// verificationComparison result of the comparison of the manifests of a volume
+type verificationComparison struct {
+	mismatched int64
+	paths      []string
+}
+
+// verifyTransferredData runs a checksum pass over the source and destination of the PVCs to verify
+// and records the results in the status, returns whether all verifications completed, the reasons
+// of the failed ones failing the migration and the reasons of those only reported as a warning
+// as mismatches of sources in use are tolerated
+func (t *Task) verifyTransferredData() (bool, []string, []string, error) {
+	reasons := []string{}
+	warnings := []string{}
+	pvcs := t.getPVCsToVerify()
+	if len(pvcs) == 0 {
+		return true, reasons, warnings, nil
+	}
+	srcCluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return false, reasons, warnings, liberr.Wrap(err)
+	}
+	destCluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return false, reasons, warnings, liberr.Wrap(err)
+	}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, reasons, warnings, liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, reasons, warnings, liberr.Wrap(err)
+	}
+	image, err := srcCluster.GetRsyncTransferImage(t.Client)
+	if err != nil {
+		return false, reasons, warnings, liberr.Wrap(err)
+	}
+	allCompleted := true
+	for i := range pvcs {
+		pvc := pvcs[i]
+		verification := t.Owner.Status.GetVolumeVerificationForPVC(pvc.ObjectReference)
+		if !verification.IsComplete() {
+			completed, err := t.runVolumeVerification(srcCluster, destCluster, srcClient, destClient, image, pvc, verification)
+			if err != nil {
+				return false, reasons, warnings, liberr.Wrap(err)
+			}
+			if !completed {
+				allCompleted = false
+				continue
+			}
+		}
+		if !verification.Failed {
+			continue
+		}
+		reason := fmt.Sprintf("Verification of PVC %s failed: %s",
+			path.Join(pvc.Namespace, pvc.Name), verification.Message)
+		if verification.HasMismatch() && t.Owner.IsVerificationMismatchTolerated(pvc) {
+			verification.MismatchTolerated = true
+			warnings = append(warnings, reason)
+			continue
+		}
+		operation := t.Owner.Status.GetRsyncOperationStatusForPVC(pvc.ObjectReference)
+		operation.Succeeded = false
+		operation.Failed = true
+		reasons = append(reasons, reason)
+	}
+	return allCompleted, reasons, warnings, nil
+}
+
+// getPVCsToVerify returns the PVCs for which verification is requested
+func (t *Task) getPVCsToVerify() []migapi.PVCToMigrate {
+	pvcs := []migapi.PVCToMigrate{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.Verify {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	return pvcs
+}
+
+// runVolumeVerification ensures the verification Pods of a PVC are created on both clusters,
+// once both manifests are written, compares them and records the result
+func (t *Task) runVolumeVerification(srcCluster, destCluster *migapi.MigCluster, srcClient, destClient compat.Client,
+	image string, pvc migapi.PVCToMigrate, verification *migapi.VolumeVerification) (bool, error) {
+	destNs := pvc.Namespace
+	if pvc.TargetNamespace != "" {
+		destNs = pvc.TargetNamespace
+	}
+	srcNode, err := t.getSourceVerificationNode(srcClient, pvc)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	// PVCs copied from a snapshot are compared with the clone that was copied
+	srcPod, err := t.ensureVerificationPod(srcClient, pvc, getSourceClaimName(pvc), pvc.Namespace, srcNode, image, 0)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	srcSummary, failed, err := t.getVerificationSummary(srcCluster, srcPod)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if failed {
+		t.completeVolumeVerification(verification, false,
+			fmt.Sprintf("verification Pod %s failed", path.Join(srcPod.Namespace, srcPod.Name)))
+		return true, nil
+	}
+	if srcSummary == nil {
+		return false, nil
+	}
+	// the destination samples the files selected by the interval of the source
+	destNode, err := getDestinationVerificationNode(destClient, destNs)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destPod, err := t.ensureVerificationPod(destClient, pvc, pvc.GetTargetName(), destNs, destNode, image, srcSummary.interval)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	destSummary, failed, err := t.getVerificationSummary(destCluster, destPod)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if failed {
+		t.completeVolumeVerification(verification, false,
+			fmt.Sprintf("verification Pod %s failed", path.Join(destPod.Namespace, destPod.Name)))
+		return true, nil
+	}
+	if destSummary == nil {
+		return false, nil
+	}
+	if destSummary.interval != srcSummary.interval {
+		t.completeVolumeVerification(verification, false,
+			fmt.Sprintf("the destination volume was sampled with interval %d, the source volume with interval %d",
+				destSummary.interval, srcSummary.interval))
+		return true, nil
+	}
+	comparison, err := t.compareVerificationManifests(srcCluster, destCluster, srcPod, destPod)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	setVerificationResult(srcSummary, destSummary, comparison, verification)
+	if verification.MismatchedFileCount > 0 {
+		t.completeVolumeVerification(verification, false,
+			fmt.Sprintf("%d files do not match the source volume", verification.MismatchedFileCount))
+	} else if srcSummary.bytes != destSummary.bytes {
+		t.completeVolumeVerification(verification, false,
+			fmt.Sprintf("the destination volume holds %d bytes, the source volume holds %d bytes",
+				destSummary.bytes, srcSummary.bytes))
+	} else {
+		t.completeVolumeVerification(verification, true, "")
+	}
+	t.Log.Info("Completed verification of PVC.",
+		"persistentVolumeClaim", path.Join(pvc.Namespace, pvc.Name),
+		"succeeded", verification.Succeeded,
+		"fileCount", verification.FileCount,
+		"mismatchedFileCount", verification.MismatchedFileCount)
+	return true, nil
+}
+
+// getVerificationSummary returns the summary printed by a verification Pod once its manifest
+// is written, nil while the checksums are computed, and whether the Pod failed. The Pods keep
+// running until deleted as their manifest is lost once they exit.
+func (t *Task) getVerificationSummary(cluster *migapi.MigCluster, pod *corev1.Pod) (*verificationSummary, bool, error) {
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+	case corev1.PodSucceeded, corev1.PodFailed:
+		return nil, true, nil
+	default:
+		t.Log.Info("Waiting for verification Pod to start.",
+			"pod", path.Join(pod.Namespace, pod.Name),
+			"podPhase", pod.Status.Phase)
+		return nil, false, nil
+	}
+	tailLines := int64(10)
+	podLog, err := t.getPodLog(cluster, pod, DirectVolumeMigrationVerification, &tailLines)
+	if err != nil {
+		return nil, false, liberr.Wrap(err)
+	}
+	summary := parseVerificationSummary(podLog)
+	if summary == nil {
+		t.Log.Info("Waiting for verification Pod to write the manifest.",
+			"pod", path.Join(pod.Namespace, pod.Name))
+	}
+	return summary, false, nil
+}
+
+// compareVerificationManifests streams the manifest of the source Pod into the destination Pod,
+// which compares it with its own manifest. Only the sampled files are listed in the manifests.
+func (t *Task) compareVerificationManifests(srcCluster, destCluster *migapi.MigCluster,
+	srcPod, destPod *corev1.Pod) (*verificationComparison, error) {
+	srcConfig, err := srcCluster.BuildRestConfig(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	destConfig, err := destCluster.BuildRestConfig(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	read := pods.PodCommand{
+		RestCfg: srcConfig,
+		Pod:     srcPod,
+		Args:    []string{"cat", path.Join(DirectVolumeMigrationVerificationDataPath, "manifest")},
+	}
+	err = read.Run()
+	if err != nil {
+		return nil, liberr.Wrap(fmt.Errorf("reading the manifest of Pod %s failed: %v %s",
+			path.Join(srcPod.Namespace, srcPod.Name), err, read.Err.String()))
+	}
+	compare := pods.PodCommand{
+		RestCfg: destConfig,
+		Pod:     destPod,
+		Args:    []string{"/bin/bash", "-c", verificationCompareScript},
+		In:      &read.Out,
+	}
+	err = compare.Run()
+	if err != nil {
+		return nil, liberr.Wrap(fmt.Errorf("comparing the manifests in Pod %s failed: %v %s",
+			path.Join(destPod.Namespace, destPod.Name), err, compare.Err.String()))
+	}
+	comparison := parseVerificationComparison(compare.Out.String())
+	if comparison == nil {
+		return nil, liberr.Wrap(fmt.Errorf("invalid output of the comparison of the manifests in Pod %s: %s",
+			path.Join(destPod.Namespace, destPod.Name), compare.Out.String()))
+	}
+	return comparison, nil
+}
+
+// completeVolumeVerification records the outcome of a verification
+func (t *Task) completeVolumeVerification(verification *migapi.VolumeVerification, succeeded bool, message string) {
+	now := metav1.Now()
+	verification.Succeeded = succeeded
+	verification.Failed = !succeeded
+	verification.Message = message
+	verification.CompletionTimestamp = &now
+}
+
+// getSourceVerificationNode returns the node of the last Rsync client Pod of the PVC
+// so that RWO volumes can be attached to the verification Pod
+func (t *Task) getSourceVerificationNode(client compat.Client, pvc migapi.PVCToMigrate) (string, error) {
+	operation := t.Owner.Status.GetRsyncOperationStatusForPVC(pvc.ObjectReference)
+	pod, err := t.getLatestPodForOperation(client, *operation)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	if pod == nil {
+		return "", nil
+	}
+	return pod.Spec.NodeName, nil
+}
+
+// getDestinationVerificationNode returns the node of the Rsync transfer Pod of the namespace
+// so that RWO volumes can be attached to the verification Pod
+func getDestinationVerificationNode(client compat.Client, namespace string) (string, error) {
+	pod := corev1.Pod{}
+	key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransfer, Namespace: namespace}
+	err := client.Get(context.TODO(), key, &pod)
+	if k8serror.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return pod.Spec.NodeName, nil
+}
+
+// getVerificationPodName returns the name of the verification Pod of a claim, both Pods
+// are named after their own claim as they share the namespace in a storage conversion
+func getVerificationPodName(claimName string) string {
+	return fmt.Sprintf("dvm-verify-%s", getMD5Hash(claimName))
+}
+
+// ensureVerificationPod creates the verification Pod of a PVC in the namespace if it doesn't exist,
+// the Pod samples files with the interval, computed from the sample size when 0
+func (t *Task) ensureVerificationPod(client compat.Client, pvc migapi.PVCToMigrate, claimName string,
+	namespace string, nodeName string, image string, interval int) (*corev1.Pod, error) {
+	pod := corev1.Pod{}
+	key := types.NamespacedName{Name: getVerificationPodName(claimName), Namespace: namespace}
+	err := client.Get(context.TODO(), key, &pod)
+	if err == nil {
+		return &pod, nil
+	}
+	if !k8serror.IsNotFound(err) {
+		return nil, liberr.Wrap(err)
+	}
+	isPrivileged, err := isRsyncPrivileged(client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	newPod := t.buildVerificationPod(pvc, claimName, namespace, nodeName, image, interval, isPrivileged)
+	t.Log.Info("Creating verification Pod.",
+		"pod", path.Join(newPod.Namespace, newPod.Name),
+		"nodeName", nodeName)
+	err = client.Create(context.TODO(), newPod)
+	if err != nil && !k8serror.IsAlreadyExists(err) {
+		return nil, liberr.Wrap(err)
+	}
+	return newPod, nil
+}
+
+// buildVerificationPod builds a Pod attaching the claim of the PVC read-only and writing the manifest of its files
+func (t *Task) buildVerificationPod(pvc migapi.PVCToMigrate, claimName string, namespace string,
+	nodeName string, image string, interval int, isPrivileged bool) *corev1.Pod {
+	runAsUser := int64(0)
+	volumeName := getMD5Hash(pvc.Name)
+	labels := t.Owner.GetCorrelationLabels()
+	labels["app"] = DirectVolumeMigrationRsyncTransfer
+	labels["purpose"] = DirectVolumeMigrationVerification
+	container := corev1.Container{
+		Name:    DirectVolumeMigrationVerification,
+		Image:   image,
+		Command: []string{"/bin/bash", "-c", verificationScript},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "SAMPLE_SIZE",
+				Value: strconv.Itoa(t.Owner.GetVerificationSampleSize()),
+			},
+			{
+				Name:  "INTERVAL",
+				Value: strconv.Itoa(interval),
+			},
+			{
+				Name:  "BLOCK",
+				Value: strconv.FormatBool(pvc.IsBlock()),
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      DirectVolumeMigrationVerification,
+				MountPath: DirectVolumeMigrationVerificationDataPath,
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &isPrivileged,
+			RunAsUser:  &runAsUser,
+		},
+	}
+	if isVerificationFiltered(pvc) {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "RSYNC_FILTERS",
+			Value: strings.Join(t.getRsyncFilterOptions(pvc.Filters), " "),
+		})
+	}
+	if pvc.IsBlock() {
+		container.VolumeDevices = []corev1.VolumeDevice{
+			{
+				Name:       volumeName,
+				DevicePath: path.Join(DirectVolumeMigrationVerificationMountPath, DirectVolumeMigrationBlockDevice),
+			},
+		}
+	} else {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: DirectVolumeMigrationVerificationMountPath,
+			ReadOnly:  true,
+		})
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getVerificationPodName(claimName),
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			NodeName:      nodeName,
+			Containers:    []corev1.Container{container},
+			Volumes: []corev1.Volume{
+				{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claimName,
+							ReadOnly:  true,
+						},
+					},
+				},
+				{
+					Name: DirectVolumeMigrationVerification,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// parseVerificationSummary parses the summary printed by the verification script,
+// returns nil until the manifest is written
+func parseVerificationSummary(log string) *verificationSummary {
+	matches := verificationHeaderRegex.FindStringSubmatch(log)
+	if matches == nil || !strings.Contains(log, "\n# ready") {
+		return nil
+	}
+	files, _ := strconv.ParseInt(matches[1], 10, 64)
+	bytes, _ := strconv.ParseInt(matches[2], 10, 64)
+	interval, _ := strconv.Atoi(matches[3])
+	return &verificationSummary{
+		files:    files,
+		bytes:    bytes,
+		interval: interval,
+	}
+}
+
+// parseVerificationComparison parses the output of the comparison script,
+// returns nil when the header is missing
+func parseVerificationComparison(output string) *verificationComparison {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	matches := verificationMismatchedRegex.FindStringSubmatch(lines[0])
+	if matches == nil {
+		return nil
+	}
+	mismatched, _ := strconv.ParseInt(matches[1], 10, 64)
+	paths := []string{}
+	for _, line := range lines[1:] {
+		if line != "" {
+			paths = append(paths, strings.TrimPrefix(line, "./"))
+		}
+	}
+	return &verificationComparison{
+		mismatched: mismatched,
+		paths:      paths,
+	}
+}
+
+// isVerificationFiltered returns whether the Rsync filters of the PVC apply to its verification
+func isVerificationFiltered(pvc migapi.PVCToMigrate) bool {
+	return !pvc.IsBlock() && !pvc.Filters.IsEmpty()
+}
+
+// setVerificationResult records the counts and the mismatched paths of the verification
+func setVerificationResult(src, dest *verificationSummary, comparison *verificationComparison,
+	verification *migapi.VolumeVerification) {
+	count := comparison.mismatched
+	// sampled manifests may not cover files that only exist on one side
+	diff := src.files - dest.files
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > count {
+		count = diff
+	}
+	paths := comparison.paths
+	if len(paths) > migapi.MaxVerificationMismatchedPaths {
+		paths = paths[:migapi.MaxVerificationMismatchedPaths]
+	}
+	verification.SampleInterval = src.interval
+	verification.FileCount = src.files
+	verification.ByteCount = src.bytes
+	verification.DestinationFileCount = dest.files
+	verification.DestinationByteCount = dest.bytes
+	verification.MismatchedFileCount = count
+	verification.MismatchedPaths = paths
+}
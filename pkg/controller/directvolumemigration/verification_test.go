@@ -0,0 +1,199 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_parseVerificationSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want *verificationSummary
+	}{
+		{
+			name: "manifest written",
+			log:  "# files=3 bytes=1024 interval=1\n# ready\n",
+			want: &verificationSummary{files: 3, bytes: 1024, interval: 1},
+		},
+		{
+			name: "manifest written after warnings",
+			log:  "rsync: warning\n# files=30000 bytes=1024 interval=3\n# ready\n",
+			want: &verificationSummary{files: 30000, bytes: 1024, interval: 3},
+		},
+		{
+			name: "manifest being written",
+			log:  "",
+			want: nil,
+		},
+		{
+			name: "summary without ready marker",
+			log:  "# files=3 bytes=1024 interval=1\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseVerificationSummary(tt.log); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVerificationSummary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseVerificationComparison(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *verificationComparison
+	}{
+		{
+			name:   "identical manifests",
+			output: "# mismatched=0\n",
+			want:   &verificationComparison{paths: []string{}},
+		},
+		{
+			name:   "mismatched files",
+			output: "# mismatched=12\n./disk\ndir/b.txt\n",
+			want:   &verificationComparison{mismatched: 12, paths: []string{"disk", "dir/b.txt"}},
+		},
+		{
+			name:   "missing header",
+			output: "cat: /verification/manifest: No such file or directory\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseVerificationComparison(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVerificationComparison() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setVerificationResult(t *testing.T) {
+	tests := []struct {
+		name           string
+		src            *verificationSummary
+		dest           *verificationSummary
+		comparison     *verificationComparison
+		wantMismatched int64
+		wantPaths      []string
+	}{
+		{
+			name:           "identical volumes",
+			src:            &verificationSummary{files: 2, bytes: 10, interval: 1},
+			dest:           &verificationSummary{files: 2, bytes: 10, interval: 1},
+			comparison:     &verificationComparison{paths: []string{}},
+			wantMismatched: 0,
+			wantPaths:      []string{},
+		},
+		{
+			name:           "changed, missing and extra files",
+			src:            &verificationSummary{files: 3, bytes: 10, interval: 1},
+			dest:           &verificationSummary{files: 3, bytes: 10, interval: 1},
+			comparison:     &verificationComparison{mismatched: 3, paths: []string{"b", "c", "d"}},
+			wantMismatched: 3,
+			wantPaths:      []string{"b", "c", "d"},
+		},
+		{
+			name:           "sampled volumes with different file counts",
+			src:            &verificationSummary{files: 20, bytes: 10, interval: 10},
+			dest:           &verificationSummary{files: 15, bytes: 10, interval: 10},
+			comparison:     &verificationComparison{paths: []string{}},
+			wantMismatched: 5,
+			wantPaths:      []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := &migapi.VolumeVerification{}
+			setVerificationResult(tt.src, tt.dest, tt.comparison, verification)
+			if verification.MismatchedFileCount != tt.wantMismatched {
+				t.Errorf("setVerificationResult() mismatched = %d, want %d",
+					verification.MismatchedFileCount, tt.wantMismatched)
+			}
+			if !reflect.DeepEqual(verification.MismatchedPaths, tt.wantPaths) {
+				t.Errorf("setVerificationResult() paths = %v, want %v",
+					verification.MismatchedPaths, tt.wantPaths)
+			}
+			if verification.FileCount != tt.src.files || verification.DestinationFileCount != tt.dest.files {
+				t.Errorf("setVerificationResult() counts = %v", verification)
+			}
+		})
+	}
+}
+
+func TestTask_buildVerificationPod(t *testing.T) {
+	tests := []struct {
+		name        string
+		volumeMode  corev1.PersistentVolumeMode
+		filters     *migapi.RsyncFilters
+		wantDevices int
+		wantMounts  int
+		wantFilters string
+	}{
+		{
+			name:        "filesystem volume is mounted read-only",
+			wantDevices: 0,
+			wantMounts:  2,
+		},
+		{
+			name:        "block volume is attached as a device",
+			volumeMode:  corev1.PersistentVolumeBlock,
+			wantDevices: 1,
+			wantMounts:  1,
+		},
+		{
+			name:        "filtered volume lists files with the Rsync filters",
+			filters:     &migapi.RsyncFilters{Exclude: []string{"*.tmp"}},
+			wantDevices: 0,
+			wantMounts:  2,
+			wantFilters: "--exclude='*.tmp'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Owner: &migapi.DirectVolumeMigration{}}
+			pvc := migapi.PVCToMigrate{
+				ObjectReference: &corev1.ObjectReference{Name: "pvc", Namespace: "ns"},
+				VolumeMode:      tt.volumeMode,
+				Filters:         tt.filters,
+			}
+			pod := task.buildVerificationPod(pvc, "pvc", "ns", "node-1", "image", 7, false)
+			container := pod.Spec.Containers[0]
+			env := map[string]string{}
+			for _, envVar := range container.Env {
+				env[envVar.Name] = envVar.Value
+			}
+			if env["INTERVAL"] != "7" {
+				t.Errorf("buildVerificationPod() INTERVAL = %v, want 7", env["INTERVAL"])
+			}
+			if env["SAMPLE_SIZE"] != "10000" {
+				t.Errorf("buildVerificationPod() SAMPLE_SIZE = %v, want 10000", env["SAMPLE_SIZE"])
+			}
+			if env["RSYNC_FILTERS"] != tt.wantFilters {
+				t.Errorf("buildVerificationPod() RSYNC_FILTERS = %v, want %v", env["RSYNC_FILTERS"], tt.wantFilters)
+			}
+			if len(container.VolumeDevices) != tt.wantDevices || len(container.VolumeMounts) != tt.wantMounts {
+				t.Errorf("buildVerificationPod() devices = %v, mounts = %v", container.VolumeDevices, container.VolumeMounts)
+			}
+			if container.VolumeMounts[0].MountPath != DirectVolumeMigrationVerificationDataPath {
+				t.Errorf("buildVerificationPod() scratch volume is not mounted, mounts = %v", container.VolumeMounts)
+			}
+			if tt.wantMounts > 1 && !container.VolumeMounts[1].ReadOnly {
+				t.Errorf("buildVerificationPod() volume is not mounted read-only")
+			}
+			if pod.Spec.NodeName != "node-1" || pod.Labels["app"] != DirectVolumeMigrationRsyncTransfer {
+				t.Errorf("buildVerificationPod() nodeName = %s, labels = %v", pod.Spec.NodeName, pod.Labels)
+			}
+			if !pod.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly {
+				t.Errorf("buildVerificationPod() claim is not read-only")
+			}
+		})
+	}
+}
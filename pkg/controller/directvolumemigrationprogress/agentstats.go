@@ -0,0 +1,87 @@
+package directvolumemigrationprogress
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/transfer"
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TransferAgentLogTailLines number of log lines read to find the last progress line of the transfer agent
+const TransferAgentLogTailLines = 5
+
+// isTransferAgentPod returns whether the Pod copies the PVC with the transfer agent
+func isTransferAgentPod(pod *kapi.Pod) bool {
+	return pod.Annotations[migapi.TransferMethodAnnotation] == migapi.TransferAgentMethod
+}
+
+// GetTransferAgentStats converts a progress line of the transfer agent to Rsync statistics
+func GetTransferAgentStats(progress *transfer.Progress) *migapi.RsyncStats {
+	if progress == nil {
+		return nil
+	}
+	stats := &migapi.RsyncStats{
+		TotalFiles:        progress.TotalFiles,
+		TransferredFiles:  progress.TransferredFiles,
+		DeletedFiles:      progress.DeletedFiles,
+		TotalBytes:        progress.TotalBytes,
+		TransferredBytes:  progress.TransferredBytes,
+		SentBytes:         progress.SentBytes,
+		ReceivedBytes:     progress.ReceivedBytes,
+		AverageThroughput: progress.BytesPerSecond,
+		Complete:          progress.Type == transfer.ProgressCompleted,
+	}
+	if progress.EstimatedSecondsRemaining != nil && !stats.Complete {
+		stats.EstimatedTimeRemaining = &metav1.Duration{
+			Duration: time.Duration(*progress.EstimatedSecondsRemaining) * time.Second,
+		}
+	}
+	stats.UpdateSpeedup()
+	return stats
+}
+
+// formatTransferRate formats a throughput the way Rsync reports it with --human-readable
+func formatTransferRate(bytesPerSecond int64) string {
+	rate := float64(bytesPerSecond)
+	for _, unit := range []string{"B", "kB", "MB", "GB"} {
+		if rate < 1000 {
+			return fmt.Sprintf("%.2f%s/s", rate, unit)
+		}
+		rate /= 1000
+	}
+	return fmt.Sprintf("%.2fTB/s", rate)
+}
+
+// updateTransferAgentStats reads the last progress line of a transfer agent Pod and records
+// the progress and the statistics of the attempt
+func (r *RsyncPodProgressTask) updateTransferAgentStats(pod *kapi.Pod, rsyncPodStatus *migapi.RsyncPodStatus) {
+	tailLines := int64(TransferAgentLogTailLines)
+	logMessage, err := r.getRawPodLogs(pod, RsyncContainerName, &tailLines)
+	if err != nil {
+		log.Info("Failed to get logs from transfer agent Pod on source cluster",
+			"pod", path.Join(pod.Namespace, pod.Name))
+		return
+	}
+	progress := transfer.ParseProgress(logMessage)
+	if progress == nil {
+		return
+	}
+	if rsyncPodStatus.PodPhase != kapi.PodSucceeded {
+		rsyncPodStatus.LastObservedProgressPercent = ProgressValueToString(progress.Percent)
+	}
+	if progress.BytesPerSecond > 0 {
+		rsyncPodStatus.LastObservedTransferRate = formatTransferRate(progress.BytesPerSecond)
+	}
+	// the progress lines are not meant to be read, only the failure of the transfer is reported
+	switch {
+	case progress.Type == transfer.ProgressFailed && progress.Message != "":
+		rsyncPodStatus.LogMessage = progress.Message
+	case progress.Type != transfer.ProgressFailed:
+		rsyncPodStatus.LogMessage = ""
+	}
+	rsyncPodStatus.Stats = GetTransferAgentStats(progress)
+}
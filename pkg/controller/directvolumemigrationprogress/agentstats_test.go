@@ -0,0 +1,96 @@
+package directvolumemigrationprogress
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/transfer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetTransferAgentStats(t *testing.T) {
+	remaining := int64(90)
+	tests := []struct {
+		name     string
+		progress *transfer.Progress
+		want     *migapi.RsyncStats
+	}{
+		{
+			name: "no progress line",
+			want: nil,
+		},
+		{
+			name: "running transfer",
+			progress: &transfer.Progress{
+				Type:                      transfer.ProgressRunning,
+				TotalFiles:                10,
+				TransferredFiles:          4,
+				TotalBytes:                4000,
+				TransferredBytes:          1000,
+				SentBytes:                 1500,
+				ReceivedBytes:             500,
+				BytesPerSecond:            100,
+				EstimatedSecondsRemaining: &remaining,
+			},
+			want: &migapi.RsyncStats{
+				TotalFiles:             10,
+				TransferredFiles:       4,
+				TotalBytes:             4000,
+				TransferredBytes:       1000,
+				SentBytes:              1500,
+				ReceivedBytes:          500,
+				AverageThroughput:      100,
+				Speedup:                "2.00",
+				EstimatedTimeRemaining: &metav1.Duration{Duration: 90 * time.Second},
+			},
+		},
+		{
+			name: "completed transfer",
+			progress: &transfer.Progress{
+				Type:         transfer.ProgressCompleted,
+				TotalFiles:   10,
+				DeletedFiles: 2,
+				TotalBytes:   4000,
+				SentBytes:    200,
+			},
+			want: &migapi.RsyncStats{
+				TotalFiles:   10,
+				DeletedFiles: 2,
+				TotalBytes:   4000,
+				SentBytes:    200,
+				Speedup:      "20.00",
+				Complete:     true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetTransferAgentStats(tt.progress); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTransferAgentStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatTransferRate(t *testing.T) {
+	tests := []struct {
+		bytesPerSecond int64
+		want           string
+	}{
+		{bytesPerSecond: 512, want: "512.00B/s"},
+		{bytesPerSecond: 1500, want: "1.50kB/s"},
+		{bytesPerSecond: 25300000, want: "25.30MB/s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatTransferRate(tt.bytesPerSecond); got != tt.want {
+				t.Errorf("formatTransferRate() = %v, want %v", got, tt.want)
+			}
+			if GetTransferRate(formatTransferRate(tt.bytesPerSecond)) == "" {
+				t.Errorf("GetTransferRate() does not match %v", tt.want)
+			}
+		})
+	}
+}
@@ -202,6 +202,7 @@ func (r *RsyncPodProgressTask) Run() error {
 		rsyncPodStatus := r.getRsyncClientContainerStatus(pod, r)
 
 		if rsyncPodStatus != nil {
+			r.updateRsyncStats(pod, rsyncPodStatus)
 			pvProgress.Status.RsyncPodStatus = *rsyncPodStatus
 		}
 	} else if podSelector != nil && podNamespace != "" {
@@ -219,6 +220,7 @@ func (r *RsyncPodProgressTask) Run() error {
 			}
 			rsyncPodStatus := r.getRsyncClientContainerStatus(pod, r)
 			if rsyncPodStatus != nil {
+				r.updateRsyncStats(pod, rsyncPodStatus)
 				// dead pods go in history
 				if IsPodTerminal(rsyncPodStatus.PodPhase) {
 					err := r.addDVMPDoneLabel(pod)
@@ -251,6 +253,8 @@ func (r *RsyncPodProgressTask) Run() error {
 		r.updateCumulativeProgressPercentage()
 		// update total elapsed time
 		r.updateCumulativeElapsedTime()
+		// update statistics of all attempts
+		r.updateCumulativeStats()
 	}
 	return nil
 }
@@ -297,6 +301,10 @@ func MergeProgressStats(p1, p2 *migapi.RsyncPodStatus) {
 	p1.ExitCode = getNonNil(p1.ExitCode, p2.ExitCode)
 	p1.LastObservedProgressPercent = MaxProgressString(p1.LastObservedProgressPercent, p2.LastObservedProgressPercent)
 	p1.LastObservedTransferRate = getNonEmpty(p1.LastObservedTransferRate, p2.LastObservedTransferRate)
+	// statistics reported on completion are never replaced by progress statistics
+	if p2.Stats != nil && (p1.Stats == nil || !p1.Stats.Complete || p2.Stats.Complete) {
+		p1.Stats = p2.Stats
+	}
 }
 
 func IsPodTerminal(phase kapi.PodPhase) bool {
@@ -454,29 +462,50 @@ func (r *RsyncPodProgressTask) getAllMatchingRsyncPods() (*kapi.PodList, error)
 }
 
 func (r *RsyncPodProgressTask) getPodLogs(pod *kapi.Pod, containerName string, tailLines *int64, previous bool) (string, error) {
-	config, err := r.Cluster.BuildRestConfig(r.Client)
+	readCloser, err := r.streamPodLogs(pod, containerName, tailLines, previous)
 	if err != nil {
 		return "", err
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	defer readCloser.Close()
+
+	return parseLogs(readCloser)
+}
+
+// getRawPodLogs returns the logs of the container without truncating the lines
+func (r *RsyncPodProgressTask) getRawPodLogs(pod *kapi.Pod, containerName string, tailLines *int64) (string, error) {
+	readCloser, err := r.streamPodLogs(pod, containerName, tailLines, false)
 	if err != nil {
 		return "", err
 	}
 
-	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &kapi.PodLogOptions{
-		TailLines: tailLines,
-		Previous:  previous,
-		Container: containerName,
-	})
-	readCloser, err := req.Stream(context.TODO())
+	defer readCloser.Close()
+
+	buf := new(strings.Builder)
+	_, err = io.Copy(buf, readCloser)
 	if err != nil {
 		return "", err
 	}
+	return buf.String(), nil
+}
 
-	defer readCloser.Close()
+func (r *RsyncPodProgressTask) streamPodLogs(pod *kapi.Pod, containerName string, tailLines *int64, previous bool) (io.ReadCloser, error) {
+	config, err := r.Cluster.BuildRestConfig(r.Client)
+	if err != nil {
+		return nil, err
+	}
 
-	return parseLogs(readCloser)
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &kapi.PodLogOptions{
+		TailLines: tailLines,
+		Previous:  previous,
+		Container: containerName,
+	})
+	return req.Stream(context.TODO())
 }
 
 // GetProgressPercent given logs from Rsync Pod, returns logged progress percentage
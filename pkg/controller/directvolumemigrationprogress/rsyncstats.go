@@ -0,0 +1,207 @@
+package directvolumemigrationprogress
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RsyncStatsLogTailLines number of log lines read to find the Rsync statistics,
+// the STATS2 output is printed both on stdout and in the log file
+const RsyncStatsLogTailLines = 40
+
+// rsyncNumber matches a number printed by Rsync with --human-readable
+const rsyncNumber = `([\d.,]+[KMGTP]?)`
+
+var (
+	rsyncTotalFilesRegex       = regexp.MustCompile(`Number of files: ` + rsyncNumber)
+	rsyncDeletedFilesRegex     = regexp.MustCompile(`Number of deleted files: ` + rsyncNumber)
+	rsyncTransferredFilesRegex = regexp.MustCompile(`Number of regular files transferred: ` + rsyncNumber)
+	rsyncTotalBytesRegex       = regexp.MustCompile(`Total file size: ` + rsyncNumber + ` bytes`)
+	rsyncTransferredBytesRegex = regexp.MustCompile(`Total transferred file size: ` + rsyncNumber + ` bytes`)
+	rsyncSentBytesRegex        = regexp.MustCompile(`Total bytes sent: ` + rsyncNumber)
+	rsyncReceivedBytesRegex    = regexp.MustCompile(`Total bytes received: ` + rsyncNumber)
+	rsyncThroughputRegex       = regexp.MustCompile(`sent \S+ bytes\s+received \S+ bytes\s+` + rsyncNumber + ` bytes/sec`)
+	rsyncSpeedupRegex          = regexp.MustCompile(`speedup is ([\d.,]+)`)
+	// FLIST2 output of a non-incremental file list
+	rsyncFilesToConsiderRegex = regexp.MustCompile(rsyncNumber + ` files to consider`)
+	// PROGRESS2 output, the total of to-chk/ir-chk grows while the incremental file list is built
+	rsyncProgressRegex = regexp.MustCompile(rsyncNumber + `\s+\d+%\s+\S+/s\s+\S+\s+\(xfr#(\d+), (?:ir|to)-chk=\d+/(\d+)\)`)
+)
+
+// ParseRsyncStats given logs from Rsync Pod, returns the statistics of the Rsync run.
+// The STATS2 summary is used when Rsync completed, the last PROGRESS2 line otherwise.
+// Returns nil when the logs hold no statistics.
+func ParseRsyncStats(message string) *migapi.RsyncStats {
+	if sent, found := getLastRsyncNumber(rsyncSentBytesRegex, message); found {
+		stats := &migapi.RsyncStats{
+			SentBytes: sent,
+			Complete:  true,
+		}
+		stats.ReceivedBytes, _ = getLastRsyncNumber(rsyncReceivedBytesRegex, message)
+		stats.TotalFiles, _ = getLastRsyncNumber(rsyncTotalFilesRegex, message)
+		stats.DeletedFiles, _ = getLastRsyncNumber(rsyncDeletedFilesRegex, message)
+		stats.TransferredFiles, _ = getLastRsyncNumber(rsyncTransferredFilesRegex, message)
+		stats.TotalBytes, _ = getLastRsyncNumber(rsyncTotalBytesRegex, message)
+		stats.TransferredBytes, _ = getLastRsyncNumber(rsyncTransferredBytesRegex, message)
+		stats.AverageThroughput, _ = getLastRsyncNumber(rsyncThroughputRegex, message)
+		if speedup := getLastSubmatch(rsyncSpeedupRegex, message); speedup != nil {
+			stats.Speedup = strings.ReplaceAll(speedup[1], ",", "")
+		} else {
+			stats.UpdateSpeedup()
+		}
+		return stats
+	}
+	var stats *migapi.RsyncStats
+	if progress := getLastSubmatch(rsyncProgressRegex, message); progress != nil {
+		stats = &migapi.RsyncStats{}
+		stats.TransferredBytes, _ = parseRsyncNumber(progress[1])
+		stats.TransferredFiles, _ = strconv.ParseInt(progress[2], 10, 64)
+		stats.TotalFiles, _ = strconv.ParseInt(progress[3], 10, 64)
+	}
+	if files, found := getLastRsyncNumber(rsyncFilesToConsiderRegex, message); found {
+		if stats == nil {
+			stats = &migapi.RsyncStats{}
+		}
+		if files > stats.TotalFiles {
+			stats.TotalFiles = files
+		}
+	}
+	return stats
+}
+
+// parseRsyncNumber parses a number printed by Rsync, either with digit separators
+// or in units of 1000 with a suffix as printed with a single --human-readable
+func parseRsyncNumber(s string) (int64, bool) {
+	s = strings.ReplaceAll(s, ",", "")
+	multiplier := float64(1)
+	for i, suffix := range []string{"K", "M", "G", "T", "P"} {
+		if strings.HasSuffix(s, suffix) {
+			s = strings.TrimSuffix(s, suffix)
+			for n := 0; n <= i; n++ {
+				multiplier *= 1000
+			}
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int64(value * multiplier), true
+}
+
+func getLastSubmatch(r *regexp.Regexp, message string) []string {
+	matches := r.FindAllStringSubmatch(message, -1)
+	if len(matches) > 0 {
+		return matches[len(matches)-1]
+	}
+	return nil
+}
+
+func getLastRsyncNumber(r *regexp.Regexp, message string) (int64, bool) {
+	match := getLastSubmatch(r, message)
+	if match == nil {
+		return 0, false
+	}
+	return parseRsyncNumber(match[1])
+}
+
+// SetRunningRsyncStats computes the average throughput and the time remaining of a running Rsync
+// from the observed progress percentage and the time elapsed since the Rsync container started
+func SetRunningRsyncStats(stats *migapi.RsyncStats, progressPercent string, elapsed time.Duration) {
+	if stats == nil || stats.Complete || elapsed <= 0 {
+		return
+	}
+	stats.AverageThroughput = int64(float64(stats.TransferredBytes) / elapsed.Seconds())
+	progress := ProgressStringToValue(progressPercent)
+	if progress <= 0 || progress >= 100 {
+		stats.EstimatedTimeRemaining = nil
+		return
+	}
+	remaining := time.Duration(float64(elapsed) * float64(100-progress) / float64(progress))
+	stats.EstimatedTimeRemaining = &metav1.Duration{Duration: remaining.Round(time.Second)}
+}
+
+// GetCumulativeRsyncStats returns the statistics of successive Rsync attempts of a PVC.
+// The counters of the transfers are summed, the totals are the ones of the latest attempt.
+func GetCumulativeRsyncStats(attempts []*migapi.RsyncStats) *migapi.RsyncStats {
+	var cumulative *migapi.RsyncStats
+	for _, stats := range attempts {
+		if stats == nil {
+			continue
+		}
+		if cumulative == nil {
+			cumulative = &migapi.RsyncStats{}
+		}
+		cumulative.TotalFiles = stats.TotalFiles
+		cumulative.TotalBytes = stats.TotalBytes
+		cumulative.TransferredFiles += stats.TransferredFiles
+		cumulative.TransferredBytes += stats.TransferredBytes
+		cumulative.DeletedFiles += stats.DeletedFiles
+		cumulative.SentBytes += stats.SentBytes
+		cumulative.ReceivedBytes += stats.ReceivedBytes
+		cumulative.AverageThroughput = stats.AverageThroughput
+		cumulative.EstimatedTimeRemaining = stats.EstimatedTimeRemaining
+		cumulative.Complete = stats.Complete
+	}
+	if cumulative != nil {
+		cumulative.UpdateSpeedup()
+	}
+	return cumulative
+}
+
+// updateRsyncStats reads the untruncated tail of the logs of a running or terminated Rsync Pod
+// and records the statistics of the Rsync attempt
+func (r *RsyncPodProgressTask) updateRsyncStats(pod *kapi.Pod, rsyncPodStatus *migapi.RsyncPodStatus) {
+	if rsyncPodStatus.PodPhase != kapi.PodRunning && !IsPodTerminal(rsyncPodStatus.PodPhase) {
+		return
+	}
+	if isTransferAgentPod(pod) {
+		r.updateTransferAgentStats(pod, rsyncPodStatus)
+		return
+	}
+	tailLines := int64(RsyncStatsLogTailLines)
+	logMessage, err := r.getRawPodLogs(pod, RsyncContainerName, &tailLines)
+	if err != nil {
+		log.Info("Failed to get logs from Rsync Pod on source cluster",
+			"pod", path.Join(pod.Namespace, pod.Name))
+		return
+	}
+	stats := ParseRsyncStats(logMessage)
+	if stats == nil {
+		return
+	}
+	if startedAt := getRsyncContainerStartTime(pod); startedAt != nil {
+		SetRunningRsyncStats(stats, rsyncPodStatus.LastObservedProgressPercent, time.Since(startedAt.Time))
+	}
+	rsyncPodStatus.Stats = stats
+}
+
+// updateCumulativeStats computes the statistics of all Rsync attempts
+func (r *RsyncPodProgressTask) updateCumulativeStats() {
+	attempts := []*migapi.RsyncStats{}
+	for _, podHistory := range r.Owner.Status.RsyncPodStatuses {
+		if podHistory.PodName != r.Owner.Status.PodName {
+			attempts = append(attempts, podHistory.Stats)
+		}
+	}
+	attempts = append(attempts, r.Owner.Status.Stats)
+	r.Owner.Status.CumulativeStats = GetCumulativeRsyncStats(attempts)
+}
+
+// getRsyncContainerStartTime returns the start time of the running Rsync container
+func getRsyncContainerStartTime(pod *kapi.Pod) *metav1.Time {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == RsyncContainerName && containerStatus.State.Running != nil {
+			return &containerStatus.State.Running.StartedAt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package directvolumemigrationprogress
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseRsyncStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    *migapi.RsyncStats
+	}{
+		{
+			name: "completed rsync with log file prefixes",
+			message: `2021/05/20 10:00:01 [12] sent 1.05M bytes  received 1.23K bytes  700.82K bytes/sec
+2021/05/20 10:00:01 [12] total size is 1.05M  speedup is 1.00
+2021/05/20 10:00:01 [12] Number of files: 12 (reg: 10, dir: 2)
+2021/05/20 10:00:01 [12] Number of created files: 11 (reg: 10, dir: 1)
+2021/05/20 10:00:01 [12] Number of deleted files: 3 (reg: 3)
+2021/05/20 10:00:01 [12] Number of regular files transferred: 10
+2021/05/20 10:00:01 [12] Total file size: 1.05M bytes
+2021/05/20 10:00:01 [12] Total transferred file size: 1.04M bytes
+2021/05/20 10:00:01 [12] Total bytes sent: 1.05M
+2021/05/20 10:00:01 [12] Total bytes received: 1.23K`,
+			want: &migapi.RsyncStats{
+				TotalFiles:        12,
+				DeletedFiles:      3,
+				TransferredFiles:  10,
+				TotalBytes:        1050000,
+				TransferredBytes:  1040000,
+				SentBytes:         1050000,
+				ReceivedBytes:     1230,
+				AverageThroughput: 700820,
+				Speedup:           "1.00",
+				Complete:          true,
+			},
+		},
+		{
+			name: "completed rsync with digit separators",
+			message: `Number of files: 1,204 (reg: 1,200, dir: 4)
+Number of regular files transferred: 1,200
+Total file size: 2,048,000 bytes
+Total transferred file size: 2,048,000 bytes
+Total bytes sent: 1,024,000
+Total bytes received: 24,000`,
+			want: &migapi.RsyncStats{
+				TotalFiles:       1204,
+				TransferredFiles: 1200,
+				TotalBytes:       2048000,
+				TransferredBytes: 2048000,
+				SentBytes:        1024000,
+				ReceivedBytes:    24000,
+				Speedup:          "1.95",
+				Complete:         true,
+			},
+		},
+		{
+			name: "running rsync with incremental file list",
+			message: `        427.68K  41%  417.66kB/s    0:00:01 (xfr#4, ir-chk=1006/1011)
+          1.02M  52%  500.12kB/s    0:00:01 (xfr#6, ir-chk=1010/1020)`,
+			want: &migapi.RsyncStats{
+				TotalFiles:       1020,
+				TransferredFiles: 6,
+				TransferredBytes: 1020000,
+			},
+		},
+		{
+			name:    "file list built before the transfer started",
+			message: `2021/05/20 10:00:01 [12] 1,340 files to consider`,
+			want:    &migapi.RsyncStats{TotalFiles: 1340},
+		},
+		{
+			name:    "no statistics in logs",
+			message: `rsync: connection unexpectedly closed (0 bytes received so far) [sender]`,
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRsyncStats(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRsyncStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseRsyncNumber(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      int64
+		wantFound bool
+	}{
+		{input: "1,024", want: 1024, wantFound: true},
+		{input: "12", want: 12, wantFound: true},
+		{input: "1.50K", want: 1500, wantFound: true},
+		{input: "2.5M", want: 2500000, wantFound: true},
+		{input: "1.00G", want: 1000000000, wantFound: true},
+		{input: "abc", want: 0, wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, found := parseRsyncNumber(tt.input)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("parseRsyncNumber() = %v, %v, want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestSetRunningRsyncStats(t *testing.T) {
+	tests := []struct {
+		name            string
+		stats           *migapi.RsyncStats
+		progressPercent string
+		elapsed         time.Duration
+		want            *migapi.RsyncStats
+	}{
+		{
+			name:            "quarter of the data transferred",
+			stats:           &migapi.RsyncStats{TransferredBytes: 1000},
+			progressPercent: "25%",
+			elapsed:         10 * time.Second,
+			want: &migapi.RsyncStats{
+				TransferredBytes:       1000,
+				AverageThroughput:      100,
+				EstimatedTimeRemaining: &metav1.Duration{Duration: 30 * time.Second},
+			},
+		},
+		{
+			name:            "no progress observed yet",
+			stats:           &migapi.RsyncStats{TransferredBytes: 1000},
+			progressPercent: "",
+			elapsed:         10 * time.Second,
+			want:            &migapi.RsyncStats{TransferredBytes: 1000, AverageThroughput: 100},
+		},
+		{
+			name:            "completed rsync is left untouched",
+			stats:           &migapi.RsyncStats{TransferredBytes: 1000, AverageThroughput: 10, Complete: true},
+			progressPercent: "100%",
+			elapsed:         10 * time.Second,
+			want:            &migapi.RsyncStats{TransferredBytes: 1000, AverageThroughput: 10, Complete: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRunningRsyncStats(tt.stats, tt.progressPercent, tt.elapsed)
+			if !reflect.DeepEqual(tt.stats, tt.want) {
+				t.Errorf("SetRunningRsyncStats() = %v, want %v", tt.stats, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCumulativeRsyncStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts []*migapi.RsyncStats
+		want     *migapi.RsyncStats
+	}{
+		{
+			name:     "no statistics for any attempt",
+			attempts: []*migapi.RsyncStats{nil, nil},
+			want:     nil,
+		},
+		{
+			name: "failed attempt followed by a successful retry",
+			attempts: []*migapi.RsyncStats{
+				{TotalFiles: 10, TotalBytes: 1000, TransferredFiles: 4, TransferredBytes: 400, AverageThroughput: 40},
+				nil,
+				{TotalFiles: 12, TotalBytes: 1200, TransferredFiles: 8, TransferredBytes: 800,
+					SentBytes: 550, ReceivedBytes: 50, AverageThroughput: 80, Complete: true},
+			},
+			want: &migapi.RsyncStats{
+				TotalFiles:        12,
+				TotalBytes:        1200,
+				TransferredFiles:  12,
+				TransferredBytes:  1200,
+				SentBytes:         550,
+				ReceivedBytes:     50,
+				AverageThroughput: 80,
+				Speedup:           "2.00",
+				Complete:          true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetCumulativeRsyncStats(tt.attempts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCumulativeRsyncStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -253,6 +253,9 @@ type TreeNode struct {
 	ClusterType string     `json:"clusterType"`
 	Children    []TreeNode `json:"children,omitempty"`
 	ObjectLink  string     `json:"objectLink"`
+
+	// Hook execution status, set on hook nodes.
+	HookStatus *migapi.HookStatus `json:"hookStatus,omitempty"`
 }
 
 //
@@ -729,6 +732,7 @@ func (t *PlanTree) addHooks(migration *model.Migration, parent *TreeNode) error
 					Namespace:   m.Namespace,
 					Name:        m.Name,
 					ClusterType: clusterTypeHost,
+					HookStatus:  migrationObject.Status.FindHook(hookRef.Phase),
 				}
 				err := t.addHookJobsForCluster(m, migration, &node)
 				if err != nil {
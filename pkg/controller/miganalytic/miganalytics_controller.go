@@ -291,7 +291,12 @@ func (r *ReconcileMigAnalytic) analyze(analytic *migapi.MigAnalytic) error {
 			}
 		}
 		if analytic.Spec.AnalyzeImageCount && !isExcluded("imagestreams", excludedResources) && !Settings.DisImgCopy {
-			err := r.analyzeImages(client, &ns, analytic.Spec.ListImages, analytic.Spec.ListImagesLimit)
+			// The tag filter only applies to direct image migrations
+			var tagFilter *migapi.ImageStreamTagFilter
+			if !plan.Spec.IndirectImageMigration {
+				tagFilter = plan.Spec.ImageStreamTagFilter
+			}
+			err := r.analyzeImages(client, &ns, analytic.Spec.ListImages, analytic.Spec.ListImagesLimit, tagFilter)
 			if err != nil {
 				return liberr.Wrap(err)
 			}
@@ -500,7 +505,8 @@ func (r *ReconcileMigAnalytic) analyzeK8SResources(dynamic dynamic.Interface,
 func (r *ReconcileMigAnalytic) analyzeImages(client compat.Client,
 	namespace *migapi.MigAnalyticNamespace,
 	listImages bool,
-	listImagesLimit int) error {
+	listImagesLimit int,
+	tagFilter *migapi.ImageStreamTagFilter) error {
 	imageStreamList := imagev1.ImageStreamList{}
 
 	major, minor := client.MajorVersion(), client.MinorVersion()
@@ -514,7 +520,13 @@ func (r *ReconcileMigAnalytic) analyzeImages(client compat.Client,
 		return liberr.Wrap(err)
 	}
 
+	now := time.Now()
 	for _, im := range imageStreamList.Items {
+		// Count the images selected by the tag filter of direct image migrations
+		im, _, err := tagFilter.Apply(im, now)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
 		for _, tag := range im.Status.Tags {
 			for i := len(tag.Items) - 1; i >= 0; i-- {
 				dockerImageReference := tag.Items[i].DockerImageReference
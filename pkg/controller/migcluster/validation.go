@@ -28,6 +28,8 @@ const (
 	SaTokenNotPrivileged           = "SaTokenNotPrivileged"
 	OperatorVersionMismatch        = "OperatorVersionMismatch"
 	ClusterOperatorVersionNotFound = "ClusterOperatorVersionNotFound"
+	InvalidTransferEndpoint        = "InvalidTransferEndpoint"
+	InvalidImageRegistry           = "InvalidImageRegistry"
 )
 
 // Categories
@@ -47,6 +49,7 @@ const (
 	Unauthorized       = "Unauthorized"
 	VersionCheckFailed = "VersionCheckFailed"
 	VersionNotFound    = "VersionNotFound"
+	NotSupported       = "NotSupported"
 )
 
 // Statuses
@@ -100,6 +103,18 @@ func (r ReconcileMigCluster) validate(ctx context.Context, cluster *migapi.MigCl
 		return liberr.Wrap(err)
 	}
 
+	// DVM transfer endpoint
+	err = r.validateTransferEndpoint(ctx, cluster)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+
+	// DIM image registry
+	err = r.validateImageRegistry(ctx, cluster)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+
 	return nil
 }
 
@@ -148,6 +163,78 @@ func (r ReconcileMigCluster) validateURL(ctx context.Context, cluster *migapi.Mi
 	return nil
 }
 
+func (r ReconcileMigCluster) validateTransferEndpoint(ctx context.Context, cluster *migapi.MigCluster) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateTransferEndpoint")
+		defer span.Finish()
+	}
+
+	switch cluster.GetTransferEndpointType() {
+	case migapi.RouteTransferEndpoint,
+		migapi.LoadBalancerTransferEndpoint,
+		migapi.NodePortTransferEndpoint,
+		migapi.IngressTransferEndpoint:
+		return nil
+	}
+	cluster.Status.SetCondition(migapi.Condition{
+		Type:     InvalidTransferEndpoint,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message: fmt.Sprintf("The `transferEndpoint.type` [%s] is not supported, must be: (%s).",
+			cluster.GetTransferEndpointType(),
+			strings.Join([]string{
+				migapi.RouteTransferEndpoint,
+				migapi.LoadBalancerTransferEndpoint,
+				migapi.NodePortTransferEndpoint,
+				migapi.IngressTransferEndpoint,
+			}, "|")),
+	})
+	return nil
+}
+
+// Validate the image registry direct image migrations copy images to.
+func (r ReconcileMigCluster) validateImageRegistry(ctx context.Context, cluster *migapi.MigCluster) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateImageRegistry")
+		defer span.Finish()
+	}
+
+	registry := cluster.Spec.ImageRegistry
+	if registry == nil {
+		return nil
+	}
+	err := registry.Validate()
+	if err != nil {
+		cluster.Status.SetCondition(migapi.Condition{
+			Type:     InvalidImageRegistry,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  fmt.Sprintf("The `imageRegistry` is invalid: %s.", err.Error()),
+		})
+		return nil
+	}
+	if registry.CredentialsSecretRef == nil {
+		return nil
+	}
+	secret, err := migapi.GetSecret(r, registry.CredentialsSecretRef)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if secret == nil {
+		cluster.Status.SetCondition(migapi.Condition{
+			Type:     InvalidImageRegistry,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message: fmt.Sprintf("The `imageRegistry.credentialsSecretRef` must reference a valid `secret`, subject: %s.",
+				path.Join(registry.CredentialsSecretRef.Namespace, registry.CredentialsSecretRef.Name)),
+		})
+	}
+	return nil
+}
+
 func (r ReconcileMigCluster) validateSaSecret(ctx context.Context, cluster *migapi.MigCluster) error {
 	if opentracing.SpanFromContext(ctx) != nil {
 		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateSaSecret")
@@ -17,11 +17,16 @@ limitations under the License.
 package mighook
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -78,3 +83,100 @@ func TestReconcile(t *testing.T) {
 	// g.Expect(c.Delete(context.TODO(), deploy)).To(gomega.Succeed())
 
 }
+
+func TestReconcileMigHook_validateWebhook_bounds(t *testing.T) {
+	tests := []struct {
+		name        string
+		webhook     migapi.MigHookWebhook
+		wantInvalid bool
+	}{
+		{
+			name:    "defaults",
+			webhook: migapi.MigHookWebhook{},
+		},
+		{
+			name:    "max backoff and timeout",
+			webhook: migapi.MigHookWebhook{BackoffSeconds: 300, TimeoutSeconds: 300},
+		},
+		{
+			name:        "negative backoff",
+			webhook:     migapi.MigHookWebhook{BackoffSeconds: -1},
+			wantInvalid: true,
+		},
+		{
+			name:        "backoff above max",
+			webhook:     migapi.MigHookWebhook{BackoffSeconds: 301},
+			wantInvalid: true,
+		},
+		{
+			name:        "timeout above max",
+			webhook:     migapi.MigHookWebhook{TimeoutSeconds: 3600},
+			wantInvalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := tt.webhook
+			webhook.URL = "https://hooks.example.com/migration"
+			hook := &migapi.MigHook{
+				ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "openshift-migration"},
+				Spec:       migapi.MigHookSpec{Webhook: &webhook},
+			}
+			r := ReconcileMigHook{Client: fake.NewFakeClient()}
+			err := r.validateWebhook(context.TODO(), hook)
+			if err != nil {
+				t.Fatalf("validateWebhook() error = %v", err)
+			}
+			if got := hook.Status.HasCondition(InvalidWebhook); got != tt.wantInvalid {
+				t.Errorf("validateWebhook() invalid = %v, want %v, conditions %v", got, tt.wantInvalid, hook.Status.Conditions.List)
+			}
+		})
+	}
+}
+
+func TestReconcileMigHook_validateWebhook_headersSecretRef(t *testing.T) {
+	secrets := []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "headers", Namespace: "openshift-migration"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "headers", Namespace: "other"}},
+	}
+	tests := []struct {
+		name        string
+		ref         *corev1.ObjectReference
+		wantInvalid bool
+	}{
+		{
+			name: "secret in the hook namespace",
+			ref:  &corev1.ObjectReference{Name: "headers", Namespace: "openshift-migration"},
+		},
+		{
+			name: "namespace defaults to the hook namespace",
+			ref:  &corev1.ObjectReference{Name: "headers"},
+		},
+		{
+			name:        "secret in another namespace",
+			ref:         &corev1.ObjectReference{Name: "headers", Namespace: "other"},
+			wantInvalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &migapi.MigHook{
+				ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "openshift-migration"},
+				Spec: migapi.MigHookSpec{
+					Webhook: &migapi.MigHookWebhook{
+						URL:              "https://hooks.example.com/migration",
+						HeadersSecretRef: tt.ref,
+					},
+				},
+			}
+			r := ReconcileMigHook{Client: fake.NewFakeClient(secrets[0], secrets[1])}
+			err := r.validateWebhook(context.TODO(), hook)
+			if err != nil {
+				t.Fatalf("validateWebhook() error = %v", err)
+			}
+			if got := hook.Status.HasCondition(InvalidWebhook); got != tt.wantInvalid {
+				t.Errorf("validateWebhook() invalid = %v, want %v, conditions %v", got, tt.wantInvalid, hook.Status.Conditions.List)
+			}
+		})
+	}
+}
@@ -3,10 +3,17 @@ package mighook
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/template"
 
 	liberr "github.com/konveyor/controller/pkg/error"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/opentracing/opentracing-go"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // Types
@@ -14,8 +21,11 @@ const (
 	InvalidTargetCluster = "InvalidTarget"
 	InvalidImage         = "InvalidImage"
 	InvalidPlaybookData  = "InvalidPlaybookData"
+	InvalidScriptData    = "InvalidScriptData"
 	InvalidAnsibleHook   = "InvalidAnsibleHook"
 	InvalidCustomHook    = "InvalidCustomHook"
+	InvalidScriptHook    = "InvalidScriptHook"
+	InvalidWebhook       = "InvalidWebhook"
 )
 
 // Categories
@@ -47,6 +57,13 @@ func (r ReconcileMigHook) validate(ctx context.Context, hook *migapi.MigHook) er
 		defer span.Finish()
 	}
 
+	if hook.IsWebhook() {
+		err := r.validateWebhook(ctx, hook)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		return nil
+	}
 	err := r.validateImage(ctx, hook)
 	if err != nil {
 		return liberr.Wrap(err)
@@ -59,10 +76,18 @@ func (r ReconcileMigHook) validate(ctx context.Context, hook *migapi.MigHook) er
 	if err != nil {
 		return liberr.Wrap(err)
 	}
+	err = r.validateScriptData(ctx, hook)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
 	err = r.validateCustom(ctx, hook)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
+	err = r.validateScript(ctx, hook)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
 	return nil
 }
 
@@ -120,27 +145,209 @@ func (r ReconcileMigHook) validatePlaybookData(ctx context.Context, hook *migapi
 	return nil
 }
 
+func (r ReconcileMigHook) validateScriptData(ctx context.Context, hook *migapi.MigHook) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateScriptData")
+		defer span.Finish()
+	}
+	if _, err := base64.StdEncoding.DecodeString(hook.Spec.Script); err != nil {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidScriptData,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "Spec.Script should contain a base64 encoded script.",
+		})
+	}
+	return nil
+}
+
 func (r ReconcileMigHook) validateCustom(ctx context.Context, hook *migapi.MigHook) error {
 	if opentracing.SpanFromContext(ctx) != nil {
 		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateCustom")
 		defer span.Finish()
 	}
-	if hook.Spec.Custom && hook.Spec.Playbook != "" {
+	if hook.Spec.Custom && (hook.Spec.Playbook != "" || hook.Spec.Script != "") {
 		hook.Status.SetCondition(migapi.Condition{
 			Type:     InvalidCustomHook,
 			Status:   True,
 			Reason:   NotSet,
 			Category: Critical,
-			Message:  "An Ansible Playbook must not be specified when spec.custom is true.",
+			Message:  "An Ansible Playbook or script must not be specified when spec.custom is true.",
 		})
-	} else if !hook.Spec.Custom && hook.Spec.Playbook == "" {
+	} else if !hook.Spec.Custom && hook.Spec.Playbook == "" && hook.Spec.Script == "" {
 		hook.Status.SetCondition(migapi.Condition{
 			Type:     InvalidAnsibleHook,
 			Status:   True,
 			Reason:   NotSet,
 			Category: Critical,
-			Message:  "An Ansible Playbook must be specified when spec.custom is false.",
+			Message:  "An Ansible Playbook or script must be specified when spec.custom is false.",
+		})
+	} else if !hook.Spec.Custom && (len(hook.Spec.Command) > 0 || len(hook.Spec.Args) > 0) {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidCustomHook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "The spec.command and spec.args overrides are only supported when spec.custom is true.",
+		})
+	}
+	return nil
+}
+
+func (r ReconcileMigHook) validateScript(ctx context.Context, hook *migapi.MigHook) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateScript")
+		defer span.Finish()
+	}
+	if hook.Spec.Script != "" && hook.Spec.Playbook != "" {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidScriptHook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "An Ansible Playbook and a script must not both be specified.",
+		})
+	} else if hook.Spec.Interpreter != "" && hook.Spec.Script == "" {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidScriptHook,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "Spec.Interpreter must only be specified together with spec.script.",
 		})
 	}
 	return nil
 }
+
+func (r ReconcileMigHook) validateWebhook(ctx context.Context, hook *migapi.MigHook) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateWebhook")
+		defer span.Finish()
+	}
+	webhook := hook.Spec.Webhook
+
+	// Job settings.
+	if hook.Spec.Custom || hook.Spec.Playbook != "" || hook.Spec.Script != "" || hook.Spec.Image != "" {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidWebhook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "An image, Ansible Playbook or script must not be specified together with spec.webhook.",
+		})
+		return nil
+	}
+
+	// URL
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidWebhook,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "Spec.webhook.url must be an absolute http or https URL.",
+		})
+		return nil
+	}
+
+	// Method
+	switch webhook.GetMethod() {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidWebhook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "Spec.webhook.method must be one of GET, POST, PUT, PATCH or DELETE.",
+		})
+		return nil
+	}
+
+	// Success codes
+	for _, code := range webhook.SuccessCodes {
+		if code < 100 || code > 599 {
+			hook.Status.SetCondition(migapi.Condition{
+				Type:     InvalidWebhook,
+				Status:   True,
+				Reason:   NotSupported,
+				Category: Critical,
+				Message:  "Spec.webhook.successCodes must only contain valid HTTP status codes.",
+			})
+			return nil
+		}
+	}
+
+	// Backoff and timeout
+	if webhook.BackoffSeconds < 0 || webhook.BackoffSeconds > migapi.MaxWebhookBackoffSeconds {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidWebhook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("Spec.webhook.backoffSeconds must be between 0 and %d.",
+				migapi.MaxWebhookBackoffSeconds),
+		})
+		return nil
+	}
+	if webhook.TimeoutSeconds < 0 || webhook.TimeoutSeconds > migapi.MaxWebhookTimeoutSeconds {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidWebhook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("Spec.webhook.timeoutSeconds must be between 0 and %d.",
+				migapi.MaxWebhookTimeoutSeconds),
+		})
+		return nil
+	}
+
+	// Headers, only read from a secret in the namespace of the hook.
+	if ref := webhook.HeadersSecretRef; ref != nil {
+		if ref.Namespace != "" && ref.Namespace != hook.Namespace {
+			hook.Status.SetCondition(migapi.Condition{
+				Type:     InvalidWebhook,
+				Status:   True,
+				Reason:   NotSupported,
+				Category: Critical,
+				Message:  "The secret referenced by spec.webhook.headersSecretRef must be in the namespace of the MigHook.",
+			})
+			return nil
+		}
+		secret := corev1.Secret{}
+		err := r.Get(
+			context.TODO(),
+			types.NamespacedName{
+				Namespace: hook.Namespace,
+				Name:      ref.Name,
+			},
+			&secret)
+		if k8serror.IsNotFound(err) {
+			hook.Status.SetCondition(migapi.Condition{
+				Type:     InvalidWebhook,
+				Status:   True,
+				Reason:   NotFound,
+				Category: Critical,
+				Message:  "The secret referenced by spec.webhook.headersSecretRef does not exist.",
+			})
+			return nil
+		} else if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+
+	// Body
+	if _, err := template.New("body").Parse(webhook.Body); err != nil {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidWebhook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "Spec.webhook.body is not a valid template.",
+		})
+	}
+
+	return nil
+}
@@ -67,8 +67,12 @@ var PhaseDescriptions = map[string]string{
 	EnsureFinalRestore:                     "Creating final Velero restore.",
 	FinalRestoreCreated:                    "Waiting for final Velero restore to complete.",
 	FinalRestoreFailed:                     "Migration failed during final Velero restore.",
+	RewriteWorkloadImages:                  "Rewriting target cluster workloads to the internal registry images copied by the Direct Image Migration.",
+	RewriteRegistryReferences:              "Rewriting source registry references in target cluster BuildConfigs, triggers, pull secrets and ImageStreams.",
 	Verification:                           "Verifying health of migrated Pods.",
 	Rollback:                               "Starting rollback",
+	SwapPVCReferences:                      "Swapping PVC references of source cluster workloads to the converted PVCs.",
+	RestorePVCReferences:                   "Rolling back. Restoring PVC references of source cluster workloads to the source PVCs.",
 	CreateDirectImageMigration:             "Creating Direct Image Migration",
 	CreateDirectVolumeMigration:            "Creating Direct Volume Migration",
 	WaitForDirectImageMigrationToComplete:  "Waiting for Direct Image Migration to complete.",
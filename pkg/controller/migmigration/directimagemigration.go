@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strings"
 
 	liberr "github.com/konveyor/controller/pkg/error"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -71,6 +73,9 @@ func (t *Task) buildDirectImageMigration() *migapi.DirectImageMigration {
 			SrcMigClusterRef:  t.PlanResources.MigPlan.Spec.SrcMigClusterRef,
 			DestMigClusterRef: t.PlanResources.MigPlan.Spec.DestMigClusterRef,
 			Namespaces:        t.PlanResources.MigPlan.Spec.Namespaces,
+			TagFilter:         t.PlanResources.MigPlan.Spec.ImageStreamTagFilter.DeepCopy(),
+			DestImageRegistry: t.PlanResources.MigPlan.Spec.DestImageRegistry.DeepCopy(),
+			VerifySignatures:  t.PlanResources.MigPlan.Spec.VerifyImageSignatures,
 		},
 	}
 	migapi.SetOwnerReference(t.Owner, t.Owner, dim)
@@ -113,3 +118,182 @@ func (t *Task) deleteDirectImageMigrationResources() error {
 
 	return nil
 }
+
+// Rewrite the images of the restored workloads to the images copied by the DirectImageMigration.
+// Workload images are copied into the destination namespaces of the workloads. When images are copied
+// to an image registry, the references to the images of the migrated ImageStreams are rewritten as well.
+func (t *Task) rewriteWorkloadImages() error {
+	dim, err := t.getDirectImageMigration()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if dim == nil {
+		return nil
+	}
+	registry, err := dim.GetDestinationImageRegistry(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if registry == nil && len(dim.Status.WorkloadImages) == 0 {
+		return nil
+	}
+	srcInternalRegistry, err := t.PlanResources.SrcMigCluster.GetInternalRegistryPath(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	// The destination cluster may have no internal registry when images are copied to an image registry.
+	destInternalRegistry, err := t.PlanResources.DestMigCluster.GetInternalRegistryPath(t.Client)
+	if err != nil {
+		if registry == nil {
+			return liberr.Wrap(err)
+		}
+		destInternalRegistry = ""
+	}
+	client, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	references := getWorkloadImageReferences(dim.Status.WorkloadImages,
+		srcInternalRegistry,
+		destInternalRegistry,
+		t.PlanResources.MigPlan.GetNamespaceMapping())
+	if registry != nil {
+		dismList := migapi.DirectImageStreamMigrationList{}
+		err = t.Client.List(
+			context.TODO(),
+			&dismList,
+			k8sclient.MatchingLabels(dim.GetCorrelationLabels()))
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		addImageStreamReferences(references, dismList.Items, srcInternalRegistry, destInternalRegistry)
+	}
+	for ns, nsReferences := range references {
+		t.Log.Info("Rewriting workload images to the copied images.",
+			"namespace", ns)
+		err = t.updatePodTemplates(client, ns, func(spec *v1.PodSpec) bool {
+			return replaceImages(spec, nsReferences)
+		})
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Get the references of the copied workload images, by destination namespace.
+// Restored workloads reference either the source image or, when Velero swapped the registry
+// on restore, the same repository in the destination internal registry.
+// Returns: namespace => {restored image reference => copied image reference}
+func getWorkloadImageReferences(images []*migapi.WorkloadImageListItem,
+	srcInternalRegistry, destInternalRegistry string, nsMapping map[string]string) map[string]map[string]string {
+	references := map[string]map[string]string{}
+	for _, image := range images {
+		if !image.Copied || image.DestReference == "" {
+			continue
+		}
+		if _, found := references[image.DestNamespace]; !found {
+			references[image.DestNamespace] = map[string]string{}
+		}
+		references[image.DestNamespace][image.SourceReference] = image.DestReference
+		if srcInternalRegistry == "" || destInternalRegistry == "" ||
+			!strings.HasPrefix(image.SourceReference, srcInternalRegistry+"/") {
+			continue
+		}
+		repository := strings.SplitN(strings.TrimPrefix(image.SourceReference, srcInternalRegistry+"/"), "/", 2)
+		if len(repository) != 2 {
+			continue
+		}
+		if ns, found := nsMapping[repository[0]]; found {
+			repository[0] = ns
+		}
+		swapped := destInternalRegistry + "/" + strings.Join(repository, "/")
+		references[image.DestNamespace][swapped] = image.DestReference
+	}
+	return references
+}
+
+// Add the references of the ImageStream images copied to an image registry, by destination namespace.
+// Restored workloads reference the images of an ImageStream in the source internal registry or, when
+// Velero swapped the registry on restore, in the destination internal registry, by tag or by digest.
+// Tags are rewritten only when the newest image of the tag was copied. The DISM status only lists some
+// of the copied images, the digests of the others are rewritten by the "repository@" reference, and the
+// listed images that were not copied are referenced to themselves so that they are left unchanged.
+func addImageStreamReferences(references map[string]map[string]string,
+	disms []migapi.DirectImageStreamMigration, srcInternalRegistry, destInternalRegistry string) {
+	for _, dism := range disms {
+		if dism.Spec.ImageStreamRef == nil {
+			continue
+		}
+		destNamespace := dism.GetDestinationNamespace()
+		repositories := []string{}
+		if srcInternalRegistry != "" {
+			repositories = append(repositories,
+				path.Join(srcInternalRegistry, dism.Spec.ImageStreamRef.Namespace, dism.Spec.ImageStreamRef.Name))
+		}
+		if destInternalRegistry != "" {
+			repositories = append(repositories,
+				path.Join(destInternalRegistry, destNamespace, dism.Spec.ImageStreamRef.Name))
+		}
+		heads := map[string]*migapi.ImageCopy{}
+		for _, image := range dism.Status.Images {
+			heads[image.Tag] = image
+		}
+		for _, image := range dism.Status.Images {
+			if _, found := references[destNamespace]; !found {
+				references[destNamespace] = map[string]string{}
+			}
+			if !image.IsCopied() {
+				for _, repository := range repositories {
+					references[destNamespace][repository+"@"+image.Digest] = repository + "@" + image.Digest
+				}
+				continue
+			}
+			copied := image.GetDestDigestReference()
+			for _, repository := range repositories {
+				references[destNamespace][repository+"@"+image.Digest] = copied
+				references[destNamespace][repository+"@"] = strings.TrimSuffix(copied, image.GetDestDigest())
+				if heads[image.Tag] != image {
+					continue
+				}
+				references[destNamespace][repository+":"+image.Tag] = copied
+				if image.Tag == "latest" {
+					references[destNamespace][repository] = copied
+				}
+			}
+		}
+	}
+}
+
+// Replace the images of the containers of a Pod spec.
+// Returns: whether any image was replaced.
+func replaceImages(spec *v1.PodSpec, references map[string]string) bool {
+	replaced := false
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			if image, found := getImageReference(references, containers[i].Image); found && image != containers[i].Image {
+				containers[i].Image = image
+				replaced = true
+			}
+		}
+	}
+	return replaced
+}
+
+// Get the reference replacing an image. An image referenced by a digest with no reference
+// of its own is replaced by the digest in the repository of the "repository@" reference.
+// Returns: the reference and whether found.
+func getImageReference(references map[string]string, image string) (string, bool) {
+	if reference, found := references[image]; found {
+		return reference, true
+	}
+	i := strings.LastIndex(image, "@")
+	if i == -1 {
+		return "", false
+	}
+	repository, found := references[image[:i+1]]
+	if !found {
+		return "", false
+	}
+	return repository + image[i+1:], true
+}
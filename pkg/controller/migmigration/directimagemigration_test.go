@@ -0,0 +1,195 @@
+package migmigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	srcRegistry  = "docker-registry.default.svc:5000"
+	destRegistry = "image-registry.openshift-image-registry.svc:5000"
+)
+
+func Test_getWorkloadImageReferences(t1 *testing.T) {
+	tests := []struct {
+		name      string
+		images    []*migapi.WorkloadImageListItem
+		nsMapping map[string]string
+		want      map[string]map[string]string
+	}{
+		{
+			name: "copied image is referenced by source and swapped references",
+			images: []*migapi.WorkloadImageListItem{
+				{
+					Namespace:       "app",
+					DestNamespace:   "app-new",
+					SourceReference: srcRegistry + "/shared/base:1.0",
+					DestReference:   destRegistry + "/app-new/base:1.0",
+					Copied:          true,
+				},
+			},
+			nsMapping: map[string]string{"app": "app-new"},
+			want: map[string]map[string]string{
+				"app-new": {
+					srcRegistry + "/shared/base:1.0":  destRegistry + "/app-new/base:1.0",
+					destRegistry + "/shared/base:1.0": destRegistry + "/app-new/base:1.0",
+				},
+			},
+		},
+		{
+			name: "swapped reference follows the namespace mapping",
+			images: []*migapi.WorkloadImageListItem{
+				{
+					Namespace:       "app",
+					DestNamespace:   "app-new",
+					SourceReference: srcRegistry + "/app/tool@sha256:abc",
+					DestReference:   destRegistry + "/app-new/tool@sha256:abc",
+					Copied:          true,
+				},
+			},
+			nsMapping: map[string]string{"app": "app-new"},
+			want: map[string]map[string]string{
+				"app-new": {
+					srcRegistry + "/app/tool@sha256:abc":      destRegistry + "/app-new/tool@sha256:abc",
+					destRegistry + "/app-new/tool@sha256:abc": destRegistry + "/app-new/tool@sha256:abc",
+				},
+			},
+		},
+		{
+			name: "failed images are not referenced",
+			images: []*migapi.WorkloadImageListItem{
+				{
+					Namespace:       "app",
+					DestNamespace:   "app",
+					SourceReference: srcRegistry + "/shared/base:1.0",
+					DestReference:   destRegistry + "/app/base:1.0",
+					Errors:          []string{"manifest unknown"},
+				},
+			},
+			nsMapping: map[string]string{"app": "app"},
+			want:      map[string]map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			got := getWorkloadImageReferences(tt.images, srcRegistry, destRegistry, tt.nsMapping)
+			if !reflect.DeepEqual(got, tt.want) {
+				t1.Errorf("getWorkloadImageReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_replaceImages(t1 *testing.T) {
+	references := map[string]string{
+		srcRegistry + "/shared/base:1.0": destRegistry + "/app/base:1.0",
+	}
+	tests := []struct {
+		name           string
+		spec           v1.PodSpec
+		want           bool
+		wantImages     []string
+		wantInitImages []string
+	}{
+		{
+			name: "referenced images of containers and init containers are replaced",
+			spec: v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "init", Image: srcRegistry + "/shared/base:1.0"}},
+				Containers: []v1.Container{
+					{Name: "app", Image: srcRegistry + "/shared/base:1.0"},
+					{Name: "proxy", Image: "quay.io/konveyor/proxy:latest"},
+				},
+			},
+			want:           true,
+			wantImages:     []string{destRegistry + "/app/base:1.0", "quay.io/konveyor/proxy:latest"},
+			wantInitImages: []string{destRegistry + "/app/base:1.0"},
+		},
+		{
+			name: "unreferenced images are unchanged",
+			spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "proxy", Image: "quay.io/konveyor/proxy:latest"}},
+			},
+			want:           false,
+			wantImages:     []string{"quay.io/konveyor/proxy:latest"},
+			wantInitImages: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			spec := tt.spec
+			if got := replaceImages(&spec, references); got != tt.want {
+				t1.Errorf("replaceImages() = %v, want %v", got, tt.want)
+			}
+			images := []string{}
+			for _, container := range spec.Containers {
+				images = append(images, container.Image)
+			}
+			initImages := []string{}
+			for _, container := range spec.InitContainers {
+				initImages = append(initImages, container.Image)
+			}
+			if !reflect.DeepEqual(images, tt.wantImages) {
+				t1.Errorf("replaceImages() images = %v, want %v", images, tt.wantImages)
+			}
+			if !reflect.DeepEqual(initImages, tt.wantInitImages) {
+				t1.Errorf("replaceImages() init images = %v, want %v", initImages, tt.wantInitImages)
+			}
+		})
+	}
+}
+
+func Test_addImageStreamReferences(t1 *testing.T) {
+	const registry = "quay.example.com/migration"
+	dism := migapi.DirectImageStreamMigration{
+		Spec: migapi.DirectImageStreamMigrationSpec{
+			ImageStreamRef: &v1.ObjectReference{Namespace: "app", Name: "web"},
+			DestNamespace:  "app-new",
+		},
+		Status: migapi.DirectImageStreamMigrationStatus{
+			Images: []*migapi.ImageCopy{
+				{Tag: "latest", Digest: "sha256:old", DestReference: registry + "/app-new/web:latest", Phase: migapi.ImageCopyCopied,
+					CopiedDigest: "sha256:converted"},
+				{Tag: "latest", Digest: "sha256:new", DestReference: registry + "/app-new/web:latest", Phase: migapi.ImageCopySkippedExisting},
+				{Tag: "v2", Digest: "sha256:v2", DestReference: registry + "/app-new/web:v2", Phase: migapi.ImageCopyFailed},
+			},
+		},
+	}
+	want := map[string]map[string]string{
+		"app-new": {
+			srcRegistry + "/app/web@sha256:old":      registry + "/app-new/web@sha256:converted",
+			destRegistry + "/app-new/web@sha256:old": registry + "/app-new/web@sha256:converted",
+			srcRegistry + "/app/web@":                registry + "/app-new/web@",
+			destRegistry + "/app-new/web@":           registry + "/app-new/web@",
+			srcRegistry + "/app/web@sha256:v2":       srcRegistry + "/app/web@sha256:v2",
+			destRegistry + "/app-new/web@sha256:v2":  destRegistry + "/app-new/web@sha256:v2",
+			srcRegistry + "/app/web@sha256:new":      registry + "/app-new/web@sha256:new",
+			srcRegistry + "/app/web:latest":          registry + "/app-new/web@sha256:new",
+			srcRegistry + "/app/web":                 registry + "/app-new/web@sha256:new",
+			destRegistry + "/app-new/web@sha256:new": registry + "/app-new/web@sha256:new",
+			destRegistry + "/app-new/web:latest":     registry + "/app-new/web@sha256:new",
+			destRegistry + "/app-new/web":            registry + "/app-new/web@sha256:new",
+		},
+	}
+	got := map[string]map[string]string{}
+	addImageStreamReferences(got, []migapi.DirectImageStreamMigration{dism}, srcRegistry, destRegistry)
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("addImageStreamReferences() = %v, want %v", got, want)
+	}
+	images := map[string]string{
+		srcRegistry + "/app/web@sha256:history":  registry + "/app-new/web@sha256:history",
+		srcRegistry + "/app/web@sha256:v2":       srcRegistry + "/app/web@sha256:v2",
+		srcRegistry + "/app/web@sha256:old":      registry + "/app-new/web@sha256:converted",
+		srcRegistry + "/app/other@sha256:old":    "",
+		destRegistry + "/app-new/web:v2":         "",
+		destRegistry + "/app-new/web@sha256:abc": registry + "/app-new/web@sha256:abc",
+	}
+	for image, wantReference := range images {
+		reference, found := getImageReference(got["app-new"], image)
+		if found != (wantReference != "") || reference != wantReference {
+			t1.Errorf("getImageReference(%s) = %s, %v, want %s", image, reference, found, wantReference)
+		}
+	}
+}
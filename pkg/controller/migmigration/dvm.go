@@ -26,6 +26,16 @@ func (t *Task) createDirectVolumeMigration() error {
 	if existingDvm != nil {
 		return nil
 	}
+	// Adopt the DVM replicating the volumes of the plan
+	if t.PlanResources.MigPlan.Spec.VolumeReplication != nil {
+		replicatingDvm, err := t.getReplicatingDirectVolumeMigration()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if replicatingDvm != nil {
+			return t.adoptDirectVolumeMigration(replicatingDvm)
+		}
+	}
 	t.Log.Info("Building DirectVolumeMigration resource definition")
 	dvm := t.buildDirectVolumeMigration()
 	if dvm == nil {
@@ -57,12 +67,64 @@ func (t *Task) buildDirectVolumeMigration() *migapi.DirectVolumeMigration {
 			DestMigClusterRef:           t.PlanResources.DestMigCluster.GetObjectReference(),
 			PersistentVolumeClaims:      *pvcList,
 			CreateDestinationNamespaces: true,
+			TransferPodPolicy:           t.PlanResources.MigPlan.Spec.TransferPodPolicy.DeepCopy(),
+			TransferMethod:              t.PlanResources.MigPlan.Spec.DirectVolumeTransferMethod,
+			BandwidthSchedule:           t.PlanResources.MigPlan.Spec.BandwidthSchedule.DeepCopy(),
+			OwnershipPolicy:             t.PlanResources.MigPlan.Spec.OwnershipPolicy,
+			SourceQuiesced:              !t.stage() && t.quiesce(),
 		},
 	}
+	// Stage migrations start continuous replication when enabled on the plan
+	if replication := t.PlanResources.MigPlan.Spec.VolumeReplication; replication != nil && t.stage() {
+		dvm.Labels[migapi.MigPlanLabel] = string(t.PlanResources.MigPlan.UID)
+		dvm.Spec.Continuous = true
+		dvm.Spec.ReplicationIntervalSeconds = replication.IntervalSeconds
+	}
 	migapi.SetOwnerReference(t.Owner, t.Owner, dvm)
 	return dvm
 }
 
+// Get the DVM continuously replicating the volumes of the plan.
+func (t *Task) getReplicatingDirectVolumeMigration() (*migapi.DirectVolumeMigration, error) {
+	list := migapi.DirectVolumeMigrationList{}
+	err := t.Client.List(
+		context.TODO(),
+		&list,
+		k8sclient.MatchingLabels{
+			migapi.MigPlanLabel: string(t.PlanResources.MigPlan.UID),
+		})
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range list.Items {
+		dvm := &list.Items[i]
+		if !dvm.Spec.Continuous || dvm.Spec.Cutover || dvm.HasErrors() ||
+			dvm.Status.Phase == dvmc.Completed || dvm.DeletionTimestamp != nil {
+			continue
+		}
+		return dvm, nil
+	}
+	return nil, nil
+}
+
+// Take ownership of a DVM created by a previous migration.
+// A final migration requests the cutover, the final Rsync pass
+// runs after the applications have been quiesced.
+func (t *Task) adoptDirectVolumeMigration(dvm *migapi.DirectVolumeMigration) error {
+	key, value := t.Owner.GetCorrelationLabel()
+	dvm.Labels[key] = value
+	dvm.Labels[migapi.DirectVolumeMigrationLabel] = t.UID()
+	migapi.SetOwnerReference(t.Owner, t.Owner, dvm)
+	if !t.stage() {
+		dvm.Spec.Cutover = true
+		dvm.Spec.SourceQuiesced = t.quiesce()
+	}
+	t.Log.Info("Adopting DirectVolumeMigration replicating the volumes of the MigPlan",
+		"directVolumeMigration", path.Join(dvm.Namespace, dvm.Name),
+		"cutover", dvm.Spec.Cutover)
+	return t.Client.Update(context.TODO(), dvm)
+}
+
 func (t *Task) getDirectVolumeMigration() (*migapi.DirectVolumeMigration, error) {
 	// Get correlation labels
 	labels := t.Owner.GetCorrelationLabels()
@@ -107,6 +169,9 @@ func (t *Task) hasDirectVolumeMigrationCompleted(dvm *migapi.DirectVolumeMigrati
 	case dvm.Status.Phase == dvmc.Completed && dvm.Status.Itinerary == "VolumeMigration" && dvm.Status.HasCondition(dvmc.Succeeded):
 		// completed successfully
 		completed = true
+	case dvm.Spec.Continuous && !dvm.Spec.Cutover && dvm.Status.Phase == dvmc.WaitForNextRsyncPass:
+		// initial pass completed, replication continues until cutover
+		completed = true
 	case (dvm.Status.Phase == dvmc.MigrationFailed || dvm.Status.Phase == dvmc.Completed) && dvm.Status.HasCondition(dvmc.Failed):
 		failureReasons = append(failureReasons, fmt.Sprintf("direct volume migration failed. %s", volumeProgress))
 		completed = true
@@ -192,6 +257,9 @@ func (t *Task) getDVMPodProgress(dvm migapi.DirectVolumeMigration) []string {
 			if pod.TotalElapsedTime != nil {
 				p += fmt.Sprintf(" (%s)", pod.TotalElapsedTime.Duration.Round(time.Second))
 			}
+			if pod.Stats != nil && pod.Stats.EstimatedTimeRemaining != nil {
+				p += fmt.Sprintf(" (ETA %s)", pod.Stats.EstimatedTimeRemaining.Duration.Round(time.Second))
+			}
 			progress = append(progress, p)
 		}
 	}
@@ -202,24 +270,40 @@ func (t *Task) getDirectVolumeClaimList() *[]migapi.PVCToMigrate {
 	nsMapping := t.PlanResources.MigPlan.GetNamespaceMapping()
 	pvcList := []migapi.PVCToMigrate{}
 	for _, pv := range t.PlanResources.MigPlan.Spec.PersistentVolumes.List {
-		if pv.Selection.Action != migapi.PvCopyAction || pv.Selection.CopyMethod != migapi.PvFilesystemCopyMethod {
+		if pv.Selection.Action != migapi.PvCopyAction ||
+			(pv.Selection.CopyMethod != migapi.PvFilesystemCopyMethod && !pv.IsDirectSnapshotCopy()) {
 			continue
 		}
+		// CSI snapshot copies are copied from a clone of a snapshot of the source volume
+		volumeSnapshotClass := ""
+		if pv.IsDirectSnapshotCopy() {
+			volumeSnapshotClass = pv.VolumeSnapshotClass
+		}
 		accessModes := pv.PVC.AccessModes
 		// if the user overrides access modes, set up the destination PVC with user-defined
 		// access mode
 		if pv.Selection.AccessMode != "" {
 			accessModes = []kapi.PersistentVolumeAccessMode{pv.Selection.AccessMode}
 		}
+		// a storage conversion copies the PVC to a PVC of the new storage class in the same namespace
+		targetName := ""
+		if t.PlanResources.MigPlan.IsStorageConversion() {
+			targetName = pv.GetConversionTargetName()
+		}
 		pvcList = append(pvcList, migapi.PVCToMigrate{
 			ObjectReference: &kapi.ObjectReference{
 				Name:      pv.PVC.Name,
 				Namespace: pv.PVC.Namespace,
 			},
-			TargetStorageClass: pv.Selection.StorageClass,
-			TargetAccessModes:  accessModes,
-			TargetNamespace:    nsMapping[pv.PVC.Namespace],
-			Verify:             pv.Selection.Verify,
+			TargetStorageClass:  pv.Selection.StorageClass,
+			TargetAccessModes:   accessModes,
+			TargetNamespace:     nsMapping[pv.PVC.Namespace],
+			Verify:              pv.Selection.Verify,
+			VolumeMode:          pv.PVC.VolumeMode,
+			VolumeSnapshotClass: volumeSnapshotClass,
+			Filters:             pv.Selection.Filters.DeepCopy(),
+			AdoptExisting:       pv.Selection.AdoptExisting,
+			TargetName:          targetName,
 		})
 	}
 	if len(pvcList) > 0 {
@@ -236,6 +320,14 @@ func (t *Task) deleteDirectVolumeMigrationResources() error {
 		return liberr.Wrap(err)
 	}
 
+	// delete the DVM replicating the volumes of the plan
+	if dvm == nil && t.PlanResources.MigPlan.Spec.VolumeReplication != nil {
+		dvm, err = t.getReplicatingDirectVolumeMigration()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+
 	if dvm != nil {
 		// delete the DVM instance
 		t.Log.Info("Deleting DirectVolumeMigration on host cluster "+
@@ -143,6 +143,37 @@ func TestTask_hasDirectVolumeMigrationCompleted(t1 *testing.T) {
 			wantFailureReasons: nil,
 			wantCompleted:      true,
 		},
+		{
+			name: "continuous replication waiting for the next pass",
+			args: args{dvm: &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{
+					Continuous: true,
+				},
+				Status: migapi.DirectVolumeMigrationStatus{
+					Itinerary: dvmc.VolumeMigration.Name,
+					Phase:     dvmc.WaitForNextRsyncPass,
+				},
+			}},
+			wantProgress:       nil,
+			wantFailureReasons: nil,
+			wantCompleted:      true,
+		},
+		{
+			name: "continuous replication after cutover",
+			args: args{dvm: &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{
+					Continuous: true,
+					Cutover:    true,
+				},
+				Status: migapi.DirectVolumeMigrationStatus{
+					Itinerary: dvmc.VolumeMigration.Name,
+					Phase:     dvmc.WaitForNextRsyncPass,
+				},
+			}},
+			wantProgress:       []string{"0 total volumes; 0 successful; 0 running; 0 failed"},
+			wantFailureReasons: nil,
+			wantCompleted:      false,
+		},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
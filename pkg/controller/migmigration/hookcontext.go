@@ -0,0 +1,185 @@
+package migmigration
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Hook context document.
+const (
+	HookContextKey       = "context.json"
+	HookContextVolume    = "migration-context"
+	HookContextMountPath = "/tmp/migration"
+)
+
+// Migration types.
+const (
+	StageMigrationType    = "stage"
+	FinalMigrationType    = "final"
+	RollbackMigrationType = "rollback"
+)
+
+// Migration context passed to hooks.
+type HookContext struct {
+	Plan               HookContextObject    `json:"plan"`
+	Migration          HookContextMigration `json:"migration"`
+	Phase              string               `json:"phase"`
+	Namespaces         []string             `json:"namespaces"`
+	NamespaceMapping   map[string]string    `json:"namespaceMapping"`
+	SourceCluster      HookContextCluster   `json:"sourceCluster"`
+	DestinationCluster HookContextCluster   `json:"destinationCluster"`
+	PersistentVolumes  []HookContextPV      `json:"persistentVolumes"`
+}
+
+// Object reference within the hook context.
+type HookContextObject struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Migration within the hook context.
+type HookContextMigration struct {
+	HookContextObject
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+}
+
+// Cluster within the hook context.
+type HookContextCluster struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// PV within the hook context.
+type HookContextPV struct {
+	Name         string           `json:"name"`
+	PVC          migapi.PVC       `json:"pvc"`
+	StorageClass string           `json:"storageClass,omitempty"`
+	Capacity     string           `json:"capacity,omitempty"`
+	Selection    migapi.Selection `json:"selection"`
+}
+
+// Build the hook context for the specified hook phase.
+func (t *Task) getHookContext(phase string) *HookContext {
+	plan := t.PlanResources.MigPlan
+	ctx := &HookContext{
+		Plan: HookContextObject{
+			Namespace: plan.Namespace,
+			Name:      plan.Name,
+		},
+		Migration: HookContextMigration{
+			HookContextObject: HookContextObject{
+				Namespace: t.Owner.Namespace,
+				Name:      t.Owner.Name,
+			},
+			UID:  string(t.Owner.UID),
+			Type: t.migrationType(),
+		},
+		Phase:             phase,
+		Namespaces:        t.sourceNamespaces(),
+		NamespaceMapping:  plan.GetNamespaceMapping(),
+		PersistentVolumes: []HookContextPV{},
+	}
+	if cluster := t.PlanResources.SrcMigCluster; cluster != nil {
+		ctx.SourceCluster = HookContextCluster{
+			Name: cluster.Name,
+			URL:  cluster.Spec.URL,
+		}
+	}
+	if cluster := t.PlanResources.DestMigCluster; cluster != nil {
+		ctx.DestinationCluster = HookContextCluster{
+			Name: cluster.Name,
+			URL:  cluster.Spec.URL,
+		}
+	}
+	for _, pv := range plan.Spec.PersistentVolumes.List {
+		ctx.PersistentVolumes = append(
+			ctx.PersistentVolumes,
+			HookContextPV{
+				Name:         pv.Name,
+				PVC:          pv.PVC,
+				StorageClass: pv.StorageClass,
+				Capacity:     pv.Capacity.String(),
+				Selection:    pv.Selection,
+			})
+	}
+
+	return ctx
+}
+
+// Get the migration type.
+func (t *Task) migrationType() string {
+	switch {
+	case t.rollback():
+		return RollbackMigrationType
+	case t.stage():
+		return StageMigrationType
+	default:
+		return FinalMigrationType
+	}
+}
+
+// Render the hook context as a JSON document.
+func (r *HookContext) JSON() (string, error) {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return string(content), nil
+}
+
+// Get the environment variables for the common hook context fields.
+func (r *HookContext) EnvVars() []corev1.EnvVar {
+	mapping := []string{}
+	for src, dest := range r.NamespaceMapping {
+		mapping = append(mapping, src+":"+dest)
+	}
+	sort.Strings(mapping)
+	return []corev1.EnvVar{
+		{
+			Name:  "MIGRATION_CONTEXT",
+			Value: HookContextMountPath + "/" + HookContextKey,
+		},
+		{
+			Name:  "MIGRATION_NAME",
+			Value: r.Migration.Name,
+		},
+		{
+			Name:  "MIGRATION_UID",
+			Value: r.Migration.UID,
+		},
+		{
+			Name:  "MIGRATION_TYPE",
+			Value: r.Migration.Type,
+		},
+		{
+			Name:  "MIGRATION_HOOK_PHASE",
+			Value: r.Phase,
+		},
+		{
+			Name:  "MIGRATION_NAMESPACE_MAPPING",
+			Value: strings.Join(mapping, ","),
+		},
+		{
+			Name:  "MIGRATION_SOURCE_CLUSTER",
+			Value: r.SourceCluster.Name,
+		},
+		{
+			Name:  "MIGRATION_SOURCE_CLUSTER_URL",
+			Value: r.SourceCluster.URL,
+		},
+		{
+			Name:  "MIGRATION_DESTINATION_CLUSTER",
+			Value: r.DestinationCluster.Name,
+		},
+		{
+			Name:  "MIGRATION_DESTINATION_CLUSTER_URL",
+			Value: r.DestinationCluster.URL,
+		},
+	}
+}
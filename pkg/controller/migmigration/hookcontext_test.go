@@ -0,0 +1,112 @@
+package migmigration
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_getHookContext(t1 *testing.T) {
+	tests := []struct {
+		name      string
+		migration migapi.MigMigration
+		wantType  string
+	}{
+		{
+			name: "final migration",
+			migration: migapi.MigMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "openshift-migration", UID: "uid-1"},
+			},
+			wantType: FinalMigrationType,
+		},
+		{
+			name: "stage migration",
+			migration: migapi.MigMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "openshift-migration", UID: "uid-1"},
+				Spec:       migapi.MigMigrationSpec{Stage: true},
+			},
+			wantType: StageMigrationType,
+		},
+		{
+			name: "rollback migration",
+			migration: migapi.MigMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "openshift-migration", UID: "uid-1"},
+				Spec:       migapi.MigMigrationSpec{Rollback: true},
+			},
+			wantType: RollbackMigrationType,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &tt.migration,
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{
+						ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "openshift-migration"},
+						Spec: migapi.MigPlanSpec{
+							Namespaces: []string{"ns-1", "ns-2:ns-3"},
+							PersistentVolumes: migapi.PersistentVolumes{List: []migapi.PV{
+								{
+									Name: "pv-0",
+									PVC:  migapi.PVC{Namespace: "ns-1", Name: "pvc-0"},
+									Selection: migapi.Selection{
+										Action: migapi.PvCopyAction,
+									},
+								},
+							}},
+						},
+					},
+					SrcMigCluster: &migapi.MigCluster{
+						ObjectMeta: metav1.ObjectMeta{Name: "source"},
+						Spec:       migapi.MigClusterSpec{URL: "https://source:6443"},
+					},
+					DestMigCluster: &migapi.MigCluster{
+						ObjectMeta: metav1.ObjectMeta{Name: "host"},
+						Spec:       migapi.MigClusterSpec{IsHostCluster: true},
+					},
+				},
+			}
+			got := t.getHookContext(migapi.PreRestoreHookPhase)
+			if got.Migration.Type != tt.wantType {
+				t1.Errorf("getHookContext() type = %v, want %v", got.Migration.Type, tt.wantType)
+			}
+			wantMapping := map[string]string{"ns-1": "ns-1", "ns-2": "ns-3"}
+			if !reflect.DeepEqual(got.NamespaceMapping, wantMapping) {
+				t1.Errorf("getHookContext() mapping = %v, want %v", got.NamespaceMapping, wantMapping)
+			}
+			if !reflect.DeepEqual(got.Namespaces, []string{"ns-1", "ns-2"}) {
+				t1.Errorf("getHookContext() namespaces = %v", got.Namespaces)
+			}
+			if got.SourceCluster.URL != "https://source:6443" || got.DestinationCluster.Name != "host" {
+				t1.Errorf("getHookContext() clusters = %v, %v", got.SourceCluster, got.DestinationCluster)
+			}
+			if len(got.PersistentVolumes) != 1 || got.PersistentVolumes[0].Selection.Action != migapi.PvCopyAction {
+				t1.Errorf("getHookContext() pvs = %v", got.PersistentVolumes)
+			}
+			content, err := got.JSON()
+			if err != nil {
+				t1.Errorf("JSON() unexpected error = %v", err)
+				return
+			}
+			decoded := HookContext{}
+			err = json.Unmarshal([]byte(content), &decoded)
+			if err != nil {
+				t1.Errorf("JSON() invalid document = %v", err)
+				return
+			}
+			if decoded.Migration.UID != "uid-1" || decoded.Migration.Name != "migration" {
+				t1.Errorf("JSON() migration = %v", decoded.Migration)
+			}
+			env := map[string]string{}
+			for _, envVar := range got.EnvVars() {
+				env[envVar.Name] = envVar.Value
+			}
+			if env["MIGRATION_NAMESPACE_MAPPING"] != "ns-1:ns-1,ns-2:ns-3" {
+				t1.Errorf("EnvVars() mapping = %v", env["MIGRATION_NAMESPACE_MAPPING"])
+			}
+		})
+	}
+}
@@ -6,14 +6,17 @@ import (
 	"fmt"
 	"path"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 
 	liberr "github.com/konveyor/controller/pkg/error"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	migevent "github.com/konveyor/mig-controller/pkg/event"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -21,9 +24,32 @@ import (
 const HookJobFailedLimit = 6
 const BackoffLimitExceededError = "BackoffLimitExceeded"
 
+// Hook retries.
+const (
+	HookRetryBackoffBase = 10 * time.Second
+	HookRetryBackoffMax  = 5 * time.Minute
+)
+
+// Captured hook output.
+const (
+	HookLogTailLines  = 20
+	HookLogLimitBytes = 4096
+)
+
+// Label set on a rollback migration created by a failed hook.
+const RollbackForLabel = "migration.openshift.io/rollback-for"
+
+// HookFailedError is returned when the hook itself failed.
+type HookFailedError struct {
+	Message string
+}
+
+func (e *HookFailedError) Error() string {
+	return e.Message
+}
+
 func (t *Task) runHooks(hookPhase string) (bool, error) {
 	hook := migapi.MigPlanHook{}
-	var client k8sclient.Client
 	var err error
 
 	for _, h := range t.PlanResources.MigPlan.Spec.Hooks {
@@ -35,6 +61,12 @@ func (t *Task) runHooks(hookPhase string) (bool, error) {
 	migHook := migapi.MigHook{}
 
 	if hook.Reference != nil {
+		hookStatus := t.getHookStatus(hook)
+		switch hookStatus.Status {
+		case migapi.HookSucceeded, migapi.HookIgnored:
+			return true, nil
+		}
+
 		t.Log.Info("Found MigHook ref attached for phase, starting hook job.",
 			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
 			"migHookPhase", hookPhase)
@@ -49,46 +81,136 @@ func (t *Task) runHooks(hookPhase string) (bool, error) {
 			return false, liberr.Wrap(err)
 		}
 
-		t.Log.Info("Getting k8s client for MigHook",
-			"migHook", path.Join(migHook.Namespace, migHook.Name))
-		client, err = t.getHookClient(migHook)
-		if err != nil {
-			return false, liberr.Wrap(err)
-		}
-
-		svc := corev1.ServiceAccount{}
-		ref := types.NamespacedName{
-			Namespace: hook.ExecutionNamespace,
-			Name:      hook.ServiceAccount,
+		var result bool
+		if migHook.IsWebhook() {
+			result, err = t.runWebhook(hook, migHook)
+		} else {
+			result, err = t.runHookJob(hook, migHook)
 		}
-		t.Log.Info("Getting executor ServiceAccount for MigHook ",
-			"serviceAccount", path.Join(svc.Namespace, svc.Name),
-			"migHook", path.Join(migHook.Namespace, migHook.Name))
-		err = client.Get(context.TODO(), ref, &svc)
 		if err != nil {
+			if failure, cast := err.(*HookFailedError); cast {
+				return t.applyHookFailurePolicy(hook, hookStatus, failure)
+			}
 			return false, liberr.Wrap(err)
 		}
 
-		t.Log.Info("Building Job resource definition for MigHook",
-			"migHook", path.Join(migHook.Namespace, migHook.Name))
-		job, err := t.prepareJob(hook, migHook, client)
-		if err != nil {
-			return false, liberr.Wrap(err)
-		}
+		return result, nil
+	}
+	t.Log.Info("No hook attached to MigPlan for HookPhase, continuing.",
+		"hookPhase", hookPhase)
+	return true, nil
+}
+
+func (t *Task) runHookJob(hook migapi.MigPlanHook, migHook migapi.MigHook) (bool, error) {
+	t.Log.Info("Getting k8s client for MigHook",
+		"migHook", path.Join(migHook.Namespace, migHook.Name))
+	client, err := t.getHookClient(migHook)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+
+	svc := corev1.ServiceAccount{}
+	ref := types.NamespacedName{
+		Namespace: hook.ExecutionNamespace,
+		Name:      hook.ServiceAccount,
+	}
+	t.Log.Info("Getting executor ServiceAccount for MigHook ",
+		"serviceAccount", path.Join(svc.Namespace, svc.Name),
+		"migHook", path.Join(migHook.Namespace, migHook.Name))
+	err = client.Get(context.TODO(), ref, &svc)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+
+	t.Log.Info("Building Job resource definition for MigHook",
+		"migHook", path.Join(migHook.Namespace, migHook.Name))
+	job, err := t.prepareJob(hook, migHook, client)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+
+	t.Log.Info("Creating Job for MigHook",
+		"job", path.Join(job.Namespace, job.Name),
+		"migHook", migHook.Namespace, migHook.Name)
+	return t.ensureJob(job, hook, migHook, client)
+}
 
-		t.Log.Info("Creating Job for MigHook",
-			"job", path.Join(job.Namespace, job.Name),
-			"migHook", migHook.Namespace, migHook.Name)
-		result, err := t.ensureJob(job, hook, migHook, client)
+// Apply the failure policy of a failed hook.
+// Returns true when the migration should continue.
+func (t *Task) applyHookFailurePolicy(hook migapi.MigPlanHook, hookStatus *migapi.HookStatus, failure *HookFailedError) (bool, error) {
+	policy := hook.GetFailurePolicy()
+	t.Log.Info("Hook failed, applying failure policy.",
+		"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
+		"migHookPhase", hook.Phase,
+		"failurePolicy", policy)
+	switch policy {
+	case migapi.HookFailurePolicyIgnore:
+		hookStatus.Status = migapi.HookIgnored
+		hookStatus.Message = failure.Error() + " Failure ignored."
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     HookFailureIgnored,
+			Status:   True,
+			Reason:   hook.Phase,
+			Category: migapi.Warn,
+			Message:  fmt.Sprintf("The %s hook failed and the failure was ignored.", hook.Phase),
+			Durable:  true,
+		})
+		return true, nil
+	case migapi.HookFailurePolicyRollback:
+		hookStatus.Status = migapi.HookFailed
+		err := t.ensureRollbackMigration()
 		if err != nil {
 			return false, liberr.Wrap(err)
 		}
+		hookStatus.Message = failure.Error() + " Rollback requested."
+		return false, failure
+	default:
+		hookStatus.Status = migapi.HookFailed
+		hookStatus.Message = failure.Error()
+		return false, failure
+	}
+}
 
-		return result, nil
+// Create a rollback migration for the plan.
+// The name is derived from the failed migration so only one is created.
+func (t *Task) ensureRollbackMigration() error {
+	name := t.Owner.Name + "-rollback"
+	if len(name) > 253 {
+		name = name[:253]
 	}
-	t.Log.Info("No hook attached to MigPlan for HookPhase, continuing.",
-		"hookPhase", hookPhase)
-	return true, nil
+	migration := &migapi.MigMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: t.Owner.Namespace,
+			Name:      name,
+			Labels: map[string]string{
+				RollbackForLabel: string(t.Owner.UID),
+			},
+		},
+		Spec: migapi.MigMigrationSpec{
+			MigPlanRef: t.Owner.Spec.MigPlanRef,
+			Rollback:   true,
+		},
+	}
+	err := t.Client.Create(context.TODO(), migration)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return liberr.Wrap(err)
+	}
+	t.Log.Info("Created rollback migration for failed hook.",
+		"migMigration", path.Join(migration.Namespace, migration.Name))
+	return nil
+}
+
+// Find or add the hook status for the hook phase.
+func (t *Task) getHookStatus(hook migapi.MigPlanHook) *migapi.HookStatus {
+	hookStatus := t.Owner.Status.FindHook(hook.Phase)
+	if hookStatus == nil {
+		hookStatus = &migapi.HookStatus{
+			Phase:     hook.Phase,
+			Reference: hook.Reference,
+		}
+		t.Owner.Status.Hooks = append(t.Owner.Status.Hooks, hookStatus)
+	}
+	return hookStatus
 }
 
 func (t *Task) stopHookJobs() (bool, error) {
@@ -97,11 +219,11 @@ func (t *Task) stopHookJobs() (bool, error) {
 
 	migHook := migapi.MigHook{}
 	for _, hook := range t.PlanResources.MigPlan.Spec.Hooks {
-		t.Log.Info("Found MigHook ref, stopping hook job(s).",
-			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
 		if hook.Reference == nil {
 			continue
 		}
+		t.Log.Info("Found MigHook ref, stopping hook job(s).",
+			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
 
 		t.Log.Info("Getting MigHook",
 			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
@@ -116,6 +238,11 @@ func (t *Task) stopHookJobs() (bool, error) {
 			return false, liberr.Wrap(err)
 		}
 
+		// Webhooks do not run a job.
+		if migHook.IsWebhook() {
+			continue
+		}
+
 		t.Log.Info("Getting k8s client for MigHook",
 			"migHook", path.Join(migHook.Namespace, migHook.Name))
 		client, err = t.getHookClient(migHook)
@@ -153,7 +280,11 @@ func (t *Task) stopHookJobs() (bool, error) {
 }
 
 func (t *Task) ensureJob(job *batchv1.Job, hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (bool, error) {
+	hookStatus := t.getHookStatus(hook)
 	runningJob, err := migHook.GetPhaseJob(client, hook.Phase, string(t.Owner.UID))
+	if err != nil {
+		return false, err
+	}
 	if runningJob != nil {
 		// Logs abnormal events for Hook Jobs if any are found
 		migevent.LogAbnormalEventsForResource(
@@ -163,57 +294,181 @@ func (t *Task) ensureJob(job *batchv1.Job, hook migapi.MigPlanHook, migHook miga
 			runningJob.UID, "Job")
 	}
 
-	if runningJob == nil && err == nil {
+	switch {
+	case runningJob == nil:
+		// Wait for the backoff before retrying a failed hook.
+		if hookStatus.Attempts > 0 && hookStatus.LastAttemptTime != nil {
+			wait := HookRetryBackoff(hookStatus.Attempts) - time.Since(hookStatus.LastAttemptTime.Time)
+			if wait > 0 {
+				t.setProgress([]string{
+					fmt.Sprintf("Hook %s: Retrying in %s", hook.Phase, wait.Round(time.Second))})
+				return false, nil
+			}
+		}
 		err = client.Create(context.TODO(), job)
 		if err != nil {
 			return false, err
 		}
+		now := metav1.Now()
+		hookStatus.Attempts++
+		hookStatus.LastAttemptTime = &now
+		hookStatus.Status = migapi.HookRunning
+		hookStatus.Job = &corev1.ObjectReference{Namespace: job.Namespace, Name: job.Name}
+		hookStatus.ExitCode = nil
+		hookStatus.Log = ""
+		hookStatus.Message = ""
 		return false, nil
-	} else if err != nil {
-		return false, err
-	} else if runningJob.Status.Failed >= HookJobFailedLimit {
-		err := fmt.Errorf("Hook job %s failed.", runningJob.Name)
+	case runningJob.DeletionTimestamp != nil:
 		t.setProgress([]string{
-			fmt.Sprintf("Job %s/%s: Failed", runningJob.Namespace, runningJob.Name)})
-		return false, err
-	} else if len(runningJob.Status.Conditions) > 0 && runningJob.Status.Conditions[0].Reason == BackoffLimitExceededError {
-		err := fmt.Errorf("Hook job %s failed.", runningJob.Name)
+			fmt.Sprintf("Job %s/%s: Deleting", runningJob.Namespace, runningJob.Name)})
+		return false, nil
+	case runningJob.Status.Failed >= HookJobFailedLimit,
+		len(runningJob.Status.Conditions) > 0 && runningJob.Status.Conditions[0].Reason == BackoffLimitExceededError:
+		t.captureHookOutput(hookStatus, runningJob, migHook, client)
+		if hookStatus.Attempts <= hook.Retries {
+			hookStatus.Status = migapi.HookRetrying
+			hookStatus.Message = fmt.Sprintf("Hook job %s failed, attempt %d of %d.",
+				runningJob.Name, hookStatus.Attempts, hook.Retries+1)
+			t.setProgress([]string{
+				fmt.Sprintf("Job %s/%s: Failed, retrying", runningJob.Namespace, runningJob.Name)})
+			err = client.Delete(context.TODO(), runningJob,
+				k8sclient.PropagationPolicy(metav1.DeletePropagationForeground))
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return false, err
+			}
+			return false, nil
+		}
 		t.setProgress([]string{
 			fmt.Sprintf("Job %s/%s: Failed", runningJob.Namespace, runningJob.Name)})
-		return false, err
-	} else if runningJob.Status.Succeeded == 1 {
+		return false, &HookFailedError{
+			Message: fmt.Sprintf("Hook job %s failed.", runningJob.Name),
+		}
+	case runningJob.Status.Succeeded == 1:
+		t.captureHookOutput(hookStatus, runningJob, migHook, client)
+		hookStatus.Status = migapi.HookSucceeded
 		t.setProgress([]string{
 			fmt.Sprintf("Job %s/%s: Succeeded", runningJob.Namespace, runningJob.Name)})
 		return true, nil
-	} else {
+	default:
 		t.setProgress([]string{
 			fmt.Sprintf("Job %s/%s: Running", runningJob.Namespace, runningJob.Name)})
 		return false, nil
 	}
 }
 
-func (t *Task) prepareJob(hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (*batchv1.Job, error) {
-	job := &batchv1.Job{}
+// Record the exit code and the tail of the log of the most recent hook pod.
+// Failures to collect the output are logged and otherwise ignored.
+func (t *Task) captureHookOutput(hookStatus *migapi.HookStatus, job *batchv1.Job, migHook migapi.MigHook, client k8sclient.Client) {
+	hookStatus.Job = &corev1.ObjectReference{Namespace: job.Namespace, Name: job.Name}
+	podList := corev1.PodList{}
+	err := client.List(
+		context.TODO(),
+		&podList,
+		k8sclient.InNamespace(job.Namespace),
+		k8sclient.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		t.Log.Info("Unable to list hook pods.", "job", path.Join(job.Namespace, job.Name), "error", err.Error())
+		return
+	}
+	var pod *corev1.Pod
+	for i := range podList.Items {
+		candidate := &podList.Items[i]
+		if pod == nil || pod.CreationTimestamp.Before(&candidate.CreationTimestamp) {
+			pod = candidate
+		}
+	}
+	if pod == nil || len(pod.Status.ContainerStatuses) == 0 {
+		return
+	}
+	containerStatus := pod.Status.ContainerStatuses[0]
+	previous := false
+	if terminated := containerStatus.State.Terminated; terminated != nil {
+		hookStatus.ExitCode = &terminated.ExitCode
+	} else if terminated := containerStatus.LastTerminationState.Terminated; terminated != nil {
+		hookStatus.ExitCode = &terminated.ExitCode
+		previous = true
+	}
+	cluster := t.getHookCluster(migHook)
+	if cluster == nil {
+		return
+	}
+	podLog, err := getHookPodLog(t.Client, cluster, pod, containerStatus.Name, previous)
+	if err != nil {
+		t.Log.Info("Unable to get hook pod log.", "pod", path.Join(pod.Namespace, pod.Name), "error", err.Error())
+		return
+	}
+	hookStatus.Log = podLog
+}
 
-	if migHook.Spec.Custom {
-		job = t.baseJobTemplate(hook, migHook)
-	} else {
+// Get the tail of the log of a hook pod.
+func getHookPodLog(client k8sclient.Client, cluster *migapi.MigCluster, pod *corev1.Pod, container string, previous bool) (string, error) {
+	config, err := cluster.BuildRestConfig(client)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	tailLines := int64(HookLogTailLines)
+	limitBytes := int64(HookLogLimitBytes)
+	content, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container:  container,
+		Previous:   previous,
+		TailLines:  &tailLines,
+		LimitBytes: &limitBytes,
+	}).DoRaw(context.TODO())
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return string(content), nil
+}
 
-		configMap, err := t.configMapTemplate(hook, migHook)
-		if err != nil {
-			return nil, err
-		}
+// Get the cluster on which the hook is executed.
+func (t *Task) getHookCluster(migHook migapi.MigHook) *migapi.MigCluster {
+	switch migHook.Spec.TargetCluster {
+	case "source":
+		return t.PlanResources.SrcMigCluster
+	case "destination":
+		return t.PlanResources.DestMigCluster
+	}
+	return nil
+}
 
-		phaseConfigMap, err := migHook.GetPhaseConfigMap(client, hook.Phase, string(t.Owner.UID))
-		if phaseConfigMap == nil && err == nil {
+// Get the delay before the next attempt of a failed hook.
+func HookRetryBackoff(attempts int) time.Duration {
+	backoff := HookRetryBackoffBase << uint(attempts-1)
+	if backoff > HookRetryBackoffMax {
+		return HookRetryBackoffMax
+	}
+	return backoff
+}
 
-			err = client.Create(context.TODO(), configMap)
-			if err != nil {
-				return nil, err
-			}
-		} else if err != nil {
+func (t *Task) prepareJob(hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (*batchv1.Job, error) {
+	configMap, err := t.configMapTemplate(hook, migHook)
+	if err != nil {
+		return nil, err
+	}
+
+	phaseConfigMap, err := migHook.GetPhaseConfigMap(client, hook.Phase, string(t.Owner.UID))
+	if phaseConfigMap == nil && err == nil {
+		err = client.Create(context.TODO(), configMap)
+		if err != nil {
 			return nil, err
 		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		configMap = phaseConfigMap
+	}
+
+	var job *batchv1.Job
+	switch {
+	case migHook.Spec.Custom:
+		job = t.baseJobTemplate(hook, migHook, configMap.Name)
+	case migHook.IsScript():
+		job = t.scriptJobTemplate(hook, migHook, configMap.Name)
+	default:
 		job = t.playbookJobTemplate(hook, migHook, configMap.Name)
 	}
 
@@ -248,25 +503,43 @@ func (t *Task) configMapTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook
 	labels[migapi.HookPhaseLabel] = hook.Phase
 	labels[migapi.HookOwnerLabel] = string(t.Owner.UID)
 
-	playbookData, err := base64.StdEncoding.DecodeString(migHook.Spec.Playbook)
+	hookContext, err := t.getHookContext(hook.Phase).JSON()
 	if err != nil {
 		return nil, err
 	}
 
+	data := map[string]string{
+		HookContextKey: hookContext,
+	}
+	switch {
+	case migHook.Spec.Custom:
+		// Custom images only receive the context.
+	case migHook.IsScript():
+		scriptData, err := base64.StdEncoding.DecodeString(migHook.Spec.Script)
+		if err != nil {
+			return nil, err
+		}
+		data["script"] = string(scriptData)
+	default:
+		playbookData, err := base64.StdEncoding.DecodeString(migHook.Spec.Playbook)
+		if err != nil {
+			return nil, err
+		}
+		data["playbook.yml"] = string(playbookData)
+	}
+
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:    hook.ExecutionNamespace,
 			GenerateName: strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase + "-"),
 			Labels:       labels,
 		},
-		Data: map[string]string{
-			"playbook.yml": string(playbookData),
-		},
+		Data: data,
 	}, nil
 }
 
 func (t *Task) playbookJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook, configMap string) *batchv1.Job {
-	jobTemplate := t.baseJobTemplate(hook, migHook)
+	jobTemplate := t.baseJobTemplate(hook, migHook, configMap)
 
 	jobTemplate.Spec.Template.Spec.Containers[0].Command = []string{
 		"/bin/entrypoint",
@@ -277,15 +550,16 @@ func (t *Task) playbookJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHo
 		"/tmp/runner",
 	}
 
-	jobTemplate.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
-		{
+	jobTemplate.Spec.Template.Spec.Containers[0].VolumeMounts = append(
+		jobTemplate.Spec.Template.Spec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{
 			Name:      "playbook",
 			MountPath: "/tmp/playbook",
-		},
-	}
+		})
 
-	jobTemplate.Spec.Template.Spec.Volumes = []corev1.Volume{
-		{
+	jobTemplate.Spec.Template.Spec.Volumes = append(
+		jobTemplate.Spec.Template.Spec.Volumes,
+		corev1.Volume{
 			Name: "playbook",
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
@@ -294,13 +568,43 @@ func (t *Task) playbookJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHo
 					},
 				},
 			},
-		},
+		})
+
+	return jobTemplate
+}
+
+func (t *Task) scriptJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook, configMap string) *batchv1.Job {
+	jobTemplate := t.baseJobTemplate(hook, migHook, configMap)
+
+	jobTemplate.Spec.Template.Spec.Containers[0].Command = []string{
+		migHook.GetInterpreter(),
+		"/tmp/script/script",
 	}
 
+	jobTemplate.Spec.Template.Spec.Containers[0].VolumeMounts = append(
+		jobTemplate.Spec.Template.Spec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{
+			Name:      "script",
+			MountPath: "/tmp/script",
+		})
+
+	jobTemplate.Spec.Template.Spec.Volumes = append(
+		jobTemplate.Spec.Template.Spec.Volumes,
+		corev1.Volume{
+			Name: "script",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: configMap,
+					},
+				},
+			},
+		})
+
 	return jobTemplate
 }
 
-func (t *Task) baseJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook) *batchv1.Job {
+func (t *Task) baseJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook, configMap string) *batchv1.Job {
 	deadlineSeconds := int64(1800)
 
 	if migHook.Spec.ActiveDeadlineSeconds != 0 {
@@ -311,6 +615,19 @@ func (t *Task) baseJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook)
 	labels[migapi.HookPhaseLabel] = hook.Phase
 	labels[migapi.HookOwnerLabel] = string(t.Owner.UID)
 
+	env := []corev1.EnvVar{
+		{
+			Name:  "MIGRATION_NAMESPACES",
+			Value: strings.Join(t.PlanResources.MigPlan.Spec.Namespaces, ","),
+		},
+		{
+			Name:  "MIGRATION_PLAN_NAME",
+			Value: t.PlanResources.MigPlan.Name,
+		},
+	}
+	env = append(env, t.getHookContext(hook.Phase).EnvVars()...)
+	env = append(env, migHook.Spec.Env...)
+
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:    hook.ExecutionNamespace,
@@ -322,16 +639,35 @@ func (t *Task) baseJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook)
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
-							Name:  strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase),
-							Image: migHook.Spec.Image,
-							Env: []corev1.EnvVar{
+							Name:      strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase),
+							Image:     migHook.Spec.Image,
+							Command:   migHook.Spec.Command,
+							Args:      migHook.Spec.Args,
+							Env:       env,
+							EnvFrom:   migHook.Spec.EnvFrom,
+							Resources: migHook.Spec.Resources,
+							VolumeMounts: []corev1.VolumeMount{
 								{
-									Name:  "MIGRATION_NAMESPACES",
-									Value: strings.Join(t.PlanResources.MigPlan.Spec.Namespaces, ","),
+									Name:      HookContextVolume,
+									MountPath: HookContextMountPath,
 								},
-								{
-									Name:  "MIGRATION_PLAN_NAME",
-									Value: t.PlanResources.MigPlan.Name,
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: HookContextVolume,
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: configMap,
+									},
+									Items: []corev1.KeyToPath{
+										{
+											Key:  HookContextKey,
+											Path: HookContextKey,
+										},
+									},
 								},
 							},
 						},
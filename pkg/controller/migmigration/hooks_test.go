@@ -0,0 +1,357 @@
+package migmigration
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTask_configMapTemplate(t1 *testing.T) {
+	tests := []struct {
+		name    string
+		migHook migapi.MigHook
+		key     string
+		want    string
+	}{
+		{
+			name: "playbook hook",
+			migHook: migapi.MigHook{
+				Spec: migapi.MigHookSpec{
+					Playbook: base64.StdEncoding.EncodeToString([]byte("- hosts: localhost")),
+				},
+			},
+			key:  "playbook.yml",
+			want: "- hosts: localhost",
+		},
+		{
+			name: "script hook",
+			migHook: migapi.MigHook{
+				Spec: migapi.MigHookSpec{
+					Script:      base64.StdEncoding.EncodeToString([]byte("echo hello")),
+					Interpreter: "/bin/bash",
+				},
+			},
+			key:  "script",
+			want: "echo hello",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{ObjectMeta: metav1.ObjectMeta{Name: "plan"}},
+				},
+			}
+			got, err := t.configMapTemplate(migapi.MigPlanHook{Phase: migapi.PreBackupHookPhase}, tt.migHook)
+			if err != nil {
+				t1.Errorf("configMapTemplate() unexpected error = %v", err)
+				return
+			}
+			if got.Data[tt.key] != tt.want {
+				t1.Errorf("configMapTemplate() got = %v, want %v", got.Data[tt.key], tt.want)
+			}
+			if _, found := got.Data[HookContextKey]; !found {
+				t1.Errorf("configMapTemplate() missing %s", HookContextKey)
+			}
+		})
+	}
+}
+
+func TestTask_scriptJobTemplate(t1 *testing.T) {
+	tests := []struct {
+		name        string
+		migHook     migapi.MigHook
+		wantCommand []string
+	}{
+		{
+			name: "default interpreter",
+			migHook: migapi.MigHook{
+				Spec: migapi.MigHookSpec{Script: "ZWNobw=="},
+			},
+			wantCommand: []string{migapi.DefaultHookInterpreter, "/tmp/script/script"},
+		},
+		{
+			name: "python interpreter",
+			migHook: migapi.MigHook{
+				Spec: migapi.MigHookSpec{Script: "ZWNobw==", Interpreter: "/usr/bin/python3"},
+			},
+			wantCommand: []string{"/usr/bin/python3", "/tmp/script/script"},
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{ObjectMeta: metav1.ObjectMeta{Name: "plan"}},
+				},
+			}
+			job := t.scriptJobTemplate(migapi.MigPlanHook{Phase: migapi.PreBackupHookPhase}, tt.migHook, "script-cm")
+			container := job.Spec.Template.Spec.Containers[0]
+			if !reflect.DeepEqual(container.Command, tt.wantCommand) {
+				t1.Errorf("scriptJobTemplate() command = %v, want %v", container.Command, tt.wantCommand)
+			}
+			volumes := job.Spec.Template.Spec.Volumes
+			if len(volumes) != 2 || volumes[0].Name != HookContextVolume || volumes[1].Name != "script" {
+				t1.Errorf("scriptJobTemplate() volumes = %v, want context and script volumes", volumes)
+			}
+			for _, volume := range volumes {
+				if volume.ConfigMap == nil || volume.ConfigMap.Name != "script-cm" {
+					t1.Errorf("scriptJobTemplate() volume = %v, want configMap script-cm", volume)
+				}
+			}
+		})
+	}
+}
+
+func TestTask_baseJobTemplate(t1 *testing.T) {
+	resources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+	migHook := migapi.MigHook{
+		Spec: migapi.MigHookSpec{
+			Custom:  true,
+			Image:   "quay.io/konveyor/hook-runner:latest",
+			Command: []string{"/usr/bin/curl"},
+			Args:    []string{"-X", "POST", "http://example.com"},
+			Env: []corev1.EnvVar{
+				{Name: "FOO", Value: "bar"},
+			},
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}}},
+			},
+			Resources: resources,
+		},
+	}
+	t := &Task{
+		Owner: &migapi.MigMigration{},
+		PlanResources: &migapi.PlanResources{
+			MigPlan: &migapi.MigPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "plan"},
+				Spec:       migapi.MigPlanSpec{Namespaces: []string{"ns-1", "ns-2"}},
+			},
+		},
+	}
+	job := t.baseJobTemplate(migapi.MigPlanHook{Phase: migapi.PostRestoreHookPhase}, migHook, "context-cm")
+	container := job.Spec.Template.Spec.Containers[0]
+	if !reflect.DeepEqual(container.Command, migHook.Spec.Command) {
+		t1.Errorf("baseJobTemplate() command = %v, want %v", container.Command, migHook.Spec.Command)
+	}
+	if !reflect.DeepEqual(container.Args, migHook.Spec.Args) {
+		t1.Errorf("baseJobTemplate() args = %v, want %v", container.Args, migHook.Spec.Args)
+	}
+	env := map[string]string{}
+	for _, envVar := range container.Env {
+		env[envVar.Name] = envVar.Value
+	}
+	wantEnv := map[string]string{
+		"MIGRATION_NAMESPACES": "ns-1,ns-2",
+		"MIGRATION_PLAN_NAME":  "plan",
+		"MIGRATION_TYPE":       FinalMigrationType,
+		"MIGRATION_HOOK_PHASE": migapi.PostRestoreHookPhase,
+		"MIGRATION_CONTEXT":    "/tmp/migration/context.json",
+		"FOO":                  "bar",
+	}
+	for name, value := range wantEnv {
+		if env[name] != value {
+			t1.Errorf("baseJobTemplate() env %s = %v, want %v", name, env[name], value)
+		}
+	}
+	if !reflect.DeepEqual(container.EnvFrom, migHook.Spec.EnvFrom) {
+		t1.Errorf("baseJobTemplate() envFrom = %v, want %v", container.EnvFrom, migHook.Spec.EnvFrom)
+	}
+	if !reflect.DeepEqual(container.Resources, resources) {
+		t1.Errorf("baseJobTemplate() resources = %v, want %v", container.Resources, resources)
+	}
+}
+
+func TestHookRetryBackoff(t1 *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 3, want: 40 * time.Second},
+		{attempts: 10, want: HookRetryBackoffMax},
+	}
+	for _, tt := range tests {
+		t1.Run(fmt.Sprintf("attempt %d", tt.attempts), func(t1 *testing.T) {
+			if got := HookRetryBackoff(tt.attempts); got != tt.want {
+				t1.Errorf("HookRetryBackoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_ensureJob(t1 *testing.T) {
+	migHook := migapi.MigHook{ObjectMeta: metav1.ObjectMeta{UID: "hook-uid"}}
+	failedJob := func() *batchv1.Job {
+		labels := migHook.GetCorrelationLabels()
+		labels[migapi.HookPhaseLabel] = migapi.PreBackupHookPhase
+		labels[migapi.HookOwnerLabel] = "uid-1"
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hook-job",
+				Namespace: "ns",
+				Labels:    labels,
+			},
+			Status: batchv1.JobStatus{Failed: HookJobFailedLimit},
+		}
+	}
+	tests := []struct {
+		name         string
+		retries      int
+		attempts     int
+		existing     []runtime.Object
+		want         bool
+		wantFailed   bool
+		wantStatus   string
+		wantAttempts int
+	}{
+		{
+			name:         "job created",
+			existing:     []runtime.Object{},
+			wantStatus:   migapi.HookRunning,
+			wantAttempts: 1,
+		},
+		{
+			name:         "job failed, retried",
+			retries:      1,
+			attempts:     1,
+			existing:     []runtime.Object{failedJob()},
+			wantStatus:   migapi.HookRetrying,
+			wantAttempts: 1,
+		},
+		{
+			name:         "job failed, retries exhausted",
+			retries:      1,
+			attempts:     2,
+			existing:     []runtime.Object{failedJob()},
+			wantFailed:   true,
+			wantAttempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			hook := migapi.MigPlanHook{
+				Phase:     migapi.PreBackupHookPhase,
+				Reference: &corev1.ObjectReference{Namespace: "ns", Name: "hook"},
+				Retries:   tt.retries,
+			}
+			owner := &migapi.MigMigration{ObjectMeta: metav1.ObjectMeta{UID: "uid-1"}}
+			owner.Status.Hooks = []*migapi.HookStatus{
+				{Phase: hook.Phase, Reference: hook.Reference, Attempts: tt.attempts},
+			}
+			client := fake.NewFakeClient(tt.existing...)
+			t := &Task{
+				Log:           log.WithName("test_ensureJob"),
+				Owner:         owner,
+				PlanResources: &migapi.PlanResources{MigPlan: &migapi.MigPlan{}},
+			}
+			job := failedJob()
+			job.Status = batchv1.JobStatus{}
+			got, err := t.ensureJob(job, hook, migHook, client)
+			_, failed := err.(*HookFailedError)
+			if failed != tt.wantFailed || (err != nil && !failed) {
+				t1.Errorf("ensureJob() error = %v, wantFailed %v", err, tt.wantFailed)
+				return
+			}
+			if got != tt.want {
+				t1.Errorf("ensureJob() got = %v, want %v", got, tt.want)
+			}
+			hookStatus := owner.Status.FindHook(hook.Phase)
+			if !tt.wantFailed && hookStatus.Status != tt.wantStatus {
+				t1.Errorf("ensureJob() status = %v, want %v", hookStatus.Status, tt.wantStatus)
+			}
+			if hookStatus.Attempts != tt.wantAttempts {
+				t1.Errorf("ensureJob() attempts = %v, want %v", hookStatus.Attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestTask_applyHookFailurePolicy(t1 *testing.T) {
+	tests := []struct {
+		name          string
+		policy        string
+		want          bool
+		wantStatus    string
+		wantRollback  bool
+		wantCondition bool
+	}{
+		{
+			name:       "fail",
+			wantStatus: migapi.HookFailed,
+		},
+		{
+			name:          "ignore",
+			policy:        migapi.HookFailurePolicyIgnore,
+			want:          true,
+			wantStatus:    migapi.HookIgnored,
+			wantCondition: true,
+		},
+		{
+			name:         "rollback",
+			policy:       migapi.HookFailurePolicyRollback,
+			wantStatus:   migapi.HookFailed,
+			wantRollback: true,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			hook := migapi.MigPlanHook{
+				Phase:         migapi.PostRestoreHookPhase,
+				Reference:     &corev1.ObjectReference{Namespace: "ns", Name: "hook"},
+				FailurePolicy: tt.policy,
+			}
+			owner := &migapi.MigMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-migration", Name: "migration", UID: "uid-1"},
+				Spec: migapi.MigMigrationSpec{
+					MigPlanRef: &corev1.ObjectReference{Namespace: "openshift-migration", Name: "plan"},
+				},
+			}
+			client := fake.NewFakeClientWithScheme(scheme.Scheme)
+			t := &Task{Log: log.WithName("test_applyHookFailurePolicy"), Owner: owner, Client: client}
+			hookStatus := t.getHookStatus(hook)
+			got, err := t.applyHookFailurePolicy(hook, hookStatus, &HookFailedError{Message: "failed."})
+			if (err != nil) == tt.want {
+				t1.Errorf("applyHookFailurePolicy() error = %v", err)
+			}
+			if got != tt.want {
+				t1.Errorf("applyHookFailurePolicy() got = %v, want %v", got, tt.want)
+			}
+			if hookStatus.Status != tt.wantStatus {
+				t1.Errorf("applyHookFailurePolicy() status = %v, want %v", hookStatus.Status, tt.wantStatus)
+			}
+			if owner.Status.HasCondition(HookFailureIgnored) != tt.wantCondition {
+				t1.Errorf("applyHookFailurePolicy() condition = %v, want %v",
+					owner.Status.HasCondition(HookFailureIgnored), tt.wantCondition)
+			}
+			rollback := migapi.MigMigration{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: owner.Namespace, Name: "migration-rollback"}, &rollback)
+			if (err == nil) != tt.wantRollback {
+				t1.Errorf("applyHookFailurePolicy() rollback created = %v, want %v", err == nil, tt.wantRollback)
+			}
+			if tt.wantRollback && !rollback.Spec.Rollback {
+				t1.Errorf("applyHookFailurePolicy() rollback migration = %v", rollback.Spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package migmigration
+
+import (
+	"context"
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	ocappsv1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Update the Pod templates of the workloads in a namespace.
+// The update function returns whether the Pod spec has been changed, only changed workloads are updated.
+// Jobs and Pods not owned by a workload are immutable and are left unchanged, as are the
+// ReplicaSets owned by a Deployment.
+func (t *Task) updatePodTemplates(client k8sclient.Client, ns string, update func(*v1.PodSpec) bool) error {
+	options := k8sclient.InNamespace(ns)
+	dcList := ocappsv1.DeploymentConfigList{}
+	err := client.List(context.TODO(), &dcList, options)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for i := range dcList.Items {
+		dc := &dcList.Items[i]
+		if dc.Spec.Template == nil || !update(&dc.Spec.Template.Spec) {
+			continue
+		}
+		t.Log.Info("Updating Pod template of DeploymentConfig.",
+			"deploymentConfig", path.Join(dc.Namespace, dc.Name))
+		err = client.Update(context.TODO(), dc)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	deploymentList := appsv1.DeploymentList{}
+	err = client.List(context.TODO(), &deploymentList, options)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for i := range deploymentList.Items {
+		deployment := &deploymentList.Items[i]
+		if !update(&deployment.Spec.Template.Spec) {
+			continue
+		}
+		t.Log.Info("Updating Pod template of Deployment.",
+			"deployment", path.Join(deployment.Namespace, deployment.Name))
+		err = client.Update(context.TODO(), deployment)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	setList := appsv1.StatefulSetList{}
+	err = client.List(context.TODO(), &setList, options)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for i := range setList.Items {
+		set := &setList.Items[i]
+		if !update(&set.Spec.Template.Spec) {
+			continue
+		}
+		t.Log.Info("Updating Pod template of StatefulSet.",
+			"statefulSet", path.Join(set.Namespace, set.Name))
+		err = client.Update(context.TODO(), set)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	replicaSetList := appsv1.ReplicaSetList{}
+	err = client.List(context.TODO(), &replicaSetList, options)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for i := range replicaSetList.Items {
+		set := &replicaSetList.Items[i]
+		if len(set.OwnerReferences) > 0 || !update(&set.Spec.Template.Spec) {
+			continue
+		}
+		t.Log.Info("Updating Pod template of ReplicaSet.",
+			"replicaSet", path.Join(set.Namespace, set.Name))
+		err = client.Update(context.TODO(), set)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	daemonSetList := appsv1.DaemonSetList{}
+	err = client.List(context.TODO(), &daemonSetList, options)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for i := range daemonSetList.Items {
+		set := &daemonSetList.Items[i]
+		if !update(&set.Spec.Template.Spec) {
+			continue
+		}
+		t.Log.Info("Updating Pod template of DaemonSet.",
+			"daemonSet", path.Join(set.Namespace, set.Name))
+		err = client.Update(context.TODO(), set)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	cronJobList := batchv1beta.CronJobList{}
+	err = client.List(context.TODO(), &cronJobList, options)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for i := range cronJobList.Items {
+		cronJob := &cronJobList.Items[i]
+		if !update(&cronJob.Spec.JobTemplate.Spec.Template.Spec) {
+			continue
+		}
+		t.Log.Info("Updating Pod template of CronJob.",
+			"cronJob", path.Join(cronJob.Namespace, cronJob.Name))
+		err = client.Update(context.TODO(), cronJob)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migmigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	ocappsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// registryRewriter rewrites image references of the source cluster registries to the destination registries.
+// hosts - source registry host => destination registry host.
+// nsMapping - source namespace => destination namespace.
+type registryRewriter struct {
+	hosts     map[string]string
+	nsMapping map[string]string
+}
+
+// Rewrite an image reference of a source registry to the destination registry and namespace.
+// Returns: the rewritten reference and whether it changed.
+func (r *registryRewriter) rewrite(reference string) (string, bool) {
+	for srcHost, destHost := range r.hosts {
+		if !strings.HasPrefix(reference, srcHost+"/") {
+			continue
+		}
+		repository := strings.SplitN(strings.TrimPrefix(reference, srcHost+"/"), "/", 2)
+		if ns, found := r.nsMapping[repository[0]]; found && len(repository) == 2 {
+			repository[0] = ns
+		}
+		rewritten := destHost + "/" + strings.Join(repository, "/")
+		return rewritten, rewritten != reference
+	}
+	return reference, false
+}
+
+// Rewrite the name of a DockerImage object reference.
+// Returns: the description of the rewrite, empty when unchanged.
+func (r *registryRewriter) rewriteObjectReference(field string, ref *v1.ObjectReference) string {
+	if ref == nil || ref.Kind != "DockerImage" {
+		return ""
+	}
+	return r.rewriteField(field, &ref.Name)
+}
+
+// Rewrite an image reference field.
+// Returns: the description of the rewrite, empty when unchanged.
+func (r *registryRewriter) rewriteField(field string, reference *string) string {
+	rewritten, changed := r.rewrite(*reference)
+	if !changed {
+		return ""
+	}
+	rewrite := fmt.Sprintf("%s: %s -> %s", field, *reference, rewritten)
+	*reference = rewritten
+	return rewrite
+}
+
+// Rewrite the output, strategy and image change trigger references of a BuildConfig.
+// Returns: the descriptions of the rewrites.
+func (r *registryRewriter) rewriteBuildConfig(bc *buildv1.BuildConfig) []string {
+	rewrites := []string{
+		r.rewriteObjectReference("spec.output.to", bc.Spec.Output.To),
+	}
+	strategy := &bc.Spec.Strategy
+	if strategy.DockerStrategy != nil {
+		rewrites = append(rewrites,
+			r.rewriteObjectReference("spec.strategy.dockerStrategy.from", strategy.DockerStrategy.From))
+	}
+	if strategy.SourceStrategy != nil {
+		rewrites = append(rewrites,
+			r.rewriteObjectReference("spec.strategy.sourceStrategy.from", &strategy.SourceStrategy.From))
+	}
+	if strategy.CustomStrategy != nil {
+		rewrites = append(rewrites,
+			r.rewriteObjectReference("spec.strategy.customStrategy.from", &strategy.CustomStrategy.From))
+	}
+	for i, trigger := range bc.Spec.Triggers {
+		if trigger.ImageChange == nil {
+			continue
+		}
+		field := fmt.Sprintf("spec.triggers[%d].imageChange", i)
+		rewrites = append(rewrites,
+			r.rewriteObjectReference(field+".from", trigger.ImageChange.From),
+			r.rewriteField(field+".lastTriggeredImageID", &trigger.ImageChange.LastTriggeredImageID))
+	}
+	return compactRewrites(rewrites)
+}
+
+// Rewrite the image change trigger references of a DeploymentConfig.
+// Returns: the descriptions of the rewrites.
+func (r *registryRewriter) rewriteDeploymentConfig(dc *ocappsv1.DeploymentConfig) []string {
+	rewrites := []string{}
+	for i, trigger := range dc.Spec.Triggers {
+		if trigger.ImageChangeParams == nil {
+			continue
+		}
+		field := fmt.Sprintf("spec.triggers[%d].imageChangeParams", i)
+		rewrites = append(rewrites,
+			r.rewriteObjectReference(field+".from", &trigger.ImageChangeParams.From),
+			r.rewriteField(field+".lastTriggeredImage", &trigger.ImageChangeParams.LastTriggeredImage))
+	}
+	return compactRewrites(rewrites)
+}
+
+// Rewrite the repository and the DockerImage tag references of an ImageStream.
+// Returns: the descriptions of the rewrites.
+func (r *registryRewriter) rewriteImageStream(is *imagev1.ImageStream) []string {
+	rewrites := []string{
+		r.rewriteField("spec.dockerImageRepository", &is.Spec.DockerImageRepository),
+	}
+	for i := range is.Spec.Tags {
+		rewrites = append(rewrites,
+			r.rewriteObjectReference(fmt.Sprintf("spec.tags[%d].from", i), is.Spec.Tags[i].From))
+	}
+	return compactRewrites(rewrites)
+}
+
+// Rewrite the registry hosts of the credentials of a pull secret. Credentials are left unchanged
+// when the secret already holds credentials for the destination host.
+// Returns: the descriptions of the rewrites.
+func (r *registryRewriter) rewritePullSecret(secret *v1.Secret) ([]string, error) {
+	var key string
+	switch secret.Type {
+	case v1.SecretTypeDockercfg:
+		key = v1.DockerConfigKey
+	case v1.SecretTypeDockerConfigJson:
+		key = v1.DockerConfigJsonKey
+	default:
+		return nil, nil
+	}
+	data, found := secret.Data[key]
+	if !found {
+		return nil, nil
+	}
+	config := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	auths := config
+	if key == v1.DockerConfigJsonKey {
+		auths = map[string]json.RawMessage{}
+		if raw, found := config["auths"]; found {
+			err = json.Unmarshal(raw, &auths)
+			if err != nil {
+				return nil, liberr.Wrap(err)
+			}
+		}
+	}
+	rewrites := []string{}
+	hosts := []string{}
+	for host := range auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		destHost, found := r.hosts[host]
+		if !found || destHost == host {
+			continue
+		}
+		if _, found := auths[destHost]; found {
+			continue
+		}
+		auths[destHost] = auths[host]
+		delete(auths, host)
+		rewrites = append(rewrites, fmt.Sprintf("data[%s]: %s -> %s", key, host, destHost))
+	}
+	if len(rewrites) == 0 {
+		return rewrites, nil
+	}
+	if key == v1.DockerConfigJsonKey {
+		config["auths"], err = json.Marshal(auths)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+	}
+	secret.Data[key], err = json.Marshal(config)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	return rewrites, nil
+}
+
+// Remove the empty descriptions of unchanged fields.
+func compactRewrites(rewrites []string) []string {
+	compacted := []string{}
+	for _, rewrite := range rewrites {
+		if rewrite != "" {
+			compacted = append(compacted, rewrite)
+		}
+	}
+	return compacted
+}
+
+// Get the destination registry hosts of the source registry hosts, for both
+// the internal registries and the exposed registry routes.
+func (t *Task) getRegistryHosts() (map[string]string, error) {
+	hosts := map[string]string{}
+	srcInternalRegistry, err := t.PlanResources.SrcMigCluster.GetInternalRegistryPath(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	destInternalRegistry, err := t.PlanResources.DestMigCluster.GetInternalRegistryPath(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if srcInternalRegistry != "" && destInternalRegistry != "" {
+		hosts[srcInternalRegistry] = destInternalRegistry
+	}
+	srcRegistry, err := t.PlanResources.SrcMigCluster.GetRegistryPath(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	destRegistry, err := t.PlanResources.DestMigCluster.GetRegistryPath(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if _, found := hosts[srcRegistry]; !found && srcRegistry != "" && destRegistry != "" {
+		hosts[srcRegistry] = destRegistry
+	}
+	return hosts, nil
+}
+
+// Rewrite the references to the source cluster registries left in the restored BuildConfigs,
+// DeploymentConfig image change triggers, pull secrets and ImageStreams to the destination registries.
+// Each rewrite is reported by the RegistryReferencesRewritten condition.
+func (t *Task) rewriteRegistryReferences() error {
+	hosts, err := t.getRegistryHosts()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+	client, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	rewriter := &registryRewriter{
+		hosts:     hosts,
+		nsMapping: t.PlanResources.MigPlan.GetNamespaceMapping(),
+	}
+	rewrites := []string{}
+	for _, ns := range t.destinationNamespaces() {
+		nsRewrites, err := t.rewriteNamespaceRegistryReferences(client, ns, rewriter)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		rewrites = append(rewrites, nsRewrites...)
+	}
+	if len(rewrites) > 0 {
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     RegistryReferencesRewritten,
+			Status:   True,
+			Category: Advisory,
+			Message:  "[] source registry references rewritten to the destination registry.",
+			Items:    rewrites,
+			Durable:  true,
+		})
+	}
+	return nil
+}
+
+// Rewrite the references to the source cluster registries in a destination namespace.
+// The OpenShift resources are skipped when the destination cluster does not serve their API.
+// Returns: the descriptions of the rewrites.
+func (t *Task) rewriteNamespaceRegistryReferences(client k8sclient.Client, ns string, rewriter *registryRewriter) ([]string, error) {
+	rewrites := []string{}
+	report := func(kind, name string, objectRewrites []string) {
+		for _, rewrite := range objectRewrites {
+			t.Log.Info("Rewrote source registry reference.",
+				"kind", kind,
+				"name", path.Join(ns, name),
+				"rewrite", rewrite)
+			rewrites = append(rewrites, fmt.Sprintf("%s %s %s", kind, path.Join(ns, name), rewrite))
+		}
+	}
+	options := k8sclient.InNamespace(ns)
+	bcList := buildv1.BuildConfigList{}
+	err := client.List(context.TODO(), &bcList, options)
+	if err != nil && !meta.IsNoMatchError(err) {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range bcList.Items {
+		bc := &bcList.Items[i]
+		bcRewrites := rewriter.rewriteBuildConfig(bc)
+		if len(bcRewrites) == 0 {
+			continue
+		}
+		err = client.Update(context.TODO(), bc)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		report("BuildConfig", bc.Name, bcRewrites)
+	}
+	dcList := ocappsv1.DeploymentConfigList{}
+	err = client.List(context.TODO(), &dcList, options)
+	if err != nil && !meta.IsNoMatchError(err) {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range dcList.Items {
+		dc := &dcList.Items[i]
+		dcRewrites := rewriter.rewriteDeploymentConfig(dc)
+		if len(dcRewrites) == 0 {
+			continue
+		}
+		err = client.Update(context.TODO(), dc)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		report("DeploymentConfig", dc.Name, dcRewrites)
+	}
+	isList := imagev1.ImageStreamList{}
+	err = client.List(context.TODO(), &isList, options)
+	if err != nil && !meta.IsNoMatchError(err) {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range isList.Items {
+		is := &isList.Items[i]
+		isRewrites := rewriter.rewriteImageStream(is)
+		if len(isRewrites) == 0 {
+			continue
+		}
+		err = client.Update(context.TODO(), is)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		report("ImageStream", is.Name, isRewrites)
+	}
+	secretList := v1.SecretList{}
+	err = client.List(context.TODO(), &secretList, options)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		// Pull secrets generated for service accounts hold the credentials of the destination cluster.
+		if _, found := secret.Annotations[v1.ServiceAccountNameKey]; found {
+			continue
+		}
+		secretRewrites, err := rewriter.rewritePullSecret(secret)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		if len(secretRewrites) == 0 {
+			continue
+		}
+		err = client.Update(context.TODO(), secret)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		report("Secret", secret.Name, secretRewrites)
+	}
+	return rewrites, nil
+}
@@ -0,0 +1,234 @@
+package migmigration
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ocappsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	srcRoute  = "registry-src.apps.example.com"
+	destRoute = "registry-dest.apps.example.com"
+)
+
+func newRegistryRewriter() *registryRewriter {
+	return &registryRewriter{
+		hosts: map[string]string{
+			srcRegistry: destRegistry,
+			srcRoute:    destRoute,
+		},
+		nsMapping: map[string]string{"app": "app-new"},
+	}
+}
+
+func Test_registryRewriter_rewrite(t1 *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		want      string
+		changed   bool
+	}{
+		{
+			name:      "internal registry reference is rewritten to the destination namespace",
+			reference: srcRegistry + "/app/base:1.0",
+			want:      destRegistry + "/app-new/base:1.0",
+			changed:   true,
+		},
+		{
+			name:      "route reference of an unmapped namespace keeps its namespace",
+			reference: srcRoute + "/shared/base@sha256:abc",
+			want:      destRoute + "/shared/base@sha256:abc",
+			changed:   true,
+		},
+		{
+			name:      "external reference is unchanged",
+			reference: "quay.io/app/base:1.0",
+			want:      "quay.io/app/base:1.0",
+		},
+		{
+			name:      "host prefix of another host is unchanged",
+			reference: srcRoute + ".other/app/base:1.0",
+			want:      srcRoute + ".other/app/base:1.0",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			got, changed := newRegistryRewriter().rewrite(tt.reference)
+			if got != tt.want || changed != tt.changed {
+				t1.Errorf("rewrite() = %v, %v, want %v, %v", got, changed, tt.want, tt.changed)
+			}
+		})
+	}
+}
+
+func Test_registryRewriter_rewriteBuildConfig(t1 *testing.T) {
+	bc := &buildv1.BuildConfig{
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{
+					SourceStrategy: &buildv1.SourceBuildStrategy{
+						From: v1.ObjectReference{Kind: "DockerImage", Name: srcRegistry + "/app/builder:1.0"},
+					},
+				},
+				Output: buildv1.BuildOutput{
+					To: &v1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+				},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{
+				{Type: buildv1.ConfigChangeBuildTriggerType},
+				{
+					Type: buildv1.ImageChangeBuildTriggerType,
+					ImageChange: &buildv1.ImageChangeTrigger{
+						LastTriggeredImageID: srcRegistry + "/app/builder@sha256:abc",
+					},
+				},
+			},
+		},
+	}
+	want := []string{
+		"spec.strategy.sourceStrategy.from: " + srcRegistry + "/app/builder:1.0 -> " + destRegistry + "/app-new/builder:1.0",
+		"spec.triggers[1].imageChange.lastTriggeredImageID: " + srcRegistry + "/app/builder@sha256:abc -> " + destRegistry + "/app-new/builder@sha256:abc",
+	}
+	got := newRegistryRewriter().rewriteBuildConfig(bc)
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("rewriteBuildConfig() = %v, want %v", got, want)
+	}
+	if bc.Spec.Strategy.SourceStrategy.From.Name != destRegistry+"/app-new/builder:1.0" {
+		t1.Errorf("rewriteBuildConfig() did not update strategy, got %v", bc.Spec.Strategy.SourceStrategy.From.Name)
+	}
+	if bc.Spec.Output.To.Name != "app:latest" {
+		t1.Errorf("rewriteBuildConfig() updated ImageStreamTag output, got %v", bc.Spec.Output.To.Name)
+	}
+}
+
+func Test_registryRewriter_rewriteDeploymentConfig(t1 *testing.T) {
+	dc := &ocappsv1.DeploymentConfig{
+		Spec: ocappsv1.DeploymentConfigSpec{
+			Triggers: ocappsv1.DeploymentTriggerPolicies{
+				{
+					Type: ocappsv1.DeploymentTriggerOnImageChange,
+					ImageChangeParams: &ocappsv1.DeploymentTriggerImageChangeParams{
+						From:               v1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+						LastTriggeredImage: srcRoute + "/app/app@sha256:abc",
+					},
+				},
+			},
+		},
+	}
+	want := []string{
+		"spec.triggers[0].imageChangeParams.lastTriggeredImage: " + srcRoute + "/app/app@sha256:abc -> " + destRoute + "/app-new/app@sha256:abc",
+	}
+	got := newRegistryRewriter().rewriteDeploymentConfig(dc)
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("rewriteDeploymentConfig() = %v, want %v", got, want)
+	}
+}
+
+func Test_registryRewriter_rewriteImageStream(t1 *testing.T) {
+	is := &imagev1.ImageStream{
+		Spec: imagev1.ImageStreamSpec{
+			DockerImageRepository: srcRegistry + "/app/app",
+			Tags: []imagev1.TagReference{
+				{Name: "base", From: &v1.ObjectReference{Kind: "DockerImage", Name: "quay.io/app/base:1.0"}},
+				{Name: "latest", From: &v1.ObjectReference{Kind: "DockerImage", Name: srcRegistry + "/shared/app:1.0"}},
+			},
+		},
+	}
+	want := []string{
+		"spec.dockerImageRepository: " + srcRegistry + "/app/app -> " + destRegistry + "/app-new/app",
+		"spec.tags[1].from: " + srcRegistry + "/shared/app:1.0 -> " + destRegistry + "/shared/app:1.0",
+	}
+	got := newRegistryRewriter().rewriteImageStream(is)
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("rewriteImageStream() = %v, want %v", got, want)
+	}
+}
+
+func Test_registryRewriter_rewritePullSecret(t1 *testing.T) {
+	tests := []struct {
+		name     string
+		secret   *v1.Secret
+		key      string
+		want     []string
+		wantData string
+	}{
+		{
+			name: "dockerconfigjson auths are renamed",
+			secret: &v1.Secret{
+				Type: v1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					v1.DockerConfigJsonKey: []byte(`{"auths":{"` + srcRoute + `":{"auth":"dXNlcjpwYXNz"},"quay.io":{"auth":"cXVheQ=="}}}`),
+				},
+			},
+			key:      v1.DockerConfigJsonKey,
+			want:     []string{"data[.dockerconfigjson]: " + srcRoute + " -> " + destRoute},
+			wantData: `{"auths":{"` + destRoute + `":{"auth":"dXNlcjpwYXNz"},"quay.io":{"auth":"cXVheQ=="}}}`,
+		},
+		{
+			name: "dockercfg hosts are renamed",
+			secret: &v1.Secret{
+				Type: v1.SecretTypeDockercfg,
+				Data: map[string][]byte{
+					v1.DockerConfigKey: []byte(`{"` + srcRegistry + `":{"auth":"dXNlcjpwYXNz"}}`),
+				},
+			},
+			key:      v1.DockerConfigKey,
+			want:     []string{"data[.dockercfg]: " + srcRegistry + " -> " + destRegistry},
+			wantData: `{"` + destRegistry + `":{"auth":"dXNlcjpwYXNz"}}`,
+		},
+		{
+			name: "existing destination credentials are kept",
+			secret: &v1.Secret{
+				Type: v1.SecretTypeDockercfg,
+				Data: map[string][]byte{
+					v1.DockerConfigKey: []byte(`{"` + srcRegistry + `":{"auth":"c3Jj"},"` + destRegistry + `":{"auth":"ZGVzdA=="}}`),
+				},
+			},
+			key:      v1.DockerConfigKey,
+			want:     []string{},
+			wantData: `{"` + srcRegistry + `":{"auth":"c3Jj"},"` + destRegistry + `":{"auth":"ZGVzdA=="}}`,
+		},
+		{
+			name: "opaque secret is ignored",
+			secret: &v1.Secret{
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{"password": []byte(srcRegistry)},
+			},
+			key:      "password",
+			wantData: srcRegistry,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			got, err := newRegistryRewriter().rewritePullSecret(tt.secret)
+			if err != nil {
+				t1.Fatalf("rewritePullSecret() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t1.Errorf("rewritePullSecret() = %v, want %v", got, tt.want)
+			}
+			data := tt.secret.Data[tt.key]
+			if tt.secret.Type == v1.SecretTypeOpaque {
+				if string(data) != tt.wantData {
+					t1.Errorf("rewritePullSecret() data = %s, want %s", data, tt.wantData)
+				}
+				return
+			}
+			var gotData, wantData interface{}
+			if err := json.Unmarshal(data, &gotData); err != nil {
+				t1.Fatalf("rewritePullSecret() data error = %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.wantData), &wantData); err != nil {
+				t1.Fatalf("wantData error = %v", err)
+			}
+			if !reflect.DeepEqual(gotData, wantData) {
+				t1.Errorf("rewritePullSecret() data = %s, want %s", data, tt.wantData)
+			}
+		})
+	}
+}
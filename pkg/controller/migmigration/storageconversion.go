@@ -0,0 +1,194 @@
+package migmigration
+
+import (
+	"context"
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	ocappsv1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Get the PVCs converted by a storage conversion, by namespace.
+// Returns: namespace => {source PVC name => converted PVC name}
+func (t *Task) getConvertedClaims() map[string]map[string]string {
+	claims := map[string]map[string]string{}
+	for _, pv := range t.PlanResources.MigPlan.Spec.PersistentVolumes.List {
+		if pv.Selection.Action != migapi.PvCopyAction ||
+			(pv.Selection.CopyMethod != migapi.PvFilesystemCopyMethod && !pv.IsDirectSnapshotCopy()) {
+			continue
+		}
+		if _, found := claims[pv.PVC.Namespace]; !found {
+			claims[pv.PVC.Namespace] = map[string]string{}
+		}
+		claims[pv.PVC.Namespace][pv.PVC.Name] = pv.GetConversionTargetName()
+	}
+	return claims
+}
+
+// Get the converted PVCs of a storage conversion mapped back to their source PVCs, by namespace.
+// Returns: namespace => {converted PVC name => source PVC name}
+func (t *Task) getRestoredClaims() map[string]map[string]string {
+	claims := map[string]map[string]string{}
+	for ns, nsClaims := range t.getConvertedClaims() {
+		claims[ns] = map[string]string{}
+		for source, converted := range nsClaims {
+			claims[ns][converted] = source
+		}
+	}
+	return claims
+}
+
+// Swap the PVC references of the workloads on the source cluster to the converted PVCs.
+func (t *Task) swapPVCReferences() error {
+	client, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	t.Log.Info("Swapping PVC references of workloads to the converted PVCs.")
+	return t.replaceClaimReferences(client, t.getConvertedClaims())
+}
+
+// Restore the PVC references of the workloads on the source cluster to the source PVCs.
+// Both the source and the converted PVCs are kept.
+func (t *Task) restorePVCReferences() error {
+	client, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	t.Log.Info("Restoring PVC references of workloads to the source PVCs.")
+	return t.replaceClaimReferences(client, t.getRestoredClaims())
+}
+
+// Replace the PVCs referenced by the Pod templates of the workloads in the namespaces.
+// Jobs and Pods not owned by a workload are immutable and are left unchanged. Plans converting
+// PVCs created from the volumeClaimTemplates of StatefulSets are rejected by plan validation.
+func (t *Task) replaceClaimReferences(client k8sclient.Client, claims map[string]map[string]string) error {
+	for ns, nsClaims := range claims {
+		options := k8sclient.InNamespace(ns)
+		dcList := ocappsv1.DeploymentConfigList{}
+		err := client.List(context.TODO(), &dcList, options)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for i := range dcList.Items {
+			dc := &dcList.Items[i]
+			if dc.Spec.Template == nil || !replaceClaimNames(&dc.Spec.Template.Spec, nsClaims) {
+				continue
+			}
+			t.Log.Info("Replacing PVC references of DeploymentConfig.",
+				"deploymentConfig", path.Join(dc.Namespace, dc.Name))
+			err = client.Update(context.TODO(), dc)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		deploymentList := appsv1.DeploymentList{}
+		err = client.List(context.TODO(), &deploymentList, options)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for i := range deploymentList.Items {
+			deployment := &deploymentList.Items[i]
+			if !replaceClaimNames(&deployment.Spec.Template.Spec, nsClaims) {
+				continue
+			}
+			t.Log.Info("Replacing PVC references of Deployment.",
+				"deployment", path.Join(deployment.Namespace, deployment.Name))
+			err = client.Update(context.TODO(), deployment)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		setList := appsv1.StatefulSetList{}
+		err = client.List(context.TODO(), &setList, options)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for i := range setList.Items {
+			set := &setList.Items[i]
+			if !replaceClaimNames(&set.Spec.Template.Spec, nsClaims) {
+				continue
+			}
+			t.Log.Info("Replacing PVC references of StatefulSet.",
+				"statefulSet", path.Join(set.Namespace, set.Name))
+			err = client.Update(context.TODO(), set)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		replicaSetList := appsv1.ReplicaSetList{}
+		err = client.List(context.TODO(), &replicaSetList, options)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for i := range replicaSetList.Items {
+			set := &replicaSetList.Items[i]
+			if len(set.OwnerReferences) > 0 || !replaceClaimNames(&set.Spec.Template.Spec, nsClaims) {
+				continue
+			}
+			t.Log.Info("Replacing PVC references of ReplicaSet.",
+				"replicaSet", path.Join(set.Namespace, set.Name))
+			err = client.Update(context.TODO(), set)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		daemonSetList := appsv1.DaemonSetList{}
+		err = client.List(context.TODO(), &daemonSetList, options)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for i := range daemonSetList.Items {
+			set := &daemonSetList.Items[i]
+			if !replaceClaimNames(&set.Spec.Template.Spec, nsClaims) {
+				continue
+			}
+			t.Log.Info("Replacing PVC references of DaemonSet.",
+				"daemonSet", path.Join(set.Namespace, set.Name))
+			err = client.Update(context.TODO(), set)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		cronJobList := batchv1beta.CronJobList{}
+		err = client.List(context.TODO(), &cronJobList, options)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for i := range cronJobList.Items {
+			cronJob := &cronJobList.Items[i]
+			if !replaceClaimNames(&cronJob.Spec.JobTemplate.Spec.Template.Spec, nsClaims) {
+				continue
+			}
+			t.Log.Info("Replacing PVC references of CronJob.",
+				"cronJob", path.Join(cronJob.Namespace, cronJob.Name))
+			err = client.Update(context.TODO(), cronJob)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
+// Replace the PVCs referenced by the volumes of a Pod spec.
+// Returns: whether any PVC reference was replaced.
+func replaceClaimNames(spec *v1.PodSpec, claims map[string]string) bool {
+	replaced := false
+	for i := range spec.Volumes {
+		claim := spec.Volumes[i].PersistentVolumeClaim
+		if claim == nil {
+			continue
+		}
+		if name, found := claims[claim.ClaimName]; found {
+			claim.ClaimName = name
+			replaced = true
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,90 @@
+package migmigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+func Test_replaceClaimNames(t1 *testing.T) {
+	tests := []struct {
+		name       string
+		volumes    []v1.Volume
+		claims     map[string]string
+		want       bool
+		wantClaims []string
+	}{
+		{
+			name: "referenced claim is replaced",
+			volumes: []v1.Volume{
+				{Name: "data", VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-1"}}},
+				{Name: "logs", VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-2"}}},
+			},
+			claims:     map[string]string{"pvc-1": "pvc-1-gp3"},
+			want:       true,
+			wantClaims: []string{"pvc-1-gp3", "pvc-2"},
+		},
+		{
+			name: "unreferenced claims and other volumes are unchanged",
+			volumes: []v1.Volume{
+				{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{}}},
+				{Name: "logs", VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-2"}}},
+			},
+			claims:     map[string]string{"pvc-1": "pvc-1-gp3"},
+			want:       false,
+			wantClaims: []string{"pvc-2"},
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			spec := &v1.PodSpec{Volumes: tt.volumes}
+			if got := replaceClaimNames(spec, tt.claims); got != tt.want {
+				t1.Errorf("replaceClaimNames() = %v, want %v", got, tt.want)
+			}
+			claims := []string{}
+			for _, volume := range spec.Volumes {
+				if volume.PersistentVolumeClaim != nil {
+					claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+				}
+			}
+			if !reflect.DeepEqual(claims, tt.wantClaims) {
+				t1.Errorf("replaceClaimNames() claims = %v, want %v", claims, tt.wantClaims)
+			}
+		})
+	}
+}
+
+func TestTask_getRestoredClaims(t1 *testing.T) {
+	plan := &migapi.MigPlan{
+		Spec: migapi.MigPlanSpec{
+			StorageConversion: true,
+			PersistentVolumes: migapi.PersistentVolumes{
+				List: []migapi.PV{
+					{
+						PVC:       migapi.PVC{Name: "pvc-1", Namespace: "ns-1"},
+						Selection: migapi.Selection{Action: migapi.PvCopyAction, CopyMethod: migapi.PvFilesystemCopyMethod, StorageClass: "gp3"},
+					},
+					{
+						PVC:       migapi.PVC{Name: "pvc-2", Namespace: "ns-1"},
+						Selection: migapi.Selection{Action: migapi.PvCopyAction, CopyMethod: migapi.PvFilesystemCopyMethod, TargetName: "logs"},
+					},
+					{
+						PVC:       migapi.PVC{Name: "pvc-3", Namespace: "ns-1"},
+						Selection: migapi.Selection{Action: migapi.PvSkipAction},
+					},
+				},
+			},
+		},
+	}
+	t := Task{PlanResources: &migapi.PlanResources{MigPlan: plan}}
+	want := map[string]map[string]string{"ns-1": {"pvc-1": "pvc-1-gp3", "pvc-2": "logs"}}
+	if got := t.getConvertedClaims(); !reflect.DeepEqual(got, want) {
+		t1.Errorf("getConvertedClaims() = %v, want %v", got, want)
+	}
+	want = map[string]map[string]string{"ns-1": {"pvc-1-gp3": "pvc-1", "logs": "pvc-2"}}
+	if got := t.getRestoredClaims(); !reflect.DeepEqual(got, want) {
+		t1.Errorf("getRestoredClaims() = %v, want %v", got, want)
+	}
+}
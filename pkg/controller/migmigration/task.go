@@ -79,6 +79,8 @@ const (
 	EnsureFinalRestore                     = "EnsureFinalRestore"
 	FinalRestoreCreated                    = "FinalRestoreCreated"
 	FinalRestoreFailed                     = "FinalRestoreFailed"
+	RewriteWorkloadImages                  = "RewriteWorkloadImages"
+	RewriteRegistryReferences              = "RewriteRegistryReferences"
 	Verification                           = "Verification"
 	EnsureStagePodsDeleted                 = "EnsureStagePodsDeleted"
 	EnsureStagePodsTerminated              = "EnsureStagePodsTerminated"
@@ -95,6 +97,8 @@ const (
 	Canceling                              = "Canceling"
 	Canceled                               = "Canceled"
 	Rollback                               = "Rollback"
+	SwapPVCReferences                      = "SwapPVCReferences"
+	RestorePVCReferences                   = "RestorePVCReferences"
 	Completed                              = "Completed"
 )
 
@@ -132,6 +136,7 @@ const (
 	StepCleanupHelpers   = "CleanupHelpers"
 	StepCleanupMigrated  = "CleanupMigrated"
 	StepCleanupUnquiesce = "CleanupUnquiesce"
+	StepConversion       = "StorageConversion"
 )
 
 // Itinerary defines itinerary
@@ -229,6 +234,8 @@ var FinalItinerary = Itinerary{
 		{Name: EnsureInitialBackupReplicated, Step: StepRestore},
 		{Name: EnsureFinalRestore, Step: StepRestore},
 		{Name: FinalRestoreCreated, Step: StepRestore},
+		{Name: RewriteWorkloadImages, Step: StepRestore, all: DirectImage | EnableImage},
+		{Name: RewriteRegistryReferences, Step: StepRestore, all: EnableImage},
 		{Name: UnQuiesceDestApplications, Step: StepRestore},
 		{Name: PostRestoreHooks, Step: PostRestoreHooks, all: HasPostRestoreHooks},
 		{Name: DeleteRegistries, Step: StepCleanup},
@@ -272,6 +279,7 @@ var RollbackItinerary = Itinerary{
 		{Name: DeleteRestores, Step: StepCleanupVelero},
 		{Name: DeleteRegistries, Step: StepCleanupHelpers},
 		{Name: EnsureStagePodsDeleted, Step: StepCleanupHelpers},
+		{Name: DeleteDirectVolumeMigrationResources, Step: StepCleanupHelpers, all: DirectVolume},
 		{Name: EnsureAnnotationsDeleted, Step: StepCleanupHelpers, any: HasPVs | HasISs},
 		{Name: DeleteMigrated, Step: StepCleanupMigrated},
 		{Name: EnsureMigratedDeleted, Step: StepCleanupMigrated},
@@ -280,6 +288,47 @@ var RollbackItinerary = Itinerary{
 	},
 }
 
+var StorageConversionStageItinerary = Itinerary{
+	Name: "StorageConversionStage",
+	Phases: []Phase{
+		{Name: Created, Step: StepPrepare},
+		{Name: Started, Step: StepPrepare},
+		{Name: StartRefresh, Step: StepPrepare},
+		{Name: WaitForRefresh, Step: StepPrepare},
+		{Name: CreateDirectVolumeMigration, Step: StepStageBackup, all: DirectVolume | EnableVolume},
+		{Name: WaitForDirectVolumeMigrationToComplete, Step: StepDirectVolume, all: DirectVolume | EnableVolume},
+		{Name: Completed, Step: StepCleanup},
+	},
+}
+
+var StorageConversionItinerary = Itinerary{
+	Name: "StorageConversion",
+	Phases: []Phase{
+		{Name: Created, Step: StepPrepare},
+		{Name: Started, Step: StepPrepare},
+		{Name: StartRefresh, Step: StepPrepare},
+		{Name: WaitForRefresh, Step: StepPrepare},
+		{Name: QuiesceApplications, Step: StepStageBackup, all: Quiesce},
+		{Name: EnsureQuiesced, Step: StepStageBackup, all: Quiesce},
+		{Name: CreateDirectVolumeMigration, Step: StepStageBackup, all: DirectVolume | EnableVolume},
+		{Name: WaitForDirectVolumeMigrationToComplete, Step: StepDirectVolume, all: DirectVolume | EnableVolume},
+		{Name: SwapPVCReferences, Step: StepConversion, all: DirectVolume | EnableVolume},
+		{Name: UnQuiesceSrcApplications, Step: StepConversion},
+		{Name: Completed, Step: StepCleanup},
+	},
+}
+
+var StorageConversionRollbackItinerary = Itinerary{
+	Name: "StorageConversionRollback",
+	Phases: []Phase{
+		{Name: Rollback, Step: StepCleanupHelpers},
+		{Name: DeleteDirectVolumeMigrationResources, Step: StepCleanupHelpers, all: DirectVolume},
+		{Name: RestorePVCReferences, Step: StepConversion, all: DirectVolume},
+		{Name: UnQuiesceSrcApplications, Step: StepCleanupUnquiesce},
+		{Name: Completed, Step: StepCleanup},
+	},
+}
+
 // Phase defines phase in the migration
 type Phase struct {
 	// A phase name.
@@ -693,6 +742,38 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case SwapPVCReferences:
+		err := t.swapPVCReferences()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RestorePVCReferences:
+		err := t.restorePVCReferences()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RewriteWorkloadImages:
+		err := t.rewriteWorkloadImages()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RewriteRegistryReferences:
+		err := t.rewriteRegistryReferences()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case UnQuiesceDestApplications:
 		err := t.unQuiesceDestApplications()
 		if err != nil {
@@ -1122,6 +1203,14 @@ func (t *Task) init() error {
 		t.Itinerary = FailedItinerary
 	} else if t.canceled() {
 		t.Itinerary = CancelItinerary
+	} else if t.storageConversion() {
+		if t.rollback() {
+			t.Itinerary = StorageConversionRollbackItinerary
+		} else if t.stage() {
+			t.Itinerary = StorageConversionStageItinerary
+		} else {
+			t.Itinerary = StorageConversionItinerary
+		}
 	} else if t.rollback() {
 		t.Itinerary = RollbackItinerary
 	} else if t.stage() {
@@ -1472,6 +1561,11 @@ func (t *Task) rollback() bool {
 	return t.Owner.Spec.Rollback
 }
 
+// Get whether the migration converts the storage class of PVCs within the source cluster.
+func (t *Task) storageConversion() bool {
+	return t.PlanResources.MigPlan.IsStorageConversion()
+}
+
 // Get whether the migration is stage.
 func (t *Task) stage() bool {
 	return t.Owner.Spec.Stage
@@ -1520,11 +1614,12 @@ func (t *Task) getStagePVs() migapi.PersistentVolumes {
 	directVolumesEnabled := !t.PlanResources.MigPlan.Spec.IndirectVolumeMigration
 	volumes := []migapi.PV{}
 	for _, pv := range t.PlanResources.MigPlan.Spec.PersistentVolumes.List {
-		// If the pv is skipped or if its a filesystem copy with DVM enabled then
-		// don't include it in a stage PV
+		// If the pv is skipped or if its a filesystem or CSI snapshot copy with DVM
+		// enabled then don't include it in a stage PV
 		if pv.Selection.Action == migapi.PvSkipAction ||
 			(directVolumesEnabled && pv.Selection.Action == migapi.PvCopyAction &&
-				pv.Selection.CopyMethod == migapi.PvFilesystemCopyMethod) {
+				pv.Selection.CopyMethod == migapi.PvFilesystemCopyMethod) ||
+			(directVolumesEnabled && pv.IsDirectSnapshotCopy()) {
 			continue
 		}
 		volumes = append(volumes, pv)
@@ -1551,9 +1646,12 @@ func (t *Task) getPVCs() map[k8sclient.ObjectKey]migapi.PV {
 // First return value is PVs overall, and second is limited to Move or snapshot copy PVs
 func (t *Task) hasPVs() (bool, bool) {
 	var anyPVs bool
+	directVolumesEnabled := !t.PlanResources.MigPlan.Spec.IndirectVolumeMigration
 	for _, pv := range t.PlanResources.MigPlan.Spec.PersistentVolumes.List {
+		// CSI snapshot copies with DVM enabled are not handled by Velero
 		if pv.Selection.Action == migapi.PvMoveAction ||
-			pv.Selection.Action == migapi.PvCopyAction && pv.Selection.CopyMethod == migapi.PvSnapshotCopyMethod {
+			pv.Selection.Action == migapi.PvCopyAction && pv.Selection.CopyMethod == migapi.PvSnapshotCopyMethod &&
+				!(directVolumesEnabled && pv.IsDirectSnapshotCopy()) {
 			return true, true
 		}
 		if pv.Selection.Action != migapi.PvSkipAction {
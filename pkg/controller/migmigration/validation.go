@@ -41,6 +41,8 @@ const (
 	StaleDestVeleroCRsDeleted          = "StaleDestVeleroCRsDeleted"
 	StaleResticCRsDeleted              = "StaleResticCRsDeleted"
 	DirectVolumeMigrationBlocked       = "DirectVolumeMigrationBlocked"
+	HookFailureIgnored                 = "HookFailureIgnored"
+	RegistryReferencesRewritten        = "RegistryReferencesRewritten"
 )
 
 // Categories
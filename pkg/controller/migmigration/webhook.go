@@ -0,0 +1,190 @@
+package migmigration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Max number of response body characters reported in the step progress.
+const WebhookResponseSummaryLimit = 256
+
+// Data used to render the webhook body template.
+type webhookTemplateData struct {
+	Plan               *migapi.MigPlan
+	Migration          *migapi.MigMigration
+	Phase              string
+	SourceCluster      *migapi.MigCluster
+	DestinationCluster *migapi.MigCluster
+	Context            *HookContext
+}
+
+// Result of a single webhook call.
+type webhookResult struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+// Execute a webhook hook.
+// A single call is made per reconcile. A failed call is retried, once its backoff has
+// elapsed, on a later reconcile until it succeeds or the retries of the plan hook are exhausted.
+func (t *Task) runWebhook(hook migapi.MigPlanHook, migHook migapi.MigHook) (bool, error) {
+	webhook := migHook.Spec.Webhook
+	hookStatus := t.getHookStatus(hook)
+	attempts := hook.Retries + 1
+
+	// Wait for the backoff before retrying a failed call.
+	if hookStatus.NextAttemptTime != nil {
+		wait := time.Until(hookStatus.NextAttemptTime.Time)
+		if wait > 0 {
+			t.setProgress([]string{
+				fmt.Sprintf("Webhook %s %s: Retrying in %s", webhook.GetMethod(), webhook.URL, wait.Round(time.Second))})
+			return false, nil
+		}
+	}
+
+	body, err := t.renderWebhookBody(hook, migHook)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	headers, err := t.getWebhookHeaders(migHook)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+
+	t.Log.Info("Calling webhook for MigHook.",
+		"migHook", path.Join(migHook.Namespace, migHook.Name),
+		"migHookPhase", hook.Phase,
+		"url", webhook.URL,
+		"attempt", hookStatus.Attempts+1)
+	now := metav1.Now()
+	hookStatus.Attempts++
+	hookStatus.LastAttemptTime = &now
+	hookStatus.NextAttemptTime = nil
+	result, err := callWebhook(webhook, headers, body)
+	if err != nil {
+		t.Log.Info("Webhook call failed.",
+			"migHook", path.Join(migHook.Namespace, migHook.Name),
+			"error", err.Error())
+		hookStatus.Message = err.Error()
+		t.setProgress([]string{
+			fmt.Sprintf("Webhook %s %s: %s (attempt %d/%d)",
+				webhook.GetMethod(), webhook.URL, err.Error(), hookStatus.Attempts, attempts)})
+	} else {
+		t.setProgress([]string{
+			fmt.Sprintf("Webhook %s %s: %s (attempt %d/%d)",
+				webhook.GetMethod(), webhook.URL, result.Status, hookStatus.Attempts, attempts),
+			fmt.Sprintf("Response: %s", result.Body)})
+		hookStatus.Message = result.Status
+		hookStatus.Log = result.Body
+		if webhook.IsSuccess(result.StatusCode) {
+			hookStatus.Status = migapi.HookSucceeded
+			return true, nil
+		}
+	}
+
+	if hookStatus.Attempts >= attempts {
+		return false, &HookFailedError{
+			Message: fmt.Sprintf("Webhook %s %s failed after %d attempts.", webhook.GetMethod(), webhook.URL, attempts),
+		}
+	}
+	next := metav1.NewTime(now.Add(webhook.GetBackoff(hookStatus.Attempts - 1)))
+	hookStatus.NextAttemptTime = &next
+	hookStatus.Status = migapi.HookRetrying
+	return false, nil
+}
+
+// Render the body template.
+func (t *Task) renderWebhookBody(hook migapi.MigPlanHook, migHook migapi.MigHook) (string, error) {
+	webhook := migHook.Spec.Webhook
+	if webhook.Body == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("body").Parse(webhook.Body)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	data := webhookTemplateData{
+		Plan:               t.PlanResources.MigPlan,
+		Migration:          t.Owner,
+		Phase:              hook.Phase,
+		SourceCluster:      t.PlanResources.SrcMigCluster,
+		DestinationCluster: t.PlanResources.DestMigCluster,
+		Context:            t.getHookContext(hook.Phase),
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return buf.String(), nil
+}
+
+// Get the request headers from the referenced secret.
+// The secret is always read from the namespace of the MigHook.
+func (t *Task) getWebhookHeaders(migHook migapi.MigHook) (map[string]string, error) {
+	headers := map[string]string{}
+	ref := migHook.Spec.Webhook.HeadersSecretRef
+	if ref == nil {
+		return headers, nil
+	}
+	secret := corev1.Secret{}
+	err := t.Client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Namespace: migHook.Namespace,
+			Name:      ref.Name,
+		},
+		&secret)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for key, value := range secret.Data {
+		headers[key] = string(value)
+	}
+	return headers, nil
+}
+
+// Make a single webhook call.
+func callWebhook(webhook *migapi.MigHookWebhook, headers map[string]string, body string) (*webhookResult, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request, err := http.NewRequest(webhook.GetMethod(), webhook.URL, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	client := http.Client{Timeout: webhook.GetTimeout()}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(response.Body, WebhookResponseSummaryLimit))
+	if err != nil {
+		return nil, err
+	}
+	return &webhookResult{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Body:       strings.Join(strings.Fields(string(content)), " "),
+	}, nil
+}
@@ -0,0 +1,179 @@
+package migmigration
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTask_renderWebhookBody(t1 *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty body",
+			body: "",
+			want: "",
+		},
+		{
+			name: "plan and migration context",
+			body: `{"plan":"{{.Plan.Name}}","migration":"{{.Migration.Name}}","phase":"{{.Phase}}","stage":{{.Migration.Spec.Stage}}}`,
+			want: `{"plan":"plan","migration":"migration","phase":"PreBackup","stage":false}`,
+		},
+		{
+			name:    "unknown field",
+			body:    "{{.Unknown}}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{ObjectMeta: metav1.ObjectMeta{Name: "migration"}},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{ObjectMeta: metav1.ObjectMeta{Name: "plan"}},
+				},
+			}
+			migHook := migapi.MigHook{
+				Spec: migapi.MigHookSpec{
+					Webhook: &migapi.MigHookWebhook{Body: tt.body},
+				},
+			}
+			got, err := t.renderWebhookBody(migapi.MigPlanHook{Phase: migapi.PreBackupHookPhase}, migHook)
+			if (err != nil) != tt.wantErr {
+				t1.Errorf("renderWebhookBody() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t1.Errorf("renderWebhookBody() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_runWebhook(t1 *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		successCodes []int
+		wantAttempts int
+		wantSuccess  bool
+	}{
+		{
+			name:         "default success codes",
+			status:       http.StatusOK,
+			wantAttempts: 1,
+			wantSuccess:  true,
+		},
+		{
+			name:         "custom success codes",
+			status:       http.StatusConflict,
+			successCodes: []int{http.StatusConflict},
+			wantAttempts: 1,
+			wantSuccess:  true,
+		},
+		{
+			name:         "retries exhausted",
+			status:       http.StatusServiceUnavailable,
+			wantAttempts: 2,
+			wantSuccess:  false,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			attempts := 0
+			var body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				content, _ := ioutil.ReadAll(r.Body)
+				body = string(content)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			t := &Task{
+				Log:   log.WithName("test_runWebhook"),
+				Owner: &migapi.MigMigration{ObjectMeta: metav1.ObjectMeta{Name: "migration"}},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{ObjectMeta: metav1.ObjectMeta{Name: "plan"}},
+				},
+			}
+			migHook := migapi.MigHook{
+				Spec: migapi.MigHookSpec{
+					Webhook: &migapi.MigHookWebhook{
+						URL:            server.URL,
+						Body:           "{{.Plan.Name}}",
+						SuccessCodes:   tt.successCodes,
+						BackoffSeconds: 1,
+					},
+				},
+			}
+			hook := migapi.MigPlanHook{Phase: migapi.PreBackupHookPhase, Retries: 1}
+			var succeeded bool
+			var err error
+			for reconcile := 0; reconcile < 5 && !succeeded && err == nil; reconcile++ {
+				succeeded, err = t.runWebhook(hook, migHook)
+				hookStatus := t.getHookStatus(hook)
+				if succeeded || err != nil {
+					break
+				}
+				if hookStatus.NextAttemptTime == nil || hookStatus.Status != migapi.HookRetrying {
+					t1.Fatalf("runWebhook() retry not scheduled, status %+v", hookStatus)
+				}
+				// No call is made until the backoff has elapsed.
+				calls := attempts
+				if succeeded, err = t.runWebhook(hook, migHook); succeeded || err != nil || attempts != calls {
+					t1.Fatalf("runWebhook() called during backoff, attempts = %v, want %v", attempts, calls)
+				}
+				past := metav1.NewTime(hookStatus.NextAttemptTime.Add(-time.Minute))
+				hookStatus.NextAttemptTime = &past
+			}
+			if succeeded != tt.wantSuccess || (err == nil) != tt.wantSuccess {
+				t1.Errorf("runWebhook() succeeded = %v, err = %v, wantSuccess %v", succeeded, err, tt.wantSuccess)
+			}
+			if attempts != tt.wantAttempts {
+				t1.Errorf("runWebhook() attempts = %v, want %v", attempts, tt.wantAttempts)
+			}
+			if body != "plan" {
+				t1.Errorf("runWebhook() body = %v, want plan", body)
+			}
+		})
+	}
+}
+
+func TestTask_getWebhookHeaders(t1 *testing.T) {
+	client := fake.NewFakeClient(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "headers", Namespace: "openshift-migration"},
+			Data:       map[string][]byte{"Authorization": []byte("Bearer hook")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "headers", Namespace: "other"},
+			Data:       map[string][]byte{"Authorization": []byte("Bearer other")},
+		})
+	t := &Task{Client: client}
+	migHook := migapi.MigHook{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "openshift-migration"},
+		Spec: migapi.MigHookSpec{
+			Webhook: &migapi.MigHookWebhook{
+				HeadersSecretRef: &corev1.ObjectReference{Name: "headers", Namespace: "other"},
+			},
+		},
+	}
+	headers, err := t.getWebhookHeaders(migHook)
+	if err != nil {
+		t1.Fatalf("getWebhookHeaders() error = %v", err)
+	}
+	if got := headers["Authorization"]; got != "Bearer hook" {
+		t1.Errorf("getWebhookHeaders() Authorization = %v, want the header of the hook namespace", got)
+	}
+}
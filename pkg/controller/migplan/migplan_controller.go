@@ -119,6 +119,18 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the network preflight DirectVolumeMigrations.
+	err = c.Watch(
+		&source.Kind{Type: &migapi.DirectVolumeMigration{}},
+		&handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &migapi.MigPlan{},
+		},
+		&PreflightPredicate{})
+	if err != nil {
+		return err
+	}
+
 	// Indexes
 	indexer := mgr.GetFieldIndexer()
 
@@ -304,6 +316,13 @@ func (r *ReconcileMigPlan) Reconcile(ctx context.Context, request reconcile.Requ
 			!plan.Status.HasBlockerCondition(),
 		"The migration plan is ready.")
 
+	// Network preflight
+	err = r.ensureNetworkPreflight(ctx, plan)
+	if err != nil {
+		log.Trace(err)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	// End staging conditions.
 	plan.Status.EndStagingConditions()
 
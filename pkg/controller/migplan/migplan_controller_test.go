@@ -23,6 +23,7 @@ import (
 	"time"
 
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -609,3 +610,438 @@ func TestReconcileMigPlan_processProposedPVCapacities(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileMigPlan_validatePvSelections_block(t *testing.T) {
+	blockPV := migapi.PV{
+		Name: "pv-0",
+		PVC:  migapi.PVC{Namespace: "test-ns", Name: "pvc-0", VolumeMode: corev1.PersistentVolumeBlock},
+		Supported: migapi.Supported{
+			Actions:     []string{migapi.PvCopyAction},
+			CopyMethods: []string{migapi.PvFilesystemCopyMethod, migapi.PvSnapshotCopyMethod},
+		},
+		Selection: migapi.Selection{
+			Action:       migapi.PvCopyAction,
+			CopyMethod:   migapi.PvFilesystemCopyMethod,
+			StorageClass: "block-sc",
+		},
+	}
+	tests := []struct {
+		name     string
+		indirect bool
+		wantCond bool
+	}{
+		{
+			name:     "block volume copied with direct volume migration",
+			indirect: false,
+			wantCond: false,
+		},
+		{
+			name:     "block volume copied with indirect volume migration",
+			indirect: true,
+			wantCond: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &migapi.MigPlan{
+				Spec: migapi.MigPlanSpec{
+					IndirectVolumeMigration: tt.indirect,
+					PersistentVolumes:       migapi.PersistentVolumes{List: []migapi.PV{blockPV}},
+				},
+				Status: migapi.MigPlanStatus{
+					DestStorageClasses: []migapi.StorageClass{
+						{Name: "block-sc", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+					},
+				},
+			}
+			r := ReconcileMigPlan{}
+			err := r.validatePvSelections(context.TODO(), plan)
+			if err != nil {
+				t.Fatalf("validatePvSelections() error = %v", err)
+			}
+			if got := plan.Status.HasCondition(PvBlockRequiresDirectVolumeMigration); got != tt.wantCond {
+				t.Errorf("validatePvSelections() block condition = %v, want %v", got, tt.wantCond)
+			}
+			if plan.Status.HasAnyCondition(PvInvalidAction, PvInvalidCopyMethod, PvInvalidStorageClass) {
+				t.Errorf("validatePvSelections() rejected the block volume selection: %v", plan.Status.Conditions)
+			}
+		})
+	}
+}
+
+func Test_getVolumeSnapshotClassName(t *testing.T) {
+	snapshotClasses := []migapi.VolumeSnapshotClass{
+		{Name: "rbd-snapclass", Driver: "rbd.csi.ceph.com"},
+		{Name: "rbd-default-snapclass", Driver: "rbd.csi.ceph.com", Default: true},
+		{Name: "ebs-snapclass", Driver: "ebs.csi.aws.com"},
+	}
+	csiPV := func(driver string) corev1.PersistentVolume {
+		return corev1.PersistentVolume{
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: driver},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		pv   corev1.PersistentVolume
+		want string
+	}{
+		{
+			name: "default class of the driver is preferred",
+			pv:   csiPV("rbd.csi.ceph.com"),
+			want: "rbd-default-snapclass",
+		},
+		{
+			name: "only class of the driver",
+			pv:   csiPV("ebs.csi.aws.com"),
+			want: "ebs-snapclass",
+		},
+		{
+			name: "no class for the driver",
+			pv:   csiPV("cephfs.csi.ceph.com"),
+			want: "",
+		},
+		{
+			name: "not a CSI volume",
+			pv: corev1.PersistentVolume{
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/"},
+					},
+				},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getVolumeSnapshotClassName(tt.pv, snapshotClasses); got != tt.want {
+				t.Errorf("getVolumeSnapshotClassName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileMigPlan_validatePvSelections_directSnapshot(t *testing.T) {
+	tests := []struct {
+		name                string
+		indirect            bool
+		copyMethod          string
+		directSnapshot      bool
+		volumeSnapshotClass string
+		wantDirect          bool
+		wantInvalid         bool
+		wantWarnSnapshot    bool
+	}{
+		{
+			name:                "snapshot copy is made by velero unless selected",
+			copyMethod:          migapi.PvSnapshotCopyMethod,
+			volumeSnapshotClass: "rbd-snapclass",
+			wantWarnSnapshot:    true,
+		},
+		{
+			name:                "selected CSI snapshot copy",
+			copyMethod:          migapi.PvSnapshotCopyMethod,
+			directSnapshot:      true,
+			volumeSnapshotClass: "rbd-snapclass",
+			wantDirect:          true,
+		},
+		{
+			name:             "selected CSI snapshot copy without a VolumeSnapshotClass",
+			copyMethod:       migapi.PvSnapshotCopyMethod,
+			directSnapshot:   true,
+			wantInvalid:      true,
+			wantWarnSnapshot: true,
+		},
+		{
+			name:                "selected CSI snapshot copy of a filesystem copy",
+			copyMethod:          migapi.PvFilesystemCopyMethod,
+			directSnapshot:      true,
+			volumeSnapshotClass: "rbd-snapclass",
+			wantInvalid:         true,
+		},
+		{
+			name:                "selected CSI snapshot copy with indirect volume migration",
+			indirect:            true,
+			copyMethod:          migapi.PvSnapshotCopyMethod,
+			directSnapshot:      true,
+			volumeSnapshotClass: "rbd-snapclass",
+			wantDirect:          true,
+			wantInvalid:         true,
+			wantWarnSnapshot:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := migapi.PV{
+				Name: "pv-0",
+				PVC:  migapi.PVC{Namespace: "test-ns", Name: "pvc-0"},
+				Supported: migapi.Supported{
+					Actions:     []string{migapi.PvCopyAction},
+					CopyMethods: []string{migapi.PvFilesystemCopyMethod, migapi.PvSnapshotCopyMethod},
+				},
+				Selection: migapi.Selection{
+					Action:         migapi.PvCopyAction,
+					CopyMethod:     tt.copyMethod,
+					StorageClass:   "sc",
+					DirectSnapshot: tt.directSnapshot,
+				},
+				VolumeSnapshotClass: tt.volumeSnapshotClass,
+			}
+			if got := pv.IsDirectSnapshotCopy(); got != tt.wantDirect {
+				t.Errorf("IsDirectSnapshotCopy() = %v, want %v", got, tt.wantDirect)
+			}
+			plan := &migapi.MigPlan{
+				Spec: migapi.MigPlanSpec{
+					IndirectVolumeMigration: tt.indirect,
+					PersistentVolumes:       migapi.PersistentVolumes{List: []migapi.PV{pv}},
+				},
+				Status: migapi.MigPlanStatus{
+					DestStorageClasses: []migapi.StorageClass{
+						{Name: "sc", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+					},
+				},
+			}
+			r := ReconcileMigPlan{}
+			err := r.validatePvSelections(context.TODO(), plan)
+			if err != nil {
+				t.Fatalf("validatePvSelections() error = %v", err)
+			}
+			if got := plan.Status.HasCondition(PvInvalidDirectSnapshot); got != tt.wantInvalid {
+				t.Errorf("validatePvSelections() invalid direct snapshot condition = %v, want %v", got, tt.wantInvalid)
+			}
+			if got := plan.Status.HasCondition(PvWarnCopyMethodSnapshot); got != tt.wantWarnSnapshot {
+				t.Errorf("validatePvSelections() snapshot warning condition = %v, want %v", got, tt.wantWarnSnapshot)
+			}
+		})
+	}
+}
+
+func TestReconcileMigPlan_validatePvSelections_filters(t *testing.T) {
+	tests := []struct {
+		name        string
+		indirect    bool
+		volumeMode  corev1.PersistentVolumeMode
+		filters     *migapi.RsyncFilters
+		wantInvalid bool
+		wantIgnored bool
+	}{
+		{
+			name:    "valid filters copied with direct volume migration",
+			filters: &migapi.RsyncFilters{Exclude: []string{"lost+found/", "*.tmp"}},
+		},
+		{
+			name:        "invalid filters",
+			filters:     &migapi.RsyncFilters{Exclude: []string{"*.tmp'"}},
+			wantInvalid: true,
+		},
+		{
+			name:        "filters of a volume copied with indirect volume migration",
+			indirect:    true,
+			filters:     &migapi.RsyncFilters{Exclude: []string{"*.tmp"}},
+			wantIgnored: true,
+		},
+		{
+			name:        "filters of a block volume",
+			volumeMode:  corev1.PersistentVolumeBlock,
+			filters:     &migapi.RsyncFilters{Exclude: []string{"*.tmp"}},
+			wantIgnored: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := migapi.PV{
+				Name: "pv-0",
+				PVC:  migapi.PVC{Namespace: "test-ns", Name: "pvc-0", VolumeMode: tt.volumeMode},
+				Supported: migapi.Supported{
+					Actions:     []string{migapi.PvCopyAction},
+					CopyMethods: []string{migapi.PvFilesystemCopyMethod, migapi.PvSnapshotCopyMethod},
+				},
+				Selection: migapi.Selection{
+					Action:       migapi.PvCopyAction,
+					CopyMethod:   migapi.PvFilesystemCopyMethod,
+					StorageClass: "sc",
+					Filters:      tt.filters,
+				},
+			}
+			plan := &migapi.MigPlan{
+				Spec: migapi.MigPlanSpec{
+					IndirectVolumeMigration: tt.indirect,
+					PersistentVolumes:       migapi.PersistentVolumes{List: []migapi.PV{pv}},
+				},
+				Status: migapi.MigPlanStatus{
+					DestStorageClasses: []migapi.StorageClass{
+						{Name: "sc", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+					},
+				},
+			}
+			r := ReconcileMigPlan{}
+			err := r.validatePvSelections(context.TODO(), plan)
+			if err != nil {
+				t.Fatalf("validatePvSelections() error = %v", err)
+			}
+			if got := plan.Status.HasCondition(PvInvalidRsyncFilters); got != tt.wantInvalid {
+				t.Errorf("validatePvSelections() invalid filters condition = %v, want %v", got, tt.wantInvalid)
+			}
+			if got := plan.Status.HasCondition(PvWarnRsyncFiltersIgnored); got != tt.wantIgnored {
+				t.Errorf("validatePvSelections() ignored filters condition = %v, want %v", got, tt.wantIgnored)
+			}
+		})
+	}
+}
+
+func Test_setNetworkPreflightConditions(t *testing.T) {
+	tests := []struct {
+		name          string
+		dvm           *migapi.DirectVolumeMigration
+		wantRunning   bool
+		wantSucceeded bool
+		wantFailed    bool
+		wantError     bool
+	}{
+		{
+			name: "preflight running",
+			dvm: &migapi.DirectVolumeMigration{
+				Status: migapi.DirectVolumeMigrationStatus{Phase: "CreatePreflightClients"},
+			},
+			wantRunning: true,
+		},
+		{
+			name: "some nodes failed to connect",
+			dvm: &migapi.DirectVolumeMigration{
+				Status: migapi.DirectVolumeMigrationStatus{
+					Phase: "Completed",
+					PreflightResults: []*migapi.NetworkPreflightResult{
+						{Node: "node-a", Connected: true, Throughput: 50 * 1024 * 1024},
+						{Node: "node-b", Message: "timed out"},
+					},
+				},
+			},
+			wantSucceeded: true,
+			wantFailed:    true,
+		},
+		{
+			name: "preflight failed",
+			dvm: &migapi.DirectVolumeMigration{
+				Status: migapi.DirectVolumeMigrationStatus{
+					Phase:  "Completed",
+					Errors: []string{"endpoint not ready"},
+				},
+			},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &migapi.MigPlan{}
+			setNetworkPreflightConditions(plan, tt.dvm)
+			if got := plan.Status.HasCondition(NetworkPreflightRunning); got != tt.wantRunning {
+				t.Errorf("setNetworkPreflightConditions() running = %v, want %v", got, tt.wantRunning)
+			}
+			if got := plan.Status.HasCondition(NetworkPreflightSucceeded); got != tt.wantSucceeded {
+				t.Errorf("setNetworkPreflightConditions() succeeded = %v, want %v", got, tt.wantSucceeded)
+			}
+			if got := plan.Status.HasCondition(NetworkPreflightFailed); got != tt.wantFailed {
+				t.Errorf("setNetworkPreflightConditions() failed = %v, want %v", got, tt.wantFailed)
+			}
+			if got := plan.Status.HasCondition(NetworkPreflightError); got != tt.wantError {
+				t.Errorf("setNetworkPreflightConditions() error = %v, want %v", got, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestReconcileMigPlan_validate_storageConversion(t *testing.T) {
+	ready := migapi.Conditions{
+		List: []migapi.Condition{
+			{Type: "Ready", Status: "True", Category: "Required"},
+		},
+	}
+	cluster := &migapi.MigCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "migcluster-host", Namespace: "openshift-migration"},
+		Spec:       migapi.MigClusterSpec{ExposedRegistryPath: "registry.apps.example.com"},
+		Status:     migapi.MigClusterStatus{Conditions: ready},
+	}
+	storage := &migapi.MigStorage{
+		ObjectMeta: metav1.ObjectMeta{Name: "migstorage", Namespace: "openshift-migration"},
+		Status:     migapi.MigStorageStatus{Conditions: ready},
+	}
+	clusterRef := &corev1.ObjectReference{Name: "migcluster-host", Namespace: "openshift-migration"}
+	tests := []struct {
+		name              string
+		storageConversion bool
+		wantReady         bool
+	}{
+		{
+			name:              "storage conversion within the same cluster is ready",
+			storageConversion: true,
+			wantReady:         true,
+		},
+		{
+			name:              "migration to the same cluster is not distinct",
+			storageConversion: false,
+			wantReady:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &migapi.MigPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "migplan-conversion", Namespace: "openshift-migration"},
+				Spec: migapi.MigPlanSpec{
+					SrcMigClusterRef:  clusterRef,
+					DestMigClusterRef: clusterRef,
+					MigStorageRef:     &corev1.ObjectReference{Name: "migstorage", Namespace: "openshift-migration"},
+					Namespaces:        []string{"test-ns"},
+					StorageConversion: tt.storageConversion,
+				},
+			}
+			r := ReconcileMigPlan{Client: fake.NewFakeClient(cluster, storage)}
+			plan.Status.BeginStagingConditions()
+			for _, validate := range []func(context.Context, *migapi.MigPlan) error{
+				r.validateSourceCluster,
+				r.validateDestinationCluster,
+				r.validateStorage,
+				r.validateStorageConversion,
+			} {
+				if err := validate(context.TODO(), plan); err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+			}
+			plan.Status.SetCondition(migapi.Condition{Type: StorageEnsured, Status: True, Category: migapi.Required})
+			plan.Status.SetCondition(migapi.Condition{Type: PvsDiscovered, Status: True, Category: migapi.Required})
+			plan.Status.SetReady(
+				plan.Status.HasCondition(StorageEnsured, PvsDiscovered) &&
+					!plan.Status.HasBlockerCondition(),
+				"The migration plan is ready.")
+			plan.Status.EndStagingConditions()
+			if got := plan.Status.IsReady(); got != tt.wantReady {
+				t.Errorf("validate() ready = %v, want %v, conditions %v", got, tt.wantReady, plan.Status.Conditions.List)
+			}
+			if got := plan.Status.HasCondition(InvalidDestinationCluster); got == tt.wantReady {
+				t.Errorf("validate() not distinct condition = %v, want %v", got, !tt.wantReady)
+			}
+		})
+	}
+}
+
+func Test_getTemplateClaims(t *testing.T) {
+	statefulSets := []appsv1.StatefulSet{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "db"},
+			Spec: appsv1.StatefulSetSpec{
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+					{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "logs"}},
+				},
+			},
+		},
+	}
+	claims := []string{"data-db-0", "logs-db-12", "data-db-backup", "data-cache-0", "shared"}
+	want := []string{"data-db-0", "logs-db-12"}
+	if got := getTemplateClaims(statefulSets, claims); !reflect.DeepEqual(got, want) {
+		t.Errorf("getTemplateClaims() = %v, want %v", got, want)
+	}
+}
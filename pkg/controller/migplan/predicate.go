@@ -188,3 +188,23 @@ func (r MigrationPredicate) Update(e event.UpdateEvent) bool {
 
 	return false
 }
+
+type PreflightPredicate struct {
+	predicate.Funcs
+}
+
+func (r PreflightPredicate) Create(e event.CreateEvent) bool {
+	return false
+}
+
+func (r PreflightPredicate) Update(e event.UpdateEvent) bool {
+	old, cast := e.ObjectOld.(*migapi.DirectVolumeMigration)
+	if !cast {
+		return false
+	}
+	new, cast := e.ObjectNew.(*migapi.DirectVolumeMigration)
+	if !cast {
+		return false
+	}
+	return new.Spec.Preflight && old.Status.Phase != new.Status.Phase
+}
@@ -0,0 +1,184 @@
+package migplan
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	dvmc "github.com/konveyor/mig-controller/pkg/controller/directvolumemigration"
+	"github.com/opentracing/opentracing-go"
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Ensure the network preflight DVM of the plan exists and report its results.
+// The check runs once per plan, set Refresh on the plan to run it again.
+func (r *ReconcileMigPlan) ensureNetworkPreflight(ctx context.Context, plan *migapi.MigPlan) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "ensureNetworkPreflight")
+		defer span.Finish()
+	}
+	if !plan.Spec.NetworkPreflight {
+		return nil
+	}
+	dvm, err := r.getNetworkPreflight(plan)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if dvm != nil && plan.Spec.Refresh && dvm.Status.Phase == dvmc.Completed {
+		log.Info("Deleting completed network preflight DirectVolumeMigration to run the check again.",
+			"directVolumeMigration", path.Join(dvm.Namespace, dvm.Name))
+		err = r.Delete(context.TODO(), dvm)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		dvm = nil
+	}
+	if dvm == nil {
+		if !plan.Status.IsReady() || plan.Status.HasCondition(Suspended) {
+			return nil
+		}
+		dvm = buildNetworkPreflight(plan)
+		if dvm == nil {
+			return nil
+		}
+		log.Info("Creating network preflight DirectVolumeMigration.")
+		err = r.Create(context.TODO(), dvm)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	setNetworkPreflightConditions(plan, dvm)
+	return nil
+}
+
+// Get the network preflight DVM of the plan.
+func (r *ReconcileMigPlan) getNetworkPreflight(plan *migapi.MigPlan) (*migapi.DirectVolumeMigration, error) {
+	list := migapi.DirectVolumeMigrationList{}
+	err := r.List(
+		context.TODO(),
+		&list,
+		k8sclient.MatchingLabels(plan.GetCorrelationLabels()))
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range list.Items {
+		dvm := &list.Items[i]
+		if dvm.Spec.Preflight && dvm.DeletionTimestamp == nil {
+			return dvm, nil
+		}
+	}
+	return nil, nil
+}
+
+// Build the network preflight DVM of the volumes copied by direct volume migration.
+// Returns nil when the plan has no such volume.
+func buildNetworkPreflight(plan *migapi.MigPlan) *migapi.DirectVolumeMigration {
+	if plan.Spec.IndirectVolumeMigration {
+		return nil
+	}
+	nsMapping := plan.GetNamespaceMapping()
+	pvcList := []migapi.PVCToMigrate{}
+	for _, pv := range plan.Spec.PersistentVolumes.List {
+		if pv.Selection.Action != migapi.PvCopyAction ||
+			(pv.Selection.CopyMethod != migapi.PvFilesystemCopyMethod && !pv.IsDirectSnapshotCopy()) {
+			continue
+		}
+		volumeSnapshotClass := ""
+		if pv.IsDirectSnapshotCopy() {
+			volumeSnapshotClass = pv.VolumeSnapshotClass
+		}
+		pvcList = append(pvcList, migapi.PVCToMigrate{
+			ObjectReference: &kapi.ObjectReference{
+				Name:      pv.PVC.Name,
+				Namespace: pv.PVC.Namespace,
+			},
+			TargetNamespace:     nsMapping[pv.PVC.Namespace],
+			VolumeMode:          pv.PVC.VolumeMode,
+			VolumeSnapshotClass: volumeSnapshotClass,
+		})
+	}
+	if len(pvcList) == 0 {
+		return nil
+	}
+	dvm := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       plan.GetCorrelationLabels(),
+			GenerateName: plan.Name + "-preflight-",
+			Namespace:    migapi.OpenshiftMigrationNamespace,
+		},
+		Spec: migapi.DirectVolumeMigrationSpec{
+			SrcMigClusterRef:       plan.Spec.SrcMigClusterRef,
+			DestMigClusterRef:      plan.Spec.DestMigClusterRef,
+			PersistentVolumeClaims: pvcList,
+			TransferPodPolicy:      plan.Spec.TransferPodPolicy.DeepCopy(),
+			Preflight:              true,
+		},
+	}
+	migapi.SetOwnerReference(plan, plan, dvm)
+	return dvm
+}
+
+// Set the conditions of the plan reporting the network preflight results.
+func setNetworkPreflightConditions(plan *migapi.MigPlan, dvm *migapi.DirectVolumeMigration) {
+	if dvm.Status.Phase != dvmc.Completed {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     NetworkPreflightRunning,
+			Status:   True,
+			Reason:   NotDone,
+			Category: Advisory,
+			Message:  "The network preflight check between the source nodes and the destination cluster is running.",
+		})
+		return
+	}
+	if dvm.HasErrors() {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     NetworkPreflightError,
+			Status:   True,
+			Reason:   NotDone,
+			Category: Warn,
+			Message:  "The network preflight check could not be completed: [], set Refresh to run it again.",
+			Items:    dvm.Status.Errors,
+		})
+		return
+	}
+	succeeded := []string{}
+	failed := []string{}
+	for _, result := range dvm.Status.PreflightResults {
+		node := result.Node
+		if node == "" {
+			node = "scheduled node"
+		}
+		if result.Connected {
+			succeeded = append(succeeded,
+				fmt.Sprintf("%s at %.1f MiB/s", node, float64(result.Throughput)/(1024*1024)))
+			continue
+		}
+		failed = append(failed, fmt.Sprintf("%s: %s", node, strings.TrimSpace(result.Message)))
+	}
+	if len(succeeded) > 0 {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     NetworkPreflightSucceeded,
+			Status:   True,
+			Reason:   Done,
+			Category: Advisory,
+			Message:  "The source nodes [] reached the destination cluster through the direct volume migration transfer endpoint.",
+			Items:    succeeded,
+		})
+	}
+	if len(failed) > 0 {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     NetworkPreflightFailed,
+			Status:   True,
+			Reason:   NotHealthy,
+			Category: Warn,
+			Message: "The source nodes [] failed to reach the destination cluster through the direct volume" +
+				" migration transfer endpoint, direct volume migration is expected to fail.",
+			Items: failed,
+		})
+	}
+}
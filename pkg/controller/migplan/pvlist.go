@@ -90,6 +90,16 @@ func (r *ReconcileMigPlan) updatePvs(ctx context.Context, plan *migapi.MigPlan)
 	}
 	plan.Status.DestStorageClasses = destStorageClasses
 
+	// Get VolumeSnapshotClasses
+	// CSI snapshot copies are optional, discovery continues without them.
+	srcSnapshotClasses, err := srcMigCluster.GetVolumeSnapshotClasses(srcClient)
+	if err != nil {
+		log.Error(err, "PV Discovery: Failed to list VolumeSnapshotClasses of the source cluster, "+
+			"continuing without CSI snapshot copies.",
+			"migPlan", path.Join(plan.Namespace, plan.Name))
+		srcSnapshotClasses = nil
+	}
+
 	plan.Spec.BeginPvStaging()
 	if plan.IsResourceExcluded("persistentvolumeclaims") {
 		log.Info("PV Discovery: 'persistentvolumeclaims' found in MigPlan "+
@@ -137,9 +147,10 @@ func (r *ReconcileMigPlan) updatePvs(ctx context.Context, plan *migapi.MigPlan)
 					Actions:     r.getSupportedActions(pv, claim),
 					CopyMethods: r.getSupportedCopyMethods(pv),
 				},
-				Selection: selection,
-				PVC:       claim,
-				NFS:       pv.Spec.NFS,
+				Selection:           selection,
+				PVC:                 claim,
+				NFS:                 pv.Spec.NFS,
+				VolumeSnapshotClass: getVolumeSnapshotClassName(pv, srcSnapshotClasses),
 			})
 	}
 
@@ -261,12 +272,21 @@ func (r *ReconcileMigPlan) getClaims(client k8sclient.Client, plan *migapi.MigPl
 				Name:         pvc.Name,
 				AccessModes:  pvc.Spec.AccessModes,
 				HasReference: pvcInPodVolumes(pvc, podList),
+				VolumeMode:   getVolumeMode(pvc),
 			})
 	}
 
 	return claims, nil
 }
 
+// Gets the volume mode of the PVC, defaults to Filesystem.
+func getVolumeMode(pvc core.PersistentVolumeClaim) core.PersistentVolumeMode {
+	if pvc.Spec.VolumeMode == nil {
+		return core.PersistentVolumeFilesystem
+	}
+	return *pvc.Spec.VolumeMode
+}
+
 // Determine the supported PV actions.
 func (r *ReconcileMigPlan) getSupportedActions(pv core.PersistentVolume, claim migapi.PVC) []string {
 	supportedActions := []string{}
@@ -304,6 +324,27 @@ func (r *ReconcileMigPlan) getSupportedCopyMethods(pv core.PersistentVolume) []s
 	}
 }
 
+// Gets the name of the VolumeSnapshotClass able to snapshot a CSI PV.
+// The default class of the CSI driver is preferred.
+func getVolumeSnapshotClassName(pv core.PersistentVolume, snapshotClasses []migapi.VolumeSnapshotClass) string {
+	if pv.Spec.CSI == nil {
+		return ""
+	}
+	name := ""
+	for _, snapshotClass := range snapshotClasses {
+		if snapshotClass.Driver != pv.Spec.CSI.Driver {
+			continue
+		}
+		if snapshotClass.Default {
+			return snapshotClass.Name
+		}
+		if name == "" {
+			name = snapshotClass.Name
+		}
+	}
+	return name
+}
+
 // Gets the StorageClass name for the PV
 func getStorageClassName(pv core.PersistentVolume) string {
 	storageClassName := pv.Spec.StorageClassName
@@ -6,6 +6,8 @@ import (
 	"net"
 	"path"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	liberr "github.com/konveyor/controller/pkg/error"
@@ -16,6 +18,7 @@ import (
 	migref "github.com/konveyor/mig-controller/pkg/reference"
 	"github.com/konveyor/mig-controller/pkg/settings"
 	"github.com/opentracing/opentracing-go"
+	appsv1 "k8s.io/api/apps/v1"
 	kapi "k8s.io/api/core/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/fields"
@@ -65,6 +68,10 @@ const (
 	PvUsageAnalysisFailed                      = "PvUsageAnalysisFailed"
 	PvNoCopyMethodSelection                    = "PvNoCopyMethodSelection"
 	PvWarnCopyMethodSnapshot                   = "PvWarnCopyMethodSnapshot"
+	PvBlockRequiresDirectVolumeMigration       = "PvBlockRequiresDirectVolumeMigration"
+	PvInvalidRsyncFilters                      = "PvInvalidRsyncFilters"
+	PvWarnRsyncFiltersIgnored                  = "PvWarnRsyncFiltersIgnored"
+	PvInvalidDirectSnapshot                    = "PvInvalidDirectSnapshot"
 	NfsNotAccessible                           = "NfsNotAccessible"
 	NfsAccessCannotBeValidated                 = "NfsAccessCannotBeValidated"
 	PvLimitExceeded                            = "PvLimitExceeded"
@@ -81,6 +88,18 @@ const (
 	InvalidHookSAName                          = "InvalidHookSAName"
 	HookPhaseUnknown                           = "HookPhaseUnknown"
 	HookPhaseDuplicate                         = "HookPhaseDuplicate"
+	InvalidHookFailurePolicy                   = "InvalidHookFailurePolicy"
+	NetworkPreflightRunning                    = "NetworkPreflightRunning"
+	NetworkPreflightSucceeded                  = "NetworkPreflightSucceeded"
+	NetworkPreflightFailed                     = "NetworkPreflightFailed"
+	NetworkPreflightError                      = "NetworkPreflightError"
+	InvalidDirectVolumeTransferMethod          = "InvalidDirectVolumeTransferMethod"
+	InvalidBandwidthSchedule                   = "InvalidBandwidthSchedule"
+	InvalidStorageConversion                   = "InvalidStorageConversion"
+	StorageConversionTemplateClaims            = "StorageConversionTemplateClaims"
+	InvalidOwnershipPolicy                     = "InvalidOwnershipPolicy"
+	InvalidImageStreamTagFilter                = "InvalidImageStreamTagFilter"
+	InvalidDestImageRegistry                   = "InvalidDestImageRegistry"
 )
 
 // Categories
@@ -105,6 +124,7 @@ const (
 	NotHealthy            = "NotHealthy"
 	NodeSelectorsDetected = "NodeSelectorsDetected"
 	DuplicateNs           = "DuplicateNamespaces"
+	NotSupported          = "NotSupported"
 )
 
 // Statuses
@@ -172,6 +192,27 @@ func (r ReconcileMigPlan) validate(ctx context.Context, plan *migapi.MigPlan) er
 		return liberr.Wrap(err)
 	}
 
+	// Direct volume transfer method
+	r.validateDirectVolumeTransferMethod(plan)
+
+	// Direct volume bandwidth schedule
+	r.validateBandwidthSchedule(plan)
+
+	// Storage conversion
+	err = r.validateStorageConversion(ctx, plan)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+
+	// Direct volume ownership policy
+	r.validateOwnershipPolicy(plan)
+
+	// Direct image tag filter
+	r.validateImageStreamTagFilter(plan)
+
+	// Direct image destination registry
+	r.validateDestImageRegistry(plan)
+
 	// Validate health of Pods
 	err = r.validatePodHealth(ctx, plan)
 	if err != nil {
@@ -510,8 +551,8 @@ func (r ReconcileMigPlan) validateDestinationCluster(ctx context.Context, plan *
 		return nil
 	}
 
-	// NotDistinct
-	if reflect.DeepEqual(ref, plan.Spec.SrcMigClusterRef) {
+	// NotDistinct, a storage conversion migrates within the source cluster.
+	if !plan.IsStorageConversion() && reflect.DeepEqual(ref, plan.Spec.SrcMigClusterRef) {
 		plan.Status.SetCondition(migapi.Condition{
 			Type:     InvalidDestinationCluster,
 			Status:   True,
@@ -552,7 +593,10 @@ func (r ReconcileMigPlan) validateDestinationCluster(ctx context.Context, plan *
 		return nil
 	}
 
-	// No Registry Path
+	// No Registry Path, not used when images are copied to an image registry
+	if plan.Spec.DestImageRegistry != nil || cluster.Spec.ImageRegistry != nil {
+		return nil
+	}
 	registryPath, err := cluster.GetRegistryPath(r)
 	if !plan.Spec.IndirectImageMigration && (err != nil || registryPath == "") {
 		plan.Status.SetCondition(migapi.Condition{
@@ -816,6 +860,10 @@ func (r ReconcileMigPlan) validatePvSelections(ctx context.Context, plan *migapi
 	missingCopyMethod := make([]string, 0)
 	invalidCopyMethod := make([]string, 0)
 	warnCopyMethodSnapshot := make([]string, 0)
+	blockRequiresDirect := make([]string, 0)
+	invalidFilters := make([]string, 0)
+	ignoredFilters := make([]string, 0)
+	invalidDirectSnapshot := make([]string, 0)
 
 	if plan.Status.HasAnyCondition(Suspended) {
 		return nil
@@ -880,8 +928,29 @@ func (r ReconcileMigPlan) validatePvSelections(ctx context.Context, plan *migapi
 			if !found {
 				invalidCopyMethod = append(invalidCopyMethod, pv.Name)
 			} else if pv.Selection.CopyMethod == migapi.PvSnapshotCopyMethod {
-				// Warn if Snapshot is selected
-				warnCopyMethodSnapshot = append(warnCopyMethodSnapshot, pv.Name)
+				// Warn if Snapshot is selected, unless the CSI snapshot is copied by DVM
+				if plan.Spec.IndirectVolumeMigration || !pv.IsDirectSnapshotCopy() {
+					warnCopyMethodSnapshot = append(warnCopyMethodSnapshot, pv.Name)
+				}
+			} else if pv.PVC.IsBlock() && plan.Spec.IndirectVolumeMigration {
+				// Block volumes are copied as raw devices by DVM only
+				blockRequiresDirect = append(blockRequiresDirect, pv.Name)
+			}
+		}
+		// CSI snapshot copies are made by DVM from a VolumeSnapshot of the source volume
+		if pv.Selection.DirectSnapshot &&
+			(plan.Spec.IndirectVolumeMigration ||
+				pv.Selection.CopyMethod != migapi.PvSnapshotCopyMethod ||
+				pv.VolumeSnapshotClass == "") {
+			invalidDirectSnapshot = append(invalidDirectSnapshot, pv.Name)
+		}
+		// Include and exclude patterns only apply to the files copied by Rsync
+		if !pv.Selection.Filters.IsEmpty() {
+			if err := pv.Selection.Filters.Validate(); err != nil {
+				invalidFilters = append(invalidFilters, pv.Name)
+			} else if plan.Spec.IndirectVolumeMigration || pv.PVC.IsBlock() ||
+				(pv.Selection.CopyMethod != migapi.PvFilesystemCopyMethod && !pv.IsDirectSnapshotCopy()) {
+				ignoredFilters = append(ignoredFilters, pv.Name)
 			}
 		}
 
@@ -973,10 +1042,235 @@ func (r ReconcileMigPlan) validatePvSelections(ctx context.Context, plan *migapi
 			Items: warnCopyMethodSnapshot,
 		})
 	}
+	if len(blockRequiresDirect) > 0 {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     PvBlockRequiresDirectVolumeMigration,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Error,
+			Message: "PV in `persistentVolumes` [] is a block volume, `filesystem` copy of block volumes requires" +
+				" direct volume migration.",
+			Items: blockRequiresDirect,
+		})
+	}
+	if len(invalidFilters) > 0 {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     PvInvalidRsyncFilters,
+			Status:   True,
+			Category: Error,
+			Message:  "PV in `persistentVolumes` [] has invalid `Selected.Filters` patterns.",
+			Items:    invalidFilters,
+		})
+	}
+	if len(ignoredFilters) > 0 {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     PvWarnRsyncFiltersIgnored,
+			Status:   True,
+			Category: Warn,
+			Message: "`Selected.Filters` of PV in `persistentVolumes` [] are ignored, filters only apply to the" +
+				" filesystem volumes copied by direct volume migration.",
+			Items: ignoredFilters,
+		})
+	}
+	if len(invalidDirectSnapshot) > 0 {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     PvInvalidDirectSnapshot,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Error,
+			Message: "PV in `persistentVolumes` [] has `Selected.DirectSnapshot` set, CSI snapshot copies require" +
+				" direct volume migration, the `snapshot` copy method and a VolumeSnapshotClass for the CSI driver.",
+			Items: invalidDirectSnapshot,
+		})
+	}
+
+	return nil
+}
+
+// Validate the tool copying the data of direct volume migrations.
+func (r ReconcileMigPlan) validateDirectVolumeTransferMethod(plan *migapi.MigPlan) {
+	switch plan.Spec.DirectVolumeTransferMethod {
+	case "", migapi.RsyncTransferMethod, migapi.TransferAgentMethod:
+		return
+	}
+	plan.Status.SetCondition(migapi.Condition{
+		Type:     InvalidDirectVolumeTransferMethod,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message: fmt.Sprintf("`directVolumeTransferMethod` must be either %s or %s.",
+			migapi.RsyncTransferMethod, migapi.TransferAgentMethod),
+	})
+}
+
+// Validate the time windows limiting the bandwidth of direct volume migrations.
+func (r ReconcileMigPlan) validateBandwidthSchedule(plan *migapi.MigPlan) {
+	err := plan.Spec.BandwidthSchedule.Validate()
+	if err == nil {
+		return
+	}
+	plan.Status.SetCondition(migapi.Condition{
+		Type:     InvalidBandwidthSchedule,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message:  fmt.Sprintf("The `bandwidthSchedule` is invalid: %s.", err.Error()),
+	})
+}
+
+// Validate the filter selecting the ImageStream tags copied by direct image migrations.
+func (r ReconcileMigPlan) validateImageStreamTagFilter(plan *migapi.MigPlan) {
+	err := plan.Spec.ImageStreamTagFilter.Validate()
+	if err == nil {
+		return
+	}
+	plan.Status.SetCondition(migapi.Condition{
+		Type:     InvalidImageStreamTagFilter,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message:  fmt.Sprintf("The `imageStreamTagFilter` is invalid: %s.", err.Error()),
+	})
+}
+
+// Validate the image registry direct image migrations copy images to.
+func (r ReconcileMigPlan) validateDestImageRegistry(plan *migapi.MigPlan) {
+	err := plan.Spec.DestImageRegistry.Validate()
+	if err == nil {
+		return
+	}
+	plan.Status.SetCondition(migapi.Condition{
+		Type:     InvalidDestImageRegistry,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message:  fmt.Sprintf("The `destImageRegistry` is invalid: %s.", err.Error()),
+	})
+}
+
+// Validate that a storage conversion copies the PVCs within their own cluster and namespaces.
+func (r ReconcileMigPlan) validateStorageConversion(ctx context.Context, plan *migapi.MigPlan) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateStorageConversion")
+		defer span.Finish()
+	}
+	if !plan.IsStorageConversion() {
+		return nil
+	}
+	invalid := !migref.RefEquals(plan.Spec.SrcMigClusterRef, plan.Spec.DestMigClusterRef) ||
+		plan.Spec.IndirectVolumeMigration
+	for srcNs, destNs := range plan.GetNamespaceMapping() {
+		if srcNs != destNs {
+			invalid = true
+		}
+	}
+	if invalid {
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     InvalidStorageConversion,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: "A `storageConversion` requires the same source and destination cluster," +
+				" namespaces that are not mapped and direct volume migration.",
+		})
+		return nil
+	}
+	return r.validateStorageConversionClaims(plan)
+}
 
+// Validate the PVCs converted by a storage conversion are not created from the
+// volumeClaimTemplates of StatefulSets, which cannot reference the converted PVCs.
+func (r ReconcileMigPlan) validateStorageConversionClaims(plan *migapi.MigPlan) error {
+	claims := map[string][]string{}
+	for _, pv := range plan.Spec.PersistentVolumes.List {
+		if pv.Selection.Action != migapi.PvCopyAction ||
+			(pv.Selection.CopyMethod != migapi.PvFilesystemCopyMethod && !pv.IsDirectSnapshotCopy()) {
+			continue
+		}
+		claims[pv.PVC.Namespace] = append(claims[pv.PVC.Namespace], pv.PVC.Name)
+	}
+	if len(claims) == 0 {
+		return nil
+	}
+	cluster, err := plan.GetSourceCluster(r)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if cluster == nil || !cluster.Status.IsReady() {
+		return nil
+	}
+	client, err := cluster.GetClient(r)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	templateClaims := []string{}
+	for ns, names := range claims {
+		list := appsv1.StatefulSetList{}
+		err := client.List(context.TODO(), &list, k8sclient.InNamespace(ns))
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for _, name := range getTemplateClaims(list.Items, names) {
+			templateClaims = append(templateClaims, path.Join(ns, name))
+		}
+	}
+	if len(templateClaims) > 0 {
+		sort.Strings(templateClaims)
+		plan.Status.SetCondition(migapi.Condition{
+			Type:     StorageConversionTemplateClaims,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: "PVCs [] are created from the `volumeClaimTemplates` of StatefulSets, which cannot be" +
+				" converted by a `storageConversion`. Deselect them to convert the other PVCs.",
+			Items: templateClaims,
+		})
+	}
 	return nil
 }
 
+// Get the PVCs created from the volumeClaimTemplates of the StatefulSets.
+// A StatefulSet names those PVCs <template>-<statefulset>-<ordinal>.
+func getTemplateClaims(statefulSets []appsv1.StatefulSet, claims []string) []string {
+	templateClaims := []string{}
+	for _, claim := range claims {
+		for _, set := range statefulSets {
+			found := false
+			for _, template := range set.Spec.VolumeClaimTemplates {
+				prefix := template.Name + "-" + set.Name + "-"
+				if !strings.HasPrefix(claim, prefix) {
+					continue
+				}
+				if _, err := strconv.Atoi(strings.TrimPrefix(claim, prefix)); err == nil {
+					found = true
+					break
+				}
+			}
+			if found {
+				templateClaims = append(templateClaims, claim)
+				break
+			}
+		}
+	}
+	return templateClaims
+}
+
+// Validate the policy keeping the file ownership of direct volumes writable on the destination.
+func (r ReconcileMigPlan) validateOwnershipPolicy(plan *migapi.MigPlan) {
+	switch plan.Spec.OwnershipPolicy {
+	case "", migapi.PreserveOwnershipPolicy, migapi.RemapOwnershipPolicy:
+		return
+	}
+	plan.Status.SetCondition(migapi.Condition{
+		Type:     InvalidOwnershipPolicy,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message: fmt.Sprintf("`ownershipPolicy` must be either %s or %s.",
+			migapi.PreserveOwnershipPolicy, migapi.RemapOwnershipPolicy),
+	})
+}
+
 // Validate proxy secrets. Should only exist 1 or none
 func (r ReconcileMigPlan) validateRegistryProxySecrets(ctx context.Context, plan *migapi.MigPlan) error {
 	if opentracing.SpanFromContext(ctx) != nil {
@@ -1233,27 +1527,56 @@ func (r ReconcileMigPlan) validateHooks(ctx context.Context, plan *migapi.MigPla
 			return liberr.Wrap(err)
 		}
 
-		// InvalidHookSA
-		if errs := validation.IsDNS1123Subdomain(hook.ServiceAccount); len(errs) != 0 {
+		// Webhooks are executed by the controller and need no executor.
+		if !migHook.IsWebhook() {
+			// InvalidHookSA
+			if errs := validation.IsDNS1123Subdomain(hook.ServiceAccount); len(errs) != 0 {
+				plan.Status.SetCondition(migapi.Condition{
+					Type:     InvalidHookSAName,
+					Status:   True,
+					Reason:   NotSet,
+					Category: Critical,
+					Message: "The serviceAccount specified is invalid, DNS-1123 subdomain regex used for validation" +
+						" is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*'",
+				})
+			}
+
+			// InvalidHookNS
+			if errs := validation.IsDNS1123Label(hook.ExecutionNamespace); len(errs) != 0 {
+				plan.Status.SetCondition(migapi.Condition{
+					Type:     InvalidHookNSName,
+					Status:   True,
+					Reason:   NotSet,
+					Category: Critical,
+					Message: "The executionNamespace specified is invalid, DNS-1123 label regex used for validation" +
+						" is '[a-z0-9]([-a-z0-9]*[a-z0-9])?'.",
+				})
+			}
+		}
+
+		// InvalidHookFailurePolicy
+		switch hook.GetFailurePolicy() {
+		case migapi.HookFailurePolicyFail,
+			migapi.HookFailurePolicyIgnore,
+			migapi.HookFailurePolicyRollback:
+		default:
 			plan.Status.SetCondition(migapi.Condition{
-				Type:     InvalidHookSAName,
+				Type:     InvalidHookFailurePolicy,
 				Status:   True,
-				Reason:   NotSet,
+				Reason:   NotSupported,
 				Category: Critical,
-				Message: "The serviceAccount specified is invalid, DNS-1123 subdomain regex used for validation" +
-					" is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*'",
+				Message: fmt.Sprintf("The failurePolicy [%s] of the %s hook is not supported,"+
+					" acceptable values are: Fail, Ignore and Rollback.", hook.FailurePolicy, hook.Phase),
 			})
 		}
-
-		// InvalidHookNS
-		if errs := validation.IsDNS1123Label(hook.ExecutionNamespace); len(errs) != 0 {
+		if hook.Retries < 0 || hook.Retries > migapi.MaxHookRetries {
 			plan.Status.SetCondition(migapi.Condition{
-				Type:     InvalidHookNSName,
+				Type:     InvalidHookFailurePolicy,
 				Status:   True,
-				Reason:   NotSet,
+				Reason:   NotSupported,
 				Category: Critical,
-				Message: "The executionNamespace specified is invalid, DNS-1123 label regex used for validation" +
-					" is '[a-z0-9]([-a-z0-9]*[a-z0-9])?'.",
+				Message: fmt.Sprintf("The retries of the %s hook must be between 0 and %d.",
+					hook.Phase, migapi.MaxHookRetries),
 			})
 		}
 
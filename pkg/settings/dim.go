@@ -0,0 +1,29 @@
+package settings
+
+// DIM options
+const (
+	ImageCopyConcurrencyKey = "DIM_IMAGE_COPY_CONCURRENCY"
+	ImageCopyAttemptsKey    = "DIM_IMAGE_COPY_ATTEMPTS"
+)
+
+// DimOpts DIM settings
+//	ImageCopyConcurrency: number of images copied in parallel by each DirectImageStreamMigration
+//	ImageCopyAttempts: number of attempts to copy an image before it is reported as failed
+type DimOpts struct {
+	ImageCopyConcurrency int
+	ImageCopyAttempts    int
+}
+
+// Load load DIM options
+func (r *DimOpts) Load() error {
+	var err error
+	r.ImageCopyConcurrency, err = getEnvLimit(ImageCopyConcurrencyKey, 3)
+	if err != nil {
+		return err
+	}
+	r.ImageCopyAttempts, err = getEnvLimit(ImageCopyAttemptsKey, 5)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ const (
 	TCPProxyKey             = "STUNNEL_TCP_PROXY"
 	StunnelVerifyCAKey      = "STUNNEL_VERIFY_CA"
 	StunnelVerifyCALevelKey = "STUNNEL_VERIFY_CA_LEVEL"
+	TransferAgentStreamsKey = "TRANSFER_AGENT_STREAMS"
 )
 
 // RsyncOpts Rsync Options
@@ -47,6 +48,7 @@ type DvmOpts struct {
 	StunnelTCPProxy      string
 	StunnelVerifyCA      bool
 	StunnelVerifyCALevel string
+	TransferAgentStreams int
 }
 
 // Load load rsync options
@@ -85,6 +87,10 @@ func (r *DvmOpts) Load() error {
 	if r.StunnelVerifyCALevel == "" {
 		r.StunnelVerifyCALevel = "2"
 	}
+	r.TransferAgentStreams, err = getEnvLimit(TransferAgentStreamsKey, 4)
+	if err != nil {
+		return err
+	}
 	err = r.RsyncOpts.Load()
 	if err != nil {
 		return err
@@ -38,6 +38,7 @@ type _Settings struct {
 	Discovery
 	Plan
 	DvmOpts
+	DimOpts
 	DisImgCopy         bool
 	EnableCachedClient bool
 	JaegerOpts
@@ -59,6 +60,10 @@ func (r *_Settings) Load() error {
 	if err != nil {
 		return err
 	}
+	err = r.DimOpts.Load()
+	if err != nil {
+		return err
+	}
 	err = r.JaegerOpts.Load()
 	if err != nil {
 		return err
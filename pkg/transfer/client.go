@@ -0,0 +1,560 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultStreams number of parallel connections of a client
+	DefaultStreams = 4
+	// entryRetries number of attempts to transfer a file over a failing connection
+	entryRetries = 3
+)
+
+// Client sends a source volume to the server of the destination volume
+type Client struct {
+	// Source directory of the volume, or device file when Block is set
+	Source string
+	Block  bool
+	// Volume name of the destination volume on the server
+	Volume string
+	// Addresses host:port of the server, tried in order
+	Addresses []string
+	// Proxy optional HTTP proxy the connections are tunneled through
+	Proxy     *url.URL
+	TLSConfig *tls.Config
+	// Streams number of parallel connections
+	Streams int
+	// Checksum compares the checksums of the files whose size and modification time match
+	Checksum bool
+	// Delete deletes the files of the destination missing from the source
+	Delete bool
+	// Exclude returns whether a path relative to the source is excluded from the transfer
+	Exclude func(path string, dir bool) bool
+	// BandwidthLimit in bytes per second shared by the streams, 0 for no limit
+	BandwidthLimit int64
+	// ConnectTimeout time during which the connection to the server is retried
+	ConnectTimeout time.Duration
+	// ProgressInterval interval of the progress lines printed to Output
+	ProgressInterval time.Duration
+	Output           io.Writer
+}
+
+// entry file of the source volume
+type entry struct {
+	request
+	source string
+}
+
+// connection to the server
+type connection struct {
+	conn    net.Conn
+	writer  *bufio.Writer
+	encoder *gob.Encoder
+	decoder *gob.Decoder
+}
+
+// Run transfers the volume and prints the progress, the last line reports the result
+func (c *Client) Run(ctx context.Context) error {
+	progress := newTracker(c.Volume, c.Output)
+	err := c.run(ctx, progress)
+	if err != nil {
+		progress.print(ProgressFailed, err.Error())
+		return err
+	}
+	progress.print(ProgressCompleted, "")
+	return nil
+}
+
+func (c *Client) run(ctx context.Context, progress *tracker) error {
+	err := validateVolume(c.Volume)
+	if err != nil {
+		return err
+	}
+	files, dirs, keep, protect, err := c.walk()
+	if err != nil {
+		return err
+	}
+	progress.totalFiles = int64(len(files) + len(dirs))
+	for _, file := range files {
+		progress.totalBytes += file.Size
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go progress.run(c.ProgressInterval, done)
+
+	limit := newLimiter(c.BandwidthLimit)
+	streams := c.Streams
+	if streams <= 0 {
+		streams = DefaultStreams
+	}
+	queue := make(chan *entry)
+	errs := make(chan error, streams)
+	wg := sync.WaitGroup{}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for i := 0; i < streams; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.worker(ctx, queue, limit, progress)
+			if err != nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+	for _, file := range files {
+		select {
+		case queue <- file:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+	close(queue)
+	wg.Wait()
+	close(errs)
+	if err, found := <-errs; found {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return c.finish(ctx, dirs, keep, protect, progress)
+}
+
+// walk lists the files, the directories and the paths kept and protected by the delete pass
+func (c *Client) walk() ([]*entry, []*entry, []string, []string, error) {
+	if c.Block {
+		file, err := os.Open(c.Source)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		defer file.Close()
+		size, err := file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		block := &entry{
+			request: request{
+				Op:       OpBlock,
+				Path:     filepath.Base(c.Source),
+				Size:     size,
+				Checksum: true,
+			},
+			source: c.Source,
+		}
+		return []*entry{block}, nil, nil, nil, nil
+	}
+	files := []*entry{}
+	dirs := []*entry{}
+	keep := []string{}
+	protect := []string{}
+	err := filepath.Walk(c.Source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(c.Source, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if c.Exclude != nil && c.Exclude(rel, info.IsDir()) {
+			protect = append(protect, rel)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		e := &entry{
+			request: request{
+				Path:     rel,
+				Mode:     uint32(info.Mode()),
+				ModTime:  info.ModTime().Unix(),
+				Checksum: c.Checksum,
+			},
+			source: path,
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			e.UID = int(stat.Uid)
+			e.GID = int(stat.Gid)
+		}
+		switch {
+		case info.IsDir():
+			e.Op = OpDir
+			dirs = append(dirs, e)
+		case info.Mode().IsRegular():
+			e.Op = OpFile
+			e.Size = info.Size()
+			files = append(files, e)
+		case info.Mode()&os.ModeSymlink != 0:
+			e.Op = OpSymlink
+			e.Target, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			files = append(files, e)
+		default:
+			// devices, sockets and pipes are not transferred
+			return nil
+		}
+		keep = append(keep, rel)
+		return nil
+	})
+	return files, dirs, keep, protect, err
+}
+
+// worker transfers the files of the queue over its own connection
+func (c *Client) worker(ctx context.Context, queue <-chan *entry, limit *limiter, progress *tracker) error {
+	var conn *connection
+	defer func() {
+		if conn != nil {
+			conn.close()
+		}
+	}()
+	for e := range queue {
+		var err error
+		for attempt := 1; attempt <= entryRetries; attempt++ {
+			if conn == nil {
+				conn, err = c.connect(ctx, progress)
+				if err != nil {
+					return err
+				}
+			}
+			err = c.send(ctx, conn, e, limit, progress)
+			if err == nil {
+				break
+			}
+			if !retryable(err) || ctx.Err() != nil {
+				return err
+			}
+			// the connection is lost, the chunks received by the server are not sent again
+			conn.close()
+			conn = nil
+		}
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&progress.processedFiles, 1)
+	}
+	if conn != nil {
+		return conn.done()
+	}
+	return nil
+}
+
+// finish deletes the extraneous files and sets the attributes of the directories, deepest
+// first so that the modification times are not changed by their content
+func (c *Client) finish(ctx context.Context, dirs []*entry, keep []string, protect []string, progress *tracker) error {
+	conn, err := c.connect(ctx, progress)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+	if c.Delete && !c.Block {
+		result, err := conn.call(request{Op: OpDelete, Keep: keep, Protect: protect})
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&progress.deletedFiles, result.Deleted)
+	}
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i].Path, string(filepath.Separator)) >
+			strings.Count(dirs[j].Path, string(filepath.Separator))
+	})
+	for _, dir := range dirs {
+		_, err = conn.call(dir.request)
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&progress.processedFiles, 1)
+	}
+	return conn.done()
+}
+
+// send transfers a file, the chunks matching the destination are skipped
+func (c *Client) send(ctx context.Context, conn *connection, e *entry, limit *limiter, progress *tracker) error {
+	if e.Op == OpSymlink || e.Op == OpDir {
+		_, err := conn.call(e.request)
+		return err
+	}
+	file, err := os.Open(e.source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = conn.write(e.request)
+	if err != nil {
+		return err
+	}
+	state := fileState{}
+	err = conn.decoder.Decode(&state)
+	if err != nil {
+		return err
+	}
+	if state.Error != "" {
+		return &remoteError{path: e.Path, message: state.Error}
+	}
+	if !state.UpToDate {
+		sent, err := c.sendChunks(ctx, conn, file, e, state.Sums, limit, progress)
+		if err != nil {
+			return err
+		}
+		if sent {
+			atomic.AddInt64(&progress.transferredFiles, 1)
+		}
+	}
+	result := reply{}
+	err = conn.decoder.Decode(&result)
+	if err != nil {
+		return err
+	}
+	if result.Error != "" {
+		return &remoteError{path: e.Path, message: result.Error}
+	}
+	atomic.AddInt64(&progress.processedBytes, e.Size)
+	return nil
+}
+
+// sendChunks sends the chunks whose checksum differs from the destination followed by the
+// checksum of the file, returns whether a chunk was sent
+func (c *Client) sendChunks(ctx context.Context, conn *connection, file *os.File, e *entry, dest [][]byte, limit *limiter, progress *tracker) (bool, error) {
+	buf := make([]byte, ChunkSize)
+	sums := make([][]byte, 0, chunkCount(e.Size))
+	sent := false
+	for index := 0; index < chunkCount(e.Size); index++ {
+		offset := int64(index) * ChunkSize
+		length := int64(ChunkSize)
+		if e.Size-offset < length {
+			length = e.Size - offset
+		}
+		n, err := file.ReadAt(buf[:length], offset)
+		if err != nil && !(err == io.EOF && int64(n) == length) {
+			return sent, fmt.Errorf("failed to read %s: %v", e.source, err)
+		}
+		sum := chunkSum(buf[:n])
+		sums = append(sums, sum)
+		if index < len(dest) && string(dest[index]) == string(sum) {
+			continue
+		}
+		err = limit.wait(ctx, n)
+		if err != nil {
+			return sent, err
+		}
+		err = conn.encoder.Encode(chunk{Index: index, Sum: sum, Data: buf[:n]})
+		if err != nil {
+			return sent, err
+		}
+		sent = true
+		atomic.AddInt64(&progress.transferredBytes, int64(n))
+	}
+	err := conn.write(chunk{Index: -1, Sum: fileSum(sums)})
+	return sent, err
+}
+
+// connect opens a connection to the first server address answering, retried until the connect timeout
+func (c *Client) connect(ctx context.Context, progress *tracker) (*connection, error) {
+	deadline := time.Now().Add(c.ConnectTimeout)
+	var lastErr error
+	for {
+		for _, address := range c.Addresses {
+			conn, err := c.dial(ctx, address, progress)
+			if err == nil {
+				return conn, nil
+			}
+			if _, refused := err.(*remoteError); refused {
+				return nil, err
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			return nil, fmt.Errorf("no server address")
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to connect to the server: %v", lastErr)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (c *Client) dial(ctx context.Context, address string, progress *tracker) (*connection, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	var raw net.Conn
+	var err error
+	if c.Proxy != nil {
+		raw, err = dialProxy(ctx, dialer, c.Proxy, address)
+	} else {
+		raw, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	config := c.TLSConfig.Clone()
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		config.ServerName = host
+	}
+	conn := tls.Client(&countingConn{Conn: raw, progress: progress}, config)
+	_ = raw.SetDeadline(time.Now().Add(dialer.Timeout))
+	err = conn.Handshake()
+	if err != nil {
+		raw.Close()
+		return nil, err
+	}
+	writer := bufio.NewWriter(conn)
+	result := &connection{
+		conn:    conn,
+		writer:  writer,
+		encoder: gob.NewEncoder(writer),
+		decoder: gob.NewDecoder(bufio.NewReader(conn)),
+	}
+	err = result.write(hello{Version: ProtocolVersion, Volume: c.Volume})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	r := reply{}
+	err = result.decoder.Decode(&r)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if r.Error != "" {
+		conn.Close()
+		return nil, &remoteError{message: fmt.Sprintf("server refused volume %s: %s", c.Volume, r.Error)}
+	}
+	_ = raw.SetDeadline(time.Time{})
+	return result, nil
+}
+
+// dialProxy opens a tunnel to the address through an HTTP proxy
+func dialProxy(ctx context.Context, dialer *net.Dialer, proxy *url.URL, address string) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", proxy.Host)
+	if err != nil {
+		return nil, err
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	err = req.Write(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused the connection to %s: %s", proxy.Host, address, resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s sent unexpected data", proxy.Host)
+	}
+	return conn, nil
+}
+
+func (c *connection) write(message interface{}) error {
+	err := c.encoder.Encode(message)
+	if err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// call sends a request and reads its reply
+func (c *connection) call(req request) (reply, error) {
+	result := reply{}
+	err := c.write(req)
+	if err != nil {
+		return result, err
+	}
+	err = c.decoder.Decode(&result)
+	if err != nil {
+		return result, err
+	}
+	if result.Error != "" {
+		return result, &remoteError{path: req.Path, message: result.Error}
+	}
+	return result, nil
+}
+
+// done ends the session
+func (c *connection) done() error {
+	return c.write(request{Op: OpDone})
+}
+
+func (c *connection) close() {
+	_ = c.conn.Close()
+}
+
+// retryable returns whether an error is a failure of the connection
+func retryable(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// remoteError error of an operation reported by the server, the connection is still usable
+type remoteError struct {
+	path    string
+	message string
+}
+
+func (e *remoteError) Error() string {
+	if e.path == "" {
+		return e.message
+	}
+	return fmt.Sprintf("%s: %s", e.path, e.message)
+}
+
+// countingConn counts the bytes sent and received on the network
+type countingConn struct {
+	net.Conn
+	progress *tracker
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(&c.progress.receivedBytes, int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(&c.progress.sentBytes, int64(n))
+	return n, err
+}
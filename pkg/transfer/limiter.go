@@ -0,0 +1,49 @@
+package transfer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter paces the chunks sent by all the streams to a bandwidth limit in bytes per second
+type limiter struct {
+	mutex sync.Mutex
+	rate  int64
+	// next time a chunk can be sent
+	next time.Time
+}
+
+func newLimiter(rate int64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &limiter{rate: rate}
+}
+
+// wait blocks until n bytes can be sent
+func (l *limiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	l.mutex.Unlock()
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
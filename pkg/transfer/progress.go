@@ -0,0 +1,155 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Types of the progress lines
+const (
+	ProgressRunning   = "progress"
+	ProgressCompleted = "completed"
+	ProgressFailed    = "failed"
+)
+
+// Progress of a volume transfer printed by the client as a line of JSON
+type Progress struct {
+	Type             string `json:"type"`
+	Volume           string `json:"volume"`
+	TotalFiles       int64  `json:"totalFiles"`
+	ProcessedFiles   int64  `json:"processedFiles"`
+	TransferredFiles int64  `json:"transferredFiles"`
+	DeletedFiles     int64  `json:"deletedFiles"`
+	TotalBytes       int64  `json:"totalBytes"`
+	ProcessedBytes   int64  `json:"processedBytes"`
+	// TransferredBytes bytes of the chunks sent, chunks already present on the destination are not sent
+	TransferredBytes int64 `json:"transferredBytes"`
+	// SentBytes and ReceivedBytes bytes sent and received on the network
+	SentBytes      int64 `json:"sentBytes"`
+	ReceivedBytes  int64 `json:"receivedBytes"`
+	Percent        int64 `json:"percent"`
+	BytesPerSecond int64 `json:"bytesPerSecond"`
+	ElapsedSeconds int64 `json:"elapsedSeconds"`
+	// EstimatedSecondsRemaining unknown until bytes are processed
+	EstimatedSecondsRemaining *int64 `json:"estimatedSecondsRemaining,omitempty"`
+	Message                   string `json:"message,omitempty"`
+}
+
+// ParseProgress returns the last progress line of the logs of a client, nil when there is none
+func ParseProgress(logs string) *Progress {
+	var last *Progress
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		progress := &Progress{}
+		if json.Unmarshal([]byte(line), progress) != nil || progress.Type == "" {
+			continue
+		}
+		last = progress
+	}
+	return last
+}
+
+// tracker counts the progress of a transfer
+type tracker struct {
+	volume           string
+	started          time.Time
+	totalFiles       int64
+	totalBytes       int64
+	processedFiles   int64
+	processedBytes   int64
+	transferredFiles int64
+	transferredBytes int64
+	deletedFiles     int64
+	sentBytes        int64
+	receivedBytes    int64
+	output           io.Writer
+	mutex            sync.Mutex
+}
+
+func newTracker(volume string, output io.Writer) *tracker {
+	return &tracker{
+		volume:  volume,
+		started: time.Now(),
+		output:  output,
+	}
+}
+
+func (t *tracker) snapshot(kind string, message string) Progress {
+	p := Progress{
+		Type:             kind,
+		Volume:           t.volume,
+		TotalFiles:       atomic.LoadInt64(&t.totalFiles),
+		ProcessedFiles:   atomic.LoadInt64(&t.processedFiles),
+		TransferredFiles: atomic.LoadInt64(&t.transferredFiles),
+		DeletedFiles:     atomic.LoadInt64(&t.deletedFiles),
+		TotalBytes:       atomic.LoadInt64(&t.totalBytes),
+		ProcessedBytes:   atomic.LoadInt64(&t.processedBytes),
+		TransferredBytes: atomic.LoadInt64(&t.transferredBytes),
+		SentBytes:        atomic.LoadInt64(&t.sentBytes),
+		ReceivedBytes:    atomic.LoadInt64(&t.receivedBytes),
+		Message:          message,
+	}
+	elapsed := time.Since(t.started)
+	p.ElapsedSeconds = int64(elapsed.Seconds())
+	if p.TotalBytes > 0 {
+		p.Percent = p.ProcessedBytes * 100 / p.TotalBytes
+	} else if p.TotalFiles > 0 {
+		p.Percent = p.ProcessedFiles * 100 / p.TotalFiles
+	}
+	if kind == ProgressCompleted {
+		p.Percent = 100
+	}
+	if elapsed >= time.Second {
+		p.BytesPerSecond = int64(float64(p.TransferredBytes) / elapsed.Seconds())
+		if p.ProcessedBytes > 0 && kind == ProgressRunning {
+			rate := float64(p.ProcessedBytes) / elapsed.Seconds()
+			remaining := int64(float64(p.TotalBytes-p.ProcessedBytes) / rate)
+			if remaining < 0 {
+				remaining = 0
+			}
+			p.EstimatedSecondsRemaining = &remaining
+		}
+	}
+	return p
+}
+
+// print writes a progress line to the output
+func (t *tracker) print(kind string, message string) {
+	if t.output == nil {
+		return
+	}
+	line, err := json.Marshal(t.snapshot(kind, message))
+	if err != nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	_, _ = t.output.Write(append(line, '\n'))
+}
+
+// run prints the progress periodically until the channel is closed
+func (t *tracker) run(interval time.Duration, done <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			t.print(ProgressRunning, "")
+		}
+	}
+}
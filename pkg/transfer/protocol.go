@@ -0,0 +1,126 @@
+// Package transfer implements the transfer agent copying the data of direct volume migrations.
+// The client walks a source volume and streams the files to the server of the destination
+// volume over parallel mutual TLS connections. Files are sent in chunks identified by their
+// SHA-256 checksum, chunks already present on the destination are skipped which makes an
+// interrupted transfer resumable and repeated transfers incremental.
+package transfer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ProtocolVersion version of the protocol spoken by the client and the server
+	ProtocolVersion = 1
+	// DefaultPort port the server listens on, the port of the Stunnel server of Rsync transfers
+	DefaultPort = 2222
+	// ChunkSize size of the chunks files are transferred in
+	ChunkSize = 1024 * 1024
+)
+
+// Operations requested by the client
+const (
+	// OpFile create or update a regular file
+	OpFile = "file"
+	// OpBlock update the content of an existing block device
+	OpBlock = "block"
+	// OpDir create a directory and set its attributes
+	OpDir = "dir"
+	// OpSymlink create a symbolic link
+	OpSymlink = "symlink"
+	// OpDelete delete the files of the destination volume missing from the source volume
+	OpDelete = "delete"
+	// OpDone end of the session
+	OpDone = "done"
+)
+
+// hello first message sent by the client on each connection
+type hello struct {
+	Version int
+	// Volume name of the destination volume, a directory of the root of the server
+	Volume string
+}
+
+// request operation requested by the client
+type request struct {
+	Op      string
+	Path    string
+	Mode    uint32
+	ModTime int64
+	UID     int
+	GID     int
+	Size    int64
+	// Target of a symbolic link
+	Target string
+	// Checksum requests the chunk checksums even when size and modification time match
+	Checksum bool
+	// Keep paths of the files kept by a delete operation
+	Keep []string
+	// Protect path prefixes never deleted by a delete operation, the paths excluded from the transfer
+	Protect []string
+}
+
+// fileState state of the destination file answered to a file or block request
+type fileState struct {
+	// UpToDate the size and the modification time match, no chunk is sent
+	UpToDate bool
+	// Sums checksums of the chunks of the destination file
+	Sums  [][]byte
+	Error string
+}
+
+// chunk content of a file, the last message of a file has Index -1 and carries the file checksum
+type chunk struct {
+	Index int
+	Sum   []byte
+	Data  []byte
+}
+
+// reply result of an operation
+type reply struct {
+	Error   string
+	Deleted int64
+}
+
+// fileSum returns the checksum of a file, the checksum of the list of the checksums of its chunks
+func fileSum(sums [][]byte) []byte {
+	h := sha256.New()
+	for _, sum := range sums {
+		h.Write(sum)
+	}
+	return h.Sum(nil)
+}
+
+// chunkSum returns the checksum of a chunk
+func chunkSum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// chunkCount returns the number of chunks of a file
+func chunkCount(size int64) int {
+	return int((size + ChunkSize - 1) / ChunkSize)
+}
+
+// validateVolume returns an error when the volume name is not a single path element
+func validateVolume(volume string) error {
+	if volume == "" || volume == "." || volume == ".." || strings.ContainsAny(volume, `/\`) {
+		return fmt.Errorf("invalid volume name %q", volume)
+	}
+	return nil
+}
+
+// validatePath returns an error when the path is not relative to the volume
+func validatePath(path string) error {
+	if path == "" || filepath.IsAbs(path) {
+		return fmt.Errorf("invalid path %q", path)
+	}
+	clean := filepath.Clean(path)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("path %q escapes the volume", path)
+	}
+	return nil
+}
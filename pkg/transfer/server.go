@@ -0,0 +1,467 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Server receives the volumes sent by the clients, each volume is a directory of the root
+type Server struct {
+	// Root directory holding the destination volumes
+	Root      string
+	TLSConfig *tls.Config
+	Log       *log.Logger
+}
+
+// ListenAndServe listens on the address and serves the clients
+func (s *Server) ListenAndServe(address string) error {
+	listener, err := tls.Listen("tcp", address, s.TLSConfig)
+	if err != nil {
+		return err
+	}
+	s.Log.Printf("listening on %s", address)
+	return s.Serve(listener)
+}
+
+// Serve serves the clients of a TLS listener
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// session state of a client connection
+type session struct {
+	server  *Server
+	root    string
+	encoder *gob.Encoder
+	decoder *gob.Decoder
+	writer  *bufio.Writer
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	remote := conn.RemoteAddr().String()
+	writer := bufio.NewWriter(conn)
+	ss := &session{
+		server:  s,
+		encoder: gob.NewEncoder(writer),
+		decoder: gob.NewDecoder(bufio.NewReader(conn)),
+		writer:  writer,
+	}
+	h := hello{}
+	err := ss.decoder.Decode(&h)
+	if err != nil {
+		s.Log.Printf("%s: failed to read hello: %v", remote, err)
+		return
+	}
+	err = ss.open(h)
+	if err != nil {
+		s.Log.Printf("%s: %v", remote, err)
+		_ = ss.send(reply{Error: err.Error()})
+		return
+	}
+	err = ss.send(reply{})
+	if err != nil {
+		return
+	}
+	s.Log.Printf("%s: receiving volume %s", remote, h.Volume)
+	for {
+		req := request{}
+		err = ss.decoder.Decode(&req)
+		if err != nil {
+			if err != io.EOF {
+				s.Log.Printf("%s: connection lost: %v", remote, err)
+			}
+			return
+		}
+		if req.Op == OpDone {
+			return
+		}
+		err = ss.handleRequest(req)
+		if err != nil {
+			// errors of the protocol end the session, errors of an operation are sent to the client
+			s.Log.Printf("%s: %v", remote, err)
+			return
+		}
+	}
+}
+
+// open checks the version of the client and the destination volume
+func (ss *session) open(h hello) error {
+	if h.Version != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d, expected %d", h.Version, ProtocolVersion)
+	}
+	err := validateVolume(h.Volume)
+	if err != nil {
+		return err
+	}
+	ss.root = filepath.Join(ss.server.Root, h.Volume)
+	info, err := os.Stat(ss.root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("volume %s is not a directory", h.Volume)
+	}
+	return nil
+}
+
+func (ss *session) send(message interface{}) error {
+	err := ss.encoder.Encode(message)
+	if err != nil {
+		return err
+	}
+	return ss.writer.Flush()
+}
+
+func (ss *session) handleRequest(req request) error {
+	var err error
+	result := reply{}
+	switch req.Op {
+	case OpFile, OpBlock:
+		// the chunks follow the file state, the reply is sent once they are received
+		return ss.receiveFile(req)
+	case OpDir:
+		err = ss.makeDir(req)
+	case OpSymlink:
+		err = ss.makeSymlink(req)
+	case OpDelete:
+		result.Deleted, err = ss.deleteExtraneous(req)
+	default:
+		return fmt.Errorf("unknown operation %q", req.Op)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return ss.send(result)
+}
+
+// resolve returns the destination path of a file of the volume. Parents that are not
+// directories are removed, a symbolic link could lead the file out of the volume.
+func (ss *session) resolve(path string) (string, error) {
+	err := validatePath(path)
+	if err != nil {
+		return "", err
+	}
+	clean := filepath.Clean(path)
+	parent := ss.root
+	components := strings.Split(clean, string(filepath.Separator))
+	for _, component := range components[:len(components)-1] {
+		parent = filepath.Join(parent, component)
+		info, err := os.Lstat(parent)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			// a file or a symbolic link replaced by a directory on the source
+			err = os.Remove(parent)
+			if err != nil {
+				return "", err
+			}
+			break
+		}
+	}
+	return filepath.Join(ss.root, clean), nil
+}
+
+// receiveFile answers the checksums of the destination chunks and writes the chunks sent by the client
+func (ss *session) receiveFile(req request) error {
+	file, sums, upToDate, err := ss.openFile(req)
+	if err != nil {
+		return ss.send(fileState{Error: err.Error()})
+	}
+	if upToDate {
+		file.Close()
+		err = ss.send(fileState{UpToDate: true})
+		if err != nil {
+			return err
+		}
+		return ss.send(ss.resultOf(ss.setAttributes(req, false)))
+	}
+	defer file.Close()
+	err = ss.send(fileState{Sums: sums})
+	if err != nil {
+		return err
+	}
+	received := make([][]byte, chunkCount(req.Size))
+	var writeErr error
+	for {
+		c := chunk{}
+		err = ss.decoder.Decode(&c)
+		if err != nil {
+			return err
+		}
+		if c.Index == -1 {
+			if writeErr == nil {
+				writeErr = ss.completeFile(file, req, sums, received, c.Sum)
+			}
+			break
+		}
+		// keep reading the chunks after a failure to stay in sync with the client
+		if writeErr != nil {
+			continue
+		}
+		writeErr = writeChunk(file, req.Size, c)
+		if writeErr == nil {
+			received[c.Index] = c.Sum
+		}
+	}
+	if writeErr == nil {
+		writeErr = ss.setAttributes(req, false)
+	}
+	return ss.send(ss.resultOf(writeErr))
+}
+
+func (ss *session) resultOf(err error) reply {
+	if err != nil {
+		return reply{Error: err.Error()}
+	}
+	return reply{}
+}
+
+// openFile opens the destination file and returns the checksums of its chunks,
+// or whether the file is up to date when its size and modification time match
+func (ss *session) openFile(req request) (*os.File, [][]byte, bool, error) {
+	path, err := ss.resolve(req.Path)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var file *os.File
+	if req.Op == OpBlock {
+		file, err = os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	} else {
+		info, err := os.Lstat(path)
+		switch {
+		case err == nil && !info.Mode().IsRegular():
+			err = os.RemoveAll(path)
+			if err != nil {
+				return nil, nil, false, err
+			}
+		case err == nil && !req.Checksum && info.Size() == req.Size && info.ModTime().Unix() == req.ModTime:
+			return nil, nil, true, nil
+		case err != nil && !os.IsNotExist(err):
+			return nil, nil, false, err
+		}
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
+	sums, err := readSums(file, req.Size)
+	if err != nil {
+		file.Close()
+		return nil, nil, false, err
+	}
+	return file, sums, false, nil
+}
+
+// readSums returns the checksums of the chunks of the first size bytes of a file
+func readSums(file *os.File, size int64) ([][]byte, error) {
+	sums := [][]byte{}
+	buf := make([]byte, ChunkSize)
+	for offset := int64(0); offset < size; offset += ChunkSize {
+		length := int64(ChunkSize)
+		if size-offset < length {
+			length = size - offset
+		}
+		n, err := file.ReadAt(buf[:length], offset)
+		if err == io.EOF && n > 0 {
+			// the last chunk of the destination is partial
+			sums = append(sums, chunkSum(buf[:n]))
+			break
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sums = append(sums, chunkSum(buf[:n]))
+	}
+	return sums, nil
+}
+
+// writeChunk writes a chunk after checking its checksum and its bounds
+func writeChunk(file *os.File, size int64, c chunk) error {
+	offset := int64(c.Index) * ChunkSize
+	if c.Index < 0 || offset >= size {
+		return fmt.Errorf("chunk %d out of the bounds of %s", c.Index, file.Name())
+	}
+	expected := int64(ChunkSize)
+	if size-offset < expected {
+		expected = size - offset
+	}
+	if int64(len(c.Data)) != expected {
+		return fmt.Errorf("chunk %d of %s has %d bytes, expected %d", c.Index, file.Name(), len(c.Data), expected)
+	}
+	if !bytes.Equal(chunkSum(c.Data), c.Sum) {
+		return fmt.Errorf("checksum mismatch of chunk %d of %s", c.Index, file.Name())
+	}
+	_, err := file.WriteAt(c.Data, offset)
+	return err
+}
+
+// completeFile truncates a regular file to its size and verifies the checksum of the file
+func (ss *session) completeFile(file *os.File, req request, existing [][]byte, received [][]byte, sum []byte) error {
+	if req.Op == OpFile {
+		err := file.Truncate(req.Size)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range received {
+		if received[i] != nil {
+			continue
+		}
+		// chunks not sent matched the destination
+		if i >= len(existing) {
+			return fmt.Errorf("chunk %d of %s was not received", i, file.Name())
+		}
+		received[i] = existing[i]
+	}
+	if !bytes.Equal(fileSum(received), sum) {
+		return fmt.Errorf("checksum mismatch of %s", file.Name())
+	}
+	return nil
+}
+
+func (ss *session) makeDir(req request) error {
+	path, err := ss.resolve(req.Path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if err == nil && !info.IsDir() {
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+	}
+	err = os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+	return ss.setAttributes(req, false)
+}
+
+func (ss *session) makeSymlink(req request) error {
+	path, err := ss.resolve(req.Path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if err == nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, _ := os.Readlink(path); target == req.Target {
+				return ss.setAttributes(req, true)
+			}
+		}
+		err = os.RemoveAll(path)
+		if err != nil {
+			return err
+		}
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	err = os.Symlink(req.Target, path)
+	if err != nil {
+		return err
+	}
+	return ss.setAttributes(req, true)
+}
+
+// setAttributes sets the owner, the permissions and the modification time of a file.
+// The owner is only set when the server runs as root, block devices keep their attributes.
+func (ss *session) setAttributes(req request, symlink bool) error {
+	if req.Op == OpBlock {
+		return nil
+	}
+	path := filepath.Join(ss.root, filepath.Clean(req.Path))
+	if os.Geteuid() == 0 {
+		err := os.Lchown(path, req.UID, req.GID)
+		if err != nil {
+			return err
+		}
+	}
+	if symlink {
+		return nil
+	}
+	mode := os.FileMode(req.Mode) & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	err := os.Chmod(path, mode)
+	if err != nil {
+		return err
+	}
+	modTime := time.Unix(req.ModTime, 0)
+	return os.Chtimes(path, modTime, modTime)
+}
+
+// deleteExtraneous deletes the files of the volume that are neither kept nor protected
+func (ss *session) deleteExtraneous(req request) (int64, error) {
+	keep := make(map[string]bool, len(req.Keep))
+	for _, path := range req.Keep {
+		keep[filepath.Clean(path)] = true
+	}
+	protected := func(path string) bool {
+		for _, prefix := range req.Protect {
+			prefix = filepath.Clean(prefix)
+			if path == prefix || strings.HasPrefix(path, prefix+string(filepath.Separator)) {
+				return true
+			}
+		}
+		// the lost+found directory of the destination file system is never deleted
+		return path == "lost+found"
+	}
+	deleted := int64(0)
+	err := filepath.Walk(ss.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(ss.root, path)
+		if err != nil || rel == "." || keep[rel] {
+			return err
+		}
+		if protected(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		err = os.RemoveAll(path)
+		if err != nil {
+			return err
+		}
+		deleted++
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return deleted, err
+}
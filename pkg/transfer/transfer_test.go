@@ -0,0 +1,289 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCerts writes a CA and a certificate signed by it, usable by the server and the client
+func writeTestCerts(t *testing.T, dir string) Certs {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs := Certs{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	files := map[string][]byte{
+		certs.CertFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		certs.KeyFile:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		certs.CAFile:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+	}
+	for path, content := range files {
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return certs
+}
+
+// startTestServer starts a server and returns a client of the volume sending the source directory
+func startTestServer(t *testing.T, source string, root string) *Client {
+	certs := writeTestCerts(t, t.TempDir())
+	serverConfig, err := certs.ServerTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConfig, err := certs.ClientTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	server := &Server{Root: root, TLSConfig: serverConfig, Log: log.New(ioutil.Discard, "", 0)}
+	go server.Serve(listener)
+	return &Client{
+		Source:         source,
+		Volume:         "volume",
+		Addresses:      []string{listener.Addr().String()},
+		TLSConfig:      clientConfig,
+		Streams:        2,
+		ConnectTimeout: time.Second,
+		Output:         &bytes.Buffer{},
+	}
+}
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkTestFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		got, err := ioutil.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			t.Errorf("failed to read %s: %v", path, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("content of %s differs, got %d bytes, want %d bytes", path, len(got), len(content))
+		}
+	}
+}
+
+func TestClient_Run(t *testing.T) {
+	source := t.TempDir()
+	root := t.TempDir()
+	dest := filepath.Join(root, "volume")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	large := strings.Repeat("0123456789abcdef", 3*ChunkSize/16+100)
+	files := map[string]string{
+		"a.txt":         "a",
+		"dir/b.txt":     "b",
+		"dir/sub/large": large,
+		"empty":         "",
+	}
+	writeTestFiles(t, source, files)
+	if err := os.Symlink("dir/b.txt", filepath.Join(source, "link")); err != nil {
+		t.Fatal(err)
+	}
+	client := startTestServer(t, source, root)
+
+	// first transfer
+	if err := client.Run(context.TODO()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	checkTestFiles(t, dest, files)
+	if target, err := os.Readlink(filepath.Join(dest, "link")); err != nil || target != "dir/b.txt" {
+		t.Errorf("link target = %q, %v", target, err)
+	}
+	progress := ParseProgress(client.Output.(*bytes.Buffer).String())
+	if progress == nil || progress.Type != ProgressCompleted || progress.TransferredBytes != int64(len(large)+2) {
+		t.Fatalf("ParseProgress() = %+v", progress)
+	}
+
+	// incremental transfer sends the changed chunk only
+	changed := "X" + large[1:]
+	files["dir/sub/large"] = changed
+	writeTestFiles(t, source, map[string]string{"dir/sub/large": changed})
+	modTime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(source, "dir/sub/large"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	client.Output = &bytes.Buffer{}
+	if err := client.Run(context.TODO()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	checkTestFiles(t, dest, files)
+	progress = ParseProgress(client.Output.(*bytes.Buffer).String())
+	if progress == nil || progress.TransferredBytes != ChunkSize || progress.TransferredFiles != 1 {
+		t.Fatalf("ParseProgress() = %+v", progress)
+	}
+}
+
+func TestClient_RunDelete(t *testing.T) {
+	source := t.TempDir()
+	root := t.TempDir()
+	dest := filepath.Join(root, "volume")
+	writeTestFiles(t, source, map[string]string{"keep": "k", "cache/data": "c"})
+	writeTestFiles(t, dest, map[string]string{
+		"keep":            "old",
+		"extra":           "e",
+		"old/file":        "o",
+		"cache/dest":      "d",
+		"lost+found/file": "l",
+	})
+	client := startTestServer(t, source, root)
+	client.Delete = true
+	client.Exclude = func(path string, dir bool) bool {
+		return path == "cache" && dir
+	}
+	if err := client.Run(context.TODO()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	checkTestFiles(t, dest, map[string]string{"keep": "k", "cache/dest": "d", "lost+found/file": "l"})
+	for _, path := range []string{"extra", "old", "cache/data"} {
+		if _, err := os.Lstat(filepath.Join(dest, path)); !os.IsNotExist(err) {
+			t.Errorf("%s exists, want deleted", path)
+		}
+	}
+	progress := ParseProgress(client.Output.(*bytes.Buffer).String())
+	if progress == nil || progress.DeletedFiles != 2 {
+		t.Fatalf("ParseProgress() = %+v", progress)
+	}
+}
+
+func TestClient_RunMissingVolume(t *testing.T) {
+	client := startTestServer(t, t.TempDir(), t.TempDir())
+	if err := client.Run(context.TODO()); err == nil {
+		t.Fatal("Run() error = nil, want missing volume error")
+	}
+	progress := ParseProgress(client.Output.(*bytes.Buffer).String())
+	if progress == nil || progress.Type != ProgressFailed || progress.Message == "" {
+		t.Fatalf("ParseProgress() = %+v", progress)
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		name        string
+		logs        string
+		wantNil     bool
+		wantType    string
+		wantPercent int64
+	}{
+		{
+			name:    "no progress line",
+			logs:    "listening on :2222\n",
+			wantNil: true,
+		},
+		{
+			name:        "last line wins",
+			logs:        `{"type":"progress","percent":10}` + "\n" + `{"type":"progress","percent":42}` + "\n",
+			wantType:    ProgressRunning,
+			wantPercent: 42,
+		},
+		{
+			name:        "truncated line ignored",
+			logs:        `{"type":"completed","percent":100}` + "\n" + `{"type":"progr`,
+			wantType:    ProgressCompleted,
+			wantPercent: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseProgress(tt.logs)
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("ParseProgress() = %v, want nil %v", got, tt.wantNil)
+			}
+			if got != nil && (got.Type != tt.wantType || got.Percent != tt.wantPercent) {
+				t.Errorf("ParseProgress() = %+v", got)
+			}
+		})
+	}
+}
+
+func Test_validatePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "a/b", wantErr: false},
+		{path: "a/../b", wantErr: false},
+		{path: "", wantErr: true},
+		{path: "/etc/passwd", wantErr: true},
+		{path: "../b", wantErr: true},
+		{path: "a/../../b", wantErr: true},
+		{path: ".", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if err := validatePath(tt.path); (err != nil) != tt.wantErr {
+				t.Errorf("validatePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}